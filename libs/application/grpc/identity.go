@@ -0,0 +1,15 @@
+package grpc
+
+import (
+	"distributed-analyzer/libs/config"
+	"log"
+)
+
+// IdentityKey returns the key the identities of users are signed with. Without
+// one, identities are accepted unsigned, which it warns about.
+func IdentityKey(cfg configloader.IdentityConfig) []byte {
+	if cfg.SigningKey == "" {
+		log.Println("No identity signing key is configured, identities of users are not signed")
+	}
+	return []byte(cfg.SigningKey)
+}
@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"distributed-analyzer/libs/config"
+	"distributed-analyzer/libs/network/ratelimit"
+)
+
+// NewServerLimiter creates the limiter enforcing the gRPC limits of a config
+// on the requests of a server. If the limits are disabled, it lets every request through.
+func NewServerLimiter(cfg configloader.GrpcLimitConfig) *ratelimit.GRPCLimiter {
+	if !cfg.Enabled {
+		return ratelimit.NewGRPCLimiter(ratelimit.GRPCConfig{})
+	}
+
+	limits := make(map[string]ratelimit.Limit, len(cfg.Methods))
+	for method, limit := range cfg.Methods {
+		limits[method] = ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+
+	return ratelimit.NewGRPCLimiter(ratelimit.GRPCConfig{
+		Rate:            cfg.Rate,
+		Burst:           cfg.Burst,
+		ExcludedMethods: cfg.ExcludedMethods,
+		MethodLimits:    limits,
+		MaxInFlight:     cfg.MaxInFlight,
+	})
+}
@@ -30,3 +30,28 @@ type ServiceConnectionConfig struct {
 	URL      string `yaml:"url"       env:"SERVICE_URL"`
 	GRPCAddr string `yaml:"grpc_addr" env:"SERVICE_GRPC_ADDR"`
 }
+
+// IdentityConfig holds the key the gateway signs the identities of users with,
+// which backend services verify. Without a key, identities are accepted as
+// given, which is only safe if no one outside the cluster reaches the services.
+type IdentityConfig struct {
+	SigningKey string `yaml:"signing_key" env:"IDENTITY_SIGNING_KEY"`
+}
+
+// GrpcLimitConfig holds the limits a gRPC server enforces on its callers.
+// Every caller has a token bucket per method, with the limit of the method or
+// Rate and Burst. Unary requests beyond MaxInFlight are shed, 0 disables it.
+type GrpcLimitConfig struct {
+	Enabled         bool                       `yaml:"enabled"          env:"GRPC_LIMIT_ENABLED"          env-default:"true"`
+	Rate            float64                    `yaml:"rate"             env:"GRPC_LIMIT_RATE"             env-default:"100"`
+	Burst           int                        `yaml:"burst"            env:"GRPC_LIMIT_BURST"            env-default:"200"`
+	MaxInFlight     int                        `yaml:"max_in_flight"    env:"GRPC_LIMIT_MAX_IN_FLIGHT"    env-default:"256"`
+	ExcludedMethods []string                   `yaml:"excluded_methods" env:"GRPC_LIMIT_EXCLUDED_METHODS"`
+	Methods         map[string]GrpcMethodLimit `yaml:"methods"`
+}
+
+// GrpcMethodLimit is the number of requests per second and the burst of a method
+type GrpcMethodLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
@@ -0,0 +1,53 @@
+package kafka
+
+import (
+	"context"
+	"crypto/rand"
+	"distributed-analyzer/libs/network/identity"
+	pb "distributed-analyzer/libs/proto/kafka"
+	"encoding/hex"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// AuditTopic is the topic the audit service consumes AuditEvents from
+const AuditTopic = "audit"
+
+// AuditPublisher publishes the actions a service performs as AuditEvents
+type AuditPublisher struct {
+	producer *Producer
+	service  string
+}
+
+// NewAuditPublisher creates an AuditPublisher for the events of a service
+func NewAuditPublisher(producer *Producer, service string) *AuditPublisher {
+	return &AuditPublisher{producer: producer, service: service}
+}
+
+// Publish publishes an action on a resource, performed by the user whose
+// identity ctx carries. Actions of other services carry no user.
+func (p *AuditPublisher) Publish(ctx context.Context, action, resource, resourceID string, details map[string]string) error {
+	event := &pb.AuditEvent{
+		EventId:    newEventID(),
+		Service:    p.service,
+		Action:     action,
+		Resource:   resource,
+		ResourceId: resourceID,
+		Details:    details,
+		Timestamp:  timestamppb.New(time.Now()),
+	}
+	if id, ok := identity.FromContext(ctx); ok {
+		event.UserId, event.TenantId = id.UserID, id.TenantID
+	}
+
+	return p.producer.PublishEvent(ctx, AuditTopic, resource+"/"+resourceID, event)
+}
+
+// newEventID returns a random identifier of an event
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,30 @@
+package kafka
+
+import "strings"
+
+// TaskAssignedTopic returns the topic subtasks are assigned on. Subtasks that
+// need a specific Go toolchain go to a topic only workers with it consume.
+func TaskAssignedTopic(toolchain string) string {
+	if toolchain == "" {
+		return "task-assigned"
+	}
+	return "task-assigned.go" + toolchain
+}
+
+// WorkerTaskAssignedTopic returns the topic only the worker with the given ID
+// consumes, used for subtasks that should run where the cache is warm
+func WorkerTaskAssignedTopic(workerID string) string {
+	return "task-assigned.worker." + topicName(workerID)
+}
+
+// topicName replaces the characters Kafka does not allow in topic names
+func topicName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,12 @@
+package model
+
+// Artifact is a file a subtask produced, such as a binary, a coverage profile
+// or its full output, kept in the storage service next to the result
+type Artifact struct {
+	SubTaskID   string `json:"subtask_id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Digest      string `json:"digest"`
+	StorageKey  string `json:"storage_key"`
+}
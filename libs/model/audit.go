@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Actions of audit records
+const (
+	AuditActionCreate = "create"
+	AuditActionRead   = "read"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionCancel = "cancel"
+	AuditActionCordon = "cordon"
+	AuditActionGrant  = "grant"
+	AuditActionRevoke = "revoke"
+)
+
+// AuditRecord is an entry of the audit log. Hash covers all other fields,
+// including the hash of the previous entry.
+type AuditRecord struct {
+	Sequence   int64             `json:"sequence"`
+	ID         string            `json:"id"`
+	Time       time.Time         `json:"time"`
+	Service    string            `json:"service,omitempty"`
+	UserID     string            `json:"user_id,omitempty"`
+	TenantID   string            `json:"tenant_id,omitempty"`
+	Action     string            `json:"action"`
+	Resource   string            `json:"resource"`
+	ResourceID string            `json:"resource_id,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	PrevHash   string            `json:"prev_hash"`
+	Hash       string            `json:"hash"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// BillingRecord is a charge of an account, for a task or as a manual adjustment
+type BillingRecord struct {
+	ID       string        `json:"id"`
+	UserID   string        `json:"user_id"`
+	TenantID string        `json:"tenant_id,omitempty"`
+	TaskID   string        `json:"task_id,omitempty"`
+	Amount   float64       `json:"amount"`
+	Currency string        `json:"currency"`
+	Usage    ResourceUsage `json:"usage"`
+	Time     time.Time     `json:"timestamp"`
+
+	// Adjustment marks records created by hand rather than for the usage of a task
+	Adjustment bool `json:"adjustment,omitempty"`
+}
+
+// Balance is the credit left on an account. Accounts belong to a tenant,
+// shared by its users, or to a user without a tenant.
+type Balance struct {
+	UserID    string    `json:"user_id,omitempty"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	Balance   float64   `json:"balance"`
+	Currency  string    `json:"currency"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
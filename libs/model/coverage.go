@@ -0,0 +1,41 @@
+package model
+
+// PackageCoverage represents the statement coverage of a package
+type PackageCoverage struct {
+	Package    string  `json:"package"`
+	Statements int64   `json:"statements"`
+	Covered    int64   `json:"covered"`
+	Percent    float64 `json:"percent"`
+}
+
+// FunctionCoverage represents the statement coverage of a function
+type FunctionCoverage struct {
+	File       string  `json:"file"`
+	Function   string  `json:"function"`
+	Line       int     `json:"line"`
+	Statements int64   `json:"statements"`
+	Covered    int64   `json:"covered"`
+	Percent    float64 `json:"percent"`
+}
+
+// CoverageReport represents the coverage merged across all subtasks of a task
+type CoverageReport struct {
+	TaskID    string             `json:"task_id"`
+	Mode      string             `json:"mode"`
+	Percent   float64            `json:"percent"`
+	Packages  []PackageCoverage  `json:"packages"`
+	Functions []FunctionCoverage `json:"functions"`
+	Profile   string             `json:"profile,omitempty"`
+}
+
+// FunctionExtent locates a function declaration in a source file.
+// Workers report extents alongside coverage profiles so that coverage
+// can be attributed to functions without access to the sources.
+type FunctionExtent struct {
+	File      string `json:"file"` // import path qualified, as in coverage profiles
+	Function  string `json:"function"`
+	StartLine int    `json:"start_line"`
+	StartCol  int    `json:"start_col"`
+	EndLine   int    `json:"end_line"`
+	EndCol    int    `json:"end_col"`
+}
@@ -0,0 +1,47 @@
+package model
+
+// Module represents a module in the build list of the analyzed main module
+type Module struct {
+	Path     string  `json:"path"`
+	Version  string  `json:"version,omitempty"`
+	Main     bool    `json:"main,omitempty"`
+	Indirect bool    `json:"indirect,omitempty"`
+	Replace  *Module `json:"replace,omitempty"`
+}
+
+// ModuleEdge represents a requirement of one module version on another, as path@version
+type ModuleEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ModuleGraph represents the module requirement graph of the analyzed main module
+type ModuleGraph struct {
+	Modules []Module     `json:"modules"`
+	Edges   []ModuleEdge `json:"edges"`
+}
+
+// VulnerablePackage represents a package of a vulnerable module and how the main module uses it
+type VulnerablePackage struct {
+	Path string `json:"path"`
+
+	// Symbols lists the vulnerable symbols; empty means the whole package is affected
+	Symbols []string `json:"symbols,omitempty"`
+
+	// Imported reports whether the package is a dependency of the main module
+	Imported bool `json:"imported"`
+
+	// ReachedSymbols lists the vulnerable symbols the main module references directly
+	ReachedSymbols []string `json:"reached_symbols,omitempty"`
+}
+
+// Vulnerability represents an OSV entry that affects a module of the build list
+type Vulnerability struct {
+	ID           string              `json:"id"`
+	Aliases      []string            `json:"aliases,omitempty"`
+	Summary      string              `json:"summary,omitempty"`
+	Module       string              `json:"module"`
+	Version      string              `json:"version"`
+	FixedVersion string              `json:"fixed_version,omitempty"`
+	Packages     []VulnerablePackage `json:"packages,omitempty"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// FuzzCrasher represents an input that made a fuzz target fail
+type FuzzCrasher struct {
+	Target   string       `json:"target"`
+	Package  string       `json:"package"`
+	Error    string       `json:"error"`
+	Stack    []StackFrame `json:"stack,omitempty"`
+	Input    []byte       `json:"input"`
+	InputKey string       `json:"input_key,omitempty"` // storage key of the input
+}
+
+// FuzzCrasherRecord represents a distinct fuzzing failure, deduplicated across workers and runs.
+// Reproducer is the smallest crashing input seen so far.
+type FuzzCrasherRecord struct {
+	Signature     string      `json:"signature"`
+	Reproducer    FuzzCrasher `json:"reproducer"`
+	Count         int         `json:"count"`
+	FirstSeenTask string      `json:"first_seen_task"`
+	LastSeenTask  string      `json:"last_seen_task"`
+	FirstSeenAt   time.Time   `json:"first_seen_at"`
+	LastSeenAt    time.Time   `json:"last_seen_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+// UnknownLicense is the license of a module whose license could not be classified
+const UnknownLicense = "unknown"
+
+// ModuleLicense represents the license of a dependency module
+type ModuleLicense struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+
+	// License is an SPDX identifier such as MIT or Apache-2.0, or UnknownLicense
+	License string `json:"license"`
+
+	// File is the license file the license was classified from
+	File string `json:"file,omitempty"`
+
+	// Violation reports whether the license breaks the allow/deny policy of the task
+	Violation bool `json:"violation,omitempty"`
+}
+
+// LicenseReport represents the licenses of every dependency of a module and the policy they were checked against
+type LicenseReport struct {
+	Modules    []ModuleLicense `json:"modules"`
+	Allow      []string        `json:"allow,omitempty"`
+	Deny       []string        `json:"deny,omitempty"`
+	Violations int             `json:"violations"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// Output streams of the commands a subtask runs
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+)
+
+// LogChunk is a piece of the output of the commands a subtask runs
+type LogChunk struct {
+	TaskID    string    `json:"task_id"`
+	SubTaskID string    `json:"subtask_id"`
+	WorkerID  string    `json:"worker_id"`
+	Stream    string    `json:"stream"`
+	Sequence  int64     `json:"sequence"`
+	Data      []byte    `json:"data"`
+	WrittenAt time.Time `json:"written_at"`
+}
@@ -0,0 +1,36 @@
+package model
+
+// MatrixCell represents one combination of a build matrix
+type MatrixCell struct {
+	GoVersion string `json:"go_version,omitempty"`
+	GOOS      string `json:"goos,omitempty"`
+	GOARCH    string `json:"goarch,omitempty"`
+	Tags      string `json:"tags,omitempty"`
+}
+
+// Platform returns the GOOS/GOARCH pair of the cell
+func (c MatrixCell) Platform() string {
+	if c.GOOS == "" && c.GOARCH == "" {
+		return ""
+	}
+	return c.GOOS + "/" + c.GOARCH
+}
+
+// MatrixCellResult represents the outcome of the subtask that ran a matrix cell
+type MatrixCellResult struct {
+	MatrixCell
+	SubTaskID string `json:"subtask_id"`
+	Passed    bool   `json:"passed"`
+	ExitCode  string `json:"exit_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// MatrixSummary represents the outcome of every cell of a build matrix
+type MatrixSummary struct {
+	GoVersions []string           `json:"go_versions"`
+	Platforms  []string           `json:"platforms"`
+	TagSets    []string           `json:"tag_sets"`
+	Cells      []MatrixCellResult `json:"cells"`
+	Passed     int                `json:"passed"`
+	Failed     int                `json:"failed"`
+}
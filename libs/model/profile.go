@@ -0,0 +1,33 @@
+package model
+
+// Kinds of profiles test and benchmark runs can collect
+const (
+	ProfileCPU   = "cpu"
+	ProfileMem   = "mem"
+	ProfileBlock = "block"
+	ProfileMutex = "mutex"
+	ProfileTrace = "trace"
+)
+
+// Hotspot represents the cost of a function in a profile. Flat is spent in
+// the function itself, Cum in the function and everything it calls.
+type Hotspot struct {
+	Function    string  `json:"function"`
+	File        string  `json:"file,omitempty"`
+	Flat        int64   `json:"flat"`
+	FlatPercent float64 `json:"flat_percent"`
+	Cum         int64   `json:"cum"`
+	CumPercent  float64 `json:"cum_percent"`
+}
+
+// ProfileHotspots represents the top functions of a profile merged across all subtasks of a task
+type ProfileHotspots struct {
+	TaskID      string    `json:"task_id"`
+	Kind        string    `json:"kind"`
+	SampleType  string    `json:"sample_type"`
+	Unit        string    `json:"unit"`
+	SampleTypes []string  `json:"sample_types"`
+	Profiles    int       `json:"profiles"`
+	Total       int64     `json:"total"`
+	Hotspots    []Hotspot `json:"hotspots"`
+}
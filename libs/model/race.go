@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// StackFrame represents a single frame of a goroutine stack
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// RaceAccess represents one of the conflicting memory accesses of a data race
+type RaceAccess struct {
+	Operation string       `json:"operation"` // e.g. "Write", "Previous read"
+	Goroutine int          `json:"goroutine"`
+	Stack     []StackFrame `json:"stack"`
+}
+
+// RaceGoroutine represents the creation site of a goroutine involved in a data race
+type RaceGoroutine struct {
+	ID    int          `json:"id"`
+	State string       `json:"state"`
+	Stack []StackFrame `json:"stack"`
+}
+
+// RaceReport represents a single WARNING: DATA RACE block of the race detector
+type RaceReport struct {
+	Test       string          `json:"test,omitempty"`
+	Accesses   []RaceAccess    `json:"accesses"`
+	Goroutines []RaceGoroutine `json:"goroutines,omitempty"`
+}
+
+// RaceRecord represents a distinct data race, deduplicated across shards and runs
+type RaceRecord struct {
+	Signature     string     `json:"signature"`
+	Report        RaceReport `json:"report"`
+	Tests         []string   `json:"tests,omitempty"`
+	Count         int        `json:"count"`
+	FirstSeenTask string     `json:"first_seen_task"`
+	LastSeenTask  string     `json:"last_seen_task"`
+	FirstSeenAt   time.Time  `json:"first_seen_at"`
+	LastSeenAt    time.Time  `json:"last_seen_at"`
+}
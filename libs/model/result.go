@@ -9,6 +9,8 @@ type TaskResult struct {
 	TaskID     string            `json:"task_id"`
 	Status     string            `json:"status"`
 	Result     map[string]string `json:"result,omitempty"`
+	OwnerID    string            `json:"owner_id,omitempty"`
+	TenantID   string            `json:"tenant_id,omitempty"`
 	CreatedAt  time.Time         `json:"created_at"`
 	UpdatedAt  time.Time         `json:"updated_at"`
 	FinishedAt time.Time         `json:"finished_at,omitempty"`
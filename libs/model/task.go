@@ -25,6 +25,8 @@ type Task struct {
 	Input       map[string]string `json:"input,omitempty"`
 	Output      map[string]string `json:"output,omitempty"`
 	Resources   []Resource        `json:"resources,omitempty"`
+	OwnerID     string            `json:"owner_id,omitempty"`
+	TenantID    string            `json:"tenant_id,omitempty"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 	CompletedAt time.Time         `json:"completed_at,omitempty"`
@@ -39,6 +41,9 @@ type SubTask struct {
 	Input     map[string]string `json:"input,omitempty"`
 	Output    map[string]string `json:"output,omitempty"`
 	WorkerID  string            `json:"worker_id,omitempty"`
+	OwnerID   string            `json:"owner_id,omitempty"`
+	TenantID  string            `json:"tenant_id,omitempty"`
+	Usage     ResourceUsage     `json:"usage"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
 }
@@ -0,0 +1,21 @@
+package model
+
+// ResourceUsage is the measured usage of the commands a subtask ran, or of
+// all subtasks of a task
+type ResourceUsage struct {
+	// CPUSeconds is the user and system CPU time of the commands
+	CPUSeconds float64 `json:"cpu_seconds"`
+
+	// MemoryGBSeconds is the peak memory of every command in GiB times its run time
+	MemoryGBSeconds float64 `json:"memory_gb_seconds"`
+
+	// MaxMemoryBytes is the highest peak memory of a command
+	MaxMemoryBytes int64 `json:"max_memory_bytes"`
+}
+
+// Add adds the usage of other commands or subtasks
+func (u *ResourceUsage) Add(other ResourceUsage) {
+	u.CPUSeconds += other.CPUSeconds
+	u.MemoryGBSeconds += other.MemoryGBSeconds
+	u.MaxMemoryBytes = max(u.MaxMemoryBytes, other.MaxMemoryBytes)
+}
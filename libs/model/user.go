@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Roles of users, named like the UserRole values of user.proto
+const (
+	RoleAdmin  = "ROLE_ADMIN"
+	RoleUser   = "ROLE_USER"
+	RoleWorker = "ROLE_WORKER"
+)
+
+// User represents a user of the system
+type User struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Roles     []string  `json:"roles"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserPermission represents a permission granted to a user on a resource
+type UserPermission struct {
+	UserID     string    `json:"user_id"`
+	Resource   string    `json:"resource"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// APIKey describes a non-interactive credential of a user, e.g. for CI bots.
+// Zero times are unset: the key does not expire, was not used or is not revoked.
+type APIKey struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at,omitzero"`
+	LastUsedAt time.Time `json:"last_used_at,omitzero"`
+	RevokedAt  time.Time `json:"revoked_at,omitzero"`
+}
@@ -18,4 +18,10 @@ type Worker struct {
 	Capabilities []Capability `json:"capabilities"`
 	Resources    []Resource   `json:"resources"`
 	LastSeen     time.Time    `json:"last_seen"`
+
+	// QueuedTasks is the number of tasks waiting in the worker's local queue
+	QueuedTasks int `json:"queued_tasks"`
+
+	// WarmRepositories lists the repositories the worker's go cache is warm for
+	WarmRepositories []string `json:"warm_repositories"`
 }
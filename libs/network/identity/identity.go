@@ -0,0 +1,208 @@
+// Package identity carries the identity of the user behind a request through
+// contexts and gRPC metadata. The gateway authenticates users and attaches
+// their identity to its calls, signed with a key it shares with the backend
+// services, which reject identities without a valid signature. Calls without
+// an identity are calls of other services and are trusted, so the gRPC ports
+// of backend services must only be reachable from inside the cluster.
+package identity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"time"
+)
+
+// Metadata keys of the identity
+const (
+	userIDKey    = "x-user-id"
+	usernameKey  = "x-username"
+	rolesKey     = "x-user-roles"
+	tenantIDKey  = "x-tenant-id"
+	issuedKey    = "x-identity-issued"
+	signatureKey = "x-identity-signature"
+)
+
+// maxAge bounds how long after it was signed an identity is accepted, so that
+// captured metadata cannot be replayed for long
+const maxAge = 5 * time.Minute
+
+// adminRole is the role of user.proto that may access the resources of every tenant
+const adminRole = "ROLE_ADMIN"
+
+// ErrInvalidSignature is returned for identities whose signature is missing, wrong or expired
+var ErrInvalidSignature = errors.New("invalid identity signature")
+
+// Identity is an authenticated user
+type Identity struct {
+	UserID   string
+	Username string
+
+	// TenantID is the team the user belongs to, empty if none
+	TenantID string
+
+	// Roles holds the names of the roles of the user, e.g. ROLE_ADMIN
+	Roles []string
+}
+
+// HasRole reports whether the user has a role
+func (i *Identity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether the user may access the resources of every tenant
+func (i *Identity) IsAdmin() bool {
+	return i.HasRole(adminRole)
+}
+
+// CanAccess reports whether the user may access a resource of an owner in a
+// tenant. Admins access every resource, members of a tenant the resources of
+// their tenant, and users without a tenant their own resources.
+func (i *Identity) CanAccess(ownerID, tenantID string) bool {
+	if i.IsAdmin() {
+		return true
+	}
+	if i.TenantID != "" {
+		return tenantID == i.TenantID
+	}
+	return tenantID == "" && ownerID == i.UserID
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the identity stored in ctx, if any
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(contextKey{}).(*Identity)
+	return id, ok
+}
+
+// NewOutgoingContext returns a copy of ctx carrying id, which gRPC calls made
+// with it pass on in their metadata, signed with key unless it is empty
+func NewOutgoingContext(ctx context.Context, id *Identity, key []byte) context.Context {
+	md := metadata.Pairs(userIDKey, id.UserID, usernameKey, id.Username, tenantIDKey, id.TenantID)
+	md.Append(rolesKey, id.Roles...)
+	if len(key) > 0 {
+		issued := strconv.FormatInt(time.Now().Unix(), 10)
+		md.Append(issuedKey, issued)
+		md.Append(signatureKey, sign(key, id, issued))
+	}
+	if existing, ok := metadata.FromOutgoingContext(ctx); ok {
+		md = metadata.Join(existing, md)
+	}
+	return metadata.NewOutgoingContext(NewContext(ctx, id), md)
+}
+
+// FromIncomingContext returns the identity in the metadata of an incoming gRPC
+// call, if any. Unless key is empty, the identity must be signed with it.
+func FromIncomingContext(ctx context.Context, key []byte) (*Identity, bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, false, nil
+	}
+
+	userIDs := md.Get(userIDKey)
+	if len(userIDs) == 0 || userIDs[0] == "" {
+		return nil, false, nil
+	}
+
+	id := &Identity{UserID: userIDs[0], Roles: md.Get(rolesKey), Username: first(md, usernameKey), TenantID: first(md, tenantIDKey)}
+	if len(key) > 0 {
+		if err := verify(key, id, first(md, issuedKey), first(md, signatureKey)); err != nil {
+			return nil, false, err
+		}
+	}
+	return id, true, nil
+}
+
+// first returns the first value of a metadata key, or an empty string
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// sign returns the signature of an identity issued at a time in Unix seconds
+func sign(key []byte, id *Identity, issued string) string {
+	mac := hmac.New(sha256.New, key)
+	// Every field is prefixed with its length, so that fields cannot run into each other
+	for _, field := range append([]string{issued, id.UserID, id.Username, id.TenantID}, id.Roles...) {
+		fmt.Fprintf(mac, "%d:%s", len(field), field)
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature of an identity and that it was issued recently
+func verify(key []byte, id *Identity, issued, signature string) error {
+	if !hmac.Equal([]byte(sign(key, id, issued)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	seconds, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > maxAge || age < -maxAge {
+		return fmt.Errorf("%w: issued %s ago", ErrInvalidSignature, age.Round(time.Second))
+	}
+	return nil
+}
+
+// ServerInterceptor stores the identity in the metadata of incoming unary
+// calls in their context, where FromContext finds it. Calls with an identity
+// that is not signed with key are rejected, unless key is empty.
+func ServerInterceptor(key []byte) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id, ok, err := FromIncomingContext(ctx, key)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if ok {
+			ctx = NewContext(ctx, id)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor stores the identity in the metadata of incoming
+// streaming calls in their context, where FromContext finds it. Calls with an
+// identity that is not signed with key are rejected, unless key is empty.
+func StreamServerInterceptor(key []byte) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id, ok, err := FromIncomingContext(ss.Context(), key)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		if ok {
+			ss = &identityStream{ServerStream: ss, ctx: NewContext(ss.Context(), id)}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// identityStream is a server stream whose context carries an identity
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,44 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// incoming turns the metadata of an outgoing context into that of an incoming call
+func incoming(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestSignedIdentity(t *testing.T) {
+	key := []byte("key")
+	alice := &Identity{UserID: "alice", Username: "alice", TenantID: "team-a", Roles: []string{"ROLE_USER"}}
+
+	id, ok, err := FromIncomingContext(incoming(NewOutgoingContext(context.Background(), alice, key)), key)
+	if err != nil || !ok || id.UserID != "alice" || id.TenantID != "team-a" {
+		t.Fatalf("FromIncomingContext() = %+v, %v, %v, expected alice", id, ok, err)
+	}
+
+	// A caller adding the admin role to the signed metadata
+	forged := incoming(NewOutgoingContext(context.Background(), alice, key))
+	md, _ := metadata.FromIncomingContext(forged)
+	md.Append(rolesKey, adminRole)
+	if _, _, err := FromIncomingContext(metadata.NewIncomingContext(context.Background(), md), key); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a forged role to be rejected, got %v", err)
+	}
+
+	for name, signingKey := range map[string][]byte{"unsigned": nil, "other key": []byte("other")} {
+		ctx := incoming(NewOutgoingContext(context.Background(), alice, signingKey))
+		if _, _, err := FromIncomingContext(ctx, key); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected an identity %s to be rejected, got %v", name, err)
+		}
+	}
+
+	if _, ok, err := FromIncomingContext(metadata.NewIncomingContext(context.Background(), metadata.MD{}), key); ok || err != nil {
+		t.Errorf("Expected a call without identity to pass without one, got %v, %v", ok, err)
+	}
+}
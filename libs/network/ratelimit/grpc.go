@@ -3,17 +3,28 @@ package ratelimit
 
 import (
 	"context"
+	"distributed-analyzer/libs/network/identity"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
-	"sync"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
 )
 
-// GRPCLimiter is a rate limiter for gRPC requests.
+// GRPCLimiter limits the requests a gRPC server handles. Every caller has a
+// token bucket per method, and requests beyond a number in flight are shed.
 type GRPCLimiter struct {
-	limiter *rate.Limiter
+	// limiter holds the token buckets.
+	limiter Limiter
 
-	// mu protects the limiter map.
-	mu sync.RWMutex
+	// inFlight is the number of unary requests being handled.
+	inFlight atomic.Int64
 
 	// config is the configuration for the rate limiter.
 	config GRPCConfig
@@ -29,6 +40,22 @@ type GRPCConfig struct {
 
 	// ExcludedMethods is a list of methods that are excluded from rate limiting.
 	ExcludedMethods []string
+
+	// MethodLimits holds the limits of methods by full name, e.g.
+	// /task.TaskService/CreateTask. Servers limit the other methods to
+	// Rate and Burst, or not at all if Rate is 0.
+	MethodLimits map[string]Limit
+
+	// MaxInFlight is the number of unary requests a server handles at once.
+	// Beyond it requests are rejected. If 0, there is no limit.
+	MaxInFlight int
+
+	// Limiter holds the token buckets, e.g. a RedisLimiter shared by all
+	// replicas of a service. If nil, they are kept in memory.
+	Limiter Limiter
+
+	// Key is the key of the bucket of the client in the Limiter.
+	Key string
 }
 
 // DefaultGRPCConfig returns a default configuration for the gRPC rate limiter.
@@ -39,7 +66,12 @@ func DefaultGRPCConfig() GRPCConfig {
 	}
 }
 
+// ClientInterceptor waits for a token before every call of a method that is not excluded.
 func ClientInterceptor(config GRPCConfig) grpc.UnaryClientInterceptor {
+	if config.Limiter != nil {
+		return sharedClientInterceptor(config)
+	}
+
 	limiter := rate.NewLimiter(rate.Limit(config.Rate), config.Burst)
 	return func(
 		ctx context.Context,
@@ -50,9 +82,154 @@ func ClientInterceptor(config GRPCConfig) grpc.UnaryClientInterceptor {
 		invoker grpc.UnaryInvoker,
 		opts ...grpc.CallOption,
 	) error {
+		if isExcluded(config.ExcludedMethods, method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
 		if err := limiter.Wait(ctx); err != nil {
 			return grpc.Errorf(grpc.Code(err), "rate limit exceeded: %v", err)
 		}
 		return invoker(ctx, method, req, reply, cc, opts...)
 	}
 }
+
+// sharedClientInterceptor waits for a token of the bucket of the client in
+// the limiter of the config. Calls proceed if the limiter is unavailable.
+func sharedClientInterceptor(config GRPCConfig) grpc.UnaryClientInterceptor {
+	limit := Limit{Rate: config.Rate, Burst: config.Burst}
+	return func(
+		ctx context.Context,
+		method string,
+		req interface{},
+		reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		for !isExcluded(config.ExcludedMethods, method) {
+			result, err := config.Limiter.Allow(ctx, config.Key, limit)
+			if err != nil {
+				log.Printf("Rate limiter unavailable: %v", err)
+				break
+			}
+			if result.Allowed {
+				break
+			}
+			if result.RetryAfter <= 0 {
+				return status.Errorf(codes.ResourceExhausted, "rate limit exceeded")
+			}
+
+			timer := time.NewTimer(result.RetryAfter)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return status.Errorf(status.FromContextError(ctx.Err()).Code(), "rate limit exceeded: %v", ctx.Err())
+			case <-timer.C:
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// NewGRPCLimiter creates a new limiter of the requests of a gRPC server.
+func NewGRPCLimiter(config GRPCConfig) *GRPCLimiter {
+	limiter := config.Limiter
+	if limiter == nil {
+		limiter = NewMemoryLimiter(10000, 10*time.Minute)
+	}
+
+	return &GRPCLimiter{
+		limiter: limiter,
+		config:  config,
+	}
+}
+
+// UnaryServerInterceptor rejects requests with ResourceExhausted when their
+// caller exceeds the limit of the method or too many requests are in flight.
+func (l *GRPCLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.allow(ctx, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+			return nil, err
+		}
+
+		if l.config.MaxInFlight > 0 && !isExcluded(l.config.ExcludedMethods, info.FullMethod) {
+			defer l.inFlight.Add(-1)
+			if l.inFlight.Add(1) > int64(l.config.MaxInFlight) {
+				return nil, status.Errorf(codes.ResourceExhausted, "server overloaded, %d requests in flight", l.config.MaxInFlight)
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects streams with ResourceExhausted when their
+// caller exceeds the limit of the method. Streams do not count as in flight,
+// since they may last for long.
+func (l *GRPCLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.allow(ss.Context(), info.FullMethod, ss.SetHeader); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allow takes a token from the bucket of the caller of a request for the
+// method. If there is none, it sets the retry-after header and returns the
+// error to reject the request with. Requests pass if the limiter is unavailable.
+func (l *GRPCLimiter) allow(ctx context.Context, method string, setHeader func(metadata.MD) error) error {
+	if isExcluded(l.config.ExcludedMethods, method) {
+		return nil
+	}
+	limit, ok := l.config.MethodLimits[method]
+	if !ok {
+		limit = Limit{Rate: l.config.Rate, Burst: l.config.Burst}
+	}
+	if limit.Rate <= 0 && !ok {
+		return nil
+	}
+
+	key := method + ":" + caller(ctx)
+	result, err := l.limiter.Allow(ctx, key, limit)
+	if err != nil {
+		log.Printf("Rate limiter unavailable, letting %s through: %v", key, err)
+		return nil
+	}
+	if result.Allowed {
+		return nil
+	}
+
+	retryAfter := strconv.Itoa(max(seconds(result.RetryAfter), 1))
+	_ = setHeader(metadata.Pairs("retry-after", retryAfter))
+	return status.Errorf(codes.ResourceExhausted, "rate limit of %s exceeded, retry after %ss", method, retryAfter)
+}
+
+// caller identifies the caller of a request by its tenant or user, or by its
+// address if it carries no identity. The identity must have been verified by
+// the identity interceptors, which run before the limiter.
+func caller(ctx context.Context) string {
+	if id, ok := identity.FromContext(ctx); ok {
+		if id.TenantID != "" {
+			return "tenant:" + id.TenantID
+		}
+		return "user:" + id.UserID
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "peer:" + host
+	}
+	return "unknown"
+}
+
+// isExcluded checks if a method is excluded from rate limiting.
+func isExcluded(excludedMethods []string, method string) bool {
+	for _, excludedMethod := range excludedMethods {
+		if method == excludedMethod {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"distributed-analyzer/libs/network/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// callAs calls a unary interceptor for a method as a tenant
+func callAs(interceptor grpc.UnaryServerInterceptor, tenant, method string, handler grpc.UnaryHandler) error {
+	ctx := identity.NewContext(context.Background(), &identity.Identity{UserID: "user-" + tenant, TenantID: tenant})
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return err
+}
+
+func okHandler(context.Context, interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func TestGRPCLimiterLimitsCallersPerMethod(t *testing.T) {
+	interceptor := NewGRPCLimiter(GRPCConfig{
+		Rate:            0,
+		MethodLimits:    map[string]Limit{"/task.TaskService/CreateTask": {Rate: 0.001, Burst: 2}},
+		ExcludedMethods: []string{"/grpc.health.v1.Health/Check"},
+	}).UnaryServerInterceptor()
+
+	for i := 0; i < 2; i++ {
+		if err := callAs(interceptor, "a", "/task.TaskService/CreateTask", okHandler); err != nil {
+			t.Fatalf("Expected call %d to pass, got %v", i, err)
+		}
+	}
+	if err := callAs(interceptor, "a", "/task.TaskService/CreateTask", okHandler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected the third call to be rejected, got %v", err)
+	}
+	if err := callAs(interceptor, "b", "/task.TaskService/CreateTask", okHandler); err != nil {
+		t.Errorf("Expected another caller to have its own limit, got %v", err)
+	}
+	if err := callAs(interceptor, "a", "/task.TaskService/GetTask", okHandler); err != nil {
+		t.Errorf("Expected a method without a limit to pass, got %v", err)
+	}
+}
+
+func TestGRPCLimiterShedsRequestsInFlight(t *testing.T) {
+	limiter := NewGRPCLimiter(GRPCConfig{MaxInFlight: 1, ExcludedMethods: []string{"/excluded"}})
+	interceptor := limiter.UnaryServerInterceptor()
+
+	var nested, excluded error
+	err := callAs(interceptor, "a", "/slow", func(context.Context, interface{}) (interface{}, error) {
+		nested = callAs(interceptor, "b", "/fast", okHandler)
+		excluded = callAs(interceptor, "b", "/excluded", okHandler)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the first request to pass, got %v", err)
+	}
+	if status.Code(nested) != codes.ResourceExhausted {
+		t.Errorf("Expected a request beyond the cap to be shed, got %v", nested)
+	}
+	if excluded != nil {
+		t.Errorf("Expected an excluded method to pass, got %v", excluded)
+	}
+	if err := callAs(interceptor, "b", "/fast", okHandler); err != nil || limiter.inFlight.Load() != 0 {
+		t.Errorf("Expected requests to pass once the first completed, got %v with %d in flight", err, limiter.inFlight.Load())
+	}
+}
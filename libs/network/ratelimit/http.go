@@ -2,23 +2,17 @@
 package ratelimit
 
 import (
+	"context"
+	"log"
 	"net/http"
-	"sync"
 	"time"
-
-	"golang.org/x/time/rate"
 )
 
-// HTTPLimiter is a rate limiter for HTTP requests.
+// HTTPLimiter is a rate limiter for HTTP requests. It takes a token from
+// the bucket of the key of every request from a Limiter.
 type HTTPLimiter struct {
-	// limiter is the underlying rate limiter.
-	limiter *rate.Limiter
-
-	// mu protects the limiter map.
-	mu sync.RWMutex
-
-	// limiters is a map of IP addresses to rate limiters.
-	limiters map[string]*rate.Limiter
+	// limiter holds the token buckets.
+	limiter Limiter
 
 	// config is the configuration for the rate limiter.
 	config HTTPConfig
@@ -50,12 +44,21 @@ type HTTPConfig struct {
 	// If nil, the IP address is used as the key.
 	KeyFunc func(*http.Request) string
 
-	// CleanupInterval is the interval at which to clean up old limiters.
-	// If 0, cleanup is disabled.
+	// LimitFunc is a function that returns the limit of a request.
+	// If nil, Rate and Burst are used for every request.
+	LimitFunc func(*http.Request) Limit
+
+	// Limiter holds the token buckets, e.g. a RedisLimiter shared by
+	// several processes. If nil, a MemoryLimiter with MaxKeys and
+	// CleanupInterval is used.
+	Limiter Limiter
+
+	// CleanupInterval is the interval at which to drop the buckets of keys
+	// that were not used for as long. If 0, cleanup is disabled.
 	CleanupInterval time.Duration
 
-	// MaxKeys is the maximum number of keys to track.
-	// If 0, there is no limit.
+	// MaxKeys is the maximum number of keys to track. Beyond it the least
+	// recently used keys are evicted. If 0, there is no limit.
 	MaxKeys int
 }
 
@@ -73,35 +76,14 @@ func DefaultHTTPConfig() HTTPConfig {
 
 // NewHTTPLimiter creates a new HTTP rate limiter with the given configuration.
 func NewHTTPLimiter(config HTTPConfig) *HTTPLimiter {
-	limiter := &HTTPLimiter{
-		limiter:  rate.NewLimiter(rate.Limit(config.Rate), config.Burst),
-		limiters: make(map[string]*rate.Limiter),
-		config:   config,
-	}
-
-	// Start the cleanup goroutine if cleanup is enabled
-	if config.CleanupInterval > 0 {
-		go limiter.cleanup()
+	limiter := config.Limiter
+	if limiter == nil {
+		limiter = NewMemoryLimiter(config.MaxKeys, config.CleanupInterval)
 	}
 
-	return limiter
-}
-
-// cleanup periodically cleans up old limiters.
-func (l *HTTPLimiter) cleanup() {
-	ticker := time.NewTicker(l.config.CleanupInterval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		l.mu.Lock()
-		// If we have too many keys, remove the oldest ones
-		if l.config.MaxKeys > 0 && len(l.limiters) > l.config.MaxKeys {
-			// This is a simple implementation that just clears all limiters
-			// A more sophisticated implementation would track the last used time
-			// and remove the oldest ones
-			l.limiters = make(map[string]*rate.Limiter)
-		}
-		l.mu.Unlock()
+	return &HTTPLimiter{
+		limiter: limiter,
+		config:  config,
 	}
 }
 
@@ -128,24 +110,17 @@ func (l *HTTPLimiter) getKey(r *http.Request) string {
 	return l.getIPAddress(r)
 }
 
-// getLimiter gets or creates a rate limiter for the given key.
-func (l *HTTPLimiter) getLimiter(key string) *rate.Limiter {
-	l.mu.RLock()
-	limiter, exists := l.limiters[key]
-	l.mu.RUnlock()
-
-	if !exists {
-		l.mu.Lock()
-		// Check again in case another goroutine created the limiter
-		limiter, exists = l.limiters[key]
-		if !exists {
-			limiter = rate.NewLimiter(rate.Limit(l.config.Rate), l.config.Burst)
-			l.limiters[key] = limiter
-		}
-		l.mu.Unlock()
+// getLimit returns the limit of a request.
+func (l *HTTPLimiter) getLimit(r *http.Request) Limit {
+	if l.config.LimitFunc != nil {
+		return l.config.LimitFunc(r)
 	}
+	return Limit{Rate: l.config.Rate, Burst: l.config.Burst}
+}
 
-	return limiter
+// Allow takes a token from the bucket of a key with the limit.
+func (l *HTTPLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.limiter.Allow(ctx, key, limit)
 }
 
 // there isExcluded checks if the request path is excluded from rate limiting.
@@ -167,14 +142,18 @@ func (l *HTTPLimiter) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Get the key for the request
-		key := l.getKey(r)
-
-		// Get the rate limiter for the key
-		limiter := l.getLimiter(key)
+		// Take a token from the bucket of the key of the request,
+		// letting the request through if the limiter is unavailable
+		result, err := l.Allow(r.Context(), l.getKey(r), l.getLimit(r))
+		if err != nil {
+			log.Printf("Rate limiter unavailable: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		result.SetHeaders(w.Header())
 
 		// Check if the request is allowed
-		if !limiter.Allow() {
+		if !result.Allowed {
 			// Return a 429 Too Many Requests responses
 			http.Error(w, l.config.Message, l.config.StatusCode)
 			return
@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareSetsHeaders(t *testing.T) {
+	config := DefaultHTTPConfig()
+	config.CleanupInterval = 0
+	config.KeyFunc = func(*http.Request) string { return "key" }
+	config.LimitFunc = func(*http.Request) Limit { return Limit{Rate: 0.5, Burst: 1} }
+	handler := NewHTTPLimiter(config).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	ok := httptest.NewRecorder()
+	handler.ServeHTTP(ok, httptest.NewRequest(http.MethodGet, "/", nil))
+	if ok.Code != http.StatusOK || ok.Header().Get("RateLimit-Limit") != "1" || ok.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Unexpected response %d %v", ok.Code, ok.Header())
+	}
+
+	limited := httptest.NewRecorder()
+	handler.ServeHTTP(limited, httptest.NewRequest(http.MethodGet, "/", nil))
+	if limited.Code != http.StatusTooManyRequests || limited.Header().Get("Retry-After") != "2" {
+		t.Errorf("Unexpected response %d %v", limited.Code, limited.Header())
+	}
+}
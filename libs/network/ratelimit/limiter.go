@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Limiter takes tokens from the token buckets of keys. The in-memory
+// MemoryLimiter limits a single process, the RedisLimiter all processes
+// sharing a Redis server.
+type Limiter interface {
+	// Allow takes a token from the bucket of a key, creating the bucket with
+	// the limit if the key is new and adjusting it if the limit changed.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Limit is the rate and burst of a token bucket.
+type Limit struct {
+	// Rate is the number of tokens added per second.
+	Rate float64
+
+	// Burst is the size of the bucket.
+	Burst int
+}
+
+// Result is the outcome of taking a token from the bucket of a key.
+type Result struct {
+	// Allowed reports whether a token was taken.
+	Allowed bool
+
+	// Limit is the limit of the bucket.
+	Limit Limit
+
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+
+	// RetryAfter is the time until a token is available if the request was not allowed.
+	RetryAfter time.Duration
+}
+
+// newResult creates the result of taking a token from a bucket of a limit
+// that has the given number of tokens left.
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	result := Result{Allowed: allowed, Limit: limit, Remaining: max(int(tokens), 0)}
+	if limit.Rate > 0 {
+		result.Reset = tokenTime(float64(limit.Burst)-tokens, limit.Rate)
+		if !allowed {
+			result.RetryAfter = tokenTime(1-tokens, limit.Rate)
+		}
+	}
+	return result
+}
+
+// tokenTime returns the time it takes to add tokens to a bucket at a rate.
+func tokenTime(tokens, r float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / r * float64(time.Second))
+}
+
+// SetHeaders sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers of a response, and Retry-After if the request was not allowed.
+func (r Result) SetHeaders(h http.Header) {
+	h.Set("RateLimit-Limit", strconv.Itoa(r.Limit.Burst))
+	h.Set("RateLimit-Remaining", strconv.Itoa(r.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(seconds(r.Reset)))
+	if !r.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(seconds(r.RetryAfter), 1)))
+	}
+}
+
+// seconds rounds a duration up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// MemoryLimiter keeps the token buckets of keys in memory. It evicts the
+// least recently used buckets beyond a maximum number of keys.
+type MemoryLimiter struct {
+	// mu protects the buckets and their order.
+	mu sync.Mutex
+
+	// buckets maps keys to their elements in lru.
+	buckets map[string]*list.Element
+
+	// lru orders the buckets by last use, most recent first.
+	lru *list.List
+
+	// maxKeys is the maximum number of keys to track, 0 for no limit.
+	maxKeys int
+}
+
+// bucket is the token bucket of a key.
+type bucket struct {
+	key      string
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+var _ Limiter = (*MemoryLimiter)(nil)
+
+// NewMemoryLimiter creates a new in-memory limiter tracking at most maxKeys
+// keys, or any number if 0. With a cleanup interval, it periodically drops
+// the buckets of keys that were not used for as long.
+func NewMemoryLimiter(maxKeys int, cleanupInterval time.Duration) *MemoryLimiter {
+	limiter := &MemoryLimiter{
+		buckets: make(map[string]*list.Element),
+		lru:     list.New(),
+		maxKeys: maxKeys,
+	}
+
+	if cleanupInterval > 0 {
+		go limiter.cleanup(cleanupInterval)
+	}
+
+	return limiter
+}
+
+// cleanup periodically drops the buckets of keys that were not used for an
+// interval. Their buckets are full again, like new ones.
+func (l *MemoryLimiter) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		cutoff := time.Now().Add(-interval)
+		l.mu.Lock()
+		for e := l.lru.Back(); e != nil && e.Value.(*bucket).lastUsed.Before(cutoff); e = l.lru.Back() {
+			l.remove(e)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// remove removes a bucket. The caller must hold mu.
+func (l *MemoryLimiter) remove(e *list.Element) {
+	l.lru.Remove(e)
+	delete(l.buckets, e.Value.(*bucket).key)
+}
+
+// Allow takes a token from the bucket of a key, creating the bucket with the
+// limit if the key is new and adjusting it if the limit of the key changed.
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b *bucket
+	if e, ok := l.buckets[key]; ok {
+		b = e.Value.(*bucket)
+		l.lru.MoveToFront(e)
+		if b.limiter.Limit() != rate.Limit(limit.Rate) || b.limiter.Burst() != limit.Burst {
+			b.limiter.SetLimitAt(now, rate.Limit(limit.Rate))
+			b.limiter.SetBurstAt(now, limit.Burst)
+		}
+	} else {
+		b = &bucket{key: key, limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		l.buckets[key] = l.lru.PushFront(b)
+		for l.maxKeys > 0 && l.lru.Len() > l.maxKeys {
+			l.remove(l.lru.Back())
+		}
+	}
+	b.lastUsed = now
+	allowed := b.limiter.AllowN(now, 1)
+	return newResult(allowed, b.limiter.TokensAt(now), limit), nil
+}
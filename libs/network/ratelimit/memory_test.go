@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// allow takes a token from the bucket of a key and fails the test on errors
+func allow(t *testing.T, limiter Limiter, key string, limit Limit) Result {
+	t.Helper()
+	result, err := limiter.Allow(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("Failed to take a token: %v", err)
+	}
+	return result
+}
+
+func TestMemoryLimiterReportsRemainingTokens(t *testing.T) {
+	limiter := NewMemoryLimiter(0, 0)
+	limit := Limit{Rate: 1, Burst: 2}
+
+	first := allow(t, limiter, "user:1", limit)
+	if !first.Allowed || first.Remaining != 1 || first.Reset <= 0 || first.Reset > time.Second {
+		t.Errorf("Unexpected first result %+v", first)
+	}
+	allow(t, limiter, "user:1", limit)
+
+	denied := allow(t, limiter, "user:1", limit)
+	if denied.Allowed || denied.Remaining != 0 || denied.RetryAfter <= 0 || denied.RetryAfter > time.Second {
+		t.Errorf("Expected the third request to be denied, got %+v", denied)
+	}
+
+	if other := allow(t, limiter, "user:2", limit); !other.Allowed {
+		t.Error("Expected another key to have its own bucket")
+	}
+}
+
+func TestMemoryLimiterEvictsLeastRecentlyUsedKeys(t *testing.T) {
+	limiter := NewMemoryLimiter(2, 0)
+	limit := Limit{Rate: 0.001, Burst: 1}
+
+	allow(t, limiter, "a", limit)
+	allow(t, limiter, "b", limit)
+	allow(t, limiter, "a", limit)
+	allow(t, limiter, "c", limit)
+
+	if _, ok := limiter.buckets["b"]; ok {
+		t.Error("Expected the least recently used key to be evicted")
+	}
+	if result := allow(t, limiter, "a", limit); result.Allowed {
+		t.Error("Expected a recently used key to keep its bucket")
+	}
+}
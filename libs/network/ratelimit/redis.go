@@ -0,0 +1,285 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tokenBucketScript takes a token from the bucket of KEYS[1] with the rate
+// ARGV[1] and burst ARGV[2]. The bucket is a hash of its tokens and the time
+// of Redis they were counted at, so the clocks of the clients do not matter.
+// It expires once it is full again. The script returns whether a token was
+// taken and the tokens left.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+if tokens == nil then
+  tokens = burst
+else
+  tokens = math.min(burst, tokens + math.max(0, now - tonumber(state[2])) * rate)
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+if rate > 0 then
+  redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+end
+return {allowed, tostring(tokens)}
+`
+
+// tokenBucketSHA is the SHA1 digest Redis caches the script under.
+var tokenBucketSHA = func() string {
+	sum := sha1.Sum([]byte(tokenBucketScript))
+	return hex.EncodeToString(sum[:])
+}()
+
+// RedisConfig holds configuration for the Redis limiter.
+type RedisConfig struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+
+	// Password authenticates the connections if set.
+	Password string
+
+	// DB is the number of the database holding the buckets.
+	DB int
+
+	// Prefix is prepended to the keys of the buckets.
+	Prefix string
+
+	// Timeout bounds dialing and every command unless the context ends earlier.
+	Timeout time.Duration
+
+	// PoolSize is the maximum number of idle connections kept open.
+	PoolSize int
+}
+
+// DefaultRedisConfig returns a default configuration for the Redis limiter of a server.
+func DefaultRedisConfig(addr string) RedisConfig {
+	return RedisConfig{
+		Addr:     addr,
+		Prefix:   "ratelimit:",
+		Timeout:  time.Second,
+		PoolSize: 10,
+	}
+}
+
+// RedisLimiter keeps the token buckets of keys in a server speaking the
+// Redis protocol, so that all processes sharing it share their limits.
+// Every token is taken atomically by a script running on the server.
+type RedisLimiter struct {
+	// config is the configuration for the limiter.
+	config RedisConfig
+
+	// idle holds the connections that are not in use.
+	idle chan *redisConn
+}
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// NewRedisLimiter creates a new Redis limiter. Connections are opened when needed.
+func NewRedisLimiter(config RedisConfig) *RedisLimiter {
+	return &RedisLimiter{
+		config: config,
+		idle:   make(chan *redisConn, max(config.PoolSize, 1)),
+	}
+}
+
+// Close closes the idle connections.
+func (l *RedisLimiter) Close() error {
+	for {
+		select {
+		case conn := <-l.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Allow takes a token from the bucket of a key, creating the bucket with the
+// limit if the key is new and adjusting it if the limit of the key changed.
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	conn, err := l.get(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	args := []string{"1", l.config.Prefix + key, strconv.FormatFloat(limit.Rate, 'f', -1, 64), strconv.Itoa(limit.Burst)}
+	reply, err := conn.do(ctx, l.config.Timeout, append([]string{"EVALSHA", tokenBucketSHA}, args...)...)
+	var redisErr redisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		reply, err = conn.do(ctx, l.config.Timeout, append([]string{"EVAL", tokenBucketScript}, args...)...)
+	}
+	if err != nil && !errors.As(err, &redisErr) {
+		conn.Close()
+		return Result{}, fmt.Errorf("rate limit %s: %w", key, err)
+	}
+	l.put(conn)
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit %s: %w", key, err)
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("rate limit %s: unexpected reply %v", key, reply)
+	}
+	allowed, _ := values[0].(int64)
+	tokensReply, _ := values[1].([]byte)
+	tokens, err := strconv.ParseFloat(string(tokensReply), 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit %s: invalid tokens %q", key, tokensReply)
+	}
+
+	return newResult(allowed == 1, tokens, limit), nil
+}
+
+// get returns an idle connection or opens a new one.
+func (l *RedisLimiter) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-l.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: l.config.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", l.config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to redis: %w", err)
+	}
+	conn := &redisConn{conn: netConn, r: bufio.NewReader(netConn), w: bufio.NewWriter(netConn)}
+
+	if l.config.Password != "" {
+		if _, err := conn.do(ctx, l.config.Timeout, "AUTH", l.config.Password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("authenticate to redis: %w", err)
+		}
+	}
+	if l.config.DB != 0 {
+		if _, err := conn.do(ctx, l.config.Timeout, "SELECT", strconv.Itoa(l.config.DB)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("select redis database: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// put returns a connection to the idle ones, or closes it if there are enough.
+func (l *RedisLimiter) put(conn *redisConn) {
+	select {
+	case l.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// redisError is an error reply of the server. The connection stays usable.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is a connection speaking RESP, the protocol of Redis.
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// Close closes the connection.
+func (c *redisConn) Close() error {
+	return c.conn.Close()
+}
+
+// do sends a command and reads its reply, which is a string, int64, []byte,
+// nil or []any of them. Error replies are returned as redisError, and are
+// values in arrays.
+func (c *redisConn) do(ctx context.Context, timeout time.Duration, args ...string) (any, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.r)
+}
+
+// readReply reads a RESP reply.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err // a nil bulk string has length -1
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]any, n)
+		for i := range values {
+			value, err := readReply(r)
+			if redisErr, ok := err.(redisError); ok {
+				value, err = redisErr, nil // keep reading the rest of the array
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+}
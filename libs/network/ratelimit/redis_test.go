@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-process stand-in for a Redis server. It runs the token
+// bucket script natively once it was loaded by EVAL, like the script cache.
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu      sync.Mutex
+	loaded  bool
+	evals   int
+	now     float64
+	buckets map[string][2]float64
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{listener: listener, password: password, now: 1000, buckets: make(map[string][2]float64)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+// advance moves the clock of the server forward
+func (f *fakeRedis) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now += d.Seconds()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authenticated := f.password == ""
+
+	for {
+		request, err := readReply(r)
+		if err != nil {
+			return
+		}
+		values := request.([]any)
+		args := make([]string, len(values))
+		for i, value := range values {
+			args[i] = string(value.([]byte))
+		}
+
+		switch {
+		case args[0] == "AUTH" && args[1] == f.password:
+			authenticated = true
+			fmt.Fprint(conn, "+OK\r\n")
+		case !authenticated:
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+		case args[0] == "EVALSHA" && args[1] == tokenBucketSHA:
+			fmt.Fprint(conn, f.eval(false, args[3], args[4], args[5]))
+		case args[0] == "EVAL" && args[1] == tokenBucketScript:
+			fmt.Fprint(conn, f.eval(true, args[3], args[4], args[5]))
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+// eval runs the token bucket script for a key and returns its reply
+func (f *fakeRedis) eval(load bool, key, rateArg, burstArg string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if load {
+		f.loaded = true
+		f.evals++
+	} else if !f.loaded {
+		return "-NOSCRIPT No matching script. Please use EVAL.\r\n"
+	}
+
+	r, _ := strconv.ParseFloat(rateArg, 64)
+	burst, _ := strconv.ParseFloat(burstArg, 64)
+	tokens := burst
+	if state, ok := f.buckets[key]; ok {
+		tokens = math.Min(burst, state[0]+math.Max(0, f.now-state[1])*r)
+	}
+	allowed := 0
+	if tokens >= 1 {
+		tokens--
+		allowed = 1
+	}
+	f.buckets[key] = [2]float64{tokens, f.now}
+
+	value := strconv.FormatFloat(tokens, 'g', -1, 64)
+	return fmt.Sprintf("*2\r\n:%d\r\n$%d\r\n%s\r\n", allowed, len(value), value)
+}
+
+func TestRedisLimiterSharesBucketsBetweenLimiters(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	config := DefaultRedisConfig(server.listener.Addr().String())
+	config.Password = "secret"
+
+	replicas := []*RedisLimiter{NewRedisLimiter(config), NewRedisLimiter(config)}
+	for _, replica := range replicas {
+		defer replica.Close()
+	}
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		result := allow(t, replicas[i%2], "tenant:a", limit)
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Errorf("Unexpected result %d %+v", i, result)
+		}
+	}
+	denied := allow(t, replicas[1], "tenant:a", limit)
+	if denied.Allowed || denied.RetryAfter != time.Second {
+		t.Errorf("Expected the replicas to share the bucket, got %+v", denied)
+	}
+	if server.evals != 1 {
+		t.Errorf("Expected the script to be loaded once, got %d loads", server.evals)
+	}
+
+	server.advance(2 * time.Second)
+	if result := allow(t, replicas[0], "tenant:a", limit); !result.Allowed || result.Remaining != 1 {
+		t.Errorf("Expected the bucket to refill, got %+v", result)
+	}
+}
+
+func TestRedisLimiterReportsUnavailableServer(t *testing.T) {
+	server := newFakeRedis(t, "")
+	addr := server.listener.Addr().String()
+	server.listener.Close()
+
+	limiter := NewRedisLimiter(DefaultRedisConfig(addr))
+	if _, err := limiter.Allow(context.Background(), "user:1", Limit{Rate: 1, Burst: 1}); err == nil {
+		t.Fatal("Expected an error without a server")
+	}
+
+	config := DefaultHTTPConfig()
+	config.CleanupInterval = 0
+	config.Limiter = limiter
+	handler := NewHTTPLimiter(config).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected requests to pass while the limiter is unavailable, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,3 @@
+module distributed-analyzer/libs/store
+
+go 1.24
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// maxLogLine bounds the length of an entry of a log
+const maxLogLine = 16 * 1024 * 1024
+
+// Log is an append-only log of entries of type T
+type Log[T any] interface {
+	// ReadAll returns the entries in the order they were appended
+	ReadAll() ([]*T, error)
+
+	// Append appends an entry, which is durable once Append returns
+	Append(entry *T) error
+}
+
+// LogFile keeps the entries in a file with one JSON entry per line, readable
+// by the owner alone. Entries are only ever appended to the file.
+type LogFile[T any] struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewLogFile opens the log at path for appending, creating it and its directory if needed
+func NewLogFile[T any](path string) (*LogFile[T], error) {
+	if err := createDir(path); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log: %w", err)
+	}
+	return &LogFile[T]{path: path, file: file}, nil
+}
+
+// Close closes the file
+func (l *LogFile[T]) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// ReadAll reads the entries from the file. A line that does not decode is
+// reported with its number, as it may have been tampered with.
+func (l *LogFile[T]) ReadAll() ([]*T, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*T
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLine)
+	for line := 1; scanner.Scan(); line++ {
+		entry := new(T)
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("failed to decode line %d of %s: %w", line, l.path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Append writes the entry as a line and syncs the file
+func (l *LogFile[T]) Append(entry *T) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
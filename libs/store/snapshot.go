@@ -0,0 +1,92 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SnapshotStore persists snapshots of a state of type T
+type SnapshotStore[T any] interface {
+	// Load returns the last saved snapshot, or an empty one if none was saved
+	Load() (*T, error)
+
+	// Save replaces the saved snapshot
+	Save(snapshot *T) error
+}
+
+// SnapshotFile keeps the snapshot in a JSON file, readable by the owner alone
+type SnapshotFile[T any] struct {
+	path string
+}
+
+// NewSnapshotFile creates a new SnapshotFile writing to path, creating its directory if needed
+func NewSnapshotFile[T any](path string) (*SnapshotFile[T], error) {
+	if err := createDir(path); err != nil {
+		return nil, err
+	}
+	return &SnapshotFile[T]{path: path}, nil
+}
+
+// Load reads the snapshot from the file
+func (f *SnapshotFile[T]) Load() (*T, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return new(T), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := new(T)
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", f.path, err)
+	}
+	return snapshot, nil
+}
+
+// Save writes the snapshot to a temporary file first and renames it over the
+// file, so a crash never leaves a partial snapshot behind
+func (f *SnapshotFile[T]) Save(snapshot *T) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), "."+filepath.Base(f.path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// Update applies a change to a state and saves a snapshot of the changed
+// state. If saving fails, the state is restored from the snapshot taken
+// before the change. The caller must hold the write lock of the state.
+func Update[T any](store SnapshotStore[T], snapshot func() *T, restore func(*T), change func() error) error {
+	previous := snapshot()
+	if err := change(); err != nil {
+		return err
+	}
+	if err := store.Save(snapshot()); err != nil {
+		restore(previous)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Package store persists the state of services in files: as snapshots that
+// are replaced on every change, or as append-only logs of changes.
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// NewID returns a random identifier
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// createDir creates the directory of a store file, readable by the owner alone
+func createDir(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return nil
+}
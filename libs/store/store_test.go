@@ -0,0 +1,70 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type counter struct {
+	Count int `json:"count"`
+}
+
+// failingStore fails to save snapshots
+type failingStore struct {
+	SnapshotStore[counter]
+}
+
+func (failingStore) Save(*counter) error {
+	return errors.New("disk full")
+}
+
+func TestUpdateRestoresStateWhenSaveFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "counter.json")
+	file, err := NewSnapshotFile[counter](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := &counter{}
+	snapshot := func() *counter { copied := *state; return &copied }
+	restore := func(c *counter) { *state = *c }
+	increment := func() error { state.Count++; return nil }
+
+	if err := Update[counter](file, snapshot, restore, increment); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	if err := Update[counter](failingStore{file}, snapshot, restore, increment); err == nil || state.Count != 1 {
+		t.Errorf("Expected a failed save to undo the change, got %v with count %d", err, state.Count)
+	}
+
+	loaded, err := file.Load()
+	if err != nil || loaded.Count != 1 {
+		t.Errorf("Expected the saved count to be 1, got %+v %v", loaded, err)
+	}
+}
+
+func TestLogFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.log")
+	log, err := NewLogFile[counter](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	for i := 1; i <= 3; i++ {
+		if err := log.Append(&counter{Count: i}); err != nil {
+			t.Fatalf("Failed to append: %v", err)
+		}
+	}
+	entries, err := log.ReadAll()
+	if err != nil || len(entries) != 3 || entries[2].Count != 3 {
+		t.Fatalf("Expected 3 entries in order, got %v %v", entries, err)
+	}
+
+	os.WriteFile(path, []byte("{\"count\":1}\nnot json\n"), 0o600)
+	if _, err := log.ReadAll(); err == nil {
+		t.Error("Expected a broken line to be reported")
+	}
+}
@@ -0,0 +1,29 @@
+// Package storetest opens stores for the tests of services
+package storetest
+
+import (
+	"distributed-analyzer/libs/store"
+	"testing"
+)
+
+// SnapshotFile opens a snapshot file at path, failing the test if it cannot
+func SnapshotFile[T any](t testing.TB, path string) *store.SnapshotFile[T] {
+	t.Helper()
+	file, err := store.NewSnapshotFile[T](path)
+	if err != nil {
+		t.Fatalf("Failed to open snapshot file: %v", err)
+	}
+	return file
+}
+
+// LogFile opens a log file at path, which is closed when the test ends,
+// failing the test if it cannot
+func LogFile[T any](t testing.TB, path string) *store.LogFile[T] {
+	t.Helper()
+	file, err := store.NewLogFile[T](path)
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
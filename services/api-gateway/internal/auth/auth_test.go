@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	userpb "distributed-analyzer/libs/proto/user"
+)
+
+// sign creates a token with the given header and claims, signed by sign
+func sign(t *testing.T, header, claims map[string]any, sign func(input []byte) []byte) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	input := encode(header) + "." + encode(claims)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func hs256(secret []byte) func([]byte) []byte {
+	return func(input []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil)
+	}
+}
+
+func TestVerifyHS256(t *testing.T) {
+	secret := []byte("secret")
+	verifier, err := NewVerifier(VerifierConfig{HMACSecret: secret, Issuer: "issuer", Audience: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	token := sign(t, map[string]any{"alg": "HS256"}, map[string]any{
+		"sub":    "user-1",
+		"iss":    "issuer",
+		"aud":    []string{"api", "other"},
+		"exp":    exp,
+		"roles":  []string{"user", "ROLE_WORKER", "unknown"},
+		"tenant": "team-a",
+	}, hs256(secret))
+
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.TenantID != "team-a" || len(claims.Roles) != 2 ||
+		claims.Roles[0] != userpb.UserRole_ROLE_USER || claims.Roles[1] != userpb.UserRole_ROLE_WORKER {
+		t.Errorf("Unexpected claims %+v", claims)
+	}
+
+	forged := sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "user-1", "iss": "issuer", "aud": "api", "exp": exp}, hs256([]byte("guess")))
+	if _, err := verifier.Verify(forged); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected a token with a bad signature to be rejected, got %v", err)
+	}
+
+	unsigned := sign(t, map[string]any{"alg": "none"}, map[string]any{"sub": "user-1", "iss": "issuer", "aud": "api", "exp": exp}, func([]byte) []byte { return nil })
+	if _, err := verifier.Verify(unsigned); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected an unsigned token to be rejected, got %v", err)
+	}
+
+	expired := sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "user-1", "iss": "issuer", "aud": "api", "exp": time.Now().Add(-time.Hour).Unix()}, hs256(secret))
+	if _, err := verifier.Verify(expired); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected an expired token to be rejected, got %v", err)
+	}
+
+	otherAudience := sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "user-1", "iss": "issuer", "aud": "other", "exp": exp}, hs256(secret))
+	if _, err := verifier.Verify(otherAudience); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected a token for another audience to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadJWKS(path)
+	if err != nil {
+		t.Fatalf("Failed to load JWKS: %v", err)
+	}
+	verifier, err := NewVerifier(VerifierConfig{Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs256 := func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+	claims := map[string]any{"sub": "admin-1", "exp": time.Now().Add(time.Hour).Unix(), "roles": "admin"}
+
+	verified, err := verifier.Verify(sign(t, map[string]any{"alg": "RS256", "kid": "key-1"}, claims, rs256))
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	if !Allowed(verified.Roles, PermissionTaskDelete) {
+		t.Errorf("Expected an admin to hold every permission, got roles %v", verified.Roles)
+	}
+
+	if _, err := verifier.Verify(sign(t, map[string]any{"alg": "RS256", "kid": "key-2"}, claims, rs256)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected a token of an unknown key to be rejected, got %v", err)
+	}
+	if _, err := verifier.Verify(sign(t, map[string]any{"alg": "HS256", "kid": "key-1"}, claims, hs256(nil))); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected an HS256 token to be rejected without a secret, got %v", err)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	worker := []userpb.UserRole{userpb.UserRole_ROLE_WORKER}
+	if !Allowed(worker, PermissionResultRead) || Allowed(worker, PermissionTaskSubmit) {
+		t.Error("Expected workers to read results but not submit tasks")
+	}
+	if Allowed(nil, PermissionTaskRead) {
+		t.Error("Expected users without roles to hold no permissions")
+	}
+}
@@ -0,0 +1,260 @@
+// Package auth verifies the JWTs of gateway clients and decides which routes their roles may use.
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	userpb "distributed-analyzer/libs/proto/user"
+)
+
+// Errors returned when a token is rejected
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// APIKeyPrefix starts the API keys of the user service, which are accepted in place of tokens
+const APIKeyPrefix = "dak_"
+
+// Signing algorithms accepted by the verifier
+const (
+	algHS256 = "HS256"
+	algRS256 = "RS256"
+)
+
+// Claims is the identity a verified token asserts
+type Claims struct {
+	Subject  string
+	Username string
+	Email    string
+	TenantID string
+	Roles    []userpb.UserRole
+
+	ExpiresAt time.Time
+}
+
+// VerifierConfig configures a Verifier
+type VerifierConfig struct {
+	// HMACSecret verifies HS256 tokens, which are rejected if it is empty
+	HMACSecret []byte
+
+	// Keys verify RS256 tokens by key ID
+	Keys map[string]*rsa.PublicKey
+
+	// Issuer and Audience are checked if set
+	Issuer   string
+	Audience string
+
+	// RolesClaim names the claim holding the roles of the user
+	RolesClaim string
+
+	// TenantClaim names the claim holding the tenant of the user
+	TenantClaim string
+
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+// Verifier verifies signed JWTs and extracts their claims
+type Verifier struct {
+	cfg VerifierConfig
+	now func() time.Time
+}
+
+// NewVerifier creates a new Verifier. It needs an HMAC secret or at least one RSA key.
+func NewVerifier(cfg VerifierConfig) (*Verifier, error) {
+	if len(cfg.HMACSecret) == 0 && len(cfg.Keys) == 0 {
+		return nil, errors.New("an HMAC secret or RSA keys are required to verify tokens")
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = "tenant"
+	}
+	return &Verifier{cfg: cfg, now: time.Now}, nil
+}
+
+// header is the JOSE header of a token
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature and the registered claims of a compact JWT and returns its claims
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+	if err := v.verifySignature(h, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	return v.checkClaims(raw)
+}
+
+// verifySignature checks the signature of the signing input with the key the header selects
+func (v *Verifier) verifySignature(h header, input string, signature []byte) error {
+	digest := sha256.Sum256([]byte(input))
+
+	switch h.Alg {
+	case algHS256:
+		if len(v.cfg.HMACSecret) == 0 {
+			return fmt.Errorf("%w: HS256 tokens are not accepted", ErrInvalidToken)
+		}
+		mac := hmac.New(sha256.New, v.cfg.HMACSecret)
+		mac.Write([]byte(input))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	case algRS256:
+		key, err := v.rsaKey(h.Kid)
+		if err != nil {
+			return err
+		}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, h.Alg)
+	}
+}
+
+// rsaKey returns the RSA key with the given ID. Tokens without a key ID may
+// only be verified if there is a single key.
+func (v *Verifier) rsaKey(kid string) (*rsa.PublicKey, error) {
+	if kid == "" && len(v.cfg.Keys) == 1 {
+		for _, key := range v.cfg.Keys {
+			return key, nil
+		}
+	}
+	key, ok := v.cfg.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// checkClaims validates the registered claims and extracts the identity
+func (v *Verifier) checkClaims(raw map[string]any) (*Claims, error) {
+	now := v.now()
+	claims := &Claims{}
+
+	exp, ok := raw["exp"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("%w: exp claim is required", ErrInvalidToken)
+	}
+	claims.ExpiresAt = time.Unix(int64(exp), 0)
+	if now.After(claims.ExpiresAt.Add(v.cfg.Leeway)) {
+		return nil, ErrTokenExpired
+	}
+	if nbf, ok := raw["nbf"].(float64); ok && now.Add(v.cfg.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	}
+
+	if v.cfg.Issuer != "" && raw["iss"] != v.cfg.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if v.cfg.Audience != "" && !containsString(stringList(raw["aud"]), v.cfg.Audience) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	claims.Subject, _ = raw["sub"].(string)
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: sub claim is required", ErrInvalidToken)
+	}
+	claims.Username, _ = raw["preferred_username"].(string)
+	claims.Email, _ = raw["email"].(string)
+	claims.TenantID, _ = raw[v.cfg.TenantClaim].(string)
+
+	for _, name := range stringList(raw[v.cfg.RolesClaim]) {
+		if role, ok := ParseRole(name); ok && !containsRole(claims.Roles, role) {
+			claims.Roles = append(claims.Roles, role)
+		}
+	}
+
+	return claims, nil
+}
+
+// ParseRole maps a role claim such as "admin" or "ROLE_ADMIN" to a role of user.proto
+func ParseRole(name string) (userpb.UserRole, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "ROLE_") {
+		name = "ROLE_" + name
+	}
+	value, ok := userpb.UserRole_value[name]
+	if !ok || value == int32(userpb.UserRole_ROLE_UNSPECIFIED) {
+		return userpb.UserRole_ROLE_UNSPECIFIED, false
+	}
+	return userpb.UserRole(value), true
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringList reads a claim holding a string, a space-separated list or an array of strings
+func stringList(claim any) []string {
+	switch c := claim.(type) {
+	case string:
+		return strings.Fields(c)
+	case []any:
+		values := make([]string, 0, len(c))
+		for _, v := range c {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRole(roles []userpb.UserRole, role userpb.UserRole) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	userpb "distributed-analyzer/libs/proto/user"
+)
+
+// Permission is an action on a kind of resource, e.g. task:submit
+type Permission string
+
+// Permissions checked by the gateway
+const (
+	PermissionTaskSubmit Permission = "task:submit"
+	PermissionTaskRead   Permission = "task:read"
+	PermissionTaskUpdate Permission = "task:update"
+	PermissionTaskDelete Permission = "task:delete"
+	PermissionResultRead Permission = "result:read"
+	PermissionAPIKeys    Permission = "apikey:manage"
+
+	PermissionBillingRead   Permission = "billing:read"
+	PermissionBillingManage Permission = "billing:manage"
+)
+
+// rolePermissions holds the permissions of every role but ROLE_ADMIN, which holds them all
+var rolePermissions = map[userpb.UserRole][]Permission{
+	userpb.UserRole_ROLE_USER: {
+		PermissionTaskSubmit,
+		PermissionTaskRead,
+		PermissionTaskUpdate,
+		PermissionTaskDelete,
+		PermissionResultRead,
+		PermissionAPIKeys,
+		PermissionBillingRead,
+	},
+	userpb.UserRole_ROLE_WORKER: {
+		PermissionTaskRead,
+		PermissionResultRead,
+	},
+}
+
+// Allowed reports whether any of the roles holds a permission
+func Allowed(roles []userpb.UserRole, permission Permission) bool {
+	for _, role := range roles {
+		if role == userpb.UserRole_ROLE_ADMIN {
+			return true
+		}
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Policy maps routes, written as "METHOD /full/path" like "GET /api/task/status/:id",
+// to the permission they require
+type Policy map[string]Permission
+
+// Permission returns the permission a route requires. Routes missing from
+// the policy are not accessible.
+func (p Policy) Permission(method, path string) (Permission, bool) {
+	permission, ok := p[method+" "+path]
+	return permission, ok
+}
@@ -3,11 +3,17 @@ package bootstrap
 import (
 	app "distributed-analyzer/libs/application"
 	component "distributed-analyzer/libs/application/http"
+	"distributed-analyzer/services/api-gateway/internal/auth"
 	"distributed-analyzer/services/api-gateway/internal/config"
 	"distributed-analyzer/services/api-gateway/internal/http"
 	"github.com/gin-gonic/gin"
+	"log"
+	"time"
 )
 
+// minHMACSecretLength is the length of the shortest HMAC secret that is accepted
+const minHMACSecretLength = 32
+
 func StartApplication(cfg *config.Config) {
 	httpComponent := initHttpComponent(cfg)
 	runner := app.NewApplicationRunner(httpComponent)
@@ -15,7 +21,54 @@ func StartApplication(cfg *config.Config) {
 }
 
 func initHttpComponent(cfg *config.Config) *component.GinHttpComponent {
-	var routes = http.RegisterRoutes(gin.Default(), cfg)
+	engine := gin.Default()
+	// The address limit keys on the client address, which a client must not be able to forge
+	if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+	var routes = http.RegisterRoutes(engine, cfg, initVerifier(cfg.Auth))
 	httpComponent := component.NewGinHttpComponent(&cfg.ServerConfig, routes)
 	return httpComponent
 }
+
+// initVerifier creates the verifier of the tokens of API clients, or returns nil if authentication is disabled
+func initVerifier(cfg config.AuthConfig) *auth.Verifier {
+	if !cfg.Enabled {
+		log.Println("Authentication is disabled, the API is open to everyone")
+		return nil
+	}
+
+	// HS256 tokens are verified with the secret, which must not be guessable
+	if cfg.HMACSecret == "" && cfg.JWKSFile == "" {
+		log.Fatalf("Authentication is enabled, but neither AUTH_HMAC_SECRET nor a JWKS file is configured")
+	}
+	if cfg.HMACSecret != "" && len(cfg.HMACSecret) < minHMACSecretLength {
+		log.Fatalf("AUTH_HMAC_SECRET must be at least %d bytes long", minHMACSecretLength)
+	}
+
+	leeway, err := time.ParseDuration(cfg.Leeway)
+	if err != nil || leeway < 0 {
+		log.Fatalf("Invalid auth leeway %q", cfg.Leeway)
+	}
+
+	verifierCfg := auth.VerifierConfig{
+		HMACSecret:  []byte(cfg.HMACSecret),
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+		RolesClaim:  cfg.RolesClaim,
+		TenantClaim: cfg.TenantClaim,
+		Leeway:      leeway,
+	}
+	if cfg.JWKSFile != "" {
+		verifierCfg.Keys, err = auth.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			log.Fatalf("Failed to load JWKS: %v", err)
+		}
+	}
+
+	verifier, err := auth.NewVerifier(verifierCfg)
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+	return verifier
+}
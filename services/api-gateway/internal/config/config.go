@@ -5,12 +5,23 @@ import configloader "distributed-analyzer/libs/config"
 type Config struct {
 	configloader.ServerConfig `yaml:",inline"`
 
-	Services ServicesConfig `yaml:"services"`
+	Services  ServicesConfig  `yaml:"services"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	// TrustedProxies are the addresses or CIDRs of the proxies whose X-Forwarded-For
+	// header is trusted for the client address. By default no proxy is trusted.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+
+	Identity configloader.IdentityConfig `yaml:"identity"`
 }
 
 type ServicesConfig struct {
 	Task      ServiceConnectionConfig `yaml:"task"`
 	Scheduler ServiceConnectionConfig `yaml:"scheduler"`
+	Result    ServiceConnectionConfig `yaml:"result"`
+	Storage   ServiceConnectionConfig `yaml:"storage"`
+	User      ServiceConnectionConfig `yaml:"user"`
 	Billing   ServiceConnectionConfig `yaml:"billing"`
 }
 
@@ -18,3 +29,42 @@ type ServiceConnectionConfig struct {
 	URL      string `yaml:"url"       env:"{PREFIX}_URL"       env-default:"http://localhost:8080"`
 	GRPCAddr string `yaml:"grpc_addr" env:"{PREFIX}_GRPC_ADDR" env-default:"localhost:9090"`
 }
+
+type AuthConfig struct {
+	Enabled     bool   `yaml:"enabled"     env:"AUTH_ENABLED"     env-default:"true"`
+	HMACSecret  string `yaml:"hmac_secret" env:"AUTH_HMAC_SECRET"`
+	JWKSFile    string `yaml:"jwks_file"   env:"AUTH_JWKS_FILE"`
+	Issuer      string `yaml:"issuer"      env:"AUTH_ISSUER"`
+	Audience    string `yaml:"audience"    env:"AUTH_AUDIENCE"`
+	RolesClaim  string `yaml:"roles_claim"  env:"AUTH_ROLES_CLAIM"  env-default:"roles"`
+	TenantClaim string `yaml:"tenant_claim" env:"AUTH_TENANT_CLAIM" env-default:"tenant"`
+	Leeway      string `yaml:"leeway"       env:"AUTH_LEEWAY"       env-default:"30s"`
+}
+
+// RateLimitConfig holds the request limits of API clients. Every user has a
+// token bucket with the limit of the best tier of their roles, or the default
+// limit. The users of a tenant with a limit share the bucket of their tenant.
+// Before their credentials are checked, clients are limited by address to
+// IPRate, which is higher as clients behind one address share it.
+// With a Redis address, the buckets are shared by all gateway replicas.
+type RateLimitConfig struct {
+	Enabled bool    `yaml:"enabled"  env:"RATE_LIMIT_ENABLED"  env-default:"true"`
+	Rate    float64 `yaml:"rate"     env:"RATE_LIMIT_RATE"     env-default:"20"`
+	Burst   int     `yaml:"burst"    env:"RATE_LIMIT_BURST"    env-default:"40"`
+	IPRate  float64 `yaml:"ip_rate"  env:"RATE_LIMIT_IP_RATE"  env-default:"100"`
+	IPBurst int     `yaml:"ip_burst" env:"RATE_LIMIT_IP_BURST" env-default:"200"`
+	MaxKeys int     `yaml:"max_keys" env:"RATE_LIMIT_MAX_KEYS" env-default:"10000"`
+
+	RedisAddr     string `yaml:"redis_addr"     env:"RATE_LIMIT_REDIS_ADDR"`
+	RedisPassword string `yaml:"redis_password" env:"RATE_LIMIT_REDIS_PASSWORD"`
+	RedisDB       int    `yaml:"redis_db"       env:"RATE_LIMIT_REDIS_DB"`
+
+	Roles   map[string]RateLimitTier `yaml:"roles"`
+	Tenants map[string]RateLimitTier `yaml:"tenants"`
+}
+
+// RateLimitTier is the number of requests per second and the burst of a tier
+type RateLimitTier struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/api-gateway/internal/http/middleware"
+	"distributed-analyzer/services/api-gateway/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	userServiceClient service.UserServiceClient
+}
+
+func NewAPIKeyHandler(userService service.UserServiceClient) *APIKeyHandler {
+	return &APIKeyHandler{userServiceClient: userService}
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	TTL  string `json:"ttl"` // Lifetime of the key such as 720h, empty for a key that does not expire
+}
+
+type CreateAPIKeyResponse struct {
+	model.APIKey
+	Key string `json:"key"` // The secret key, which cannot be retrieved again
+}
+
+func (h *APIKeyHandler) Register(rg *gin.RouterGroup) {
+	rg.POST("/keys", h.CreateAPIKey)
+	rg.GET("/keys", h.ListAPIKeys)
+	rg.DELETE("/keys/:id", h.RevokeAPIKey)
+}
+
+// CreateAPIKey Create an API key
+// @Summary Create an API key
+// @Description Issues an API key for the authenticated user, to be sent as a bearer token. The key is only shown once.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param key body CreateAPIKeyRequest true "API key information"
+// @Success 201 {object} CreateAPIKeyResponse "API key created successfully"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/user/keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ttl: " + req.TTL})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	apiKey, key, err := h.userServiceClient.CreateAPIKey(ctx, userID, req.Name, ttl)
+	if errors.Is(err, service.ErrInvalidAPIKeyRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: *apiKey, Key: key})
+}
+
+// ListAPIKeys List API keys
+// @Summary List API keys
+// @Description Lists the API keys of the authenticated user, without their secrets
+// @Tags users
+// @Produce json
+// @Success 200 {array} model.APIKey "API keys"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/user/keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	keys, err := h.userServiceClient.ListAPIKeys(ctx, userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey Revoke an API key
+// @Summary Revoke an API key
+// @Description Revokes an API key of the authenticated user, which is rejected from then on
+// @Tags users
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]string "API key revoked successfully"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 404 {object} map[string]string "API key not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/user/keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, ok := authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	err := h.userServiceClient.RevokeAPIKey(ctx, userID, c.Param("id"))
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+// authenticatedUser returns the ID of the authenticated user. API keys belong
+// to a user, so without authentication there are none to manage.
+func authenticatedUser(c *gin.Context) (string, bool) {
+	claims, ok := middleware.Claims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication is required to manage API keys"})
+		return "", false
+	}
+	return claims.Subject, true
+}
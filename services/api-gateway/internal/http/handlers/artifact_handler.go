@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/api-gateway/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ArtifactHandler struct {
+	resultServiceClient  service.ResultServiceClient
+	storageServiceClient service.StorageServiceClient
+}
+
+func NewArtifactHandler(resultService service.ResultServiceClient, storageService service.StorageServiceClient) *ArtifactHandler {
+	return &ArtifactHandler{resultServiceClient: resultService, storageServiceClient: storageService}
+}
+
+// ArtifactResponse describes an artifact of a task. Path identifies it for download.
+type ArtifactResponse struct {
+	model.Artifact
+	Path string `json:"path"`
+}
+
+func (h *ArtifactHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/artifacts/:id", h.ListArtifacts)
+	rg.GET("/artifacts/:id/*artifact", h.DownloadArtifact)
+}
+
+// ListArtifacts List task artifacts
+// @Summary List task artifacts
+// @Description Lists the artifacts the subtasks of a task produced, such as binaries, coverage profiles and full output
+// @Tags results
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {array} ArtifactResponse "Artifacts"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Task has no results"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/result/artifacts/{id} [get]
+func (h *ArtifactHandler) ListArtifacts(c *gin.Context) {
+	artifacts, ok := h.listArtifacts(c)
+	if !ok {
+		return
+	}
+
+	resp := make([]ArtifactResponse, len(artifacts))
+	for i, artifact := range artifacts {
+		resp[i] = ArtifactResponse{Artifact: artifact, Path: artifactPath(artifact)}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DownloadArtifact Download task artifact
+// @Summary Download task artifact
+// @Description Downloads an artifact of a task, named by its path or, if no other subtask produced one with the same name, by its name
+// @Tags results
+// @Produce octet-stream
+// @Param id path string true "Task ID"
+// @Param artifact path string true "Artifact path or name"
+// @Success 200 {file} file "Artifact content"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Artifact not found"
+// @Failure 409 {object} map[string]string "Artifact name is ambiguous"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/result/artifacts/{id}/{artifact} [get]
+func (h *ArtifactHandler) DownloadArtifact(c *gin.Context) {
+	name := strings.TrimPrefix(c.Param("artifact"), "/")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Artifact is required"})
+		return
+	}
+
+	artifacts, ok := h.listArtifacts(c)
+	if !ok {
+		return
+	}
+
+	var matches []model.Artifact
+	for _, artifact := range artifacts {
+		if artifactPath(artifact) == name {
+			matches = []model.Artifact{artifact}
+			break
+		}
+		if artifact.Name == name {
+			matches = append(matches, artifact)
+		}
+	}
+	if len(matches) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+		return
+	}
+	if len(matches) > 1 {
+		paths := make([]string, len(matches))
+		for i, artifact := range matches {
+			paths[i] = artifactPath(artifact)
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Several subtasks produced " + name + ", use one of the paths", "paths": paths})
+		return
+	}
+	artifact := matches[0]
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Minute)
+	defer cancel()
+
+	content, err := h.storageServiceClient.GetObject(ctx, artifact.StorageKey)
+	if errors.Is(err, service.ErrObjectNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artifact content not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get artifact: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(artifact.Name)}))
+	c.Header("Content-Length", strconv.Itoa(len(content)))
+	c.Header("ETag", strconv.Quote(artifact.Digest))
+	c.Data(http.StatusOK, artifact.ContentType, content)
+}
+
+// listArtifacts fetches the artifacts of the requested task and writes an
+// error response if they are unavailable
+func (h *ArtifactHandler) listArtifacts(c *gin.Context) ([]model.Artifact, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	artifacts, err := h.resultServiceClient.ListArtifacts(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list artifacts: " + err.Error()})
+		return nil, false
+	}
+
+	if artifacts == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task has no results"})
+		return nil, false
+	}
+
+	return artifacts, true
+}
+
+// artifactPath returns the path that identifies an artifact among those of its task
+func artifactPath(artifact model.Artifact) string {
+	return artifact.SubTaskID + "/" + artifact.Name
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"distributed-analyzer/services/api-gateway/internal/auth"
+	"distributed-analyzer/services/api-gateway/internal/http/middleware"
+	"distributed-analyzer/services/api-gateway/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type BillingHandler struct {
+	billingServiceClient service.BillingServiceClient
+}
+
+func NewBillingHandler(billingService service.BillingServiceClient) *BillingHandler {
+	return &BillingHandler{billingServiceClient: billingService}
+}
+
+type AddCreditsRequest struct {
+	UserID   string  `json:"user_id"`
+	TenantID string  `json:"tenant_id"` // Credits the account of the tenant, shared by its users, if set
+	Amount   float64 `json:"amount" binding:"required,gt=0"`
+}
+
+func (h *BillingHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/balance", h.GetBalance)
+	rg.GET("/history", h.GetHistory)
+	rg.POST("/credits", h.AddCredits)
+}
+
+// GetBalance Get account balance
+// @Summary Get account balance
+// @Description Retrieves the balance of the account of the authenticated user, which is the account of their tenant if they have one. Billing managers may query any account.
+// @Tags billing
+// @Produce json
+// @Param user_id query string false "User whose account to query, for billing managers"
+// @Param tenant_id query string false "Tenant whose account to query, for billing managers"
+// @Success 200 {object} model.Balance "Account balance"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/billing/balance [get]
+func (h *BillingHandler) GetBalance(c *gin.Context) {
+	userID, tenantID, ok := billingAccount(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	balance, err := h.billingServiceClient.GetBalance(ctx, userID, tenantID)
+	if errors.Is(err, service.ErrInvalidBillingRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get balance: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetHistory Get billing history
+// @Summary Get billing history
+// @Description Lists the charges of the account of the authenticated user, oldest first. Billing managers may query any account, and the charges of a tenant caused by one of its users.
+// @Tags billing
+// @Produce json
+// @Param user_id query string false "User whose charges to list, for billing managers"
+// @Param tenant_id query string false "Tenant whose charges to list, for billing managers"
+// @Success 200 {array} model.BillingRecord "Billing records"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Not authenticated"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/billing/history [get]
+func (h *BillingHandler) GetHistory(c *gin.Context) {
+	userID, tenantID, ok := billingAccount(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	records, err := h.billingServiceClient.GetHistory(ctx, userID, tenantID)
+	if errors.Is(err, service.ErrInvalidBillingRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get billing history: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// AddCredits Add credits to an account
+// @Summary Add credits to an account
+// @Description Credits the account of a user or tenant, e.g. after a payment
+// @Tags billing
+// @Accept json
+// @Produce json
+// @Param credits body AddCreditsRequest true "Account and amount"
+// @Success 200 {object} model.Balance "New account balance"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/billing/credits [post]
+func (h *BillingHandler) AddCredits(c *gin.Context) {
+	var req AddCreditsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	balance, err := h.billingServiceClient.AddCredits(ctx, req.UserID, req.TenantID, req.Amount)
+	if errors.Is(err, service.ErrInvalidBillingRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add credits: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// billingAccount returns the user and tenant whose account a request is about.
+// Users see the account of their tenant, or their own without one; billing
+// managers may pick any account with the user_id and tenant_id parameters.
+func billingAccount(c *gin.Context) (string, string, bool) {
+	claims, ok := middleware.Claims(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication is required to access billing"})
+		return "", "", false
+	}
+
+	userID, tenantID := c.Query("user_id"), c.Query("tenant_id")
+	if (userID == "" && tenantID == "") || !auth.Allowed(claims.Roles, auth.PermissionBillingManage) {
+		if claims.TenantID != "" {
+			return "", claims.TenantID, true
+		}
+		return claims.Subject, "", true
+	}
+	return userID, tenantID, true
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/api-gateway/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type LogHandler struct {
+	resultServiceClient service.ResultServiceClient
+}
+
+func NewLogHandler(resultService service.ResultServiceClient) *LogHandler {
+	return &LogHandler{resultServiceClient: resultService}
+}
+
+// LogEvent is a chunk of the command output of a task, sent as a "log" event
+type LogEvent struct {
+	SubTaskID string    `json:"subtask_id"`
+	WorkerID  string    `json:"worker_id"`
+	Stream    string    `json:"stream"`
+	Sequence  int64     `json:"sequence"`
+	Data      string    `json:"data"`
+	WrittenAt time.Time `json:"written_at"`
+}
+
+func (h *LogHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/:id/logs", h.StreamTaskLogs)
+}
+
+// StreamTaskLogs Stream task logs
+// @Summary Stream task logs
+// @Description Streams the stdout and stderr of the commands of a task as Server-Sent Events while it runs.
+// @Description Every "log" event holds a chunk of output; an "end" event follows once the task is finalized,
+// @Description and an "error" event if the stream broke off.
+// @Tags tasks
+// @Produce text/event-stream
+// @Param id path string true "Task ID"
+// @Param follow query bool false "Keep streaming until the task is finalized" default(true)
+// @Success 200 {object} LogEvent "Stream of log events"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "No output was received for the task"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/task/{id}/logs [get]
+func (h *LogHandler) StreamTaskLogs(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return
+	}
+
+	follow, err := strconv.ParseBool(c.DefaultQuery("follow", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid follow parameter: " + err.Error()})
+		return
+	}
+
+	// A followed stream opens right away, so the client sees it while the task
+	// has no output yet; otherwise errors are reported with a status code
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+	}
+	if follow {
+		start()
+	}
+
+	err = h.resultServiceClient.StreamLogs(c.Request.Context(), id, follow, func(chunk *model.LogChunk) error {
+		start()
+		c.SSEvent("log", LogEvent{
+			SubTaskID: chunk.SubTaskID,
+			WorkerID:  chunk.WorkerID,
+			Stream:    chunk.Stream,
+			Sequence:  chunk.Sequence,
+			Data:      string(chunk.Data),
+			WrittenAt: chunk.WrittenAt,
+		})
+		c.Writer.Flush()
+		return nil
+	})
+	if c.Request.Context().Err() != nil {
+		// The client went away
+		return
+	}
+
+	if err != nil && !started {
+		if errors.Is(err, service.ErrLogsNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Logs not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stream logs: " + err.Error()})
+		return
+	}
+
+	start()
+	if err != nil {
+		c.SSEvent("error", gin.H{"error": "Failed to stream logs: " + err.Error()})
+	} else {
+		c.SSEvent("end", gin.H{"task_id": id})
+	}
+	c.Writer.Flush()
+}
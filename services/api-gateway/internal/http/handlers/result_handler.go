@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/api-gateway/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ResultHandler struct {
+	resultServiceClient service.ResultServiceClient
+}
+
+func NewResultHandler(resultService service.ResultServiceClient) *ResultHandler {
+	return &ResultHandler{resultServiceClient: resultService}
+}
+
+func (h *ResultHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/coverage/:id", h.GetCoverageReport)
+	rg.GET("/coverage/:id/html", h.GetCoverageReportHTML)
+	rg.GET("/profile/:id/:kind", h.GetProfileHotspots)
+}
+
+// coverageTemplate renders a coverage report as a standalone HTML page
+var coverageTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"percent": func(p float64) template.CSS {
+		return template.CSS("width:" + formatPercent(p))
+	},
+	"format": formatPercent,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage of task {{.TaskID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: 4px 12px; text-align: left; border-bottom: 1px solid #ddd; }
+td.num { text-align: right; }
+.bar { background: #f2dede; width: 120px; height: 10px; }
+.bar div { background: #5cb85c; height: 10px; }
+</style>
+</head>
+<body>
+<h1>Coverage of task {{.TaskID}}</h1>
+<p>Mode: {{.Mode}}, total: {{format .Percent}} of statements</p>
+<h2>Packages</h2>
+<table>
+<tr><th>Package</th><th>Statements</th><th>Covered</th><th colspan="2">Coverage</th></tr>
+{{range .Packages}}<tr><td>{{.Package}}</td><td class="num">{{.Statements}}</td><td class="num">{{.Covered}}</td><td class="num">{{format .Percent}}</td><td><div class="bar"><div style="{{percent .Percent}}"></div></div></td></tr>
+{{end}}</table>
+<h2>Functions</h2>
+<table>
+<tr><th>File</th><th>Function</th><th>Statements</th><th colspan="2">Coverage</th></tr>
+{{range .Functions}}<tr><td>{{.File}}:{{.Line}}</td><td>{{.Function}}</td><td class="num">{{.Statements}}</td><td class="num">{{format .Percent}}</td><td><div class="bar"><div style="{{percent .Percent}}"></div></div></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// GetCoverageReport Get coverage report
+// @Summary Get coverage report
+// @Description Retrieves the coverage report merged across all subtasks of a task
+// @Tags results
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} model.CoverageReport "Coverage report"
+// @Failure 404 {object} map[string]string "Coverage report not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/result/coverage/{id} [get]
+func (h *ResultHandler) GetCoverageReport(c *gin.Context) {
+	report, ok := h.getCoverageReport(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCoverageReportHTML Render coverage report
+// @Summary Render coverage report
+// @Description Renders the merged coverage report of a task as an HTML page
+// @Tags results
+// @Produce html
+// @Param id path string true "Task ID"
+// @Success 200 {string} string "Coverage report page"
+// @Failure 404 {object} map[string]string "Coverage report not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/result/coverage/{id}/html [get]
+func (h *ResultHandler) GetCoverageReportHTML(c *gin.Context) {
+	report, ok := h.getCoverageReport(c)
+	if !ok {
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := coverageTemplate.Execute(c.Writer, report); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// getCoverageReport fetches the coverage report of the requested task and
+// writes an error response if it is unavailable
+func (h *ResultHandler) getCoverageReport(c *gin.Context) (*model.CoverageReport, bool) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID is required"})
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	report, err := h.resultServiceClient.GetCoverageReport(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get coverage report: " + err.Error()})
+		return nil, false
+	}
+
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Coverage report not found"})
+		return nil, false
+	}
+
+	return report, true
+}
+
+// GetProfileHotspots Get profile hotspots
+// @Summary Get profile hotspots
+// @Description Retrieves the top functions of a cpu, mem, block or mutex profile of a test or benchmark task, merged across all subtasks
+// @Tags results
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param kind path string true "Profile kind" Enums(cpu, mem, block, mutex)
+// @Param sample_type query string false "Sample type, e.g. alloc_space, defaults to that of the profile"
+// @Param sort query string false "Order of the functions" Enums(flat, cum) default(flat)
+// @Param top query int false "Number of functions" default(20)
+// @Success 200 {object} model.ProfileHotspots "Profile hotspots"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Profile not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /api/result/profile/{id}/{kind} [get]
+func (h *ResultHandler) GetProfileHotspots(c *gin.Context) {
+	id := c.Param("id")
+	kind := c.Param("kind")
+	if id == "" || kind == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task ID and profile kind are required"})
+		return
+	}
+
+	top, err := strconv.Atoi(c.DefaultQuery("top", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid top parameter: " + err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	hotspots, err := h.resultServiceClient.GetProfileHotspots(ctx, id, kind, c.Query("sample_type"), c.Query("sort"), top)
+	if errors.Is(err, service.ErrInvalidProfileQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get profile hotspots: " + err.Error()})
+		return
+	}
+
+	if hotspots == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hotspots)
+}
+
+// formatPercent formats a percentage with one decimal
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', 1, 64) + "%"
+}
@@ -2,20 +2,26 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/api-gateway/internal/http/middleware"
 	"distributed-analyzer/services/api-gateway/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
 type TaskHandler struct {
-	taskServiceClient service.TaskServiceClient
+	taskServiceClient    service.TaskServiceClient
+	billingServiceClient service.BillingServiceClient
 }
 
-func NewTaskHandler(taskService service.TaskServiceClient) *TaskHandler {
-	return &TaskHandler{taskServiceClient: taskService}
+// NewTaskHandler creates a TaskHandler. Submissions of users over their quota
+// are rejected if billingService is set.
+func NewTaskHandler(taskService service.TaskServiceClient, billingService service.BillingServiceClient) *TaskHandler {
+	return &TaskHandler{taskServiceClient: taskService, billingServiceClient: billingService}
 }
 
 type TaskRequest struct {
@@ -39,6 +45,8 @@ type TaskResponse struct {
 	Status      string            `json:"status"`
 	Input       map[string]string `json:"input,omitempty"`
 	Output      map[string]string `json:"output,omitempty"`
+	OwnerID     string            `json:"owner_id,omitempty"`
+	TenantID    string            `json:"tenant_id,omitempty"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 }
@@ -51,15 +59,23 @@ func (h *TaskHandler) Register(rg *gin.RouterGroup) {
 	rg.GET("/list", h.ListTasks)
 }
 
+// IdempotencyKeyHeader names the header making task submissions safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 // SubmitTask Submit a new task
 // @Summary Submit a new task
-// @Description Creates a new task in the system
+// @Description Creates a new task in the system. Retries with the Idempotency-Key of an earlier submission return its task instead of creating another.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param task body TaskRequest true "Task information"
+// @Param Idempotency-Key header string false "Key identifying the submission across retries"
+// @Success 200 {object} TaskResponse "Task of an earlier submission with the same key"
 // @Success 201 {object} TaskResponse "Task created successfully"
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 402 {object} map[string]string "Quota exceeded"
+// @Failure 422 {object} map[string]string "Idempotency key used for a different task"
+// @Failure 429 {object} map[string]string "Too many task submissions"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/task/submit [post]
 func (h *TaskHandler) SubmitTask(c *gin.Context) {
@@ -69,6 +85,10 @@ func (h *TaskHandler) SubmitTask(c *gin.Context) {
 		return
 	}
 
+	if !h.checkQuota(c) {
+		return
+	}
+
 	// Create a new task
 	task := &model.Task{
 		Name:        req.Name,
@@ -82,25 +102,68 @@ func (h *TaskHandler) SubmitTask(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	createdTask, err := h.taskServiceClient.CreateTask(ctx, task)
-	if err != nil {
+	createdTask, replayed, err := h.taskServiceClient.CreateTask(ctx, task, c.GetHeader(IdempotencyKeyHeader))
+	switch {
+	case errors.Is(err, service.ErrTaskServiceBusy):
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many task submissions, retry later"})
+		return
+	case errors.Is(err, service.ErrInvalidIdempotencyKey):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + IdempotencyKeyHeader + " header"})
+		return
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": IdempotencyKeyHeader + " was used for a different task"})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task: " + err.Error()})
 		return
 	}
 
-	// Return the created task
-	c.JSON(http.StatusCreated, TaskResponse{
+	// Return the created task, or the task of the first submission with the key
+	code := http.StatusCreated
+	if replayed {
+		code = http.StatusOK
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(code, TaskResponse{
 		ID:          createdTask.ID,
 		Name:        createdTask.Name,
 		Description: createdTask.Description,
 		Status:      string(createdTask.Status),
 		Input:       createdTask.Input,
 		Output:      createdTask.Output,
+		OwnerID:     createdTask.OwnerID,
+		TenantID:    createdTask.TenantID,
 		CreatedAt:   createdTask.CreatedAt,
 		UpdatedAt:   createdTask.UpdatedAt,
 	})
 }
 
+// checkQuota rejects a submission with 402 if the account of the user, or of
+// their tenant, has no balance left. Without authentication there is no
+// account to check. Submissions pass while the billing service is unavailable,
+// so that billing outages do not stop all work.
+func (h *TaskHandler) checkQuota(c *gin.Context) bool {
+	claims, ok := middleware.Claims(c)
+	if h.billingServiceClient == nil || !ok {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Second)
+	defer cancel()
+
+	quota, err := h.billingServiceClient.CheckQuota(ctx, claims.Subject, claims.TenantID)
+	if err != nil {
+		log.Printf("Failed to check quota of user %s, accepting the task: %v", claims.Subject, err)
+		return true
+	}
+	if !quota.Allowed {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "Quota exceeded: " + quota.Reason})
+		return false
+	}
+	return true
+}
+
 // GetTaskStatus Get task status
 // @Summary Get task status
 // @Description Retrieves the status of a task by its ID
@@ -142,6 +205,8 @@ func (h *TaskHandler) GetTaskStatus(c *gin.Context) {
 		Status:      string(task.Status),
 		Input:       task.Input,
 		Output:      task.Output,
+		OwnerID:     task.OwnerID,
+		TenantID:    task.TenantID,
 		CreatedAt:   task.CreatedAt,
 		UpdatedAt:   task.UpdatedAt,
 	})
@@ -214,6 +279,8 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		Status:      string(updatedTask.Status),
 		Input:       updatedTask.Input,
 		Output:      updatedTask.Output,
+		OwnerID:     updatedTask.OwnerID,
+		TenantID:    updatedTask.TenantID,
 		CreatedAt:   updatedTask.CreatedAt,
 		UpdatedAt:   updatedTask.UpdatedAt,
 	})
@@ -227,6 +294,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 // @Param id path string true "Task ID"
 // @Success 200 {object} map[string]string "Task deleted successfully"
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Task not found"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /api/task/delete/{id} [delete]
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
@@ -241,6 +309,10 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	defer cancel()
 
 	err := h.taskServiceClient.DeleteTask(ctx, id)
+	if errors.Is(err, service.ErrTaskNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task: " + err.Error()})
 		return
@@ -252,7 +324,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 
 // ListTasks List all tasks
 // @Summary List all tasks
-// @Description Retrieves a list of all tasks in the system
+// @Description Retrieves the tasks of the tenant of the user, or of the user if they have no tenant
 // @Tags tasks
 // @Produce json
 // @Success 200 {array} TaskResponse "List of tasks"
@@ -279,6 +351,8 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 			Status:      string(task.Status),
 			Input:       task.Input,
 			Output:      task.Output,
+			OwnerID:     task.OwnerID,
+			TenantID:    task.TenantID,
 			CreatedAt:   task.CreatedAt,
 			UpdatedAt:   task.UpdatedAt,
 		})
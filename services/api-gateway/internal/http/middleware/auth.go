@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"distributed-analyzer/libs/network/identity"
+	"distributed-analyzer/services/api-gateway/internal/auth"
+	"distributed-analyzer/services/api-gateway/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// claimsKey is the key of the claims of the authenticated user in the gin context
+const claimsKey = "auth.claims"
+
+// Authenticate rejects requests without a valid bearer token, which is either
+// a JWT or, if apiKeys is set, an API key of the user service. The identity of
+// the user is stored in the request context, which passes it on to the
+// backend services in the metadata of gRPC calls, signed with identityKey.
+func Authenticate(verifier *auth.Verifier, apiKeys service.UserServiceClient, identityKey []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Bearer token is required"})
+			return
+		}
+
+		token = strings.TrimSpace(token)
+		if apiKeys != nil && strings.HasPrefix(token, auth.APIKeyPrefix) {
+			claims, ok := verifyAPIKey(c, apiKeys, token)
+			if !ok {
+				return
+			}
+			authenticated(c, claims, identityKey)
+			return
+		}
+
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			description := "invalid token"
+			if errors.Is(err, auth.ErrTokenExpired) {
+				description = "token expired"
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token", error_description="`+description+`"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
+			return
+		}
+
+		authenticated(c, claims, identityKey)
+	}
+}
+
+// verifyAPIKey resolves an API key to the claims of its user. It aborts the
+// request if the key is invalid or the user service cannot be reached.
+func verifyAPIKey(c *gin.Context, apiKeys service.UserServiceClient, key string) (*auth.Claims, bool) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	user, err := apiKeys.VerifyAPIKey(ctx, key)
+	if errors.Is(err, service.ErrInvalidAPIKey) {
+		c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token", error_description="invalid api key"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		return nil, false
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to verify API key: " + err.Error()})
+		return nil, false
+	}
+
+	claims := &auth.Claims{Subject: user.ID, Username: user.Username, Email: user.Email, TenantID: user.TenantID}
+	for _, name := range user.Roles {
+		if role, ok := auth.ParseRole(name); ok {
+			claims.Roles = append(claims.Roles, role)
+		}
+	}
+	return claims, true
+}
+
+// authenticated stores the claims of the user of a request and continues with the request
+func authenticated(c *gin.Context, claims *auth.Claims, identityKey []byte) {
+	roles := make([]string, len(claims.Roles))
+	for i, role := range claims.Roles {
+		roles[i] = role.String()
+	}
+	id := &identity.Identity{UserID: claims.Subject, Username: claims.Username, TenantID: claims.TenantID, Roles: roles}
+
+	c.Set(claimsKey, claims)
+	c.Request = c.Request.WithContext(identity.NewOutgoingContext(c.Request.Context(), id, identityKey))
+	c.Next()
+}
+
+// Authorize rejects requests whose user lacks the permission the policy
+// requires for the route. It must run after Authenticate.
+func Authorize(policy auth.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := Claims(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+			return
+		}
+
+		permission, ok := policy.Permission(c.Request.Method, c.FullPath())
+		if !ok || !auth.Allowed(claims.Roles, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Claims returns the claims of the authenticated user of a request
+func Claims(c *gin.Context) (*auth.Claims, bool) {
+	value, ok := c.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*auth.Claims)
+	return claims, ok
+}
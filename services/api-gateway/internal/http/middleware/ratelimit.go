@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"distributed-analyzer/libs/network/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitTiers holds the limits of the principals of requests
+type RateLimitTiers struct {
+	// Default is the limit of users without a tier and of anonymous clients
+	Default ratelimit.Limit
+
+	// Roles holds the limits of roles, e.g. ROLE_ADMIN. A user gets the highest rate of their roles.
+	Roles map[string]ratelimit.Limit
+
+	// Tenants holds the limits of tenants, shared by all users of a tenant
+	Tenants map[string]ratelimit.Limit
+}
+
+// principal returns the key and limit of the bucket of a request. Requests
+// are keyed by tenant if it has a limit, by user if authenticated, and by
+// client IP otherwise.
+func (t RateLimitTiers) principal(c *gin.Context) (string, ratelimit.Limit) {
+	claims, ok := Claims(c)
+	if !ok {
+		return "ip:" + c.ClientIP(), t.Default
+	}
+
+	if limit, ok := t.Tenants[claims.TenantID]; ok && claims.TenantID != "" {
+		return "tenant:" + claims.TenantID, limit
+	}
+
+	limit, tiered := t.Default, false
+	for _, role := range claims.Roles {
+		if roleLimit, ok := t.Roles[role.String()]; ok && (!tiered || roleLimit.Rate > limit.Rate) {
+			limit, tiered = roleLimit, true
+		}
+	}
+	return "user:" + claims.Subject, limit
+}
+
+// RateLimit rejects requests of principals that exceed their limit with 429
+// and reports the state of their bucket in RateLimit-* headers. It must run
+// after Authenticate to limit users rather than addresses. Requests pass if
+// the limiter is unavailable.
+func RateLimit(limiter *ratelimit.HTTPLimiter, tiers RateLimitTiers) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, limit := tiers.principal(c)
+		allow(c, limiter, key, limit)
+	}
+}
+
+// RateLimitByAddress limits the requests of every client IP like RateLimit.
+// It runs before Authenticate, so that clients cannot make the gateway verify
+// credentials, and call the user service for API keys, without limit.
+func RateLimitByAddress(limiter *ratelimit.HTTPLimiter, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allow(c, limiter, "addr:"+c.ClientIP(), limit)
+	}
+}
+
+// allow takes a request from the bucket of key and aborts it with 429 if the bucket is empty
+func allow(c *gin.Context, limiter *ratelimit.HTTPLimiter, key string, limit ratelimit.Limit) {
+	result, err := limiter.Allow(c.Request.Context(), key, limit)
+	if err != nil {
+		log.Printf("Rate limiter unavailable, letting %s through: %v", key, err)
+		c.Next()
+		return
+	}
+	result.SetHeaders(c.Writer.Header())
+	if !result.Allowed {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+		return
+	}
+
+	c.Next()
+}
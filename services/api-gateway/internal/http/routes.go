@@ -1,20 +1,135 @@
 package http
 
 import (
+	grpcApp "distributed-analyzer/libs/application/grpc"
+	"distributed-analyzer/libs/network/ratelimit"
+	"distributed-analyzer/services/api-gateway/internal/auth"
 	"distributed-analyzer/services/api-gateway/internal/config"
 	"distributed-analyzer/services/api-gateway/internal/http/handlers"
+	"distributed-analyzer/services/api-gateway/internal/http/middleware"
+	"distributed-analyzer/services/api-gateway/internal/service"
 	"distributed-analyzer/services/api-gateway/internal/service/grpc"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.Engine, cfg *config.Config) *gin.Engine {
+// routePermissions holds the permission every API route requires
+var routePermissions = auth.Policy{
+	"POST /api/task/submit":                   auth.PermissionTaskSubmit,
+	"GET /api/task/status/:id":                auth.PermissionTaskRead,
+	"PUT /api/task/update":                    auth.PermissionTaskUpdate,
+	"DELETE /api/task/delete/:id":             auth.PermissionTaskDelete,
+	"GET /api/task/list":                      auth.PermissionTaskRead,
+	"GET /api/task/:id/logs":                  auth.PermissionTaskRead,
+	"GET /api/result/coverage/:id":            auth.PermissionResultRead,
+	"GET /api/result/coverage/:id/html":       auth.PermissionResultRead,
+	"GET /api/result/profile/:id/:kind":       auth.PermissionResultRead,
+	"GET /api/result/artifacts/:id":           auth.PermissionResultRead,
+	"GET /api/result/artifacts/:id/*artifact": auth.PermissionResultRead,
+	"POST /api/user/keys":                     auth.PermissionAPIKeys,
+	"GET /api/user/keys":                      auth.PermissionAPIKeys,
+	"DELETE /api/user/keys/:id":               auth.PermissionAPIKeys,
+	"GET /api/billing/balance":                auth.PermissionBillingRead,
+	"GET /api/billing/history":                auth.PermissionBillingRead,
+	"POST /api/billing/credits":               auth.PermissionBillingManage,
+}
+
+// RegisterRoutes registers the API routes. With a verifier, every route
+// requires a valid token or API key whose roles grant the permission of the
+// route. With rate limiting enabled, every client address is limited first,
+// and then every principal to its tier.
+func RegisterRoutes(r *gin.Engine, cfg *config.Config, verifier *auth.Verifier) *gin.Engine {
+	userServiceGrpcClient, _ := grpc.NewUserServiceGrpcClient(cfg.Services.User.GRPCAddr)
+	billingServiceGrpcClient, _ := grpc.NewBillingServiceGrpcClient(cfg.Services.Billing.GRPCAddr)
+
+	var limiter *ratelimit.HTTPLimiter
+	if cfg.RateLimit.Enabled {
+		limiter = newRateLimiter(cfg.RateLimit)
+	}
+
 	api := r.Group("/api")
-	RegisterTaskRoutes(api, cfg)
+	if limiter != nil {
+		api.Use(middleware.RateLimitByAddress(limiter, ratelimit.Limit{Rate: cfg.RateLimit.IPRate, Burst: cfg.RateLimit.IPBurst}))
+	}
+	if verifier != nil {
+		api.Use(middleware.Authenticate(verifier, userServiceGrpcClient, grpcApp.IdentityKey(cfg.Identity)))
+	}
+	if limiter != nil {
+		api.Use(middleware.RateLimit(limiter, rateLimitTiers(cfg.RateLimit)))
+	}
+	if verifier != nil {
+		api.Use(middleware.Authorize(routePermissions))
+	}
+	RegisterTaskRoutes(api, cfg, billingServiceGrpcClient)
+	RegisterResultRoutes(api, cfg)
+	RegisterLogRoutes(api, cfg)
+	RegisterArtifactRoutes(api, cfg)
+	RegisterUserRoutes(api, userServiceGrpcClient)
+	RegisterBillingRoutes(api, billingServiceGrpcClient)
 	return r
 }
 
-func RegisterTaskRoutes(rg *gin.RouterGroup, cfg *config.Config) {
+// newRateLimiter creates the limiter keeping the buckets of clients, in Redis
+// if configured and in memory otherwise
+func newRateLimiter(cfg config.RateLimitConfig) *ratelimit.HTTPLimiter {
+	limiterCfg := ratelimit.DefaultHTTPConfig()
+	limiterCfg.MaxKeys = cfg.MaxKeys
+	if cfg.RedisAddr != "" {
+		redisCfg := ratelimit.DefaultRedisConfig(cfg.RedisAddr)
+		redisCfg.Password = cfg.RedisPassword
+		redisCfg.DB = cfg.RedisDB
+		redisCfg.Prefix = "api-gateway:ratelimit:"
+		limiterCfg.Limiter = ratelimit.NewRedisLimiter(redisCfg)
+	}
+	return ratelimit.NewHTTPLimiter(limiterCfg)
+}
+
+// rateLimitTiers returns the limits of the principals of requests
+func rateLimitTiers(cfg config.RateLimitConfig) middleware.RateLimitTiers {
+	tiers := middleware.RateLimitTiers{
+		Default: ratelimit.Limit{Rate: cfg.Rate, Burst: cfg.Burst},
+		Roles:   make(map[string]ratelimit.Limit, len(cfg.Roles)),
+		Tenants: make(map[string]ratelimit.Limit, len(cfg.Tenants)),
+	}
+	for role, tier := range cfg.Roles {
+		tiers.Roles[role] = ratelimit.Limit{Rate: tier.Rate, Burst: tier.Burst}
+	}
+	for tenant, tier := range cfg.Tenants {
+		tiers.Tenants[tenant] = ratelimit.Limit{Rate: tier.Rate, Burst: tier.Burst}
+	}
+	return tiers
+}
+
+func RegisterTaskRoutes(rg *gin.RouterGroup, cfg *config.Config, billingServiceClient service.BillingServiceClient) {
 	taskServiceGrpcClient, _ := grpc.NewTaskServiceGrpcClient(cfg.Services.Task.GRPCAddr)
-	handler := handlers.NewTaskHandler(taskServiceGrpcClient)
+	handler := handlers.NewTaskHandler(taskServiceGrpcClient, billingServiceClient)
 	handler.Register(rg.Group("/task"))
 }
+
+func RegisterResultRoutes(rg *gin.RouterGroup, cfg *config.Config) {
+	resultServiceGrpcClient, _ := grpc.NewResultServiceGrpcClient(cfg.Services.Result.GRPCAddr)
+	handler := handlers.NewResultHandler(resultServiceGrpcClient)
+	handler.Register(rg.Group("/result"))
+}
+
+func RegisterLogRoutes(rg *gin.RouterGroup, cfg *config.Config) {
+	resultServiceGrpcClient, _ := grpc.NewResultServiceGrpcClient(cfg.Services.Result.GRPCAddr)
+	handler := handlers.NewLogHandler(resultServiceGrpcClient)
+	handler.Register(rg.Group("/task"))
+}
+
+func RegisterArtifactRoutes(rg *gin.RouterGroup, cfg *config.Config) {
+	resultServiceGrpcClient, _ := grpc.NewResultServiceGrpcClient(cfg.Services.Result.GRPCAddr)
+	storageServiceGrpcClient, _ := grpc.NewStorageServiceGrpcClient(cfg.Services.Storage.GRPCAddr)
+	handler := handlers.NewArtifactHandler(resultServiceGrpcClient, storageServiceGrpcClient)
+	handler.Register(rg.Group("/result"))
+}
+
+func RegisterUserRoutes(rg *gin.RouterGroup, userServiceClient service.UserServiceClient) {
+	handler := handlers.NewAPIKeyHandler(userServiceClient)
+	handler.Register(rg.Group("/user"))
+}
+
+func RegisterBillingRoutes(rg *gin.RouterGroup, billingServiceClient service.BillingServiceClient) {
+	handler := handlers.NewBillingHandler(billingServiceClient)
+	handler.Register(rg.Group("/billing"))
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"errors"
+)
+
+// ErrInvalidBillingRequest is returned when the billing service rejects an account or amount
+var ErrInvalidBillingRequest = errors.New("invalid billing request")
+
+type BillingServiceClient interface {
+	// CheckQuota reports whether the account of a user, or of their tenant if
+	// set, has balance left to submit tasks, and returns the balance with the
+	// reason it has none
+	CheckQuota(ctx context.Context, userID, tenantID string) (*QuotaStatus, error)
+
+	// GetBalance retrieves the balance of the account of a user or tenant
+	GetBalance(ctx context.Context, userID, tenantID string) (*model.Balance, error)
+
+	// GetHistory retrieves the billing records of the account of a user or
+	// tenant, narrowed to a user of the tenant if both are set
+	GetHistory(ctx context.Context, userID, tenantID string) ([]*model.BillingRecord, error)
+
+	// AddCredits credits the account of a user or tenant with a positive amount.
+	// It returns ErrInvalidBillingRequest if the account or amount is invalid.
+	AddCredits(ctx context.Context, userID, tenantID string, amount float64) (*model.Balance, error)
+}
+
+// QuotaStatus is the outcome of a quota check
+type QuotaStatus struct {
+	Allowed  bool
+	Balance  float64
+	Currency string
+	Reason   string
+}
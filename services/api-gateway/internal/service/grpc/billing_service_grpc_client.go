@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/billing"
+	clientService "distributed-analyzer/services/api-gateway/internal/service"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type BillingServiceGrpcClient struct {
+	client pb.BillingServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ clientService.BillingServiceClient = (*BillingServiceGrpcClient)(nil)
+
+func NewBillingServiceGrpcClient(serverAddr string) (*BillingServiceGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BillingServiceGrpcClient{
+		client: pb.NewBillingServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+func (b *BillingServiceGrpcClient) Close() error {
+	return b.conn.Close()
+}
+
+func (b *BillingServiceGrpcClient) CheckQuota(ctx context.Context, userID, tenantID string) (*clientService.QuotaStatus, error) {
+	resp, err := b.client.CheckQuota(ctx, &pb.CheckQuotaRequest{UserId: userID, TenantId: tenantID})
+	if err != nil {
+		return nil, invalidBillingRequest(err)
+	}
+
+	return &clientService.QuotaStatus{
+		Allowed:  resp.Allowed,
+		Balance:  resp.Balance,
+		Currency: resp.Currency,
+		Reason:   resp.Reason,
+	}, nil
+}
+
+func (b *BillingServiceGrpcClient) GetBalance(ctx context.Context, userID, tenantID string) (*model.Balance, error) {
+	resp, err := b.client.GetUserBalance(ctx, &pb.GetUserBalanceRequest{UserId: userID, TenantId: tenantID})
+	if err != nil {
+		return nil, invalidBillingRequest(err)
+	}
+
+	balance := &model.Balance{TenantID: tenantID, Balance: resp.Balance, Currency: resp.Currency}
+	if tenantID == "" {
+		balance.UserID = userID
+	}
+	return balance, nil
+}
+
+func (b *BillingServiceGrpcClient) GetHistory(ctx context.Context, userID, tenantID string) ([]*model.BillingRecord, error) {
+	resp, err := b.client.GetBillingHistory(ctx, &pb.GetBillingHistoryRequest{UserId: userID, TenantId: tenantID})
+	if err != nil {
+		return nil, invalidBillingRequest(err)
+	}
+
+	records := make([]*model.BillingRecord, len(resp.Records))
+	for i, record := range resp.Records {
+		records[i] = &model.BillingRecord{
+			ID:       record.Id,
+			UserID:   record.UserId,
+			TenantID: record.TenantId,
+			TaskID:   record.TaskId,
+			Amount:   record.Amount,
+			Currency: record.Currency,
+			Usage: model.ResourceUsage{
+				CPUSeconds:      record.CpuSeconds,
+				MemoryGBSeconds: record.MemoryGbSeconds,
+			},
+			Time: record.Timestamp.AsTime(),
+		}
+	}
+	return records, nil
+}
+
+func (b *BillingServiceGrpcClient) AddCredits(ctx context.Context, userID, tenantID string, amount float64) (*model.Balance, error) {
+	resp, err := b.client.AddUserBalance(ctx, &pb.AddUserBalanceRequest{UserId: userID, TenantId: tenantID, Amount: amount})
+	if err != nil {
+		return nil, invalidBillingRequest(err)
+	}
+
+	return &model.Balance{
+		UserID:    resp.Balance.UserId,
+		TenantID:  resp.Balance.TenantId,
+		Balance:   resp.Balance.Balance,
+		Currency:  resp.Balance.Currency,
+		UpdatedAt: resp.Balance.UpdatedAt.AsTime(),
+	}, nil
+}
+
+// invalidBillingRequest maps an InvalidArgument error of the billing service to ErrInvalidBillingRequest
+func invalidBillingRequest(err error) error {
+	if status.Code(err) == codes.InvalidArgument {
+		return fmt.Errorf("%w: %s", clientService.ErrInvalidBillingRequest, status.Convert(err).Message())
+	}
+	return err
+}
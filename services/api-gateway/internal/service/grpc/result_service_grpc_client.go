@@ -0,0 +1,187 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/result"
+	clientService "distributed-analyzer/services/api-gateway/internal/service"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+)
+
+type ResultServiceGrpcClient struct {
+	client pb.ResultAggregatorServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ clientService.ResultServiceClient = (*ResultServiceGrpcClient)(nil)
+
+func NewResultServiceGrpcClient(serverAddr string) (*ResultServiceGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultServiceGrpcClient{
+		client: pb.NewResultAggregatorServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+func (r *ResultServiceGrpcClient) Close() error {
+	return r.conn.Close()
+}
+
+func (r *ResultServiceGrpcClient) GetCoverageReport(ctx context.Context, taskID string) (*model.CoverageReport, error) {
+	resp, err := r.client.GetCoverageReport(ctx, &pb.GetCoverageReportRequest{TaskId: taskID})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return convertPbCoverageReportToModel(resp.Report), nil
+}
+
+func (r *ResultServiceGrpcClient) GetProfileHotspots(ctx context.Context, taskID, kind, sampleType, order string, limit int) (*model.ProfileHotspots, error) {
+	resp, err := r.client.GetProfileHotspots(ctx, &pb.GetProfileHotspotsRequest{
+		TaskId:     taskID,
+		Kind:       kind,
+		SampleType: sampleType,
+		Order:      order,
+		Limit:      int32(limit),
+	})
+	switch status.Code(err) {
+	case codes.NotFound:
+		return nil, nil
+	case codes.InvalidArgument:
+		return nil, fmt.Errorf("%w: %s", clientService.ErrInvalidProfileQuery, status.Convert(err).Message())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	h := resp.Hotspots
+	hotspots := &model.ProfileHotspots{
+		TaskID:      h.TaskId,
+		Kind:        h.Kind,
+		SampleType:  h.SampleType,
+		Unit:        h.Unit,
+		SampleTypes: h.SampleTypes,
+		Profiles:    int(h.Profiles),
+		Total:       h.Total,
+		Hotspots:    make([]model.Hotspot, len(h.Hotspots)),
+	}
+	for i, hotspot := range h.Hotspots {
+		hotspots.Hotspots[i] = model.Hotspot{
+			Function:    hotspot.Function,
+			File:        hotspot.File,
+			Flat:        hotspot.Flat,
+			FlatPercent: hotspot.FlatPercent,
+			Cum:         hotspot.Cum,
+			CumPercent:  hotspot.CumPercent,
+		}
+	}
+	return hotspots, nil
+}
+
+func (r *ResultServiceGrpcClient) ListArtifacts(ctx context.Context, taskID string) ([]model.Artifact, error) {
+	resp, err := r.client.ListArtifacts(ctx, &pb.ListArtifactsRequest{TaskId: taskID})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := make([]model.Artifact, len(resp.Artifacts))
+	for i, a := range resp.Artifacts {
+		artifacts[i] = model.Artifact{
+			SubTaskID:   a.SubtaskId,
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			Digest:      a.Digest,
+			StorageKey:  a.StorageKey,
+		}
+	}
+
+	return artifacts, nil
+}
+
+func (r *ResultServiceGrpcClient) StreamLogs(ctx context.Context, taskID string, follow bool, send func(*model.LogChunk) error) error {
+	stream, err := r.client.StreamLogs(ctx, &pb.StreamLogsRequest{TaskId: taskID, Follow: follow})
+	if err != nil {
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if status.Code(err) == codes.NotFound {
+			return clientService.ErrLogsNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := send(convertPbLogChunkToModel(chunk)); err != nil {
+			return err
+		}
+	}
+}
+
+// convertPbLogChunkToModel converts a pb.LogChunk to a model.LogChunk
+func convertPbLogChunkToModel(c *pb.LogChunk) *model.LogChunk {
+	return &model.LogChunk{
+		TaskID:    c.TaskId,
+		SubTaskID: c.SubtaskId,
+		WorkerID:  c.WorkerId,
+		Stream:    c.Stream,
+		Sequence:  c.Sequence,
+		Data:      c.Data,
+		WrittenAt: c.WrittenAt.AsTime(),
+	}
+}
+
+// convertPbCoverageReportToModel converts a pb.CoverageReport to a model.CoverageReport
+func convertPbCoverageReportToModel(r *pb.CoverageReport) *model.CoverageReport {
+	report := &model.CoverageReport{
+		TaskID:    r.TaskId,
+		Mode:      r.Mode,
+		Percent:   r.Percent,
+		Packages:  make([]model.PackageCoverage, len(r.Packages)),
+		Functions: make([]model.FunctionCoverage, len(r.Functions)),
+		Profile:   r.Profile,
+	}
+
+	for i, p := range r.Packages {
+		report.Packages[i] = model.PackageCoverage{
+			Package:    p.Package,
+			Statements: p.Statements,
+			Covered:    p.Covered,
+			Percent:    p.Percent,
+		}
+	}
+
+	for i, f := range r.Functions {
+		report.Functions[i] = model.FunctionCoverage{
+			File:       f.File,
+			Function:   f.Function,
+			Line:       int(f.Line),
+			Statements: f.Statements,
+			Covered:    f.Covered,
+			Percent:    f.Percent,
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/storage"
+	clientService "distributed-analyzer/services/api-gateway/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxObjectMessageSize bounds the size of objects fetched from the storage service
+const maxObjectMessageSize = 128 << 20
+
+type StorageServiceGrpcClient struct {
+	client pb.StorageServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ clientService.StorageServiceClient = (*StorageServiceGrpcClient)(nil)
+
+func NewStorageServiceGrpcClient(serverAddr string) (*StorageServiceGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StorageServiceGrpcClient{
+		client: pb.NewStorageServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+func (s *StorageServiceGrpcClient) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StorageServiceGrpcClient) GetObject(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.client.GetObject(ctx, &pb.GetObjectRequest{Key: key}, grpc.MaxCallRecvMsgSize(maxObjectMessageSize))
+	if status.Code(err) == codes.NotFound {
+		return nil, clientService.ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Content, nil
+}
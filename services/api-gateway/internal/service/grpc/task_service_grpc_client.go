@@ -7,6 +7,8 @@ import (
 	pb "distributed-analyzer/libs/proto/task"
 	clientService "distributed-analyzer/services/api-gateway/internal/service"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -35,7 +37,7 @@ func (t *TaskServiceGrpcClient) Close() error {
 	return t.conn.Close()
 }
 
-func (t *TaskServiceGrpcClient) CreateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+func (t *TaskServiceGrpcClient) CreateTask(ctx context.Context, task *model.Task, idempotencyKey string) (*model.Task, bool, error) {
 	// Convert resources if any
 	var resources []*pb.Resource
 	if len(task.Resources) > 0 {
@@ -53,14 +55,22 @@ func (t *TaskServiceGrpcClient) CreateTask(ctx context.Context, task *model.Task
 		Description: task.Description,
 		Input:       task.Input,
 		Resources:   resources,
+		RequestId:   idempotencyKey,
 	}
 
 	resp, err := t.client.CreateTask(ctx, req)
-	if err != nil {
-		return nil, err
+	switch status.Code(err) {
+	case codes.OK:
+		return convertPbTaskToModelTask(resp.Task), resp.Replayed, nil
+	case codes.ResourceExhausted:
+		return nil, false, clientService.ErrTaskServiceBusy
+	case codes.InvalidArgument:
+		return nil, false, clientService.ErrInvalidIdempotencyKey
+	case codes.FailedPrecondition:
+		return nil, false, clientService.ErrIdempotencyKeyReused
+	default:
+		return nil, false, err
 	}
-
-	return convertPbTaskToModelTask(resp.Task), nil
 }
 
 // GetTask retrieves a task by its ID
@@ -70,6 +80,9 @@ func (t *TaskServiceGrpcClient) GetTask(ctx context.Context, id string) (*model.
 	}
 
 	resp, err := t.client.GetTask(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -99,6 +112,9 @@ func (t *TaskServiceGrpcClient) DeleteTask(ctx context.Context, id string) error
 	}
 
 	_, err := t.client.DeleteTask(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return clientService.ErrTaskNotFound
+	}
 	return err
 }
 
@@ -130,6 +146,8 @@ func convertModelTaskToPbTask(task *model.Task) *pb.Task {
 		Status:      convertModelStatusToPbStatus(task.Status),
 		Input:       task.Input,
 		Output:      task.Output,
+		OwnerId:     task.OwnerID,
+		TenantId:    task.TenantID,
 		CreatedAt:   timestamppb.New(task.CreatedAt),
 		UpdatedAt:   timestamppb.New(task.UpdatedAt),
 	}
@@ -160,6 +178,8 @@ func convertPbTaskToModelTask(pbTask *pb.Task) *model.Task {
 		Status:      convertPbStatusToModelStatus(pbTask.Status),
 		Input:       pbTask.Input,
 		Output:      pbTask.Output,
+		OwnerID:     pbTask.OwnerId,
+		TenantID:    pbTask.TenantId,
 	}
 
 	if pbTask.CreatedAt != nil {
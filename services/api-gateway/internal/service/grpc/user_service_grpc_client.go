@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/user"
+	clientService "distributed-analyzer/services/api-gateway/internal/service"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+type UserServiceGrpcClient struct {
+	client pb.UserServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ clientService.UserServiceClient = (*UserServiceGrpcClient)(nil)
+
+func NewUserServiceGrpcClient(serverAddr string) (*UserServiceGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserServiceGrpcClient{
+		client: pb.NewUserServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+func (u *UserServiceGrpcClient) Close() error {
+	return u.conn.Close()
+}
+
+func (u *UserServiceGrpcClient) VerifyAPIKey(ctx context.Context, key string) (*model.User, error) {
+	resp, err := u.client.VerifyAPIKey(ctx, &pb.VerifyAPIKeyRequest{Key: key})
+	if status.Code(err) == codes.Unauthenticated {
+		return nil, clientService.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return convertPbUserToModel(resp.User), nil
+}
+
+func (u *UserServiceGrpcClient) CreateAPIKey(ctx context.Context, userID, name string, ttl time.Duration) (*model.APIKey, string, error) {
+	resp, err := u.client.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{
+		UserId:     userID,
+		Name:       name,
+		TtlSeconds: int64(ttl / time.Second),
+	})
+	switch status.Code(err) {
+	case codes.NotFound:
+		return nil, "", clientService.ErrUserNotFound
+	case codes.InvalidArgument, codes.ResourceExhausted:
+		return nil, "", fmt.Errorf("%w: %s", clientService.ErrInvalidAPIKeyRequest, status.Convert(err).Message())
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return convertPbAPIKeyToModel(resp.ApiKey), resp.Key, nil
+}
+
+func (u *UserServiceGrpcClient) ListAPIKeys(ctx context.Context, userID string) ([]*model.APIKey, error) {
+	resp, err := u.client.ListAPIKeys(ctx, &pb.ListAPIKeysRequest{UserId: userID})
+	if status.Code(err) == codes.NotFound {
+		return nil, clientService.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*model.APIKey, len(resp.ApiKeys))
+	for i, key := range resp.ApiKeys {
+		keys[i] = convertPbAPIKeyToModel(key)
+	}
+	return keys, nil
+}
+
+func (u *UserServiceGrpcClient) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	_, err := u.client.RevokeAPIKey(ctx, &pb.RevokeAPIKeyRequest{UserId: userID, KeyId: keyID})
+	if status.Code(err) == codes.NotFound {
+		return clientService.ErrAPIKeyNotFound
+	}
+	return err
+}
+
+func convertPbUserToModel(user *pb.User) *model.User {
+	roles := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roles[i] = role.String()
+	}
+
+	return &model.User{
+		ID:        user.Id,
+		Username:  user.Username,
+		Email:     user.Email,
+		Roles:     roles,
+		TenantID:  user.TenantId,
+		CreatedAt: user.CreatedAt.AsTime(),
+	}
+}
+
+func convertPbAPIKeyToModel(key *pb.APIKey) *model.APIKey {
+	return &model.APIKey{
+		ID:         key.Id,
+		UserID:     key.UserId,
+		Name:       key.Name,
+		CreatedAt:  key.CreatedAt.AsTime(),
+		ExpiresAt:  optionalTime(key.ExpiresAt),
+		LastUsedAt: optionalTime(key.LastUsedAt),
+		RevokedAt:  optionalTime(key.RevokedAt),
+	}
+}
+
+// optionalTime converts a timestamp to a time, or the zero time if it is unset
+func optionalTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"errors"
+)
+
+// ErrLogsNotFound is returned when no output was received for a task
+var ErrLogsNotFound = errors.New("logs not found")
+
+// ErrInvalidProfileQuery is returned when a profile has no such sample type or order
+var ErrInvalidProfileQuery = errors.New("invalid profile query")
+
+type ResultServiceClient interface {
+	// GetCoverageReport retrieves the merged coverage report of a task.
+	// It returns nil if the task has no coverage report.
+	GetCoverageReport(ctx context.Context, taskID string) (*model.CoverageReport, error)
+
+	// StreamLogs calls send with the chunks of the command output of a task in
+	// order. With follow, it keeps streaming until the task is finalized.
+	// It returns ErrLogsNotFound if no output was received.
+	StreamLogs(ctx context.Context, taskID string, follow bool, send func(*model.LogChunk) error) error
+
+	// ListArtifacts lists the artifacts the subtasks of a task produced.
+	// It returns nil if no subtask of the task has completed.
+	ListArtifacts(ctx context.Context, taskID string) ([]model.Artifact, error)
+
+	// GetProfileHotspots retrieves the top functions of a profile of a task,
+	// merged across its subtasks. It returns nil if the task has no such profile
+	// and ErrInvalidProfileQuery if the sample type or order is unknown.
+	GetProfileHotspots(ctx context.Context, taskID, kind, sampleType, order string, limit int) (*model.ProfileHotspots, error)
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrObjectNotFound is returned when no object exists under the requested key
+var ErrObjectNotFound = errors.New("object not found")
+
+type StorageServiceClient interface {
+	// GetObject retrieves the content of an object.
+	// It returns ErrObjectNotFound if there is no object with the key.
+	GetObject(ctx context.Context, key string) ([]byte, error)
+}
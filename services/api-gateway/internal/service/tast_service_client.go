@@ -3,21 +3,38 @@ package service
 import (
 	"context"
 	"distributed-analyzer/libs/model"
+	"errors"
 )
 
+// ErrTaskNotFound is returned when a task does not exist or belongs to another tenant
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrTaskServiceBusy is returned when the task service rejects a request because of its limits
+var ErrTaskServiceBusy = errors.New("task service busy")
+
+// ErrInvalidIdempotencyKey is returned when an idempotency key is too long
+var ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
+// ErrIdempotencyKeyReused is returned when an idempotency key was used to create a different task
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused")
+
 type TaskServiceClient interface {
-	// CreateTask creates a new task in the system
-	CreateTask(ctx context.Context, task *model.Task) (*model.Task, error)
+	// CreateTask creates a new task in the system. A repeat of an idempotency
+	// key of the caller returns the task created first and true instead.
+	// It returns ErrTaskServiceBusy if the task service sheds the request.
+	CreateTask(ctx context.Context, task *model.Task, idempotencyKey string) (*model.Task, bool, error)
 
-	// GetTask retrieves a task by its ID
+	// GetTask retrieves a task by its ID.
+	// It returns nil if there is no such task the caller may access.
 	GetTask(ctx context.Context, id string) (*model.Task, error)
 
 	// UpdateTask updates an existing task
 	UpdateTask(ctx context.Context, task *model.Task) (*model.Task, error)
 
-	// DeleteTask removes a task from the system
+	// DeleteTask removes a task from the system.
+	// It returns ErrTaskNotFound if there is no such task the caller may access.
 	DeleteTask(ctx context.Context, id string) error
 
-	// ListTasks retrieves all tasks with optional filtering
+	// ListTasks retrieves all tasks the caller may access
 	ListTasks(ctx context.Context) ([]*model.Task, error)
 }
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"errors"
+	"time"
+)
+
+// Errors returned by the user service client
+var (
+	ErrInvalidAPIKey        = errors.New("invalid api key")
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrUserNotFound         = errors.New("user not found")
+)
+
+type UserServiceClient interface {
+	// VerifyAPIKey returns the user an API key belongs to.
+	// It returns ErrInvalidAPIKey for unknown, revoked or expired keys.
+	VerifyAPIKey(ctx context.Context, key string) (*model.User, error)
+
+	// CreateAPIKey issues an API key for a user, which expires after ttl if it
+	// is positive, and returns it with its secret. It returns ErrUserNotFound
+	// for unknown users and ErrInvalidAPIKeyRequest if the key is not allowed.
+	CreateAPIKey(ctx context.Context, userID, name string, ttl time.Duration) (*model.APIKey, string, error)
+
+	// ListAPIKeys lists the API keys of a user, newest first.
+	// It returns ErrUserNotFound for unknown users.
+	ListAPIKeys(ctx context.Context, userID string) ([]*model.APIKey, error)
+
+	// RevokeAPIKey revokes an API key of a user.
+	// It returns ErrAPIKeyNotFound if the user has no such key.
+	RevokeAPIKey(ctx context.Context, userID, keyID string) error
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	configloader "distributed-analyzer/libs/config"
+	"distributed-analyzer/services/audit-service/internal/bootstrap"
+	"distributed-analyzer/services/audit-service/internal/config"
+)
+
+func main() {
+	var cfg = configloader.LoadApplicationConfig[config.Config]("audit-service")
+	bootstrap.StartApplication(&cfg)
+}
@@ -0,0 +1,3 @@
+module distributed-analyzer/services/audit-service
+
+go 1.24
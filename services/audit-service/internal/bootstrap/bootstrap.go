@@ -0,0 +1,62 @@
+// Package bootstrap provides functionality to initialize and start the application components.
+package bootstrap
+
+import (
+	"distributed-analyzer/libs/application"
+	grpcApp "distributed-analyzer/libs/application/grpc"
+	kafkaApp "distributed-analyzer/libs/application/kafka"
+	"distributed-analyzer/libs/kafka"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/logging"
+	pb "distributed-analyzer/libs/proto/audit"
+	"distributed-analyzer/libs/store"
+	"distributed-analyzer/services/audit-service/internal/config"
+	"distributed-analyzer/services/audit-service/internal/grpc"
+	auditKafka "distributed-analyzer/services/audit-service/internal/kafka"
+	"distributed-analyzer/services/audit-service/internal/service"
+	stdgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"log"
+)
+
+// StartApplication initializes and starts all application components.
+// It loads the audit log, records the audit events from Kafka and sets up the gRPC server.
+func StartApplication(cfg *config.Config) {
+	auditLog, err := store.NewLogFile[libmodel.AuditRecord](cfg.Store.Path)
+	if err != nil {
+		log.Fatalf("Failed to initialize audit log: %v", err)
+	}
+
+	auditService, err := service.NewAuditServiceImpl(auditLog, cfg.Chain.Key)
+	if err != nil {
+		log.Fatalf("Failed to initialize audit service: %v", err)
+	}
+
+	runner := application.NewApplicationRunner(
+		initGrpc(cfg, auditService),
+		initKafkaConsumerComponent(cfg, auditService),
+	)
+	runner.Defer(auditLog.Close)
+	runner.DefaultStart()
+}
+
+// initKafkaConsumerComponent creates a Kafka consumer component recording the audit events.
+func initKafkaConsumerComponent(cfg *config.Config, auditService service.AuditService) *kafkaApp.ConsumerComponent {
+	handler := auditKafka.NewAuditHandler(auditService)
+	consumer := kafka.NewConsumer([]string{kafka.AuditTopic}, cfg.Kafka.Brokers, cfg.Kafka.GroupID, handler)
+
+	return kafkaApp.NewKafkaComponent(consumer)
+}
+
+// initGrpc initializes the gRPC component with the configured server, which limits its callers.
+func initGrpc(cfg *config.Config, auditService service.AuditService) *grpcApp.Component {
+	limiter := grpcApp.NewServerLimiter(cfg.GrpcLimit)
+	grpcServer := stdgrpc.NewServer(
+		stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor(), limiter.UnaryServerInterceptor()),
+	)
+
+	pb.RegisterAuditServiceServer(grpcServer, grpc.NewAuditServer(auditService))
+	reflection.Register(grpcServer)
+
+	return grpcApp.NewGrpcComponent(grpcServer, &cfg.ServerConfig)
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	configloader "distributed-analyzer/libs/config"
+)
+
+// Config is the main configuration for the audit service
+type Config struct {
+	configloader.ServerConfig `yaml:",inline"`
+
+	Kafka     configloader.KafkaConfig     `yaml:"kafka"`
+	Store     StoreConfig                  `yaml:"store"`
+	Chain     ChainConfig                  `yaml:"chain"`
+	Log       configloader.LogConfig       `yaml:"log"`
+	GrpcLimit configloader.GrpcLimitConfig `yaml:"grpc_limit"`
+}
+
+// StoreConfig holds the settings of the append-only audit log
+type StoreConfig struct {
+	Path string `yaml:"path" env:"STORE_PATH" env-default:"/var/lib/audit-service/audit.log"`
+}
+
+// ChainConfig holds the settings of the hash chain of the audit log
+type ChainConfig struct {
+	// Key makes the hashes HMACs, so that rewriting the log needs the key.
+	// Without it, the hashes are plain SHA-256.
+	Key string `yaml:"key" env:"AUDIT_CHAIN_KEY"`
+}
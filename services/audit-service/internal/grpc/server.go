@@ -0,0 +1,182 @@
+package grpc
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/audit"
+	"distributed-analyzer/services/audit-service/internal/service"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+	"time"
+)
+
+// AuditServer implements the AuditServiceServer interface
+type AuditServer struct {
+	pb.UnimplementedAuditServiceServer
+	auditService service.AuditService
+}
+
+// NewAuditServer creates a new AuditServer
+func NewAuditServer(auditService service.AuditService) *AuditServer {
+	return &AuditServer{
+		auditService: auditService,
+	}
+}
+
+// LogAction logs an action in the audit log
+func (s *AuditServer) LogAction(ctx context.Context, req *pb.LogActionRequest) (*pb.AuditLogResponse, error) {
+	action, err := actionFromPb(req.Action)
+	if err != nil {
+		return nil, toStatusError(err, "failed to log action")
+	}
+
+	record, err := s.auditService.Record(ctx, &libmodel.AuditRecord{
+		Service:    req.Service,
+		UserID:     req.UserId,
+		TenantID:   req.TenantId,
+		Action:     action,
+		Resource:   req.Resource,
+		ResourceID: req.ResourceId,
+		Details:    req.Details,
+	})
+	if err != nil {
+		return nil, toStatusError(err, "failed to log action")
+	}
+
+	return &pb.AuditLogResponse{Log: convertRecordToPb(record)}, nil
+}
+
+// GetAuditLogs retrieves audit logs matching the filters of the request
+func (s *AuditServer) GetAuditLogs(ctx context.Context, req *pb.GetAuditLogsRequest) (*pb.GetAuditLogsResponse, error) {
+	filter := service.AuditFilter{
+		UserID:     req.UserId,
+		TenantID:   req.TenantId,
+		Resource:   req.Resource,
+		ResourceID: req.ResourceId,
+		Limit:      int(req.Limit),
+	}
+
+	var err error
+	if req.Action != pb.AuditAction_ACTION_UNSPECIFIED {
+		if filter.Action, err = actionFromPb(req.Action); err != nil {
+			return nil, toStatusError(err, "invalid action")
+		}
+	}
+	if filter.From, err = parseTime(req.FromTime); err != nil {
+		return nil, toStatusError(err, "invalid from time")
+	}
+	if filter.To, err = parseTime(req.ToTime); err != nil {
+		return nil, toStatusError(err, "invalid to time")
+	}
+
+	return s.query(ctx, filter)
+}
+
+// GetResourceAuditLogs retrieves audit logs for a specific resource
+func (s *AuditServer) GetResourceAuditLogs(ctx context.Context, req *pb.GetResourceAuditLogsRequest) (*pb.GetAuditLogsResponse, error) {
+	if req.Resource == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource is required")
+	}
+	return s.query(ctx, service.AuditFilter{Resource: req.Resource, ResourceID: req.ResourceId})
+}
+
+// GetUserAuditLogs retrieves audit logs for a specific user
+func (s *AuditServer) GetUserAuditLogs(ctx context.Context, req *pb.GetUserAuditLogsRequest) (*pb.GetAuditLogsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user ID is required")
+	}
+	return s.query(ctx, service.AuditFilter{UserID: req.UserId})
+}
+
+// VerifyChain recomputes the hash chain of the stored audit log
+func (s *AuditServer) VerifyChain(ctx context.Context, req *pb.VerifyChainRequest) (*pb.VerifyChainResponse, error) {
+	chain, err := s.auditService.Verify(ctx)
+	if err != nil {
+		return nil, toStatusError(err, "failed to verify audit log")
+	}
+
+	return &pb.VerifyChainResponse{
+		Valid:          chain.Valid,
+		Records:        chain.Records,
+		HeadHash:       chain.HeadHash,
+		BrokenSequence: chain.BrokenSequence,
+		Reason:         chain.Reason,
+	}, nil
+}
+
+// query retrieves the records matching a filter as a response
+func (s *AuditServer) query(ctx context.Context, filter service.AuditFilter) (*pb.GetAuditLogsResponse, error) {
+	records, err := s.auditService.Query(ctx, filter)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get audit logs")
+	}
+
+	logs := make([]*pb.AuditLog, len(records))
+	for i, record := range records {
+		logs[i] = convertRecordToPb(record)
+	}
+
+	return &pb.GetAuditLogsResponse{Logs: logs}, nil
+}
+
+// parseTime parses an RFC 3339 time, where an empty string is the zero time
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", service.ErrInvalidFilter, err)
+	}
+	return t, nil
+}
+
+// actionFromPb converts a pb.AuditAction to the action of a record
+func actionFromPb(action pb.AuditAction) (string, error) {
+	if action == pb.AuditAction_ACTION_UNSPECIFIED {
+		return "", fmt.Errorf("%w: action is required", service.ErrInvalidRecord)
+	}
+	name, ok := pb.AuditAction_name[int32(action)]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown action %d", service.ErrInvalidRecord, action)
+	}
+	return strings.ToLower(strings.TrimPrefix(name, "ACTION_")), nil
+}
+
+// actionToPb converts the action of a record to a pb.AuditAction, which is
+// unspecified for actions the enum does not know
+func actionToPb(action string) pb.AuditAction {
+	return pb.AuditAction(pb.AuditAction_value["ACTION_"+strings.ToUpper(action)])
+}
+
+// toStatusError maps service errors to gRPC status errors
+func toStatusError(err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidRecord), errors.Is(err, service.ErrInvalidFilter):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// convertRecordToPb converts a libmodel.AuditRecord to a pb.AuditLog
+func convertRecordToPb(record *libmodel.AuditRecord) *pb.AuditLog {
+	return &pb.AuditLog{
+		Id:         record.ID,
+		UserId:     record.UserID,
+		Action:     actionToPb(record.Action),
+		Resource:   record.Resource,
+		ResourceId: record.ResourceID,
+		Timestamp:  timestamppb.New(record.Time),
+		Sequence:   record.Sequence,
+		TenantId:   record.TenantID,
+		Service:    record.Service,
+		Details:    record.Details,
+		PrevHash:   record.PrevHash,
+		Hash:       record.Hash,
+	}
+}
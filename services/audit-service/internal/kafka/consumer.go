@@ -0,0 +1,62 @@
+package kafka
+
+import (
+	"context"
+	libkafka "distributed-analyzer/libs/kafka"
+	libmodel "distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/kafka"
+	"distributed-analyzer/services/audit-service/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"log"
+)
+
+// AuditHandler is a Kafka message handler recording the audit events of all services
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// HandleMessage handles a message from Kafka
+func (h *AuditHandler) HandleMessage(ctx context.Context, topic string, message kafka.Message) error {
+	if topic != libkafka.AuditTopic {
+		return fmt.Errorf("unknown topic: %s", topic)
+	}
+
+	var event pb.AuditEvent
+	if err := json.Unmarshal(message.Value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal AuditEvent: %w", err)
+	}
+
+	record := &libmodel.AuditRecord{
+		ID:         event.EventId,
+		Service:    event.Service,
+		UserID:     event.UserId,
+		TenantID:   event.TenantId,
+		Action:     event.Action,
+		Resource:   event.Resource,
+		ResourceID: event.ResourceId,
+		Details:    event.Details,
+	}
+	if event.Timestamp != nil {
+		record.Time = event.Timestamp.AsTime()
+	}
+
+	if _, err := h.auditService.Record(ctx, record); err != nil {
+		if errors.Is(err, service.ErrInvalidRecord) {
+			// Retrying does not make the event valid
+			log.Printf("Dropping invalid audit event %s from %s: %v", event.EventId, event.Service, err)
+			return nil
+		}
+		return fmt.Errorf("failed to record audit event %s: %w", event.EventId, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"errors"
+	"time"
+)
+
+// Errors returned by the audit service
+var (
+	ErrInvalidRecord = errors.New("invalid audit record")
+	ErrInvalidFilter = errors.New("invalid audit filter")
+)
+
+// AuditFilter narrows the records of a query. Empty fields do not filter.
+type AuditFilter struct {
+	UserID     string
+	TenantID   string
+	Resource   string
+	ResourceID string
+	Action     string
+	// From is inclusive and To is exclusive
+	From time.Time
+	To   time.Time
+	// Limit keeps the latest records if positive
+	Limit int
+}
+
+// ChainStatus is the outcome of verifying the hash chain of the audit log
+type ChainStatus struct {
+	Valid    bool
+	Records  int64
+	HeadHash string
+	// BrokenSequence is the sequence of the first record that does not match the chain
+	BrokenSequence int64
+	Reason         string
+}
+
+// AuditService defines the interface for keeping a tamper-evident log of the
+// actions performed in the system. Every record is chained to the one before
+// it by its hash, so changing, reordering or removing records is detected.
+type AuditService interface {
+	// Record appends a record to the log, assigning its sequence and hashes.
+	// A record with the ID of a logged record is dropped, and the logged one returned.
+	Record(ctx context.Context, record *libmodel.AuditRecord) (*libmodel.AuditRecord, error)
+
+	// Query retrieves the records matching a filter, oldest first
+	Query(ctx context.Context, filter AuditFilter) ([]*libmodel.AuditRecord, error)
+
+	// Verify reads the stored log and recomputes its hash chain
+	Verify(ctx context.Context) (*ChainStatus, error)
+}
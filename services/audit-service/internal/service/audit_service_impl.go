@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/store"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"log"
+	"sync"
+	"time"
+)
+
+// AuditServiceImpl implements AuditService over an append-only log. The records
+// are kept in memory for queries and read back from the log to be verified.
+type AuditServiceImpl struct {
+	log store.Log[libmodel.AuditRecord]
+	key []byte
+
+	mu      sync.RWMutex
+	records []*libmodel.AuditRecord
+	byID    map[string]*libmodel.AuditRecord
+}
+
+var _ AuditService = (*AuditServiceImpl)(nil)
+
+// NewAuditServiceImpl creates a new AuditServiceImpl loading the records of a log.
+// Hashes are HMACs with key if set. A broken chain is logged, not refused, so
+// that recording goes on; the break stays detectable by Verify.
+func NewAuditServiceImpl(auditLog store.Log[libmodel.AuditRecord], key string) (*AuditServiceImpl, error) {
+	records, err := auditLog.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load audit log: %w", err)
+	}
+
+	s := &AuditServiceImpl{
+		log:     auditLog,
+		key:     []byte(key),
+		records: records,
+		byID:    make(map[string]*libmodel.AuditRecord, len(records)),
+	}
+	for _, record := range records {
+		s.byID[record.ID] = record
+	}
+
+	if status := s.verify(records); !status.Valid {
+		log.Printf("Audit log is broken at record %d: %s", status.BrokenSequence, status.Reason)
+	}
+	return s, nil
+}
+
+// Record appends a record to the log
+func (s *AuditServiceImpl) Record(ctx context.Context, record *libmodel.AuditRecord) (*libmodel.AuditRecord, error) {
+	if record.Action == "" || record.Resource == "" {
+		return nil, fmt.Errorf("%w: action and resource are required", ErrInvalidRecord)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record.ID == "" {
+		record.ID = newRecordID()
+	} else if logged, ok := s.byID[record.ID]; ok {
+		return logged, nil
+	}
+
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	record.Time = record.Time.UTC()
+	record.Sequence = int64(len(s.records)) + 1
+	record.PrevHash = s.headHash()
+	hash, err := s.hash(record)
+	if err != nil {
+		return nil, err
+	}
+	record.Hash = hash
+
+	if err := s.log.Append(record); err != nil {
+		return nil, fmt.Errorf("failed to append audit record: %w", err)
+	}
+	s.records = append(s.records, record)
+	s.byID[record.ID] = record
+
+	return record, nil
+}
+
+// Query retrieves the records matching a filter
+func (s *AuditServiceImpl) Query(ctx context.Context, filter AuditFilter) ([]*libmodel.AuditRecord, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidFilter)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []*libmodel.AuditRecord
+	for _, record := range s.records {
+		if matches(record, filter) {
+			records = append(records, record)
+		}
+	}
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+
+	return records, nil
+}
+
+// Verify reads the stored log and recomputes its hash chain. A log shorter than
+// the records in memory had records removed from its end.
+func (s *AuditServiceImpl) Verify(ctx context.Context) (*ChainStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records, err := s.log.ReadAll()
+	if err != nil {
+		return &ChainStatus{Reason: err.Error()}, nil
+	}
+
+	status := s.verify(records)
+	if status.Valid && len(records) < len(s.records) {
+		return &ChainStatus{
+			Records:        status.Records,
+			HeadHash:       status.HeadHash,
+			BrokenSequence: int64(len(records)) + 1,
+			Reason:         fmt.Sprintf("log ends after %d of %d records", len(records), len(s.records)),
+		}, nil
+	}
+	return status, nil
+}
+
+// verify recomputes the hash chain of records
+func (s *AuditServiceImpl) verify(records []*libmodel.AuditRecord) *ChainStatus {
+	status := &ChainStatus{Records: int64(len(records))}
+	prevHash := ""
+	for i, record := range records {
+		sequence := int64(i) + 1
+		reason := ""
+		switch {
+		case record.Sequence != sequence:
+			reason = fmt.Sprintf("expected sequence %d, found %d", sequence, record.Sequence)
+		case record.PrevHash != prevHash:
+			reason = "previous hash does not match the record before"
+		default:
+			if hash, err := s.hash(record); err != nil || hash != record.Hash {
+				reason = "hash does not match the record"
+			}
+		}
+		if reason != "" {
+			status.BrokenSequence, status.Reason = sequence, reason
+			return status
+		}
+		prevHash = record.Hash
+	}
+
+	status.Valid, status.HeadHash = true, prevHash
+	return status
+}
+
+// hash computes the hash of a record over all its fields but the hash itself
+func (s *AuditServiceImpl) hash(record *libmodel.AuditRecord) (string, error) {
+	unhashed := *record
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	var h hash.Hash
+	if len(s.key) > 0 {
+		h = hmac.New(sha256.New, s.key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// headHash returns the hash of the last record, or an empty hash for an empty log
+func (s *AuditServiceImpl) headHash() string {
+	if len(s.records) == 0 {
+		return ""
+	}
+	return s.records[len(s.records)-1].Hash
+}
+
+// matches reports whether a record matches a filter
+func matches(record *libmodel.AuditRecord, filter AuditFilter) bool {
+	return (filter.UserID == "" || record.UserID == filter.UserID) &&
+		(filter.TenantID == "" || record.TenantID == filter.TenantID) &&
+		(filter.Resource == "" || record.Resource == filter.Resource) &&
+		(filter.ResourceID == "" || record.ResourceID == filter.ResourceID) &&
+		(filter.Action == "" || record.Action == filter.Action) &&
+		(filter.From.IsZero() || !record.Time.Before(filter.From)) &&
+		(filter.To.IsZero() || record.Time.Before(filter.To))
+}
+
+// newRecordID returns a random identifier of a record
+func newRecordID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
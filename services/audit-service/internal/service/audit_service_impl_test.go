@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/store/storetest"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, path, key string) *AuditServiceImpl {
+	t.Helper()
+	s, err := NewAuditServiceImpl(storetest.LogFile[libmodel.AuditRecord](t, path), key)
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	return s
+}
+
+func record(t *testing.T, s *AuditServiceImpl, id, userID, action, resource, resourceID string) *libmodel.AuditRecord {
+	t.Helper()
+	r, err := s.Record(context.Background(), &libmodel.AuditRecord{ID: id, UserID: userID, Action: action, Resource: resource, ResourceID: resourceID})
+	if err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+	return r
+}
+
+func TestRecordChainsRecords(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s := newTestService(t, path, "secret")
+
+	first := record(t, s, "e1", "alice", libmodel.AuditActionCreate, "task", "t1")
+	second := record(t, s, "e2", "bob", libmodel.AuditActionDelete, "task", "t1")
+	if first.Sequence != 1 || first.PrevHash != "" || second.Sequence != 2 || second.PrevHash != first.Hash {
+		t.Errorf("Expected the second record to chain to the first, got %+v and %+v", first, second)
+	}
+
+	// A redelivered event is dropped
+	if again := record(t, s, "e1", "alice", libmodel.AuditActionCreate, "task", "t1"); again != first {
+		t.Errorf("Expected the logged record, got %+v", again)
+	}
+
+	if _, err := s.Record(ctx, &libmodel.AuditRecord{Action: libmodel.AuditActionCreate}); !errors.Is(err, ErrInvalidRecord) {
+		t.Errorf("Expected ErrInvalidRecord, got %v", err)
+	}
+
+	status, err := s.Verify(ctx)
+	if err != nil || !status.Valid || status.Records != 2 || status.HeadHash != second.Hash {
+		t.Errorf("Expected a valid chain of 2 records, got %+v %v", status, err)
+	}
+
+	// A restarted service continues the chain
+	restarted := newTestService(t, path, "secret")
+	third := record(t, restarted, "e3", "alice", libmodel.AuditActionUpdate, "user", "bob")
+	if third.Sequence != 3 || third.PrevHash != second.Hash {
+		t.Errorf("Expected the chain to continue, got %+v", third)
+	}
+	if status, _ := restarted.Verify(ctx); !status.Valid || status.Records != 3 {
+		t.Errorf("Expected a valid chain of 3 records, got %+v", status)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s := newTestService(t, path, "secret")
+	for _, id := range []string{"e1", "e2", "e3"} {
+		record(t, s, id, "alice", libmodel.AuditActionGrant, "role", "alice")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+
+	// Changing a record breaks its hash
+	edited := strings.Replace(lines[1], `"user_id":"alice"`, `"user_id":"mallory"`, 1)
+	writeLog(t, path, lines[0]+edited+lines[2])
+	if status, _ := s.Verify(ctx); status.Valid || status.BrokenSequence != 2 {
+		t.Errorf("Expected the edited record to break the chain, got %+v", status)
+	}
+
+	// Removing a record breaks the sequence
+	writeLog(t, path, lines[0]+lines[2])
+	if status, _ := s.Verify(ctx); status.Valid || status.BrokenSequence != 2 {
+		t.Errorf("Expected the removed record to break the chain, got %+v", status)
+	}
+
+	// Removing the last records leaves a valid chain, shorter than the log in memory
+	writeLog(t, path, lines[0])
+	if status, _ := s.Verify(ctx); status.Valid || status.BrokenSequence != 2 {
+		t.Errorf("Expected the truncated log to be detected, got %+v", status)
+	}
+
+	// A log hashed without the key does not verify with it
+	writeLog(t, path, strings.Join(lines, ""))
+	if status, _ := newTestService(t, path, "other").Verify(ctx); status.Valid || status.BrokenSequence != 1 {
+		t.Errorf("Expected another key to break the chain, got %+v", status)
+	}
+}
+
+func writeLog(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryFiltersRecords(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, filepath.Join(t.TempDir(), "audit.log"), "")
+
+	record(t, s, "e1", "alice", libmodel.AuditActionCreate, "task", "t1")
+	record(t, s, "e2", "bob", libmodel.AuditActionCreate, "task", "t2")
+	record(t, s, "e3", "alice", libmodel.AuditActionDelete, "task", "t1")
+
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		want   []string
+	}{
+		{"all", AuditFilter{}, []string{"e1", "e2", "e3"}},
+		{"user", AuditFilter{UserID: "alice"}, []string{"e1", "e3"}},
+		{"resource", AuditFilter{Resource: "task", ResourceID: "t2"}, []string{"e2"}},
+		{"action", AuditFilter{Action: libmodel.AuditActionDelete}, []string{"e3"}},
+		{"limit", AuditFilter{Limit: 2}, []string{"e2", "e3"}},
+		{"future", AuditFilter{From: time.Now().Add(time.Hour)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := s.Query(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range records {
+				ids = append(ids, r.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+
+	if _, err := s.Query(ctx, AuditFilter{From: time.Now(), To: time.Now().Add(-time.Hour)}); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("Expected ErrInvalidFilter, got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	configloader "distributed-analyzer/libs/config"
+	"distributed-analyzer/services/billing-service/internal/bootstrap"
+	"distributed-analyzer/services/billing-service/internal/config"
+)
+
+func main() {
+	var cfg = configloader.LoadApplicationConfig[config.Config]("billing-service")
+	bootstrap.StartApplication(&cfg)
+}
@@ -0,0 +1,3 @@
+module distributed-analyzer/services/billing-service
+
+go 1.24
@@ -0,0 +1,70 @@
+// Package bootstrap provides functionality to initialize and start the application components.
+package bootstrap
+
+import (
+	"distributed-analyzer/libs/application"
+	grpcApp "distributed-analyzer/libs/application/grpc"
+	kafkaApp "distributed-analyzer/libs/application/kafka"
+	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/network/identity"
+	"distributed-analyzer/libs/network/logging"
+	pb "distributed-analyzer/libs/proto/billing"
+	"distributed-analyzer/libs/store"
+	"distributed-analyzer/services/billing-service/internal/config"
+	"distributed-analyzer/services/billing-service/internal/grpc"
+	billingKafka "distributed-analyzer/services/billing-service/internal/kafka"
+	"distributed-analyzer/services/billing-service/internal/model"
+	"distributed-analyzer/services/billing-service/internal/service"
+	stdgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"log"
+)
+
+// StartApplication initializes and starts all application components.
+// It replays the billing log, charges finished tasks from Kafka and sets up the gRPC server.
+func StartApplication(cfg *config.Config) {
+	billingLog, err := store.NewLogFile[model.Entry](cfg.Store.Path)
+	if err != nil {
+		log.Fatalf("Failed to initialize billing log: %v", err)
+	}
+
+	billingService, err := service.NewBillingServiceImpl(billingLog, cfg.Pricing)
+	if err != nil {
+		log.Fatalf("Failed to initialize billing service: %v", err)
+	}
+
+	kafkaProducer := kafka.NewProducer(cfg.Kafka.Brokers)
+	billingProducer := billingKafka.NewBillingProducer(kafkaProducer)
+	auditPublisher := kafka.NewAuditPublisher(kafkaProducer, "billing-service")
+
+	runner := application.NewApplicationRunner(
+		initGrpc(cfg, billingService, auditPublisher),
+		initKafkaConsumerComponent(cfg, billingService, billingProducer),
+		kafkaApp.NewKafkaProducerComponent(kafkaProducer),
+	)
+	runner.Defer(billingLog.Close)
+	runner.DefaultStart()
+}
+
+// initKafkaConsumerComponent creates a Kafka consumer component charging the tasks that finished.
+func initKafkaConsumerComponent(cfg *config.Config, billingService service.BillingService, producer *billingKafka.BillingProducer) *kafkaApp.ConsumerComponent {
+	handler := billingKafka.NewBillingHandler(billingService, producer)
+	topics := []string{"task-completed", "task-failed"}
+
+	consumer := kafka.NewConsumer(topics, cfg.Kafka.Brokers, cfg.Kafka.GroupID, handler)
+
+	return kafkaApp.NewKafkaComponent(consumer)
+}
+
+// initGrpc initializes the gRPC component with the configured server, which limits its callers.
+func initGrpc(cfg *config.Config, billingService service.BillingService, auditPublisher *kafka.AuditPublisher) *grpcApp.Component {
+	limiter := grpcApp.NewServerLimiter(cfg.GrpcLimit)
+	grpcServer := stdgrpc.NewServer(
+		stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor(), identity.ServerInterceptor(grpcApp.IdentityKey(cfg.Identity)), limiter.UnaryServerInterceptor()),
+	)
+
+	pb.RegisterBillingServiceServer(grpcServer, grpc.NewBillingServer(billingService, auditPublisher))
+	reflection.Register(grpcServer)
+
+	return grpcApp.NewGrpcComponent(grpcServer, &cfg.ServerConfig)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	configloader "distributed-analyzer/libs/config"
+)
+
+// Config is the main configuration for the billing service
+type Config struct {
+	configloader.ServerConfig `yaml:",inline"`
+
+	Kafka     configloader.KafkaConfig     `yaml:"kafka"`
+	Store     StoreConfig                  `yaml:"store"`
+	Pricing   PricingConfig                `yaml:"pricing"`
+	Log       configloader.LogConfig       `yaml:"log"`
+	GrpcLimit configloader.GrpcLimitConfig `yaml:"grpc_limit"`
+	Identity  configloader.IdentityConfig  `yaml:"identity"`
+}
+
+// StoreConfig holds the settings of the persistent billing log
+type StoreConfig struct {
+	Path string `yaml:"path" env:"STORE_PATH" env-default:"/var/lib/billing-service/billing.log"`
+}
+
+// PricingConfig holds the prices of the resources used by tasks. Accounts
+// of tenants with a rule of their own are charged by it, all other accounts
+// by the default rule.
+type PricingConfig struct {
+	Currency string               `yaml:"currency" env:"BILLING_CURRENCY" env-default:"USD"`
+	Default  PriceRule            `yaml:"default"`
+	Tenants  map[string]PriceRule `yaml:"tenants"`
+}
+
+// PriceRule is the price of a CPU-second and a GiB-second of memory, the
+// minimum charge of a task and the balance new accounts start with
+type PriceRule struct {
+	CPUSecond      float64 `yaml:"cpu_second"`
+	MemoryGBSecond float64 `yaml:"memory_gb_second"`
+	MinimumCharge  float64 `yaml:"minimum_charge"`
+	InitialBalance float64 `yaml:"initial_balance"`
+}
@@ -0,0 +1,168 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/kafka"
+	libmodel "distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/billing"
+	"distributed-analyzer/services/billing-service/internal/service"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"strconv"
+)
+
+// BillingServer implements the BillingServiceServer interface
+type BillingServer struct {
+	pb.UnimplementedBillingServiceServer
+	billingService service.BillingService
+	audit          *kafka.AuditPublisher
+}
+
+// NewBillingServer creates a new BillingServer publishing changes made by hand to audit
+func NewBillingServer(billingService service.BillingService, audit *kafka.AuditPublisher) *BillingServer {
+	return &BillingServer{
+		billingService: billingService,
+		audit:          audit,
+	}
+}
+
+// ChargeTask returns the charge of a task. Tasks are charged when they
+// complete, since only then their usage is known.
+func (s *BillingServer) ChargeTask(ctx context.Context, req *pb.ChargeTaskRequest) (*pb.BillingRecordResponse, error) {
+	record, err := s.billingService.GetTaskCharge(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, fmt.Sprintf("task %s was not charged", req.TaskId))
+	}
+
+	return &pb.BillingRecordResponse{Record: convertRecordToPb(record)}, nil
+}
+
+// GetUserBalance retrieves the balance of the account of a user or tenant
+func (s *BillingServer) GetUserBalance(ctx context.Context, req *pb.GetUserBalanceRequest) (*pb.GetUserBalanceResponse, error) {
+	balance, err := s.billingService.GetBalance(ctx, req.UserId, req.TenantId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get balance")
+	}
+
+	return &pb.GetUserBalanceResponse{Balance: balance.Balance, Currency: balance.Currency}, nil
+}
+
+// AddUserBalance credits the account of a user or tenant
+func (s *BillingServer) AddUserBalance(ctx context.Context, req *pb.AddUserBalanceRequest) (*pb.AddUserBalanceResponse, error) {
+	balance, err := s.billingService.AddBalance(ctx, req.UserId, req.TenantId, req.Amount)
+	if err != nil {
+		return nil, toStatusError(err, "failed to add balance")
+	}
+	s.publishAudit(ctx, libmodel.AuditActionUpdate, "balance", account(balance.UserID, balance.TenantID), map[string]string{
+		"amount":  strconv.FormatFloat(req.Amount, 'f', -1, 64),
+		"balance": strconv.FormatFloat(balance.Balance, 'f', -1, 64),
+	})
+
+	return &pb.AddUserBalanceResponse{Success: true, Balance: convertBalanceToPb(balance)}, nil
+}
+
+// GetBillingHistory retrieves the records of the account of a user or tenant
+func (s *BillingServer) GetBillingHistory(ctx context.Context, req *pb.GetBillingHistoryRequest) (*pb.GetBillingHistoryResponse, error) {
+	records, err := s.billingService.GetHistory(ctx, req.UserId, req.TenantId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get billing history")
+	}
+
+	pbRecords := make([]*pb.BillingRecord, len(records))
+	for i, record := range records {
+		pbRecords[i] = convertRecordToPb(record)
+	}
+
+	return &pb.GetBillingHistoryResponse{Records: pbRecords}, nil
+}
+
+// CreateBillingRecord charges the account of a user or tenant by hand
+func (s *BillingServer) CreateBillingRecord(ctx context.Context, req *pb.CreateBillingRecordRequest) (*pb.BillingRecordResponse, error) {
+	record, err := s.billingService.CreateRecord(ctx, req.UserId, req.TenantId, req.TaskId, req.Amount, req.Currency)
+	if err != nil {
+		return nil, toStatusError(err, "failed to create billing record")
+	}
+	s.publishAudit(ctx, libmodel.AuditActionCreate, "billing_record", record.ID, map[string]string{
+		"account":  account(record.UserID, record.TenantID),
+		"task_id":  record.TaskID,
+		"amount":   strconv.FormatFloat(record.Amount, 'f', -1, 64),
+		"currency": record.Currency,
+	})
+
+	return &pb.BillingRecordResponse{Record: convertRecordToPb(record)}, nil
+}
+
+// CheckQuota checks whether the account of a user or tenant has balance left
+func (s *BillingServer) CheckQuota(ctx context.Context, req *pb.CheckQuotaRequest) (*pb.CheckQuotaResponse, error) {
+	balance, allowed, err := s.billingService.CheckQuota(ctx, req.UserId, req.TenantId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to check quota")
+	}
+
+	resp := &pb.CheckQuotaResponse{Allowed: allowed, Balance: balance.Balance, Currency: balance.Currency}
+	if !allowed {
+		resp.Reason = fmt.Sprintf("balance of %.2f %s is used up", balance.Balance, balance.Currency)
+	}
+	return resp, nil
+}
+
+// publishAudit publishes a change to the audit log. Failures are logged, since
+// the change already happened.
+func (s *BillingServer) publishAudit(ctx context.Context, action, resource, resourceID string, details map[string]string) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Publish(ctx, action, resource, resourceID, details); err != nil {
+		log.Printf("Failed to publish audit event for %s %s: %v", resource, resourceID, err)
+	}
+}
+
+// account returns the ID of the account of a user or tenant
+func account(userID, tenantID string) string {
+	if tenantID != "" {
+		return tenantID
+	}
+	return userID
+}
+
+// toStatusError maps service errors to gRPC status errors
+func toStatusError(err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrRecordNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", message, err)
+	case errors.Is(err, service.ErrInvalidAccount), errors.Is(err, service.ErrInvalidAmount), errors.Is(err, service.ErrInvalidCurrency):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// convertRecordToPb converts a libmodel.BillingRecord to a pb.BillingRecord
+func convertRecordToPb(record *libmodel.BillingRecord) *pb.BillingRecord {
+	return &pb.BillingRecord{
+		Id:              record.ID,
+		UserId:          record.UserID,
+		TenantId:        record.TenantID,
+		TaskId:          record.TaskID,
+		Amount:          record.Amount,
+		Currency:        record.Currency,
+		Timestamp:       timestamppb.New(record.Time),
+		CpuSeconds:      record.Usage.CPUSeconds,
+		MemoryGbSeconds: record.Usage.MemoryGBSeconds,
+	}
+}
+
+// convertBalanceToPb converts a libmodel.Balance to a pb.UserBalance
+func convertBalanceToPb(balance *libmodel.Balance) *pb.UserBalance {
+	return &pb.UserBalance{
+		UserId:    balance.UserID,
+		TenantId:  balance.TenantID,
+		Balance:   balance.Balance,
+		Currency:  balance.Currency,
+		UpdatedAt: timestamppb.New(balance.UpdatedAt),
+	}
+}
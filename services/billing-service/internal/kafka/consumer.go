@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/kafka"
+	"distributed-analyzer/services/billing-service/internal/service"
+	"encoding/json"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"log"
+)
+
+// BillingHandler is a Kafka message handler charging finished tasks
+type BillingHandler struct {
+	billingService service.BillingService
+	producer       *BillingProducer
+}
+
+// NewBillingHandler creates a new BillingHandler
+func NewBillingHandler(billingService service.BillingService, producer *BillingProducer) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+		producer:       producer,
+	}
+}
+
+// HandleMessage handles a message from Kafka
+func (h *BillingHandler) HandleMessage(ctx context.Context, topic string, message kafka.Message) error {
+	switch topic {
+	case "task-completed":
+		var event pb.TaskCompletedEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal TaskCompletedEvent: %w", err)
+		}
+		return h.chargeTask(ctx, event.TaskId, event.OwnerId, event.TenantId, event.Usage)
+	case "task-failed":
+		// Failed tasks used the resources all the same
+		var event pb.TaskFailedEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal TaskFailedEvent: %w", err)
+		}
+		return h.chargeTask(ctx, event.TaskId, event.OwnerId, event.TenantId, event.Usage)
+	default:
+		return fmt.Errorf("unknown topic: %s", topic)
+	}
+}
+
+// chargeTask charges the owner of a finished task for its usage and publishes
+// the charge. Redelivered events do not charge the task again.
+func (h *BillingHandler) chargeTask(ctx context.Context, taskID, ownerID, tenantID string, usage *pb.ResourceUsage) error {
+	if ownerID == "" && tenantID == "" {
+		log.Printf("Not charging task %s, which has no owner", taskID)
+		return nil
+	}
+
+	record, charged, err := h.billingService.ChargeTask(ctx, taskID, ownerID, tenantID, model.ResourceUsage{
+		CPUSeconds:      usage.GetCpuSeconds(),
+		MemoryGBSeconds: usage.GetMemoryGbSeconds(),
+		MaxMemoryBytes:  usage.GetMaxMemoryBytes(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to charge task %s: %w", taskID, err)
+	}
+	if !charged {
+		log.Printf("Task %s was already charged by record %s", taskID, record.ID)
+		return nil
+	}
+
+	log.Printf("Charged %.6f %s for task %s of user %q in tenant %q", record.Amount, record.Currency, taskID, ownerID, tenantID)
+	if err := h.producer.PublishBilling(ctx, record); err != nil {
+		log.Printf("Failed to publish charge of task %s: %v", taskID, err)
+	}
+	return nil
+}
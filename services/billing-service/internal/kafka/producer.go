@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"context"
+	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/kafka"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// BillingProducer is a Kafka producer for billing events
+type BillingProducer struct {
+	*kafka.Producer
+}
+
+// NewBillingProducer creates a new BillingProducer
+func NewBillingProducer(pr *kafka.Producer) *BillingProducer {
+	return &BillingProducer{
+		Producer: pr,
+	}
+}
+
+// PublishBilling publishes a BillingEvent for a record to Kafka, keyed by the
+// account so the charges of an account stay in order
+func (p *BillingProducer) PublishBilling(ctx context.Context, record *model.BillingRecord) error {
+	event := &pb.BillingEvent{
+		UserId:    record.UserID,
+		TaskId:    record.TaskID,
+		Amount:    record.Amount,
+		Currency:  record.Currency,
+		Timestamp: timestamppb.New(record.Time),
+		TenantId:  record.TenantID,
+		RecordId:  record.ID,
+		Usage: &pb.ResourceUsage{
+			CpuSeconds:      record.Usage.CPUSeconds,
+			MemoryGbSeconds: record.Usage.MemoryGBSeconds,
+			MaxMemoryBytes:  record.Usage.MaxMemoryBytes,
+		},
+	}
+
+	key := record.TenantID
+	if key == "" {
+		key = record.UserID
+	}
+	return p.Producer.PublishEvent(ctx, "billing", key, event)
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	libmodel "distributed-analyzer/libs/model"
+)
+
+// Entry is a change of the billing state as appended to the log of the store:
+// the balance of an account after the change, and the record of the charge
+// or adjustment that caused it, if any
+type Entry struct {
+	Balance *libmodel.Balance       `json:"balance"`
+	Record  *libmodel.BillingRecord `json:"record,omitempty"`
+}
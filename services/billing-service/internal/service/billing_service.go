@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"errors"
+)
+
+// Errors returned by the billing service
+var (
+	ErrRecordNotFound  = errors.New("billing record not found")
+	ErrInvalidAccount  = errors.New("invalid account")
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrInvalidCurrency = errors.New("invalid currency")
+)
+
+// BillingService defines the interface for charging the accounts of users
+// and tenants for the resources their tasks use. An account belongs to a
+// tenant, shared by all of its users, or to a user without a tenant.
+type BillingService interface {
+	// ChargeTask charges the account of the owner of a task for the resources
+	// the task used. A task is charged once; charging it again returns the
+	// first record and false.
+	ChargeTask(ctx context.Context, taskID, userID, tenantID string, usage libmodel.ResourceUsage) (*libmodel.BillingRecord, bool, error)
+
+	// GetTaskCharge retrieves the record of the charge of a task
+	GetTaskCharge(ctx context.Context, taskID string) (*libmodel.BillingRecord, error)
+
+	// GetBalance retrieves the balance of an account
+	GetBalance(ctx context.Context, userID, tenantID string) (*libmodel.Balance, error)
+
+	// AddBalance credits an account with an amount
+	AddBalance(ctx context.Context, userID, tenantID string, amount float64) (*libmodel.Balance, error)
+
+	// GetHistory retrieves the records of an account, oldest first. The records
+	// of a tenant are narrowed to those of a user if userID is set.
+	GetHistory(ctx context.Context, userID, tenantID string) ([]*libmodel.BillingRecord, error)
+
+	// CreateRecord charges an account with an amount outside of tasks, e.g. to
+	// correct a charge. Negative amounts are refunds.
+	CreateRecord(ctx context.Context, userID, tenantID, taskID string, amount float64, currency string) (*libmodel.BillingRecord, error)
+
+	// CheckQuota reports whether an account has balance left to submit tasks, with its balance
+	CheckQuota(ctx context.Context, userID, tenantID string) (*libmodel.Balance, bool, error)
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/store"
+	"distributed-analyzer/services/billing-service/internal/config"
+	"distributed-analyzer/services/billing-service/internal/model"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// BillingServiceImpl implements the BillingService interface. The state is
+// kept in memory, and every change is appended to a log before it applies.
+type BillingServiceImpl struct {
+	log     store.Log[model.Entry]
+	pricing config.PricingConfig
+
+	balances map[string]*libmodel.Balance
+	records  []*libmodel.BillingRecord
+	charges  map[string]*libmodel.BillingRecord
+
+	mu sync.RWMutex
+}
+
+var _ BillingService = (*BillingServiceImpl)(nil)
+
+// NewBillingServiceImpl creates a new BillingServiceImpl charging by the
+// pricing, with the state replayed from the entries of a log
+func NewBillingServiceImpl(billingLog store.Log[model.Entry], pricing config.PricingConfig) (*BillingServiceImpl, error) {
+	entries, err := billingLog.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load billing state: %w", err)
+	}
+
+	s := &BillingServiceImpl{
+		log:      billingLog,
+		pricing:  pricing,
+		balances: make(map[string]*libmodel.Balance),
+		charges:  make(map[string]*libmodel.BillingRecord),
+	}
+	for _, entry := range entries {
+		s.apply(entry)
+	}
+	return s, nil
+}
+
+// ChargeTask charges the account of the owner of a task by the price rule of
+// its tenant. The charge is at least the minimum charge of the rule.
+func (s *BillingServiceImpl) ChargeTask(ctx context.Context, taskID, userID, tenantID string, usage libmodel.ResourceUsage) (*libmodel.BillingRecord, bool, error) {
+	if taskID == "" {
+		return nil, false, fmt.Errorf("%w: task id is required", ErrInvalidAccount)
+	}
+	key, err := accountKey(userID, tenantID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.charges[taskID]; ok {
+		copied := *record
+		return &copied, false, nil
+	}
+
+	rule := s.rule(tenantID)
+	amount := usage.CPUSeconds*rule.CPUSecond + usage.MemoryGBSeconds*rule.MemoryGBSecond
+	record := &libmodel.BillingRecord{
+		ID:       store.NewID(),
+		UserID:   userID,
+		TenantID: tenantID,
+		TaskID:   taskID,
+		Amount:   roundAmount(max(amount, rule.MinimumCharge)),
+		Currency: s.pricing.Currency,
+		Usage:    usage,
+		Time:     time.Now(),
+	}
+
+	if err := s.charge(key, userID, tenantID, record); err != nil {
+		return nil, false, err
+	}
+
+	copied := *record
+	return &copied, true, nil
+}
+
+// GetTaskCharge retrieves the record of the charge of a task
+func (s *BillingServiceImpl) GetTaskCharge(ctx context.Context, taskID string) (*libmodel.BillingRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.charges[taskID]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	copied := *record
+	return &copied, nil
+}
+
+// GetBalance retrieves the balance of an account. Accounts that were never
+// charged or credited have the initial balance of their price rule.
+func (s *BillingServiceImpl) GetBalance(ctx context.Context, userID, tenantID string) (*libmodel.Balance, error) {
+	key, err := accountKey(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if balance, ok := s.balances[key]; ok {
+		copied := *balance
+		return &copied, nil
+	}
+	return s.newBalance(userID, tenantID), nil
+}
+
+// AddBalance credits an account with a positive amount
+func (s *BillingServiceImpl) AddBalance(ctx context.Context, userID, tenantID string, amount float64) (*libmodel.Balance, error) {
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return nil, fmt.Errorf("%w: credits must be positive", ErrInvalidAmount)
+	}
+	key, err := accountKey(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balance := s.account(key, userID, tenantID)
+	balance.Balance = roundAmount(balance.Balance + amount)
+	balance.UpdatedAt = time.Now()
+	if err := s.append(&model.Entry{Balance: balance}); err != nil {
+		return nil, err
+	}
+
+	copied := *balance
+	return &copied, nil
+}
+
+// GetHistory retrieves the records of an account, oldest first
+func (s *BillingServiceImpl) GetHistory(ctx context.Context, userID, tenantID string) ([]*libmodel.BillingRecord, error) {
+	if _, err := accountKey(userID, tenantID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]*libmodel.BillingRecord, 0)
+	for _, record := range s.records {
+		if record.TenantID != tenantID || (userID != "" && record.UserID != userID) {
+			continue
+		}
+		copied := *record
+		records = append(records, &copied)
+	}
+	return records, nil
+}
+
+// CreateRecord charges an account with an amount in the currency of the pricing
+func (s *BillingServiceImpl) CreateRecord(ctx context.Context, userID, tenantID, taskID string, amount float64, currency string) (*libmodel.BillingRecord, error) {
+	if amount == 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return nil, fmt.Errorf("%w: amount must not be zero", ErrInvalidAmount)
+	}
+	if currency != "" && currency != s.pricing.Currency {
+		return nil, fmt.Errorf("%w: accounts are kept in %s", ErrInvalidCurrency, s.pricing.Currency)
+	}
+	key, err := accountKey(userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &libmodel.BillingRecord{
+		ID:         store.NewID(),
+		UserID:     userID,
+		TenantID:   tenantID,
+		TaskID:     taskID,
+		Amount:     roundAmount(amount),
+		Currency:   s.pricing.Currency,
+		Time:       time.Now(),
+		Adjustment: true,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.charge(key, userID, tenantID, record); err != nil {
+		return nil, err
+	}
+
+	copied := *record
+	return &copied, nil
+}
+
+// CheckQuota allows accounts to submit tasks while their balance is positive.
+// The task that uses up the balance is still charged in full.
+func (s *BillingServiceImpl) CheckQuota(ctx context.Context, userID, tenantID string) (*libmodel.Balance, bool, error) {
+	balance, err := s.GetBalance(ctx, userID, tenantID)
+	if err != nil {
+		return nil, false, err
+	}
+	return balance, balance.Balance > 0, nil
+}
+
+// charge records a charge or adjustment and deducts its amount from the
+// balance of its account. The caller must hold the write lock.
+func (s *BillingServiceImpl) charge(key, userID, tenantID string, record *libmodel.BillingRecord) error {
+	balance := s.account(key, userID, tenantID)
+	balance.Balance = roundAmount(balance.Balance - record.Amount)
+	balance.UpdatedAt = record.Time
+	return s.append(&model.Entry{Balance: balance, Record: record})
+}
+
+// account returns a copy of the balance of an account, with the initial
+// balance of its price rule if it is new. The caller must hold the lock.
+func (s *BillingServiceImpl) account(key, userID, tenantID string) *libmodel.Balance {
+	if balance, ok := s.balances[key]; ok {
+		copied := *balance
+		return &copied
+	}
+	return s.newBalance(userID, tenantID)
+}
+
+// append appends a change to the log and applies it once it is durable.
+// The caller must hold the write lock.
+func (s *BillingServiceImpl) append(entry *model.Entry) error {
+	if err := s.log.Append(entry); err != nil {
+		return fmt.Errorf("failed to save billing state: %w", err)
+	}
+	s.apply(entry)
+	return nil
+}
+
+// apply applies a change of the log to the state. The caller must hold the write lock.
+func (s *BillingServiceImpl) apply(entry *model.Entry) {
+	if entry.Balance != nil {
+		if key, err := accountKey(entry.Balance.UserID, entry.Balance.TenantID); err == nil {
+			s.balances[key] = entry.Balance
+		}
+	}
+	if record := entry.Record; record != nil {
+		s.records = append(s.records, record)
+		if record.TaskID != "" && !record.Adjustment {
+			s.charges[record.TaskID] = record
+		}
+	}
+}
+
+// newBalance returns the balance a new account starts with
+func (s *BillingServiceImpl) newBalance(userID, tenantID string) *libmodel.Balance {
+	balance := &libmodel.Balance{
+		TenantID:  tenantID,
+		Balance:   s.rule(tenantID).InitialBalance,
+		Currency:  s.pricing.Currency,
+		UpdatedAt: time.Now(),
+	}
+	if tenantID == "" {
+		balance.UserID = userID
+	}
+	return balance
+}
+
+// rule returns the price rule of a tenant, or the default rule
+func (s *BillingServiceImpl) rule(tenantID string) config.PriceRule {
+	if rule, ok := s.pricing.Tenants[tenantID]; ok && tenantID != "" {
+		return rule
+	}
+	return s.pricing.Default
+}
+
+// accountKey returns the key of the account of a tenant, or of a user without a tenant
+func accountKey(userID, tenantID string) (string, error) {
+	switch {
+	case tenantID != "":
+		return "tenant:" + tenantID, nil
+	case userID != "":
+		return "user:" + userID, nil
+	default:
+		return "", fmt.Errorf("%w: a user or tenant is required", ErrInvalidAccount)
+	}
+}
+
+// roundAmount rounds an amount to millionths, so sums do not drift
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*1e6) / 1e6
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/store/storetest"
+	"distributed-analyzer/services/billing-service/internal/config"
+	"distributed-analyzer/services/billing-service/internal/model"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+var testPricing = config.PricingConfig{
+	Currency: "USD",
+	Default:  config.PriceRule{CPUSecond: 0.01, MemoryGBSecond: 0.001, MinimumCharge: 0.05, InitialBalance: 1},
+	Tenants: map[string]config.PriceRule{
+		"team-a": {CPUSecond: 0.02, InitialBalance: 10},
+	},
+}
+
+func newTestService(t *testing.T, path string) *BillingServiceImpl {
+	t.Helper()
+	s, err := NewBillingServiceImpl(storetest.LogFile[model.Entry](t, path), testPricing)
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	return s
+}
+
+func TestChargeTaskByPriceRules(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "billing.log")
+	s := newTestService(t, path)
+
+	record, charged, err := s.ChargeTask(ctx, "task-1", "alice", "team-a", libmodel.ResourceUsage{CPUSeconds: 100, MemoryGBSeconds: 50})
+	if err != nil || !charged {
+		t.Fatalf("Failed to charge task: %v", err)
+	}
+	if record.Amount != 2 || record.TenantID != "team-a" {
+		t.Errorf("Expected the tenant rule to charge 2, got %+v", record)
+	}
+
+	// A redelivered event does not charge the task again
+	again, charged, err := s.ChargeTask(ctx, "task-1", "alice", "team-a", libmodel.ResourceUsage{CPUSeconds: 100})
+	if err != nil || charged || again.ID != record.ID {
+		t.Errorf("Expected the first charge, got %+v %v %v", again, charged, err)
+	}
+
+	// Users without a tenant pay for themselves by the default rule, at least the minimum
+	small, _, err := s.ChargeTask(ctx, "task-2", "bob", "", libmodel.ResourceUsage{CPUSeconds: 1})
+	if err != nil || small.Amount != 0.05 {
+		t.Errorf("Expected the minimum charge, got %+v %v", small, err)
+	}
+
+	// The users of a tenant share its balance, which a restarted service reads back
+	restarted := newTestService(t, path)
+	balance, err := restarted.GetBalance(ctx, "carol", "team-a")
+	if err != nil || balance.Balance != 8 {
+		t.Errorf("Expected the tenant balance to be 8, got %+v %v", balance, err)
+	}
+	if _, _, err := restarted.ChargeTask(ctx, "task-1", "alice", "team-a", libmodel.ResourceUsage{}); err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := restarted.GetHistory(ctx, "", "team-a"); len(history) != 1 {
+		t.Errorf("Expected the charge to be recorded once, got %d records", len(history))
+	}
+	if _, err := restarted.GetHistory(ctx, "", ""); !errors.Is(err, ErrInvalidAccount) {
+		t.Errorf("Expected an account to be required, got %v", err)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, filepath.Join(t.TempDir(), "billing.log"))
+
+	if _, allowed, err := s.CheckQuota(ctx, "bob", ""); err != nil || !allowed {
+		t.Fatalf("Expected a new account to have its initial balance, got %v", err)
+	}
+
+	// The task that uses up the balance is charged in full
+	if _, _, err := s.ChargeTask(ctx, "task-1", "bob", "", libmodel.ResourceUsage{CPUSeconds: 150}); err != nil {
+		t.Fatal(err)
+	}
+	balance, allowed, err := s.CheckQuota(ctx, "bob", "")
+	if err != nil || allowed || balance.Balance != -0.5 {
+		t.Errorf("Expected an overdrawn account to be over quota, got %+v %v %v", balance, allowed, err)
+	}
+
+	if _, err := s.AddBalance(ctx, "bob", "", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, allowed, _ := s.CheckQuota(ctx, "bob", ""); !allowed {
+		t.Error("Expected credits to restore the quota")
+	}
+	if _, err := s.AddBalance(ctx, "bob", "", -1); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Expected negative credits to be rejected, got %v", err)
+	}
+}
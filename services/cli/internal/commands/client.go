@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"io"
+	"net/http"
+)
+
+// tokenEnv names the environment variable the token defaults to
+const tokenEnv = "ANALYZER_TOKEN"
+
+var token string
+
+// newRequest creates a request to the gateway that carries the token of the user
+func newRequest(method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// get sends a GET request to the gateway
+func get(url string) (*http.Response, error) {
+	req, err := newRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+
+	"github.com/spf13/cobra"
+)
+
+var downloadTaskID string
+var artifact string
+var output string
+
+var downloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "Download a task artifact",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resp, err := get(fmt.Sprintf("http://localhost:8080/api/result/artifacts/%s/%s", url.PathEscape(downloadTaskID), artifact))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			var result map[string]any
+			if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && result["error"] != nil {
+				if paths, ok := result["paths"].([]any); ok {
+					return fmt.Errorf("failed to download artifact: %s %v", result["error"], paths)
+				}
+				return fmt.Errorf("failed to download artifact: %s", result["error"])
+			}
+			return fmt.Errorf("failed to download artifact: %s", resp.Status)
+		}
+
+		target := output
+		if target == "" {
+			target = path.Base(artifact)
+		}
+
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		n, err := io.Copy(f, resp.Body)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Saved %s (%d bytes)\n", target, n)
+		return f.Close()
+	},
+}
+
+func init() {
+	downloadCmd.Flags().StringVar(&downloadTaskID, "id", "", "Task ID")
+	downloadCmd.Flags().StringVar(&artifact, "artifact", "", "Artifact name, or <subtask_id>/<name> if several subtasks produced it")
+	downloadCmd.Flags().StringVarP(&output, "output", "o", "", "File to save the artifact to, defaults to its name")
+	downloadCmd.MarkFlagRequired("id")
+	downloadCmd.MarkFlagRequired("artifact")
+}
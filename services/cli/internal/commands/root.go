@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
@@ -10,8 +12,11 @@ func NewRootCommand() *cobra.Command {
 		Short: "CLI for AI Task Marketplace",
 	}
 
+	rootCmd.PersistentFlags().StringVar(&token, "token", os.Getenv(tokenEnv), "JWT to authenticate with, defaults to $"+tokenEnv)
+
 	rootCmd.AddCommand(submitCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(downloadCmd)
 
 	return rootCmd
 }
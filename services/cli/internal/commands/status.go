@@ -1,21 +1,31 @@
 package commands
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var taskID string
+var follow bool
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check task status",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		resp, err := http.Get(fmt.Sprintf("http://localhost:8080/tasks/%s", taskID))
+		if follow {
+			if err := followLogs(taskID); err != nil {
+				return err
+			}
+		}
+
+		resp, err := get(fmt.Sprintf("http://localhost:8080/tasks/%s", taskID))
 		if err != nil {
 			return err
 		}
@@ -39,7 +49,58 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// logEvent is a chunk of the command output of a task
+type logEvent struct {
+	SubTaskID string `json:"subtask_id"`
+	Stream    string `json:"stream"`
+	Data      string `json:"data"`
+}
+
+// followLogs prints the output of a task as it runs until the task is finished
+func followLogs(id string) error {
+	resp, err := get(fmt.Sprintf("http://localhost:8080/api/task/%s/logs", id))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to follow logs: %s", resp.Status)
+	}
+
+	var event string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			switch event {
+			case "log":
+				var chunk logEvent
+				if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+					return err
+				}
+				out := os.Stdout
+				if chunk.Stream == "stderr" {
+					out = os.Stderr
+				}
+				fmt.Fprint(out, chunk.Data)
+			case "error":
+				return fmt.Errorf("log stream failed: %s", data)
+			case "end":
+				return nil
+			}
+		}
+	}
+	return scanner.Err()
+}
+
 func init() {
 	statusCmd.Flags().StringVar(&taskID, "id", "", "Task ID")
+	statusCmd.Flags().BoolVar(&follow, "follow", false, "Print the output of the task while it runs, until it finishes")
 	statusCmd.MarkFlagRequired("id")
 }
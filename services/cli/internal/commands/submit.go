@@ -51,7 +51,10 @@ var submitCmd = &cobra.Command{
 			return err
 		}
 
-		req, err := http.NewRequest("POST", "http://localhost:8080/tasks", body)
+		req, err := newRequest("POST", "http://localhost:8080/tasks", body)
+		if err != nil {
+			return err
+		}
 		req.Header.Set("Content-Type", writer.FormDataContentType())
 
 		client := &http.Client{}
@@ -1,3 +1,5 @@
 module distributed-analyzer/services/result-service
 
-go 1.24
+go 1.24.0
+
+require github.com/google/pprof v0.0.0-20260115054156-294ebfa9ad83
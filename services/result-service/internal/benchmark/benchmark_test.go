@@ -0,0 +1,70 @@
+package benchmark
+
+import (
+	"distributed-analyzer/services/result-service/internal/model"
+	"testing"
+)
+
+const sampleOutput = `goos: linux
+goarch: amd64
+pkg: example.com/project/codec
+cpu: Intel(R) Xeon(R) CPU
+BenchmarkEncode-8   	 1000000	      1200 ns/op	     64 B/op	       2 allocs/op
+BenchmarkEncode-8   	 1000000	      1000 ns/op	     64 B/op	       2 allocs/op
+BenchmarkDecode-8   	  500000	      2500 ns/op	  150.00 MB/s
+PASS
+ok  	example.com/project/codec	3.210s
+`
+
+func TestParse(t *testing.T) {
+	results := Parse(sampleOutput)
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 benchmarks, got %d", len(results))
+	}
+
+	decode, encode := results[0], results[1]
+
+	if decode.Name != "example.com/project/codec.BenchmarkDecode" {
+		t.Errorf("Unexpected benchmark name %q", decode.Name)
+	}
+
+	if decode.Metrics["MB/s"] != 150 {
+		t.Errorf("Expected 150 MB/s, got %v", decode.Metrics["MB/s"])
+	}
+
+	if encode.Runs != 2 {
+		t.Errorf("Expected 2 runs, got %d", encode.Runs)
+	}
+
+	if encode.Metrics["ns/op"] != 1100 {
+		t.Errorf("Expected averaged 1100 ns/op, got %v", encode.Metrics["ns/op"])
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []model.BenchmarkResult{
+		{Name: "BenchmarkEncode", Metrics: map[string]float64{"ns/op": 1000, "B/op": 64}},
+		{Name: "BenchmarkDecode", Metrics: map[string]float64{"MB/s": 150}},
+		{Name: "BenchmarkRemoved", Metrics: map[string]float64{"ns/op": 10}},
+	}
+	current := []model.BenchmarkResult{
+		{Name: "BenchmarkEncode", Metrics: map[string]float64{"ns/op": 1050, "B/op": 128}},
+		{Name: "BenchmarkDecode", Metrics: map[string]float64{"MB/s": 100}},
+		{Name: "BenchmarkAdded", Metrics: map[string]float64{"ns/op": 5000}},
+	}
+
+	regressions := Compare(baseline, current, 10)
+
+	if len(regressions) != 2 {
+		t.Fatalf("Expected 2 regressions, got %d: %+v", len(regressions), regressions)
+	}
+
+	if regressions[0].Name != "BenchmarkDecode" || regressions[0].Unit != "MB/s" {
+		t.Errorf("Expected throughput regression first, got %+v", regressions[0])
+	}
+
+	if regressions[1].Name != "BenchmarkEncode" || regressions[1].Unit != "B/op" || regressions[1].DeltaPercent != 100 {
+		t.Errorf("Expected B/op regression of 100%%, got %+v", regressions[1])
+	}
+}
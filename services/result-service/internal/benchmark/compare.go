@@ -0,0 +1,62 @@
+package benchmark
+
+import (
+	"distributed-analyzer/services/result-service/internal/model"
+	"sort"
+)
+
+// higherIsBetter lists units for which a larger value is an improvement.
+// Every other unit (ns/op, B/op, allocs/op, ...) is treated as lower-is-better.
+var higherIsBetter = map[string]bool{
+	"MB/s": true,
+}
+
+// Compare returns the metrics of current that regressed by more than
+// thresholdPercent relative to baseline. Benchmarks that are missing from
+// either side are ignored.
+func Compare(baseline, current []model.BenchmarkResult, thresholdPercent float64) []model.BenchmarkDelta {
+	base := make(map[string]model.BenchmarkResult, len(baseline))
+	for _, b := range baseline {
+		base[b.Name] = b
+	}
+
+	regressions := make([]model.BenchmarkDelta, 0)
+	for _, cur := range current {
+		prev, ok := base[cur.Name]
+		if !ok {
+			continue
+		}
+
+		for unit, value := range cur.Metrics {
+			prevValue, ok := prev.Metrics[unit]
+			if !ok || prevValue == 0 {
+				continue
+			}
+
+			delta := (value - prevValue) / prevValue * 100
+			regressed := delta > thresholdPercent
+			if higherIsBetter[unit] {
+				regressed = -delta > thresholdPercent
+			}
+
+			if regressed {
+				regressions = append(regressions, model.BenchmarkDelta{
+					Name:         cur.Name,
+					Unit:         unit,
+					Baseline:     prevValue,
+					Current:      value,
+					DeltaPercent: delta,
+				})
+			}
+		}
+	}
+
+	sort.Slice(regressions, func(i, j int) bool {
+		if regressions[i].Name != regressions[j].Name {
+			return regressions[i].Name < regressions[j].Name
+		}
+		return regressions[i].Unit < regressions[j].Unit
+	})
+
+	return regressions
+}
@@ -0,0 +1,117 @@
+// Package benchmark parses `go test -bench` output and compares benchmark runs.
+package benchmark
+
+import (
+	"bufio"
+	"distributed-analyzer/services/result-service/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parse extracts benchmark results from the textual output of `go test -bench`.
+// Repeated runs of the same benchmark (e.g. with -count) are averaged.
+// Benchmark names are qualified with the package reported in the "pkg:" header,
+// so identically named benchmarks from different shards do not collide.
+func Parse(output string) []model.BenchmarkResult {
+	type accumulator struct {
+		runs int
+		sums map[string]float64
+	}
+
+	acc := make(map[string]*accumulator)
+	pkg := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "pkg:") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(line, "pkg:"))
+			continue
+		}
+
+		if !strings.HasPrefix(line, "Benchmark") {
+			continue
+		}
+
+		name, metrics, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+
+		a, exists := acc[name]
+		if !exists {
+			a = &accumulator{sums: make(map[string]float64)}
+			acc[name] = a
+		}
+		a.runs++
+		for unit, value := range metrics {
+			a.sums[unit] += value
+		}
+	}
+
+	results := make([]model.BenchmarkResult, 0, len(acc))
+	for name, a := range acc {
+		metrics := make(map[string]float64, len(a.sums))
+		for unit, sum := range a.sums {
+			metrics[unit] = sum / float64(a.runs)
+		}
+		results = append(results, model.BenchmarkResult{
+			Name:    name,
+			Runs:    a.runs,
+			Metrics: metrics,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results
+}
+
+// parseLine parses a single result line such as
+// "BenchmarkFoo-8   1000000   1234 ns/op   16 B/op   1 allocs/op".
+func parseLine(line string) (string, map[string]float64, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return "", nil, false
+	}
+
+	// The second field is the iteration count
+	if _, err := strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return "", nil, false
+	}
+
+	metrics := make(map[string]float64)
+	for i := 2; i+1 < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return "", nil, false
+		}
+		metrics[fields[i+1]] = value
+	}
+
+	if len(metrics) == 0 {
+		return "", nil, false
+	}
+
+	return trimProcs(fields[0]), metrics, true
+}
+
+// trimProcs removes the GOMAXPROCS suffix ("-8") from a benchmark name.
+func trimProcs(name string) string {
+	idx := strings.LastIndex(name, "-")
+	if idx <= 0 {
+		return name
+	}
+	if _, err := strconv.Atoi(name[idx+1:]); err != nil {
+		return name
+	}
+	return name[:idx]
+}
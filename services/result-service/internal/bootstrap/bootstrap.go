@@ -1,7 +1,93 @@
+// Package bootstrap provides functionality to initialize and start the application components.
 package bootstrap
 
-import "distributed-analyzer/services/result-service/internal/config"
+import (
+	"distributed-analyzer/libs/application"
+	grpcApp "distributed-analyzer/libs/application/grpc"
+	kafkaApp "distributed-analyzer/libs/application/kafka"
+	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/network/identity"
+	"distributed-analyzer/libs/network/logging"
+	pb "distributed-analyzer/libs/proto/result"
+	"distributed-analyzer/services/result-service/internal/config"
+	"distributed-analyzer/services/result-service/internal/grpc"
+	resultKafka "distributed-analyzer/services/result-service/internal/kafka"
+	"distributed-analyzer/services/result-service/internal/service"
+	stdgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"log"
+	"time"
+)
 
+// StartApplication initializes and starts all application components.
+// It sets up the result aggregator, live task logs, profile merging, coverage merging, race deduplication, flaky test detection, fuzzing crashers, test durations, module graphs and vulnerabilities, build matrix summaries, benchmark baselines, Kafka components, and gRPC server.
 func StartApplication(cfg *config.Config) {
+	kafkaProducer := kafka.NewProducer(cfg.Kafka.Brokers)
+	resultProducer := resultKafka.NewResultProducer(kafkaProducer)
 
+	taskClient, err := grpc.NewTaskServiceGrpcClient(cfg.Services.Task.GRPCAddr)
+	if err != nil {
+		log.Fatalf("Failed to create task service client: %v", err)
+	}
+
+	storageClient, err := grpc.NewStorageServiceGrpcClient(cfg.Storage.ServiceGrpcAddr)
+	if err != nil {
+		log.Fatalf("Failed to create storage service client: %v", err)
+	}
+
+	retention, err := time.ParseDuration(cfg.Aggregation.Retention)
+	if err != nil || retention <= 0 {
+		log.Fatalf("Invalid aggregation retention %q", cfg.Aggregation.Retention)
+	}
+
+	// Initialize services
+	baselineService := service.NewBenchmarkBaselineService(taskClient, resultProducer, storageClient, cfg.Benchmark.RegressionThreshold, cfg.Benchmark.AutoPromote)
+	coverageService := service.NewCoverageService(storageClient)
+	raceService := service.NewRaceService()
+	flakyService := service.NewFlakinessService()
+	fuzzService := service.NewFuzzService()
+	durationService := service.NewDurationService(taskClient)
+	depsService := service.NewDepsService()
+	matrixService := service.NewMatrixService()
+	logService := service.NewLogService(retention)
+	profileService := service.NewProfileService(storageClient, retention)
+	resultService := service.NewResultAggregatorServiceImpl(coverageService, raceService, flakyService, fuzzService, durationService, depsService, matrixService, baselineService, logService, profileService)
+
+	// Initialize components
+	kafkaConsumerComponent := initKafkaConsumerComponent(cfg, resultService, logService, resultProducer)
+	kafkaProducerComponent := kafkaApp.NewKafkaProducerComponent(kafkaProducer)
+	grpcComponent := initGrpc(cfg, resultService, baselineService, coverageService, raceService, flakyService, fuzzService, durationService, depsService, logService, profileService)
+
+	runner := application.NewApplicationRunner(grpcComponent, kafkaConsumerComponent, kafkaProducerComponent)
+	runner.Defer(taskClient.Close)
+	runner.Defer(storageClient.Close)
+
+	runner.DefaultStart()
+}
+
+// initKafkaConsumerComponent creates and configures a Kafka consumer component.
+// It sets up the message handler and subscribes to the required topics.
+func initKafkaConsumerComponent(cfg *config.Config, resultService service.ResultAggregatorService, logService *service.LogService, producer *resultKafka.ResultProducer) *kafkaApp.ConsumerComponent {
+	resultHandler := resultKafka.NewResultHandler(resultService, logService, producer)
+	topics := []string{"task-scheduled", "subtask-completed", "task-logs"}
+
+	consumer := kafka.NewConsumer(topics, cfg.Kafka.Brokers, cfg.Kafka.GroupID, resultHandler)
+
+	return kafkaApp.NewKafkaComponent(consumer)
+}
+
+// initGrpc initializes the gRPC component with the configured server.
+func initGrpc(cfg *config.Config, resultService service.ResultAggregatorService, baselineService *service.BenchmarkBaselineService, coverageService *service.CoverageService, raceService *service.RaceService, flakyService *service.FlakinessService, fuzzService *service.FuzzService, durationService *service.DurationService, depsService *service.DepsService, logService *service.LogService, profileService *service.ProfileService) *grpcApp.Component {
+	access := grpc.NewAccessChecker(resultService)
+	limiter := grpcApp.NewServerLimiter(cfg.GrpcLimit)
+	identityKey := grpcApp.IdentityKey(cfg.Identity)
+	grpcServer := stdgrpc.NewServer(
+		stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor(), identity.ServerInterceptor(identityKey), limiter.UnaryServerInterceptor(), access.UnaryInterceptor()),
+		stdgrpc.ChainStreamInterceptor(identity.StreamServerInterceptor(identityKey), limiter.StreamServerInterceptor(), access.StreamInterceptor()),
+	)
+
+	pb.RegisterResultAggregatorServiceServer(grpcServer, grpc.NewResultServer(resultService, baselineService, coverageService, raceService, flakyService, fuzzService, durationService, depsService, logService, profileService))
+	reflection.Register(grpcServer)
+
+	return grpcApp.NewGrpcComponent(grpcServer, &cfg.ServerConfig)
 }
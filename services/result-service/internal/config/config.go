@@ -20,8 +20,20 @@ type Config struct {
 	// Aggregation settings
 	Aggregation AggregationConfig `yaml:"aggregation"`
 
+	// Benchmark baseline settings
+	Benchmark BenchmarkConfig `yaml:"benchmark"`
+
+	// Service connections
+	Services ServicesConfig `yaml:"services"`
+
 	// Log settings
 	commonConfig.LogConfig `yaml:"log"`
+
+	// Limits of gRPC callers
+	GrpcLimit commonConfig.GrpcLimitConfig `yaml:"grpc_limit"`
+
+	// Key of the signatures of the identities of users
+	Identity commonConfig.IdentityConfig `yaml:"identity"`
 }
 
 type KafkaConfig struct {
@@ -43,4 +55,14 @@ type StorageConfig struct {
 type AggregationConfig struct {
 	BatchSize     int    `yaml:"batch_size"     env:"AGGREGATION_BATCH_SIZE"     env-default:"100"`
 	FlushInterval string `yaml:"flush_interval" env:"AGGREGATION_FLUSH_INTERVAL" env-default:"5s"`
+	Retention     string `yaml:"retention"      env:"AGGREGATION_RETENTION"      env-default:"24h"`
+}
+
+type BenchmarkConfig struct {
+	RegressionThreshold float64 `yaml:"regression_threshold" env:"BENCHMARK_REGRESSION_THRESHOLD" env-default:"10"`
+	AutoPromote         bool    `yaml:"auto_promote"         env:"BENCHMARK_AUTO_PROMOTE"         env-default:"true"`
+}
+
+type ServicesConfig struct {
+	Task commonConfig.ServiceConnectionConfig `yaml:"task"`
 }
@@ -0,0 +1,74 @@
+package coverage
+
+import (
+	"distributed-analyzer/libs/model"
+	"testing"
+)
+
+const shardA = `mode: set
+example.com/project/codec/codec.go:3.20,5.2 1 1
+example.com/project/codec/codec.go:7.20,9.2 1 0
+example.com/project/util/util.go:3.15,6.2 2 0
+`
+
+const shardB = `mode: set
+example.com/project/codec/codec.go:3.20,5.2 1 0
+example.com/project/codec/codec.go:7.20,9.2 1 1
+example.com/project/util/util.go:3.15,6.2 2 0
+`
+
+func TestMerge(t *testing.T) {
+	a, err := Parse(shardA)
+	if err != nil {
+		t.Fatalf("Failed to parse profile: %v", err)
+	}
+	b, err := Parse(shardB)
+	if err != nil {
+		t.Fatalf("Failed to parse profile: %v", err)
+	}
+
+	merged, err := Merge([]*Profile{a, b})
+	if err != nil {
+		t.Fatalf("Failed to merge profiles: %v", err)
+	}
+
+	if len(merged.Blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d", len(merged.Blocks))
+	}
+
+	for _, block := range merged.Blocks[:2] {
+		if block.Count != 1 {
+			t.Errorf("Expected block %+v to be covered", block)
+		}
+	}
+
+	if _, err := Merge([]*Profile{a, {Mode: "count"}}); err == nil {
+		t.Error("Expected merging different modes to fail")
+	}
+}
+
+func TestReport(t *testing.T) {
+	profile, err := Parse(shardA)
+	if err != nil {
+		t.Fatalf("Failed to parse profile: %v", err)
+	}
+
+	extents := []model.FunctionExtent{
+		{File: "example.com/project/codec/codec.go", Function: "Encode", StartLine: 3, StartCol: 1, EndLine: 5, EndCol: 2},
+		{File: "example.com/project/codec/codec.go", Function: "Decode", StartLine: 7, StartCol: 1, EndLine: 9, EndCol: 2},
+	}
+
+	report := Report("task-1", profile, extents)
+
+	if report.Percent != 25 {
+		t.Errorf("Expected 25%% total coverage, got %v", report.Percent)
+	}
+
+	if len(report.Packages) != 2 || report.Packages[0].Package != "example.com/project/codec" || report.Packages[0].Percent != 50 {
+		t.Errorf("Unexpected package coverage %+v", report.Packages)
+	}
+
+	if len(report.Functions) != 2 || report.Functions[0].Function != "Encode" || report.Functions[0].Percent != 100 || report.Functions[1].Percent != 0 {
+		t.Errorf("Unexpected function coverage %+v", report.Functions)
+	}
+}
@@ -0,0 +1,156 @@
+// Package coverage merges Go coverage profiles and summarizes them per package and function.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Block is a single basic block of a coverage profile
+type Block struct {
+	File      string
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int64
+	Count     int64
+}
+
+// Profile is a parsed coverage profile
+type Profile struct {
+	Mode   string
+	Blocks []Block
+}
+
+// blockKey identifies a block independently of its count
+type blockKey struct {
+	file                                 string
+	startLine, startCol, endLine, endCol int
+}
+
+// Parse parses the text form of a coverage profile as written by go test -coverprofile
+func Parse(text string) (*Profile, error) {
+	profile := &Profile{}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if mode, ok := strings.CutPrefix(line, "mode:"); ok {
+			profile.Mode = strings.TrimSpace(mode)
+			continue
+		}
+
+		block, err := parseBlock(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		profile.Blocks = append(profile.Blocks, block)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if profile.Mode == "" {
+		return nil, fmt.Errorf("missing mode line")
+	}
+
+	return profile, nil
+}
+
+// parseBlock parses a line of the form file:startLine.startCol,endLine.endCol numStmt count
+func parseBlock(line string) (Block, error) {
+	var block Block
+
+	colon := strings.LastIndex(line, ":")
+	if colon < 0 {
+		return block, fmt.Errorf("malformed block %q", line)
+	}
+	block.File = line[:colon]
+
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) != 3 {
+		return block, fmt.Errorf("malformed block %q", line)
+	}
+
+	_, err := fmt.Sscanf(fields[0], "%d.%d,%d.%d", &block.StartLine, &block.StartCol, &block.EndLine, &block.EndCol)
+	if err != nil {
+		return block, fmt.Errorf("malformed position %q: %w", fields[0], err)
+	}
+
+	if block.NumStmt, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+		return block, fmt.Errorf("malformed statement count %q: %w", fields[1], err)
+	}
+	if block.Count, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+		return block, fmt.Errorf("malformed hit count %q: %w", fields[2], err)
+	}
+
+	return block, nil
+}
+
+// Merge combines profiles of the same mode into one.
+// In set mode a block is covered if any profile covers it; in count and
+// atomic mode the hit counts are summed.
+func Merge(profiles []*Profile) (*Profile, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles to merge")
+	}
+
+	merged := &Profile{Mode: profiles[0].Mode}
+	index := make(map[blockKey]int)
+
+	for _, profile := range profiles {
+		if profile.Mode != merged.Mode {
+			return nil, fmt.Errorf("cannot merge %s profile into %s profile", profile.Mode, merged.Mode)
+		}
+
+		for _, block := range profile.Blocks {
+			key := blockKey{block.File, block.StartLine, block.StartCol, block.EndLine, block.EndCol}
+
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(merged.Blocks)
+				merged.Blocks = append(merged.Blocks, block)
+				continue
+			}
+
+			if merged.Mode == "set" {
+				merged.Blocks[i].Count = max(merged.Blocks[i].Count, block.Count)
+			} else {
+				merged.Blocks[i].Count += block.Count
+			}
+		}
+	}
+
+	sort.Slice(merged.Blocks, func(i, j int) bool {
+		a, b := merged.Blocks[i], merged.Blocks[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartCol < b.StartCol
+	})
+
+	return merged, nil
+}
+
+// String formats the profile in the text form understood by go tool cover
+func (p *Profile) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "mode: %s\n", p.Mode)
+	for _, block := range p.Blocks {
+		fmt.Fprintf(&b, "%s:%d.%d,%d.%d %d %d\n",
+			block.File, block.StartLine, block.StartCol, block.EndLine, block.EndCol, block.NumStmt, block.Count)
+	}
+	return b.String()
+}
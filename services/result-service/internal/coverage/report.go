@@ -0,0 +1,108 @@
+package coverage
+
+import (
+	"distributed-analyzer/libs/model"
+	"path"
+	"sort"
+)
+
+// counter accumulates statement counts
+type counter struct {
+	statements int64
+	covered    int64
+}
+
+// add counts the statements of a block
+func (c *counter) add(block Block) {
+	c.statements += block.NumStmt
+	if block.Count > 0 {
+		c.covered += block.NumStmt
+	}
+}
+
+// percent returns the covered share of statements in percent
+func (c *counter) percent() float64 {
+	if c.statements == 0 {
+		return 0
+	}
+	return float64(c.covered) / float64(c.statements) * 100
+}
+
+// Report summarizes a profile per package and, using the given extents, per function
+func Report(taskID string, profile *Profile, extents []model.FunctionExtent) *model.CoverageReport {
+	var total counter
+	packages := make(map[string]*counter)
+	functions := make([]counter, len(extents))
+
+	byFile := make(map[string][]int)
+	for i, extent := range extents {
+		byFile[extent.File] = append(byFile[extent.File], i)
+	}
+
+	for _, block := range profile.Blocks {
+		total.add(block)
+
+		pkg := path.Dir(block.File)
+		if _, ok := packages[pkg]; !ok {
+			packages[pkg] = &counter{}
+		}
+		packages[pkg].add(block)
+
+		for _, i := range byFile[block.File] {
+			if contains(extents[i], block) {
+				functions[i].add(block)
+				break
+			}
+		}
+	}
+
+	report := &model.CoverageReport{
+		TaskID:    taskID,
+		Mode:      profile.Mode,
+		Percent:   total.percent(),
+		Packages:  make([]model.PackageCoverage, 0, len(packages)),
+		Functions: make([]model.FunctionCoverage, 0, len(extents)),
+		Profile:   profile.String(),
+	}
+
+	for pkg, c := range packages {
+		report.Packages = append(report.Packages, model.PackageCoverage{
+			Package:    pkg,
+			Statements: c.statements,
+			Covered:    c.covered,
+			Percent:    c.percent(),
+		})
+	}
+	sort.Slice(report.Packages, func(i, j int) bool {
+		return report.Packages[i].Package < report.Packages[j].Package
+	})
+
+	for i, extent := range extents {
+		report.Functions = append(report.Functions, model.FunctionCoverage{
+			File:       extent.File,
+			Function:   extent.Function,
+			Line:       extent.StartLine,
+			Statements: functions[i].statements,
+			Covered:    functions[i].covered,
+			Percent:    functions[i].percent(),
+		})
+	}
+	sort.Slice(report.Functions, func(i, j int) bool {
+		a, b := report.Functions[i], report.Functions[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	return report
+}
+
+// contains reports whether a block lies within a function extent
+func contains(extent model.FunctionExtent, block Block) bool {
+	startsAfter := block.StartLine > extent.StartLine ||
+		block.StartLine == extent.StartLine && block.StartCol >= extent.StartCol
+	endsBefore := block.EndLine < extent.EndLine ||
+		block.EndLine == extent.EndLine && block.EndCol <= extent.EndCol
+	return startsAfter && endsBefore
+}
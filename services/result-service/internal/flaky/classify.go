@@ -0,0 +1,81 @@
+// Package flaky classifies tests by their outcomes across repeated runs.
+package flaky
+
+import (
+	"distributed-analyzer/services/result-service/internal/model"
+	"sort"
+	"time"
+)
+
+// Test classifications
+const (
+	StablePass = "stable_pass"
+	StableFail = "stable_fail"
+	Flaky      = "flaky"
+)
+
+// Test outcomes reported by workers
+const (
+	outcomePass = "pass"
+	outcomeFail = "fail"
+)
+
+// Classify classifies every test seen in runs, where each run maps test names
+// to outcomes. Skipped tests do not count as runs. Flaky tests come first,
+// ordered by flake rate.
+func Classify(taskID string, runs []map[string]string) *model.FlakinessReport {
+	tests := make(map[string]*model.TestFlakiness)
+	for _, run := range runs {
+		for name, outcome := range run {
+			if outcome != outcomePass && outcome != outcomeFail {
+				continue
+			}
+
+			test, ok := tests[name]
+			if !ok {
+				test = &model.TestFlakiness{Name: name}
+				tests[name] = test
+			}
+
+			test.Runs++
+			if outcome == outcomePass {
+				test.Passes++
+			} else {
+				test.Failures++
+			}
+		}
+	}
+
+	report := &model.FlakinessReport{
+		TaskID:    taskID,
+		Runs:      len(runs),
+		Tests:     make([]model.TestFlakiness, 0, len(tests)),
+		CreatedAt: time.Now(),
+	}
+
+	for _, test := range tests {
+		test.FlakeRate = float64(test.Failures) / float64(test.Runs)
+		switch {
+		case test.Failures == 0:
+			test.Classification = StablePass
+		case test.Passes == 0:
+			test.Classification = StableFail
+		default:
+			test.Classification = Flaky
+		}
+		report.Tests = append(report.Tests, *test)
+	}
+
+	sort.Slice(report.Tests, func(i, j int) bool {
+		a, b := report.Tests[i], report.Tests[j]
+		if (a.Classification == Flaky) != (b.Classification == Flaky) {
+			return a.Classification == Flaky
+		}
+		if a.Classification == Flaky && a.FlakeRate != b.FlakeRate {
+			return a.FlakeRate > b.FlakeRate
+		}
+		return a.Name < b.Name
+	})
+
+	return report
+}
@@ -0,0 +1,27 @@
+package flaky
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	runs := []map[string]string{
+		{"pkg.TestStable": "pass", "pkg.TestBroken": "fail", "pkg.TestFlaky": "pass", "pkg.TestSkipped": "skip"},
+		{"pkg.TestStable": "pass", "pkg.TestBroken": "fail", "pkg.TestFlaky": "fail", "pkg.TestSkipped": "skip"},
+		{"pkg.TestStable": "pass", "pkg.TestBroken": "fail", "pkg.TestFlaky": "pass", "pkg.TestSkipped": "skip"},
+		{"pkg.TestStable": "pass", "pkg.TestBroken": "fail", "pkg.TestFlaky": "pass", "pkg.TestSkipped": "skip"},
+	}
+
+	report := Classify("task-1", runs)
+
+	if report.Runs != 4 || len(report.Tests) != 3 {
+		t.Fatalf("Expected 4 runs of 3 tests, got %+v", report)
+	}
+
+	flaky := report.Tests[0]
+	if flaky.Name != "pkg.TestFlaky" || flaky.Classification != Flaky || flaky.FlakeRate != 0.25 {
+		t.Errorf("Expected pkg.TestFlaky first with a flake rate of 0.25, got %+v", flaky)
+	}
+
+	if report.Tests[1].Classification != StableFail || report.Tests[2].Classification != StablePass {
+		t.Errorf("Unexpected classifications %+v", report.Tests[1:])
+	}
+}
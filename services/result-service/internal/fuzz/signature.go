@@ -0,0 +1,40 @@
+// Package fuzz identifies distinct failures found by fuzzing.
+package fuzz
+
+import (
+	"crypto/sha1"
+	"distributed-analyzer/libs/model"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// maxSignatureFrames is the number of innermost frames that identify a failure
+const maxSignatureFrames = 5
+
+var (
+	hexPattern    = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	numberPattern = regexp.MustCompile(`\d+`)
+	quotedPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+)
+
+// Signature returns a stable identifier of a fuzzing failure.
+// It combines the target, the failure message with input-dependent values
+// (numbers, addresses, quoted strings) masked, and the innermost frames of
+// the stack, so the same bug found with different inputs gets the same signature.
+func Signature(crasher model.FuzzCrasher) string {
+	message := quotedPattern.ReplaceAllString(crasher.Error, `"…"`)
+	message = hexPattern.ReplaceAllString(message, "0x?")
+	message = numberPattern.ReplaceAllString(message, "?")
+
+	parts := []string{crasher.Package + "." + crasher.Target, message}
+	for i, frame := range crasher.Stack {
+		if i == maxSignatureFrames {
+			break
+		}
+		parts = append(parts, frame.Function)
+	}
+
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:8])
+}
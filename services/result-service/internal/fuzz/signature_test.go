@@ -0,0 +1,35 @@
+package fuzz
+
+import (
+	"distributed-analyzer/libs/model"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	stack := []model.StackFrame{{Function: "example.com/project/parser.Parse", File: "parser.go", Line: 10}}
+
+	a := model.FuzzCrasher{
+		Target:  "FuzzParse",
+		Package: "example.com/project/parser",
+		Error:   "runtime error: index out of range [3] with length 3",
+		Stack:   stack,
+		Input:   []byte("go test fuzz v1\nstring(\"abc\")\n"),
+	}
+	b := model.FuzzCrasher{
+		Target:  "FuzzParse",
+		Package: "example.com/project/parser",
+		Error:   "runtime error: index out of range [7] with length 7",
+		Stack:   stack,
+		Input:   []byte("go test fuzz v1\nstring(\"abcdefg\")\n"),
+	}
+
+	if Signature(a) != Signature(b) {
+		t.Error("Expected failures differing only in input-dependent values to share a signature")
+	}
+
+	c := b
+	c.Error = "runtime error: invalid memory address or nil pointer dereference"
+	if Signature(a) == Signature(c) {
+		t.Error("Expected different failures to produce different signatures")
+	}
+}
@@ -0,0 +1,96 @@
+package grpc
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/identity"
+	"distributed-analyzer/services/result-service/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// taskRequest is a request about the results of a single task
+type taskRequest interface {
+	GetTaskId() string
+}
+
+// tenantRequest is a request about the data of a single tenant
+type tenantRequest interface {
+	GetTenantId() string
+}
+
+// AccessChecker keeps users from the results of tasks of other tenants.
+// Calls of other services carry no identity and are not checked.
+type AccessChecker struct {
+	resultService service.ResultAggregatorService
+}
+
+// NewAccessChecker creates a new AccessChecker
+func NewAccessChecker(resultService service.ResultAggregatorService) *AccessChecker {
+	return &AccessChecker{resultService: resultService}
+}
+
+// UnaryInterceptor checks unary calls. It must run after identity.ServerInterceptor.
+func (a *AccessChecker) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := a.check(ctx, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor checks the request of server streaming calls once it is
+// received. It must run after identity.StreamServerInterceptor.
+func (a *AccessChecker) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &checkedStream{ServerStream: ss, checker: a})
+	}
+}
+
+// check reports an error unless the caller may access the results a request
+// asks for. Tasks of other tenants are reported as not found, so their
+// existence is not revealed. Data of other tenants is reported as not found
+// as well. Results across tasks mix tenants and are only available to admins.
+func (a *AccessChecker) check(ctx context.Context, req interface{}) error {
+	id, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	scoped, isTenantRequest := req.(tenantRequest)
+	if isTenantRequest && !id.HasRole(libmodel.RoleAdmin) && scoped.GetTenantId() != id.TenantID {
+		return status.Errorf(codes.NotFound, "no data for tenant %s", scoped.GetTenantId())
+	}
+
+	r, ok := req.(taskRequest)
+	if !ok {
+		if isTenantRequest {
+			return nil
+		}
+		if !id.HasRole(libmodel.RoleAdmin) {
+			return status.Error(codes.PermissionDenied, "results across tasks are only available to admins")
+		}
+		return nil
+	}
+
+	ownerID, tenantID, err := a.resultService.GetTaskOwner(ctx, r.GetTaskId())
+	if err != nil || !id.CanAccess(ownerID, tenantID) {
+		return status.Errorf(codes.NotFound, "no results for task %s", r.GetTaskId())
+	}
+	return nil
+}
+
+// checkedStream is a server stream whose received messages are checked
+type checkedStream struct {
+	grpc.ServerStream
+	checker *AccessChecker
+}
+
+func (s *checkedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.checker.check(s.Context(), m)
+}
@@ -0,0 +1,639 @@
+package grpc
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/result"
+	"distributed-analyzer/services/result-service/internal/model"
+	"distributed-analyzer/services/result-service/internal/service"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sort"
+)
+
+// ResultServer implements the ResultAggregatorServiceServer interface
+type ResultServer struct {
+	pb.UnimplementedResultAggregatorServiceServer
+	resultService   service.ResultAggregatorService
+	baselineService *service.BenchmarkBaselineService
+	coverageService *service.CoverageService
+	raceService     *service.RaceService
+	flakyService    *service.FlakinessService
+	fuzzService     *service.FuzzService
+	durationService *service.DurationService
+	depsService     *service.DepsService
+	logService      *service.LogService
+	profileService  *service.ProfileService
+}
+
+// NewResultServer creates a new ResultServer
+func NewResultServer(resultService service.ResultAggregatorService, baselineService *service.BenchmarkBaselineService, coverageService *service.CoverageService, raceService *service.RaceService, flakyService *service.FlakinessService, fuzzService *service.FuzzService, durationService *service.DurationService, depsService *service.DepsService, logService *service.LogService, profileService *service.ProfileService) *ResultServer {
+	return &ResultServer{
+		resultService:   resultService,
+		baselineService: baselineService,
+		coverageService: coverageService,
+		raceService:     raceService,
+		flakyService:    flakyService,
+		fuzzService:     fuzzService,
+		durationService: durationService,
+		depsService:     depsService,
+		logService:      logService,
+		profileService:  profileService,
+	}
+}
+
+// SavePartialResult saves a partial result for a task
+func (s *ResultServer) SavePartialResult(ctx context.Context, req *pb.SavePartialResultRequest) (*pb.SavePartialResultResponse, error) {
+	artifacts := make([]libmodel.Artifact, len(req.Artifacts))
+	for i, artifact := range req.Artifacts {
+		artifacts[i] = convertPbArtifactToModel(artifact)
+	}
+
+	if err := s.resultService.SavePartialResult(ctx, req.TaskId, req.SubtaskId, req.Result, artifacts, libmodel.ResourceUsage{}); err != nil {
+		return nil, toStatusError(err, "failed to save partial result")
+	}
+
+	return &pb.SavePartialResultResponse{Success: true}, nil
+}
+
+// FinalizeResult finalizes the result when all subtasks are completed
+func (s *ResultServer) FinalizeResult(ctx context.Context, req *pb.FinalizeResultRequest) (*pb.FinalizeResultResponse, error) {
+	if err := s.resultService.FinalizeResult(ctx, req.TaskId); err != nil {
+		return nil, toStatusError(err, "failed to finalize result")
+	}
+
+	return &pb.FinalizeResultResponse{Success: true}, nil
+}
+
+// GetResult retrieves the result of a completed task
+func (s *ResultServer) GetResult(ctx context.Context, req *pb.GetResultRequest) (*pb.GetResultResponse, error) {
+	result, err := s.resultService.GetResult(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get result")
+	}
+
+	return &pb.GetResultResponse{Result: result}, nil
+}
+
+// GetTaskResult retrieves the full task result object
+func (s *ResultServer) GetTaskResult(ctx context.Context, req *pb.GetTaskResultRequest) (*pb.TaskResultResponse, error) {
+	taskResult, err := s.resultService.GetTaskResult(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get task result")
+	}
+
+	return &pb.TaskResultResponse{
+		TaskResult: convertModelTaskResultToPb(taskResult),
+	}, nil
+}
+
+// GetSubTaskResults retrieves all subtask results for a task
+func (s *ResultServer) GetSubTaskResults(ctx context.Context, req *pb.GetSubTaskResultsRequest) (*pb.SubTaskResultsResponse, error) {
+	subResults, err := s.resultService.GetSubTaskResults(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get subtask results")
+	}
+
+	pbSubResults := make([]*pb.SubTaskResult, len(subResults))
+	for i, sub := range subResults {
+		pbSubResults[i] = convertModelSubTaskResultToPb(sub)
+	}
+
+	return &pb.SubTaskResultsResponse{SubtaskResults: pbSubResults}, nil
+}
+
+// SetBenchmarkBaseline accepts a completed benchmark task as the baseline for a project/branch
+func (s *ResultServer) SetBenchmarkBaseline(ctx context.Context, req *pb.SetBenchmarkBaselineRequest) (*pb.BenchmarkBaselineResponse, error) {
+	if req.Project == "" || req.TaskId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "project and task_id are required")
+	}
+
+	baseline, err := s.baselineService.SetBaseline(ctx, req.TenantId, req.Project, req.Branch, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to set benchmark baseline")
+	}
+
+	return &pb.BenchmarkBaselineResponse{Baseline: convertModelBaselineToPb(baseline)}, nil
+}
+
+// GetBenchmarkBaseline retrieves the current baseline for a project/branch
+func (s *ResultServer) GetBenchmarkBaseline(ctx context.Context, req *pb.GetBenchmarkBaselineRequest) (*pb.BenchmarkBaselineResponse, error) {
+	baseline, err := s.baselineService.GetBaseline(ctx, req.TenantId, req.Project, req.Branch)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get benchmark baseline")
+	}
+
+	return &pb.BenchmarkBaselineResponse{Baseline: convertModelBaselineToPb(baseline)}, nil
+}
+
+// GetCoverageReport retrieves the merged coverage report of a task
+func (s *ResultServer) GetCoverageReport(ctx context.Context, req *pb.GetCoverageReportRequest) (*pb.CoverageReportResponse, error) {
+	report, err := s.coverageService.GetReport(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get coverage report")
+	}
+
+	return &pb.CoverageReportResponse{Report: convertCoverageReportToPb(report)}, nil
+}
+
+// GetTaskRaces retrieves the distinct data races found by a task
+func (s *ResultServer) GetTaskRaces(ctx context.Context, req *pb.GetTaskRacesRequest) (*pb.TaskRacesResponse, error) {
+	races, err := s.raceService.GetTaskRaces(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get task races")
+	}
+
+	pbRaces := make([]*pb.TaskRace, len(races))
+	for i, r := range races {
+		pbRaces[i] = &pb.TaskRace{
+			Record:      convertRaceRecordToPb(&r.Record),
+			Occurrences: int32(r.Occurrences),
+		}
+	}
+
+	return &pb.TaskRacesResponse{Races: pbRaces}, nil
+}
+
+// ListRaces retrieves every distinct data race seen across all tasks
+func (s *ResultServer) ListRaces(ctx context.Context, req *pb.ListRacesRequest) (*pb.ListRacesResponse, error) {
+	records, err := s.raceService.ListRaces(ctx)
+	if err != nil {
+		return nil, toStatusError(err, "failed to list races")
+	}
+
+	pbRecords := make([]*pb.RaceRecord, len(records))
+	for i, r := range records {
+		pbRecords[i] = convertRaceRecordToPb(r)
+	}
+
+	return &pb.ListRacesResponse{Records: pbRecords}, nil
+}
+
+// GetFlakinessReport retrieves the test classification of a task that ran tests repeatedly
+func (s *ResultServer) GetFlakinessReport(ctx context.Context, req *pb.GetFlakinessReportRequest) (*pb.FlakinessReportResponse, error) {
+	report, err := s.flakyService.GetReport(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get flakiness report")
+	}
+
+	return &pb.FlakinessReportResponse{Report: convertFlakinessReportToPb(report)}, nil
+}
+
+// GetFlakeIndex retrieves the flakiness history of one test, or of every flaky test if no name is given
+func (s *ResultServer) GetFlakeIndex(ctx context.Context, req *pb.GetFlakeIndexRequest) (*pb.FlakeIndexResponse, error) {
+	var entries []*model.FlakeIndexEntry
+	if req.TestName != "" {
+		entry, err := s.flakyService.GetIndexEntry(ctx, req.TestName)
+		if err != nil {
+			return nil, toStatusError(err, "failed to get flake index entry")
+		}
+		entries = []*model.FlakeIndexEntry{entry}
+	} else {
+		var err error
+		if entries, err = s.flakyService.ListIndex(ctx); err != nil {
+			return nil, toStatusError(err, "failed to list flake index")
+		}
+	}
+
+	pbEntries := make([]*pb.FlakeIndexEntry, len(entries))
+	for i, e := range entries {
+		pbEntries[i] = &pb.FlakeIndexEntry{
+			Name:          e.Name,
+			Tasks:         int32(e.Tasks),
+			FlakyTasks:    int32(e.FlakyTasks),
+			Runs:          int32(e.Runs),
+			Failures:      int32(e.Failures),
+			FlakeRate:     e.FlakeRate,
+			LastFlakyTask: e.LastFlakyTask,
+			LastSeenAt:    timestamppb.New(e.LastSeenAt),
+		}
+	}
+
+	return &pb.FlakeIndexResponse{Entries: pbEntries}, nil
+}
+
+// GetFuzzCrashers retrieves the distinct failures found by a fuzzing task
+func (s *ResultServer) GetFuzzCrashers(ctx context.Context, req *pb.GetFuzzCrashersRequest) (*pb.FuzzCrashersResponse, error) {
+	records, err := s.fuzzService.GetTaskCrashers(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get fuzz crashers")
+	}
+
+	return &pb.FuzzCrashersResponse{Records: convertFuzzCrasherRecordsToPb(records)}, nil
+}
+
+// ListFuzzCrashers retrieves every distinct fuzzing failure, optionally limited to one target
+func (s *ResultServer) ListFuzzCrashers(ctx context.Context, req *pb.ListFuzzCrashersRequest) (*pb.FuzzCrashersResponse, error) {
+	records, err := s.fuzzService.ListCrashers(ctx, req.Target)
+	if err != nil {
+		return nil, toStatusError(err, "failed to list fuzz crashers")
+	}
+
+	return &pb.FuzzCrashersResponse{Records: convertFuzzCrasherRecordsToPb(records)}, nil
+}
+
+// GetPackageDurations retrieves the historical run time of every tested package of a project of a tenant
+func (s *ResultServer) GetPackageDurations(ctx context.Context, req *pb.GetPackageDurationsRequest) (*pb.PackageDurationsResponse, error) {
+	durations, err := s.durationService.GetPackageDurations(ctx, req.TenantId, req.Project)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get package durations")
+	}
+
+	return &pb.PackageDurationsResponse{Seconds: durations}, nil
+}
+
+// GetModuleGraph retrieves the module graph reported by a go_deps task
+func (s *ResultServer) GetModuleGraph(ctx context.Context, req *pb.GetModuleGraphRequest) (*pb.ModuleGraphResponse, error) {
+	graph, err := s.depsService.GetModuleGraph(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get module graph")
+	}
+
+	resp := &pb.ModuleGraphResponse{
+		Modules: make([]*pb.Module, len(graph.Modules)),
+		Edges:   make([]*pb.ModuleEdge, len(graph.Edges)),
+	}
+	for i := range graph.Modules {
+		resp.Modules[i] = convertModuleToPb(&graph.Modules[i])
+	}
+	for i, edge := range graph.Edges {
+		resp.Edges[i] = &pb.ModuleEdge{From: edge.From, To: edge.To}
+	}
+
+	return resp, nil
+}
+
+// GetVulnerabilities retrieves the vulnerabilities affecting the modules of a go_deps task
+func (s *ResultServer) GetVulnerabilities(ctx context.Context, req *pb.GetVulnerabilitiesRequest) (*pb.VulnerabilitiesResponse, error) {
+	vulns, err := s.depsService.GetVulnerabilities(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get vulnerabilities")
+	}
+
+	resp := &pb.VulnerabilitiesResponse{Vulnerabilities: make([]*pb.Vulnerability, len(vulns))}
+	for i, vuln := range vulns {
+		resp.Vulnerabilities[i] = convertVulnerabilityToPb(vuln)
+	}
+
+	return resp, nil
+}
+
+// ListArtifacts lists the artifacts the subtasks of a task produced
+func (s *ResultServer) ListArtifacts(ctx context.Context, req *pb.ListArtifactsRequest) (*pb.ListArtifactsResponse, error) {
+	artifacts, err := s.resultService.ListArtifacts(ctx, req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to list artifacts")
+	}
+
+	resp := &pb.ListArtifactsResponse{Artifacts: make([]*pb.Artifact, len(artifacts))}
+	for i, artifact := range artifacts {
+		resp.Artifacts[i] = convertArtifactToPb(artifact)
+	}
+
+	return resp, nil
+}
+
+// convertArtifactToPb converts a libmodel.Artifact to a pb.Artifact
+func convertArtifactToPb(a libmodel.Artifact) *pb.Artifact {
+	return &pb.Artifact{
+		SubtaskId:   a.SubTaskID,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Digest:      a.Digest,
+		StorageKey:  a.StorageKey,
+	}
+}
+
+// convertPbArtifactToModel converts a pb.Artifact to a libmodel.Artifact
+func convertPbArtifactToModel(a *pb.Artifact) libmodel.Artifact {
+	return libmodel.Artifact{
+		SubTaskID:   a.SubtaskId,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Digest:      a.Digest,
+		StorageKey:  a.StorageKey,
+	}
+}
+
+// StreamLogs streams the command output of a task, optionally following it until the task is finalized
+func (s *ResultServer) StreamLogs(req *pb.StreamLogsRequest, stream pb.ResultAggregatorService_StreamLogsServer) error {
+	err := s.logService.StreamLogs(stream.Context(), req.TaskId, req.Follow, func(chunk *libmodel.LogChunk) error {
+		return stream.Send(convertLogChunkToPb(chunk))
+	})
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(err).Err()
+		}
+		return toStatusError(err, "failed to stream logs")
+	}
+
+	return nil
+}
+
+// GetProfileHotspots retrieves the top functions of a profile merged across the subtasks of a task
+func (s *ResultServer) GetProfileHotspots(ctx context.Context, req *pb.GetProfileHotspotsRequest) (*pb.ProfileHotspotsResponse, error) {
+	hotspots, err := s.profileService.GetHotspots(ctx, req.TaskId, req.Kind, req.SampleType, req.Order, int(req.Limit))
+	if err != nil {
+		return nil, toStatusError(err, "failed to get profile hotspots")
+	}
+
+	pbHotspots := &pb.ProfileHotspots{
+		TaskId:      hotspots.TaskID,
+		Kind:        hotspots.Kind,
+		SampleType:  hotspots.SampleType,
+		Unit:        hotspots.Unit,
+		SampleTypes: hotspots.SampleTypes,
+		Profiles:    int32(hotspots.Profiles),
+		Total:       hotspots.Total,
+		Hotspots:    make([]*pb.Hotspot, len(hotspots.Hotspots)),
+	}
+	for i, h := range hotspots.Hotspots {
+		pbHotspots.Hotspots[i] = &pb.Hotspot{
+			Function:    h.Function,
+			File:        h.File,
+			Flat:        h.Flat,
+			FlatPercent: h.FlatPercent,
+			Cum:         h.Cum,
+			CumPercent:  h.CumPercent,
+		}
+	}
+
+	return &pb.ProfileHotspotsResponse{Hotspots: pbHotspots}, nil
+}
+
+// convertLogChunkToPb converts a libmodel.LogChunk to a pb.LogChunk
+func convertLogChunkToPb(chunk *libmodel.LogChunk) *pb.LogChunk {
+	return &pb.LogChunk{
+		TaskId:    chunk.TaskID,
+		SubtaskId: chunk.SubTaskID,
+		WorkerId:  chunk.WorkerID,
+		Stream:    chunk.Stream,
+		Sequence:  chunk.Sequence,
+		Data:      chunk.Data,
+		WrittenAt: timestamppb.New(chunk.WrittenAt),
+	}
+}
+
+// toStatusError maps service errors to gRPC status errors
+func toStatusError(err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrResultNotFound),
+		errors.Is(err, service.ErrBaselineNotFound),
+		errors.Is(err, service.ErrBenchmarkRunNotFound),
+		errors.Is(err, service.ErrLogsNotFound),
+		errors.Is(err, service.ErrProfileNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", message, err)
+	case errors.Is(err, service.ErrInvalidProfileQuery),
+		errors.Is(err, service.ErrBenchmarkRunMismatch):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	case errors.Is(err, service.ErrResultNotReady),
+		errors.Is(err, service.ErrResultAlreadyFinalized):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// convertModelTaskResultToPb converts a model.TaskResult to a pb.TaskResult
+func convertModelTaskResultToPb(r *model.TaskResult) *pb.TaskResult {
+	pbResult := &pb.TaskResult{
+		TaskId:    r.TaskID,
+		Status:    r.Status,
+		Result:    r.Result,
+		OwnerId:   r.OwnerID,
+		TenantId:  r.TenantID,
+		CreatedAt: timestamppb.New(r.CreatedAt),
+		UpdatedAt: timestamppb.New(r.UpdatedAt),
+	}
+
+	if !r.FinishedAt.IsZero() {
+		pbResult.FinishedAt = timestamppb.New(r.FinishedAt)
+	}
+
+	return pbResult
+}
+
+// convertModelSubTaskResultToPb converts a model.SubTaskResult to a pb.SubTaskResult
+func convertModelSubTaskResultToPb(r *model.SubTaskResult) *pb.SubTaskResult {
+	pbResult := &pb.SubTaskResult{
+		SubtaskId: r.SubTaskID,
+		TaskId:    r.TaskID,
+		WorkerId:  r.WorkerID,
+		Status:    r.Status,
+		Result:    r.Result,
+		Artifacts: make([]*pb.Artifact, len(r.Artifacts)),
+		CreatedAt: timestamppb.New(r.CreatedAt),
+		UpdatedAt: timestamppb.New(r.UpdatedAt),
+	}
+
+	for i, artifact := range r.Artifacts {
+		pbResult.Artifacts[i] = convertArtifactToPb(artifact)
+	}
+
+	if !r.FinishedAt.IsZero() {
+		pbResult.FinishedAt = timestamppb.New(r.FinishedAt)
+	}
+
+	return pbResult
+}
+
+// convertModelBaselineToPb converts a model.BenchmarkBaseline to a pb.BenchmarkBaseline
+func convertModelBaselineToPb(b *model.BenchmarkBaseline) *pb.BenchmarkBaseline {
+	results := make([]*pb.BenchmarkResult, len(b.Results))
+	for i, r := range b.Results {
+		units := make([]string, 0, len(r.Metrics))
+		for unit := range r.Metrics {
+			units = append(units, unit)
+		}
+		sort.Strings(units)
+
+		metrics := make([]*pb.BenchmarkMetric, len(units))
+		for j, unit := range units {
+			metrics[j] = &pb.BenchmarkMetric{Unit: unit, Value: r.Metrics[unit]}
+		}
+
+		results[i] = &pb.BenchmarkResult{
+			Name:    r.Name,
+			Runs:    int32(r.Runs),
+			Metrics: metrics,
+		}
+	}
+
+	return &pb.BenchmarkBaseline{
+		TenantId:   b.TenantID,
+		Project:    b.Project,
+		Branch:     b.Branch,
+		TaskId:     b.TaskID,
+		Results:    results,
+		AcceptedAt: timestamppb.New(b.AcceptedAt),
+	}
+}
+
+// convertCoverageReportToPb converts a model.CoverageReport to a pb.CoverageReport
+func convertCoverageReportToPb(r *libmodel.CoverageReport) *pb.CoverageReport {
+	packages := make([]*pb.PackageCoverage, len(r.Packages))
+	for i, p := range r.Packages {
+		packages[i] = &pb.PackageCoverage{
+			Package:    p.Package,
+			Statements: p.Statements,
+			Covered:    p.Covered,
+			Percent:    p.Percent,
+		}
+	}
+
+	functions := make([]*pb.FunctionCoverage, len(r.Functions))
+	for i, f := range r.Functions {
+		functions[i] = &pb.FunctionCoverage{
+			File:       f.File,
+			Function:   f.Function,
+			Line:       int32(f.Line),
+			Statements: f.Statements,
+			Covered:    f.Covered,
+			Percent:    f.Percent,
+		}
+	}
+
+	return &pb.CoverageReport{
+		TaskId:    r.TaskID,
+		Mode:      r.Mode,
+		Percent:   r.Percent,
+		Packages:  packages,
+		Functions: functions,
+		Profile:   r.Profile,
+	}
+}
+
+// convertRaceRecordToPb converts a model.RaceRecord to a pb.RaceRecord
+func convertRaceRecordToPb(r *libmodel.RaceRecord) *pb.RaceRecord {
+	accesses := make([]*pb.RaceAccess, len(r.Report.Accesses))
+	for i, a := range r.Report.Accesses {
+		accesses[i] = &pb.RaceAccess{
+			Operation: a.Operation,
+			Goroutine: int32(a.Goroutine),
+			Stack:     convertStackToPb(a.Stack),
+		}
+	}
+
+	goroutines := make([]*pb.RaceGoroutine, len(r.Report.Goroutines))
+	for i, g := range r.Report.Goroutines {
+		goroutines[i] = &pb.RaceGoroutine{
+			Id:    int32(g.ID),
+			State: g.State,
+			Stack: convertStackToPb(g.Stack),
+		}
+	}
+
+	return &pb.RaceRecord{
+		Signature: r.Signature,
+		Report: &pb.RaceReport{
+			Test:       r.Report.Test,
+			Accesses:   accesses,
+			Goroutines: goroutines,
+		},
+		Tests:         r.Tests,
+		Count:         int32(r.Count),
+		FirstSeenTask: r.FirstSeenTask,
+		LastSeenTask:  r.LastSeenTask,
+		FirstSeenAt:   timestamppb.New(r.FirstSeenAt),
+		LastSeenAt:    timestamppb.New(r.LastSeenAt),
+	}
+}
+
+// convertStackToPb converts a goroutine stack to pb.StackFrames
+func convertStackToPb(stack []libmodel.StackFrame) []*pb.StackFrame {
+	frames := make([]*pb.StackFrame, len(stack))
+	for i, f := range stack {
+		frames[i] = &pb.StackFrame{
+			Function: f.Function,
+			File:     f.File,
+			Line:     int32(f.Line),
+		}
+	}
+	return frames
+}
+
+// convertFlakinessReportToPb converts a model.FlakinessReport to a pb.FlakinessReport
+func convertFlakinessReportToPb(r *model.FlakinessReport) *pb.FlakinessReport {
+	tests := make([]*pb.TestFlakiness, len(r.Tests))
+	for i, t := range r.Tests {
+		tests[i] = &pb.TestFlakiness{
+			Name:           t.Name,
+			Runs:           int32(t.Runs),
+			Passes:         int32(t.Passes),
+			Failures:       int32(t.Failures),
+			Classification: t.Classification,
+			FlakeRate:      t.FlakeRate,
+		}
+	}
+
+	return &pb.FlakinessReport{
+		TaskId:    r.TaskID,
+		Runs:      int32(r.Runs),
+		Tests:     tests,
+		CreatedAt: timestamppb.New(r.CreatedAt),
+	}
+}
+
+// convertFuzzCrasherRecordsToPb converts model.FuzzCrasherRecords to pb.FuzzCrasherRecords
+func convertFuzzCrasherRecordsToPb(records []*libmodel.FuzzCrasherRecord) []*pb.FuzzCrasherRecord {
+	pbRecords := make([]*pb.FuzzCrasherRecord, len(records))
+	for i, r := range records {
+		pbRecords[i] = &pb.FuzzCrasherRecord{
+			Signature: r.Signature,
+			Reproducer: &pb.FuzzCrasher{
+				Target:   r.Reproducer.Target,
+				Package:  r.Reproducer.Package,
+				Error:    r.Reproducer.Error,
+				Stack:    convertStackToPb(r.Reproducer.Stack),
+				Input:    r.Reproducer.Input,
+				InputKey: r.Reproducer.InputKey,
+			},
+			Count:         int32(r.Count),
+			FirstSeenTask: r.FirstSeenTask,
+			LastSeenTask:  r.LastSeenTask,
+			FirstSeenAt:   timestamppb.New(r.FirstSeenAt),
+			LastSeenAt:    timestamppb.New(r.LastSeenAt),
+		}
+	}
+	return pbRecords
+}
+
+// convertModuleToPb converts a libmodel.Module to a pb.Module
+func convertModuleToPb(m *libmodel.Module) *pb.Module {
+	module := &pb.Module{
+		Path:     m.Path,
+		Version:  m.Version,
+		Main:     m.Main,
+		Indirect: m.Indirect,
+	}
+	if m.Replace != nil {
+		module.Replace = convertModuleToPb(m.Replace)
+	}
+	return module
+}
+
+// convertVulnerabilityToPb converts a libmodel.Vulnerability to a pb.Vulnerability
+func convertVulnerabilityToPb(v libmodel.Vulnerability) *pb.Vulnerability {
+	vuln := &pb.Vulnerability{
+		Id:           v.ID,
+		Aliases:      v.Aliases,
+		Summary:      v.Summary,
+		Module:       v.Module,
+		Version:      v.Version,
+		FixedVersion: v.FixedVersion,
+		Packages:     make([]*pb.VulnerablePackage, len(v.Packages)),
+	}
+	for i, pkg := range v.Packages {
+		vuln.Packages[i] = &pb.VulnerablePackage{
+			Path:           pkg.Path,
+			Symbols:        pkg.Symbols,
+			Imported:       pkg.Imported,
+			ReachedSymbols: pkg.ReachedSymbols,
+		}
+	}
+	return vuln
+}
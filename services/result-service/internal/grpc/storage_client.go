@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/storage"
+	"distributed-analyzer/services/result-service/internal/service"
+	"google.golang.org/grpc"
+)
+
+// maxObjectMessageSize bounds the size of objects fetched from the storage service
+const maxObjectMessageSize = 128 << 20
+
+// StorageServiceGrpcClient is a gRPC client for the storage service
+type StorageServiceGrpcClient struct {
+	client pb.StorageServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ service.ObjectStore = (*StorageServiceGrpcClient)(nil)
+
+// NewStorageServiceGrpcClient creates a new StorageServiceGrpcClient
+func NewStorageServiceGrpcClient(serverAddr string) (*StorageServiceGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StorageServiceGrpcClient{
+		client: pb.NewStorageServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (s *StorageServiceGrpcClient) Close() error {
+	return s.conn.Close()
+}
+
+// GetObject retrieves the content of an object
+func (s *StorageServiceGrpcClient) GetObject(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.client.GetObject(ctx, &pb.GetObjectRequest{Key: key}, grpc.MaxCallRecvMsgSize(maxObjectMessageSize))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Content, nil
+}
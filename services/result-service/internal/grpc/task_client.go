@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/task"
+	"distributed-analyzer/services/result-service/internal/service"
+	"google.golang.org/grpc"
+)
+
+// TaskServiceGrpcClient is a gRPC client for the task service
+type TaskServiceGrpcClient struct {
+	client pb.TaskServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ service.TaskServiceClient = (*TaskServiceGrpcClient)(nil)
+
+// NewTaskServiceGrpcClient creates a new TaskServiceGrpcClient
+func NewTaskServiceGrpcClient(serverAddr string) (*TaskServiceGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskServiceGrpcClient{
+		client: pb.NewTaskServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (t *TaskServiceGrpcClient) Close() error {
+	return t.conn.Close()
+}
+
+// GetTask retrieves a task by its ID
+func (t *TaskServiceGrpcClient) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	resp, err := t.client.GetTask(ctx, &pb.GetTaskRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	task := &model.Task{
+		ID:          resp.Task.Id,
+		Name:        resp.Task.Name,
+		Description: resp.Task.Description,
+		Input:       resp.Task.Input,
+		Output:      resp.Task.Output,
+		OwnerID:     resp.Task.OwnerId,
+		TenantID:    resp.Task.TenantId,
+	}
+
+	if resp.Task.CreatedAt != nil {
+		task.CreatedAt = resp.Task.CreatedAt.AsTime()
+	}
+
+	if resp.Task.UpdatedAt != nil {
+		task.UpdatedAt = resp.Task.UpdatedAt.AsTime()
+	}
+
+	return task, nil
+}
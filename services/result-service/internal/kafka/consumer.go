@@ -2,118 +2,155 @@ package kafka
 
 import (
 	"context"
+	"distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/kafka"
+	"distributed-analyzer/services/result-service/internal/service"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/distributedmarketplace/internal/result/service"
 	"github.com/segmentio/kafka-go"
 	"log"
-	"time"
-
-	pb "github.com/distributedmarketplace/pkg/proto/kafka"
 )
 
-// ResultConsumer is a Kafka consumer for result events
-type ResultConsumer struct {
-	readers       map[string]*kafka.Reader
+// ResultHandler is a Kafka message handler for result events
+type ResultHandler struct {
 	resultService service.ResultAggregatorService
-	stopChannels  map[string]chan struct{}
+	logService    *service.LogService
+	producer      *ResultProducer
 }
 
-// NewResultConsumer creates a new ResultConsumer
-func NewResultConsumer(brokers []string, groupID string, resultService service.ResultAggregatorService) *ResultConsumer {
-	consumer := &ResultConsumer{
-		readers:       make(map[string]*kafka.Reader),
+// NewResultHandler creates a new ResultHandler
+func NewResultHandler(resultService service.ResultAggregatorService, logService *service.LogService, producer *ResultProducer) *ResultHandler {
+	return &ResultHandler{
 		resultService: resultService,
-		stopChannels:  make(map[string]chan struct{}),
+		logService:    logService,
+		producer:      producer,
 	}
+}
 
-	// Create readers for each topic
-	topics := []string{
-		"subtask-completed",
+// HandleMessage handles a message from Kafka
+func (c *ResultHandler) HandleMessage(ctx context.Context, topic string, message kafka.Message) error {
+	switch topic {
+	case "task-scheduled":
+		return c.handleTaskScheduled(ctx, message)
+	case "subtask-completed":
+		return c.handleSubTaskCompleted(ctx, message)
+	case "task-logs":
+		return c.handleTaskLog(ctx, message)
+	default:
+		return fmt.Errorf("unknown topic: %s", topic)
 	}
+}
 
-	for _, topic := range topics {
-		consumer.readers[topic] = kafka.NewReader(kafka.ReaderConfig{
-			Brokers:     brokers,
-			Topic:       topic,
-			GroupID:     groupID,
-			MinBytes:    10e3, // 10KB
-			MaxBytes:    10e6, // 10MB
-			MaxWait:     1 * time.Second,
-			StartOffset: kafka.FirstOffset,
-		})
-		consumer.stopChannels[topic] = make(chan struct{})
+// handleTaskScheduled handles a TaskScheduledEvent
+func (c *ResultHandler) handleTaskScheduled(ctx context.Context, message kafka.Message) error {
+	var event pb.TaskScheduledEvent
+	if err := json.Unmarshal(message.Value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal TaskScheduledEvent: %w", err)
 	}
 
-	return consumer
-}
-
-// Start starts consuming messages from Kafka
-func (c *ResultConsumer) Start(ctx context.Context) {
-	for topic, reader := range c.readers {
-		go c.consumeTopic(ctx, topic, reader, c.stopChannels[topic])
+	if err := c.resultService.SetTaskOwner(ctx, event.TaskId, event.OwnerId, event.TenantId); err != nil {
+		return fmt.Errorf("failed to set task owner: %w", err)
 	}
-}
 
-// Stop stops consuming messages from Kafka
-func (c *ResultConsumer) Stop() {
-	for topic, stopCh := range c.stopChannels {
-		close(stopCh)
-		if err := c.readers[topic].Close(); err != nil {
-			log.Printf("Error closing Kafka reader for topic %s: %v", topic, err)
-		}
+	if err := c.resultService.RegisterSubTasks(ctx, event.TaskId, event.SubtaskIds); err != nil {
+		return fmt.Errorf("failed to register subtasks: %w", err)
 	}
-}
 
-// consumeTopic consumes messages from a specific topic
-func (c *ResultConsumer) consumeTopic(ctx context.Context, topic string, reader *kafka.Reader, stopCh <-chan struct{}) {
-	for {
-		select {
-		case <-stopCh:
-			return
-		case <-ctx.Done():
-			return
-		default:
-			message, err := reader.ReadMessage(ctx)
-			if err != nil {
-				log.Printf("Error reading message from topic %s: %v", topic, err)
-				continue
-			}
-
-			if err := c.handleMessage(ctx, topic, message); err != nil {
-				log.Printf("Error handling message from topic %s: %v", topic, err)
-			}
-		}
+	// The results of the subtasks may have overtaken this event
+	if _, err := c.resultService.GetTaskResult(ctx, event.TaskId); errors.Is(err, service.ErrResultNotFound) {
+		return nil
 	}
+	return c.finalize(ctx, event.TaskId)
 }
 
-// handleMessage handles a message from Kafka
-func (c *ResultConsumer) handleMessage(ctx context.Context, topic string, message kafka.Message) error {
-	switch topic {
-	case "subtask-completed":
-		return c.handleSubTaskCompleted(ctx, message)
-	default:
-		return fmt.Errorf("unknown topic: %s", topic)
+// handleTaskLog handles a TaskLogEvent
+func (c *ResultHandler) handleTaskLog(ctx context.Context, message kafka.Message) error {
+	var event pb.TaskLogEvent
+	if err := json.Unmarshal(message.Value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal TaskLogEvent: %w", err)
 	}
+
+	return c.logService.Append(ctx, &model.LogChunk{
+		TaskID:    event.TaskId,
+		SubTaskID: event.SubtaskId,
+		WorkerID:  event.WorkerId,
+		Stream:    event.Stream,
+		Sequence:  event.Sequence,
+		Data:      event.Data,
+		WrittenAt: event.WrittenAt.AsTime(),
+	})
 }
 
 // handleSubTaskCompleted handles a SubTaskCompletedEvent
-func (c *ResultConsumer) handleSubTaskCompleted(ctx context.Context, message kafka.Message) error {
+func (c *ResultHandler) handleSubTaskCompleted(ctx context.Context, message kafka.Message) error {
 	var event pb.SubTaskCompletedEvent
 	if err := json.Unmarshal(message.Value, &event); err != nil {
 		return fmt.Errorf("failed to unmarshal SubTaskCompletedEvent: %w", err)
 	}
 
+	// The owner is known from the TaskScheduledEvent, unless this event overtook it
+	if err := c.resultService.SetTaskOwner(ctx, event.TaskId, event.OwnerId, event.TenantId); err != nil {
+		return fmt.Errorf("failed to set task owner: %w", err)
+	}
+
 	// Save the partial result
-	if err := c.resultService.SavePartialResult(ctx, event.TaskId, event.SubtaskId, event.Result); err != nil {
+	artifacts := make([]model.Artifact, len(event.Artifacts))
+	for i, artifact := range event.Artifacts {
+		artifacts[i] = model.Artifact{
+			SubTaskID:   artifact.SubtaskId,
+			Name:        artifact.Name,
+			ContentType: artifact.ContentType,
+			Size:        artifact.Size,
+			Digest:      artifact.Digest,
+			StorageKey:  artifact.StorageKey,
+		}
+	}
+
+	usage := model.ResourceUsage{
+		CPUSeconds:      event.GetUsage().GetCpuSeconds(),
+		MemoryGBSeconds: event.GetUsage().GetMemoryGbSeconds(),
+		MaxMemoryBytes:  event.GetUsage().GetMaxMemoryBytes(),
+	}
+
+	if err := c.resultService.SavePartialResult(ctx, event.TaskId, event.SubtaskId, event.Result, artifacts, usage); err != nil {
 		return fmt.Errorf("failed to save partial result: %w", err)
 	}
 
-	// Try to finalize the result (this will check if all subtasks are completed)
-	if err := c.resultService.FinalizeResult(ctx, event.TaskId); err != nil {
-		log.Printf("Not finalizing result for task %s yet: %v", event.TaskId, err)
-	} else {
-		log.Printf("Result for task %s finalized successfully", event.TaskId)
+	return c.finalize(ctx, event.TaskId)
+}
+
+// finalize finalizes the result of a task once all its subtasks completed and
+// publishes whether the task completed or failed. A result that is not ready
+// yet, or was finalized by an earlier event, is left alone.
+func (c *ResultHandler) finalize(ctx context.Context, taskID string) error {
+	err := c.resultService.FinalizeResult(ctx, taskID)
+	if errors.Is(err, service.ErrResultNotReady) {
+		log.Printf("Not finalizing result for task %s yet: %v", taskID, err)
+		return nil
+	}
+	if errors.Is(err, service.ErrResultAlreadyFinalized) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to finalize result: %w", err)
+	}
+	log.Printf("Result for task %s finalized successfully", taskID)
+
+	taskResult, err := c.resultService.GetTaskResult(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get finalized result: %w", err)
+	}
+
+	if reason, failed := service.FailureReason(taskResult.Result); failed {
+		if err := c.producer.PublishTaskFailed(ctx, taskResult, reason); err != nil {
+			return fmt.Errorf("failed to publish TaskFailedEvent: %w", err)
+		}
+		return nil
+	}
+
+	if err := c.producer.PublishTaskCompleted(ctx, taskResult); err != nil {
+		return fmt.Errorf("failed to publish TaskCompletedEvent: %w", err)
 	}
 
 	return nil
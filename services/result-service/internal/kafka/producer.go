@@ -2,56 +2,89 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"github.com/segmentio/kafka-go"
+	"distributed-analyzer/libs/kafka"
+	libmodel "distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/kafka"
+	"distributed-analyzer/services/result-service/internal/model"
+	"distributed-analyzer/services/result-service/internal/service"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"time"
-
-	pb "github.com/distributedmarketplace/pkg/proto/kafka"
 )
 
 // ResultProducer is a Kafka producer for result events
 type ResultProducer struct {
-	writer *kafka.Writer
+	*kafka.Producer
 }
 
+var _ service.RegressionPublisher = (*ResultProducer)(nil)
+
 // NewResultProducer creates a new ResultProducer
-func NewResultProducer(brokers []string) *ResultProducer {
-	writer := &kafka.Writer{
-		Addr:     kafka.TCP(brokers...),
-		Balancer: &kafka.LeastBytes{},
-	}
+func NewResultProducer(pr *kafka.Producer) *ResultProducer {
 	return &ResultProducer{
-		writer: writer,
+		Producer: pr,
 	}
 }
 
-// Close closes the Kafka writer
-func (p *ResultProducer) Close() error {
-	return p.writer.Close()
-}
-
-// PublishTaskCompleted publishes a TaskCompletedEvent to Kafka
-func (p *ResultProducer) PublishTaskCompleted(ctx context.Context, taskID string, result map[string]string) error {
+// PublishTaskCompleted publishes a TaskCompletedEvent with the owner and usage of a finalized result to Kafka
+func (p *ResultProducer) PublishTaskCompleted(ctx context.Context, taskResult *model.TaskResult) error {
 	event := &pb.TaskCompletedEvent{
-		TaskId:      taskID,
-		Result:      result,
+		TaskId:      taskResult.TaskID,
+		Result:      taskResult.Result,
 		CompletedAt: timestamppb.New(time.Now()),
+		OwnerId:     taskResult.OwnerID,
+		TenantId:    taskResult.TenantID,
+		Usage:       usageToPb(taskResult.Usage),
+	}
+
+	return p.Producer.PublishEvent(ctx, "task-completed", taskResult.TaskID, event)
+}
+
+// PublishTaskFailed publishes a TaskFailedEvent carrying the merged result to Kafka
+func (p *ResultProducer) PublishTaskFailed(ctx context.Context, taskResult *model.TaskResult, errMsg string) error {
+	event := &pb.TaskFailedEvent{
+		TaskId:   taskResult.TaskID,
+		Error:    errMsg,
+		FailedAt: timestamppb.New(time.Now()),
+		Result:   taskResult.Result,
+		OwnerId:  taskResult.OwnerID,
+		TenantId: taskResult.TenantID,
+		Usage:    usageToPb(taskResult.Usage),
 	}
 
-	return p.publishEvent(ctx, "task-completed", taskID, event)
+	return p.Producer.PublishEvent(ctx, "task-failed", taskResult.TaskID, event)
 }
 
-// Helper function to publish an event to Kafka
-func (p *ResultProducer) publishEvent(ctx context.Context, topic, key string, event interface{}) error {
-	value, err := json.Marshal(event)
-	if err != nil {
-		return err
+// usageToPb converts the resource usage of a task to its protobuf representation
+func usageToPb(usage libmodel.ResourceUsage) *pb.ResourceUsage {
+	return &pb.ResourceUsage{
+		CpuSeconds:      usage.CPUSeconds,
+		MemoryGbSeconds: usage.MemoryGBSeconds,
+		MaxMemoryBytes:  usage.MaxMemoryBytes,
+	}
+}
+
+// PublishBenchmarkRegression publishes a BenchmarkRegressionEvent to Kafka
+func (p *ResultProducer) PublishBenchmarkRegression(ctx context.Context, taskID string, baseline *model.BenchmarkBaseline, thresholdPercent float64, regressions []model.BenchmarkDelta) error {
+	deltas := make([]*pb.BenchmarkDelta, len(regressions))
+	for i, r := range regressions {
+		deltas[i] = &pb.BenchmarkDelta{
+			Name:         r.Name,
+			Unit:         r.Unit,
+			Baseline:     r.Baseline,
+			Current:      r.Current,
+			DeltaPercent: r.DeltaPercent,
+		}
+	}
+
+	event := &pb.BenchmarkRegressionEvent{
+		TaskId:           taskID,
+		Project:          baseline.Project,
+		Branch:           baseline.Branch,
+		BaselineTaskId:   baseline.TaskID,
+		ThresholdPercent: thresholdPercent,
+		Regressions:      deltas,
+		DetectedAt:       timestamppb.New(time.Now()),
 	}
 
-	return p.writer.WriteMessages(ctx, kafka.Message{
-		Topic: topic,
-		Key:   []byte(key),
-		Value: value,
-	})
+	return p.Producer.PublishEvent(ctx, "benchmark-regression", taskID, event)
 }
@@ -0,0 +1,130 @@
+// Package matrix summarizes the cells of a build matrix into a grid.
+package matrix
+
+import (
+	libmodel "distributed-analyzer/libs/model"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Summarize collects the dimensions of a build matrix from its cell results.
+// Go versions are ordered by release, platforms and tag sets by name.
+func Summarize(cells []libmodel.MatrixCellResult) *libmodel.MatrixSummary {
+	summary := &libmodel.MatrixSummary{
+		GoVersions: make([]string, 0),
+		Platforms:  make([]string, 0),
+		TagSets:    make([]string, 0),
+		Cells:      cells,
+	}
+
+	versions := make(map[string]bool)
+	platforms := make(map[string]bool)
+	tagSets := make(map[string]bool)
+	for _, cell := range cells {
+		if !versions[cell.GoVersion] {
+			versions[cell.GoVersion] = true
+			summary.GoVersions = append(summary.GoVersions, cell.GoVersion)
+		}
+		if !platforms[cell.Platform()] {
+			platforms[cell.Platform()] = true
+			summary.Platforms = append(summary.Platforms, cell.Platform())
+		}
+		if !tagSets[cell.Tags] {
+			tagSets[cell.Tags] = true
+			summary.TagSets = append(summary.TagSets, cell.Tags)
+		}
+
+		if cell.Passed {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+	}
+
+	sort.Slice(summary.GoVersions, func(i, j int) bool {
+		return lessRelease(summary.GoVersions[i], summary.GoVersions[j])
+	})
+	sort.Strings(summary.Platforms)
+	sort.Strings(summary.TagSets)
+
+	return summary
+}
+
+// Grid renders a summary as a text table with a row per Go version and tag
+// set and a column per platform. Cells read ok or FAIL, and - where the
+// matrix has no such combination.
+func Grid(summary *libmodel.MatrixSummary) string {
+	outcomes := make(map[libmodel.MatrixCell]string, len(summary.Cells))
+	for _, cell := range summary.Cells {
+		outcome := "FAIL"
+		if cell.Passed {
+			outcome = "ok"
+		}
+		outcomes[cell.MatrixCell] = outcome
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	header := []string{""}
+	for _, platform := range summary.Platforms {
+		header = append(header, orDefault(platform))
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, version := range summary.GoVersions {
+		for _, tags := range summary.TagSets {
+			row := []string{rowLabel(version, tags)}
+			for _, platform := range summary.Platforms {
+				goos, goarch, _ := strings.Cut(platform, "/")
+				outcome, ok := outcomes[libmodel.MatrixCell{GoVersion: version, GOOS: goos, GOARCH: goarch, Tags: tags}]
+				if !ok {
+					outcome = "-"
+				}
+				row = append(row, outcome)
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+	}
+
+	w.Flush()
+	return b.String()
+}
+
+// rowLabel names a row of the grid, e.g. go1.22 tags=netgo
+func rowLabel(version, tags string) string {
+	label := "default"
+	if version != "" {
+		label = "go" + version
+	}
+	if tags != "" {
+		label += " tags=" + tags
+	}
+	return label
+}
+
+func orDefault(value string) string {
+	if value == "" {
+		return "default"
+	}
+	return value
+}
+
+// lessRelease orders Go releases such as 1.9 and 1.10 numerically
+func lessRelease(a, b string) bool {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr != nil || bErr != nil {
+			return a < b
+		}
+		if aNum != bNum {
+			return aNum < bNum
+		}
+	}
+	return len(aParts) < len(bParts)
+}
@@ -0,0 +1,35 @@
+package matrix
+
+import (
+	libmodel "distributed-analyzer/libs/model"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	cells := []libmodel.MatrixCellResult{
+		{MatrixCell: libmodel.MatrixCell{GoVersion: "1.10", GOOS: "linux", GOARCH: "amd64"}, Passed: true},
+		{MatrixCell: libmodel.MatrixCell{GoVersion: "1.10", GOOS: "windows", GOARCH: "amd64"}, Passed: false},
+		{MatrixCell: libmodel.MatrixCell{GoVersion: "1.9", GOOS: "linux", GOARCH: "amd64"}, Passed: true},
+	}
+
+	summary := Summarize(cells)
+
+	if strings.Join(summary.GoVersions, ",") != "1.9,1.10" || len(summary.Platforms) != 2 {
+		t.Fatalf("Unexpected dimensions %+v", summary)
+	}
+	if summary.Passed != 2 || summary.Failed != 1 {
+		t.Errorf("Expected 2 passed and 1 failed cell, got %d and %d", summary.Passed, summary.Failed)
+	}
+
+	lines := strings.Split(strings.TrimSpace(Grid(summary)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %q", lines)
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "go1.9 ok -" {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "go1.10 ok FAIL" {
+		t.Errorf("Unexpected row %q", lines[2])
+	}
+}
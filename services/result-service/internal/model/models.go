@@ -1,27 +1,95 @@
 package model
 
 import (
+	libmodel "distributed-analyzer/libs/model"
 	"time"
 )
 
 // TaskResult represents the result of a task execution
 type TaskResult struct {
-	TaskID     string            `json:"task_id"`
-	Status     string            `json:"status"`
-	Result     map[string]string `json:"result,omitempty"`
-	CreatedAt  time.Time         `json:"created_at"`
-	UpdatedAt  time.Time         `json:"updated_at"`
-	FinishedAt time.Time         `json:"finished_at,omitempty"`
+	TaskID     string                 `json:"task_id"`
+	Status     string                 `json:"status"`
+	Result     map[string]string      `json:"result,omitempty"`
+	OwnerID    string                 `json:"owner_id,omitempty"`
+	TenantID   string                 `json:"tenant_id,omitempty"`
+	Usage      libmodel.ResourceUsage `json:"usage"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	FinishedAt time.Time              `json:"finished_at,omitempty"`
 }
 
 // SubTaskResult represents the result of a subtask execution
 type SubTaskResult struct {
-	SubTaskID  string            `json:"subtask_id"`
+	SubTaskID  string                 `json:"subtask_id"`
+	TaskID     string                 `json:"task_id"`
+	WorkerID   string                 `json:"worker_id"`
+	Status     string                 `json:"status"`
+	Result     map[string]string      `json:"result,omitempty"`
+	Artifacts  []libmodel.Artifact    `json:"artifacts,omitempty"`
+	Usage      libmodel.ResourceUsage `json:"usage"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	FinishedAt time.Time              `json:"finished_at,omitempty"`
+}
+
+// BenchmarkResult represents the averaged measurements of one benchmark
+type BenchmarkResult struct {
+	Name    string             `json:"name"`
+	Runs    int                `json:"runs"`
+	Metrics map[string]float64 `json:"metrics"` // unit -> value, e.g. "ns/op" -> 1234
+}
+
+// BenchmarkBaseline represents the accepted benchmark run for a project/branch
+type BenchmarkBaseline struct {
+	TenantID   string            `json:"tenant_id"`
+	Project    string            `json:"project"`
+	Branch     string            `json:"branch"`
 	TaskID     string            `json:"task_id"`
-	WorkerID   string            `json:"worker_id"`
-	Status     string            `json:"status"`
-	Result     map[string]string `json:"result,omitempty"`
-	CreatedAt  time.Time         `json:"created_at"`
-	UpdatedAt  time.Time         `json:"updated_at"`
-	FinishedAt time.Time         `json:"finished_at,omitempty"`
+	Results    []BenchmarkResult `json:"results"`
+	AcceptedAt time.Time         `json:"accepted_at"`
+}
+
+// BenchmarkDelta describes how a single benchmark metric moved against the baseline
+type BenchmarkDelta struct {
+	Name         string  `json:"name"`
+	Unit         string  `json:"unit"`
+	Baseline     float64 `json:"baseline"`
+	Current      float64 `json:"current"`
+	DeltaPercent float64 `json:"delta_percent"`
+}
+
+// TaskRace represents a distinct data race found by a task
+type TaskRace struct {
+	Record      libmodel.RaceRecord `json:"record"`
+	Occurrences int                 `json:"occurrences"`
+}
+
+// TestFlakiness represents the outcomes of a test across the repeated runs of a task
+type TestFlakiness struct {
+	Name           string  `json:"name"`
+	Runs           int     `json:"runs"`
+	Passes         int     `json:"passes"`
+	Failures       int     `json:"failures"`
+	Classification string  `json:"classification"`
+	FlakeRate      float64 `json:"flake_rate"`
+}
+
+// FlakinessReport represents the classification of every test of a task
+type FlakinessReport struct {
+	TaskID    string          `json:"task_id"`
+	Runs      int             `json:"runs"`
+	Tests     []TestFlakiness `json:"tests"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// FlakeIndexEntry represents the flakiness history of a test across tasks
+type FlakeIndexEntry struct {
+	Name          string    `json:"name"`
+	Tasks         int       `json:"tasks"`
+	FlakyTasks    int       `json:"flaky_tasks"`
+	Runs          int       `json:"runs"`
+	Failures      int       `json:"failures"`
+	FlakeRate     float64   `json:"flake_rate"`
+	LastFlakyTask string    `json:"last_flaky_task,omitempty"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
 }
@@ -0,0 +1,126 @@
+package pprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/google/pprof/profile"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+// encodeProfile encodes a CPU profile whose samples are stacks of function
+// names, leaf first, with the given cpu values
+func encodeProfile(stacks [][]string, values []int64) []byte {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+	}
+
+	locations := make(map[string]*profile.Location)
+	for i, stack := range stacks {
+		sample := &profile.Sample{Value: []int64{1, values[i]}}
+		for _, name := range stack {
+			loc, ok := locations[name]
+			if !ok {
+				id := uint64(len(locations) + 1)
+				fn := &profile.Function{ID: id, Name: name, Filename: "main.go"}
+				loc = &profile.Location{ID: id, Line: []profile.Line{{Function: fn}}}
+				locations[name] = loc
+				p.Function = append(p.Function, fn)
+				p.Location = append(p.Location, loc)
+			}
+			sample.Location = append(sample.Location, loc)
+		}
+		p.Sample = append(p.Sample, sample)
+	}
+
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMergeHotspots(t *testing.T) {
+	shardA, err := Parse(encodeProfile([][]string{
+		{"main.hash", "main.handle", "main.main"},
+		{"main.handle", "main.main"},
+	}, []int64{60, 10}))
+	if err != nil {
+		t.Fatalf("Failed to parse profile: %v", err)
+	}
+	shardB, err := Parse(encodeProfile([][]string{
+		{"main.hash", "main.hash", "main.main"},
+		{"main.encode", "main.main"},
+	}, []int64{20, 10}))
+	if err != nil {
+		t.Fatalf("Failed to parse profile: %v", err)
+	}
+
+	merged, err := Merge([]*Profile{shardA, shardB})
+	if err != nil {
+		t.Fatalf("Failed to merge profiles: %v", err)
+	}
+
+	index, err := merged.SampleIndex("")
+	if err != nil || merged.SampleTypes[index].Type != "cpu" {
+		t.Fatalf("Expected cpu to be the default sample type, got %d: %v", index, err)
+	}
+
+	hotspots, total := merged.Hotspots(index, SortFlat, 2)
+	if total != 100 {
+		t.Errorf("Expected a total of 100, got %d", total)
+	}
+	if len(hotspots) != 2 {
+		t.Fatalf("Expected 2 hotspots, got %d", len(hotspots))
+	}
+	if h := hotspots[0]; h.Function != "main.hash" || h.Flat != 80 || h.Cum != 80 || h.FlatPercent != 80 {
+		t.Errorf("Unexpected top hotspot %+v", h)
+	}
+
+	hotspots, _ = merged.Hotspots(index, SortCum, 0)
+	if h := hotspots[0]; h.Function != "main.main" || h.Flat != 0 || h.Cum != 100 {
+		t.Errorf("Unexpected top cumulative hotspot %+v", h)
+	}
+	if len(hotspots) != 4 {
+		t.Errorf("Expected 4 hotspots, got %d", len(hotspots))
+	}
+}
+
+func TestMergeRejectsMismatchedProfiles(t *testing.T) {
+	cpu, err := Parse(encodeProfile([][]string{{"main.main"}}, []int64{1}))
+	if err != nil {
+		t.Fatalf("Failed to parse profile: %v", err)
+	}
+	heap := &Profile{SampleTypes: []ValueType{{Type: "alloc_space", Unit: "bytes"}}}
+
+	if _, err := Merge([]*Profile{cpu, heap}); err == nil {
+		t.Error("Expected profiles with different sample types not to merge")
+	}
+}
+
+func TestParseRuntimeProfile(t *testing.T) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to parse heap profile: %v", err)
+	}
+	if _, err := profile.SampleIndex("inuse_space"); err != nil {
+		t.Errorf("Expected an inuse_space sample type: %v", err)
+	}
+}
+
+func TestParseRejectsOversizedProfile(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(bytes.Repeat([]byte{0}, MaxProfileBytes+1))
+	zw.Close()
+
+	if _, err := Parse(buf.Bytes()); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Expected a profile beyond the limit to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Package pprof decodes the pprof profiles of go test, merges them and summarizes their hotspots.
+package pprof
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/google/pprof/profile"
+	"io"
+)
+
+// MaxProfileBytes bounds the size of a decompressed profile
+const MaxProfileBytes = 64 << 20
+
+// ValueType describes the values of the samples of a profile, e.g. cpu in nanoseconds
+type ValueType struct {
+	Type string
+	Unit string
+}
+
+// Frame is a function of a stack
+type Frame struct {
+	Function string
+	File     string
+}
+
+// Sample is a stack with one value per sample type of its profile
+type Sample struct {
+	// Stack lists the frames leaf first, with inlined functions expanded
+	Stack  []Frame
+	Values []int64
+}
+
+// Profile is a decoded pprof profile, reduced to what the hotspot summaries need
+type Profile struct {
+	SampleTypes       []ValueType
+	DefaultSampleType string
+	Samples           []Sample
+}
+
+// Parse decodes a profile in the protobuf format of pprof, gzipped or not.
+// Profiles larger than MaxProfileBytes once decompressed are rejected.
+func Parse(data []byte) (*Profile, error) {
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		data, err = io.ReadAll(io.LimitReader(zr, MaxProfileBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress profile: %w", err)
+		}
+	}
+	if len(data) > MaxProfileBytes {
+		return nil, fmt.Errorf("profile exceeds %d bytes", MaxProfileBytes)
+	}
+
+	p, err := profile.ParseUncompressed(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	}
+	return reduce(p), nil
+}
+
+// reduce keeps the sample types and the stacks of the functions of a profile
+func reduce(p *profile.Profile) *Profile {
+	reduced := &Profile{DefaultSampleType: p.DefaultSampleType}
+	for _, vt := range p.SampleType {
+		reduced.SampleTypes = append(reduced.SampleTypes, ValueType{Type: vt.Type, Unit: vt.Unit})
+	}
+
+	for _, s := range p.Sample {
+		sample := Sample{Values: s.Value}
+		for _, loc := range s.Location {
+			// The lines of a location list inlined functions before their caller
+			for _, line := range loc.Line {
+				if line.Function != nil {
+					sample.Stack = append(sample.Stack, Frame{Function: line.Function.Name, File: line.Function.Filename})
+				}
+			}
+		}
+		reduced.Samples = append(reduced.Samples, sample)
+	}
+	return reduced
+}
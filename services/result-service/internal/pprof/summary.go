@@ -0,0 +1,145 @@
+package pprof
+
+import (
+	"distributed-analyzer/libs/model"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Sort orders of hotspots
+const (
+	SortFlat = "flat"
+	SortCum  = "cum"
+)
+
+// Merge combines profiles of the same kind, e.g. the CPU profiles of the
+// packages of all shards, into one. The profiles must have the same sample types.
+func Merge(profiles []*Profile) (*Profile, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles to merge")
+	}
+
+	merged := &Profile{
+		SampleTypes:       profiles[0].SampleTypes,
+		DefaultSampleType: profiles[0].DefaultSampleType,
+	}
+	for i, profile := range profiles {
+		if !sameSampleTypes(profile.SampleTypes, merged.SampleTypes) {
+			return nil, fmt.Errorf("profile %d has sample types %v, expected %v", i, profile.SampleTypes, merged.SampleTypes)
+		}
+		merged.Samples = append(merged.Samples, profile.Samples...)
+	}
+	return merged, nil
+}
+
+// SampleIndex returns the index of a sample type of the profile. An empty
+// name selects the default sample type, or the last one like pprof does.
+func (p *Profile) SampleIndex(sampleType string) (int, error) {
+	if sampleType == "" {
+		sampleType = p.DefaultSampleType
+	}
+	if sampleType == "" {
+		if len(p.SampleTypes) == 0 {
+			return 0, fmt.Errorf("profile has no sample types")
+		}
+		return len(p.SampleTypes) - 1, nil
+	}
+
+	for i, vt := range p.SampleTypes {
+		if vt.Type == sampleType {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("profile has no sample type %q", sampleType)
+}
+
+// Hotspots returns the top n functions of a sample type of the profile,
+// ordered by flat or cum value, together with the total of the sample type.
+// A function appearing several times in a stack, e.g. through recursion,
+// adds to its cum value once. A non-positive n returns all functions.
+func (p *Profile) Hotspots(index int, order string, n int) ([]model.Hotspot, int64) {
+	type cost struct {
+		flat, cum int64
+	}
+	costs := make(map[Frame]*cost)
+	get := func(frame Frame) *cost {
+		c, ok := costs[frame]
+		if !ok {
+			c = &cost{}
+			costs[frame] = c
+		}
+		return c
+	}
+
+	var total int64
+	seen := make(map[Frame]bool)
+	for _, sample := range p.Samples {
+		value := sample.Values[index]
+		if value == 0 || len(sample.Stack) == 0 {
+			continue
+		}
+		total += value
+
+		get(sample.Stack[0]).flat += value
+		clear(seen)
+		for _, frame := range sample.Stack {
+			if !seen[frame] {
+				seen[frame] = true
+				get(frame).cum += value
+			}
+		}
+	}
+
+	hotspots := make([]model.Hotspot, 0, len(costs))
+	for frame, c := range costs {
+		hotspots = append(hotspots, model.Hotspot{
+			Function:    frame.Function,
+			File:        frame.File,
+			Flat:        c.flat,
+			FlatPercent: percent(c.flat, total),
+			Cum:         c.cum,
+			CumPercent:  percent(c.cum, total),
+		})
+	}
+
+	sort.Slice(hotspots, func(i, j int) bool {
+		a, b := hotspots[i], hotspots[j]
+		if order == SortCum && a.Cum != b.Cum {
+			return a.Cum > b.Cum
+		}
+		if a.Flat != b.Flat {
+			return a.Flat > b.Flat
+		}
+		if a.Cum != b.Cum {
+			return a.Cum > b.Cum
+		}
+		return a.Function < b.Function
+	})
+
+	if n > 0 && len(hotspots) > n {
+		hotspots = hotspots[:n]
+	}
+	return hotspots, total
+}
+
+// sameSampleTypes reports whether two profiles have the same sample types
+func sameSampleTypes(a, b []ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// percent returns part as a percentage of total, rounded to two decimals
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
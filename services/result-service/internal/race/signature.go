@@ -0,0 +1,39 @@
+// Package race identifies distinct data races reported by the race detector.
+package race
+
+import (
+	"crypto/sha1"
+	"distributed-analyzer/libs/model"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// Signature returns a stable identifier of a data race.
+// It is derived from the functions on the stacks of the conflicting accesses
+// and ignores addresses, goroutine IDs, line numbers and the order in which
+// the accesses were reported, so the same race found by different shards,
+// runs or revisions gets the same signature.
+func Signature(report model.RaceReport) string {
+	accesses := make([]string, len(report.Accesses))
+	for i, access := range report.Accesses {
+		functions := make([]string, len(access.Stack))
+		for j, frame := range access.Stack {
+			functions[j] = frame.Function
+		}
+		accesses[i] = normalizeOperation(access.Operation) + ":" + strings.Join(functions, ",")
+	}
+	sort.Strings(accesses)
+
+	sum := sha1.Sum([]byte(strings.Join(accesses, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizeOperation reduces an access description such as "Previous write" to read or write
+func normalizeOperation(operation string) string {
+	operation = strings.ToLower(operation)
+	if strings.Contains(operation, "write") {
+		return "write"
+	}
+	return "read"
+}
@@ -0,0 +1,35 @@
+package race
+
+import (
+	"distributed-analyzer/libs/model"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	inc := []model.StackFrame{{Function: "example.com/project/counter.(*Counter).Inc", File: "counter/counter.go", Line: 10}}
+	get := []model.StackFrame{{Function: "example.com/project/counter.(*Counter).Get", File: "counter/counter.go", Line: 14}}
+
+	report := model.RaceReport{Accesses: []model.RaceAccess{
+		{Operation: "Write", Goroutine: 8, Stack: inc},
+		{Operation: "Previous read", Goroutine: 7, Stack: get},
+	}}
+
+	moved := []model.StackFrame{{Function: inc[0].Function, File: inc[0].File, Line: 42}}
+	swapped := model.RaceReport{Accesses: []model.RaceAccess{
+		{Operation: "Read", Goroutine: 3, Stack: get},
+		{Operation: "Previous write", Goroutine: 5, Stack: moved},
+	}}
+
+	if Signature(report) != Signature(swapped) {
+		t.Error("Expected reordered accesses on shifted lines to share a signature")
+	}
+
+	other := model.RaceReport{Accesses: []model.RaceAccess{
+		{Operation: "Write", Stack: inc},
+		{Operation: "Previous write", Stack: get},
+	}}
+
+	if Signature(report) == Signature(other) {
+		t.Error("Expected different operations to produce different signatures")
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/benchmark"
+	"distributed-analyzer/services/result-service/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result keys read and written by the benchmark baseline processor
+const (
+	BenchmarkOutputKey         = "benchmark.output"
+	BenchmarkVerdictKey        = "benchmark.verdict"
+	BenchmarkBaselineTaskIDKey = "benchmark.baseline_task_id"
+	BenchmarkRegressionsKey    = "benchmark.regressions"
+)
+
+// Task input keys identifying which baseline a benchmark run is compared against
+const (
+	TaskInputProjectKey = "project"
+	TaskInputBranchKey  = "branch"
+)
+
+// Benchmark verdicts attached to a finalized task result
+const (
+	VerdictPass       = "pass"
+	VerdictRegression = "regression"
+	VerdictNoBaseline = "no_baseline"
+	VerdictUntracked  = "untracked"
+)
+
+// maxBenchmarkRuns bounds the benchmark runs kept to accept as a baseline later
+const maxBenchmarkRuns = 1024
+
+var (
+	// ErrBaselineNotFound is returned when no baseline exists for a project/branch
+	ErrBaselineNotFound = errors.New("baseline not found")
+
+	// ErrBenchmarkRunNotFound is returned when a task has no recorded benchmark results
+	ErrBenchmarkRunNotFound = errors.New("benchmark run not found")
+
+	// ErrBenchmarkRunMismatch is returned when a run is accepted as the baseline of another tenant or project/branch
+	ErrBenchmarkRunMismatch = errors.New("benchmark run belongs to another tenant or project/branch")
+)
+
+// TaskServiceClient retrieves tasks from the task service
+type TaskServiceClient interface {
+	// GetTask retrieves a task by its ID
+	GetTask(ctx context.Context, id string) (*libmodel.Task, error)
+}
+
+// RegressionPublisher publishes benchmark regression events
+type RegressionPublisher interface {
+	// PublishBenchmarkRegression publishes a BenchmarkRegressionEvent
+	PublishBenchmarkRegression(ctx context.Context, taskID string, baseline *model.BenchmarkBaseline, thresholdPercent float64, regressions []model.BenchmarkDelta) error
+}
+
+// benchmarkRun is a parsed benchmark run of a single task
+type benchmarkRun struct {
+	tenantID string
+	project  string
+	branch   string
+	results  []model.BenchmarkResult
+}
+
+// BenchmarkBaselineService keeps the latest accepted benchmark run per tenant and project/branch
+// and compares every new benchmark task against it.
+type BenchmarkBaselineService struct {
+	taskClient TaskServiceClient
+	publisher  RegressionPublisher
+
+	// store holds the outputs workers uploaded as artifacts
+	store ObjectStore
+
+	// thresholdPercent is the relative slowdown above which a metric counts as a regression
+	thresholdPercent float64
+
+	// autoPromote makes every run without regressions the new baseline
+	autoPromote bool
+
+	// baselines holds the accepted baseline, keyed by tenant and project/branch
+	baselines map[string]*model.BenchmarkBaseline
+
+	// runs holds the parsed benchmark results, keyed by task ID, and order
+	// holds their task IDs oldest first, so that the oldest runs are dropped
+	runs  map[string]*benchmarkRun
+	order []string
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*BenchmarkBaselineService)(nil)
+
+// NewBenchmarkBaselineService creates a new BenchmarkBaselineService that fetches
+// benchmark outputs too large to send inline from store
+func NewBenchmarkBaselineService(taskClient TaskServiceClient, publisher RegressionPublisher, store ObjectStore, thresholdPercent float64, autoPromote bool) *BenchmarkBaselineService {
+	return &BenchmarkBaselineService{
+		taskClient:       taskClient,
+		publisher:        publisher,
+		store:            store,
+		thresholdPercent: thresholdPercent,
+		autoPromote:      autoPromote,
+		baselines:        make(map[string]*model.BenchmarkBaseline),
+		runs:             make(map[string]*benchmarkRun),
+	}
+}
+
+// Process compares the benchmark output of a finalized task against its baseline
+// and records the verdict in the merged result.
+func (s *BenchmarkBaselineService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	outputs := make([]string, 0, len(subResults))
+	for _, sub := range subResults {
+		output, ok, err := resultValue(ctx, s.store, sub, BenchmarkOutputKey)
+		if err != nil {
+			return fmt.Errorf("failed to get benchmark output of subtask %s: %w", sub.SubTaskID, err)
+		}
+		if ok && output != "" {
+			outputs = append(outputs, output)
+		}
+	}
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	results := benchmark.Parse(strings.Join(outputs, "\n"))
+	if len(results) == 0 {
+		return nil
+	}
+
+	task, err := s.taskClient.GetTask(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task %s: %w", taskID, err)
+	}
+
+	project, branch := task.Input[TaskInputProjectKey], task.Input[TaskInputBranchKey]
+	run := &benchmarkRun{tenantID: task.TenantID, project: project, branch: branch, results: results}
+	verdict, baseline, regressions := s.compare(taskID, run)
+	result[BenchmarkVerdictKey] = verdict
+	if baseline == nil {
+		return nil
+	}
+	result[BenchmarkBaselineTaskIDKey] = baseline.TaskID
+	if len(regressions) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(regressions)
+	if err != nil {
+		return fmt.Errorf("failed to encode regressions: %w", err)
+	}
+	result[BenchmarkRegressionsKey] = string(encoded)
+
+	log.Printf("Task %s regressed %d benchmark metrics against baseline %s (%s/%s, threshold %.1f%%)",
+		taskID, len(regressions), baseline.TaskID, project, branch, s.thresholdPercent)
+
+	if err := s.publisher.PublishBenchmarkRegression(ctx, taskID, baseline, s.thresholdPercent, regressions); err != nil {
+		// The verdict is already part of the result, so publishing is best effort
+		log.Printf("Failed to publish BenchmarkRegressionEvent for task %s: %v", taskID, err)
+	}
+
+	return nil
+}
+
+// compare records the run of a task and compares it against the baseline of
+// its project/branch in one step, so that concurrent runs cannot both become
+// the first baseline. It returns the baseline the run was compared against,
+// which is nil when the run is untracked or became the first baseline.
+func (s *BenchmarkBaselineService) compare(taskID string, run *benchmarkRun) (string, *model.BenchmarkBaseline, []model.BenchmarkDelta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addRun(taskID, run)
+
+	if run.project == "" {
+		return VerdictUntracked, nil, nil
+	}
+
+	baseline, ok := s.baselines[baselineKey(run.tenantID, run.project, run.branch)]
+	if !ok {
+		s.accept(taskID, run)
+		return VerdictNoBaseline, nil, nil
+	}
+
+	regressions := benchmark.Compare(baseline.Results, run.results, s.thresholdPercent)
+	if len(regressions) > 0 {
+		return VerdictRegression, baseline, regressions
+	}
+
+	if s.autoPromote {
+		s.accept(taskID, run)
+	}
+	return VerdictPass, baseline, nil
+}
+
+// SetBaseline accepts the benchmark run of a task as the baseline for a project/branch of a tenant.
+// It returns ErrBenchmarkRunMismatch if the task belongs to another tenant or ran for another project/branch.
+func (s *BenchmarkBaselineService) SetBaseline(ctx context.Context, tenantID, project, branch, taskID string) (*model.BenchmarkBaseline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[taskID]
+	if !ok {
+		return nil, ErrBenchmarkRunNotFound
+	}
+	if run.tenantID != tenantID || run.project != project || run.branch != branch {
+		return nil, fmt.Errorf("%w: task %s ran for %s/%s", ErrBenchmarkRunMismatch, taskID, run.project, run.branch)
+	}
+
+	return s.accept(taskID, run), nil
+}
+
+// accept makes a run the baseline of its tenant and project/branch. The caller must hold the lock.
+func (s *BenchmarkBaselineService) accept(taskID string, run *benchmarkRun) *model.BenchmarkBaseline {
+	baseline := &model.BenchmarkBaseline{
+		TenantID:   run.tenantID,
+		Project:    run.project,
+		Branch:     run.branch,
+		TaskID:     taskID,
+		Results:    run.results,
+		AcceptedAt: time.Now(),
+	}
+	s.baselines[baselineKey(run.tenantID, run.project, run.branch)] = baseline
+
+	log.Printf("Task %s accepted as benchmark baseline for %s/%s", taskID, run.project, run.branch)
+	return baseline
+}
+
+// addRun records the run of a task, dropping the oldest runs beyond
+// maxBenchmarkRuns. Accepted baselines keep their own copy of the results.
+// The caller must hold the lock.
+func (s *BenchmarkBaselineService) addRun(taskID string, run *benchmarkRun) {
+	if _, ok := s.runs[taskID]; !ok {
+		s.order = append(s.order, taskID)
+	}
+	s.runs[taskID] = run
+
+	for len(s.order) > maxBenchmarkRuns {
+		delete(s.runs, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// GetBaseline retrieves the current baseline for a project/branch of a tenant
+func (s *BenchmarkBaselineService) GetBaseline(ctx context.Context, tenantID, project, branch string) (*model.BenchmarkBaseline, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	baseline, ok := s.baselines[baselineKey(tenantID, project, branch)]
+	if !ok {
+		return nil, ErrBaselineNotFound
+	}
+
+	return baseline, nil
+}
+
+// baselineKey builds the map key of the baseline of a project/branch of a tenant
+func baselineKey(tenantID, project, branch string) string {
+	return tenantID + "/" + project + "@" + branch
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/coverage"
+	"distributed-analyzer/services/result-service/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Result keys read and written by the coverage processor
+const (
+	CoverProfileKey   = "cover.profile"
+	CoverFunctionsKey = "cover.functions"
+	CoverPercentKey   = "cover.percent"
+)
+
+// ErrCoverageNotFound is returned when a task has no coverage report
+var ErrCoverageNotFound = errors.New("coverage report not found")
+
+// CoverageService merges the coverage profiles of all subtasks of a task
+// into a single profile and keeps the resulting report.
+type CoverageService struct {
+	// store holds the profiles workers uploaded as artifacts
+	store ObjectStore
+
+	// reports holds the merged coverage reports, keyed by task ID
+	reports map[string]*libmodel.CoverageReport
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*CoverageService)(nil)
+
+// NewCoverageService creates a new CoverageService that fetches profiles too large to send inline from store
+func NewCoverageService(store ObjectStore) *CoverageService {
+	return &CoverageService{
+		store:   store,
+		reports: make(map[string]*libmodel.CoverageReport),
+	}
+}
+
+// Process merges the coverage profiles of the subtasks and replaces the
+// per-shard profiles in the merged result with the combined one.
+func (s *CoverageService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	profiles := make([]*coverage.Profile, 0, len(subResults))
+	extents := make([]libmodel.FunctionExtent, 0)
+	seen := make(map[libmodel.FunctionExtent]bool)
+
+	for _, sub := range subResults {
+		text, ok, err := resultValue(ctx, s.store, sub, CoverProfileKey)
+		if err != nil {
+			return fmt.Errorf("failed to get coverage profile of subtask %s: %w", sub.SubTaskID, err)
+		}
+		if !ok {
+			continue
+		}
+
+		profile, err := coverage.Parse(text)
+		if err != nil {
+			return fmt.Errorf("failed to parse coverage profile of subtask %s: %w", sub.SubTaskID, err)
+		}
+		profiles = append(profiles, profile)
+
+		if encoded, ok := sub.Result[CoverFunctionsKey]; ok && encoded != "" {
+			var subExtents []libmodel.FunctionExtent
+			if err := json.Unmarshal([]byte(encoded), &subExtents); err != nil {
+				return fmt.Errorf("failed to decode function extents of subtask %s: %w", sub.SubTaskID, err)
+			}
+			for _, extent := range subExtents {
+				if !seen[extent] {
+					seen[extent] = true
+					extents = append(extents, extent)
+				}
+			}
+		}
+	}
+
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	merged, err := coverage.Merge(profiles)
+	if err != nil {
+		return fmt.Errorf("failed to merge coverage profiles: %w", err)
+	}
+
+	report := coverage.Report(taskID, merged, extents)
+
+	result[CoverProfileKey] = report.Profile
+	result[CoverPercentKey] = strconv.FormatFloat(report.Percent, 'f', 1, 64)
+	delete(result, CoverFunctionsKey)
+	delete(result, CoverProfileKey+ResultArtifactSuffix)
+
+	s.mu.Lock()
+	s.reports[taskID] = report
+	s.mu.Unlock()
+
+	return nil
+}
+
+// GetReport retrieves the merged coverage report of a task
+func (s *CoverageService) GetReport(ctx context.Context, taskID string) (*libmodel.CoverageReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report, ok := s.reports[taskID]
+	if !ok {
+		return nil, ErrCoverageNotFound
+	}
+
+	return report, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Result keys read and written by the dependency processor
+const (
+	DepsGraphKey        = "deps.graph"
+	VulnFindingsKey     = "vuln.findings"
+	VulnCountKey        = "vuln.count"
+	VulnReachedCountKey = "vuln.reached_count"
+)
+
+// ErrModuleGraphNotFound is returned when a task has no recorded go_deps run
+var ErrModuleGraphNotFound = errors.New("module graph not found")
+
+// ErrVulnerabilitiesNotFound is returned when a task was not matched against a vulnerability database
+var ErrVulnerabilitiesNotFound = errors.New("vulnerability report not found")
+
+// DepsService keeps the module graph and the vulnerabilities reported by the go_deps runs of every task
+type DepsService struct {
+	// graphs holds the module graph of each task, keyed by task ID
+	graphs map[string]*libmodel.ModuleGraph
+
+	// vulns holds the vulnerabilities found by each task, keyed by task ID
+	vulns map[string][]libmodel.Vulnerability
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*DepsService)(nil)
+
+// NewDepsService creates a new DepsService
+func NewDepsService() *DepsService {
+	return &DepsService{
+		graphs: make(map[string]*libmodel.ModuleGraph),
+		vulns:  make(map[string][]libmodel.Vulnerability),
+	}
+}
+
+// Process records the module graph and vulnerabilities of the subtasks and
+// replaces the per-subtask values in the merged result with the combined ones
+func (s *DepsService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	var graph *libmodel.ModuleGraph
+	var vulns []libmodel.Vulnerability
+	seen := make(map[string]bool)
+
+	for _, sub := range subResults {
+		if encoded, ok := sub.Result[DepsGraphKey]; ok && graph == nil {
+			graph = &libmodel.ModuleGraph{}
+			if err := json.Unmarshal([]byte(encoded), graph); err != nil {
+				return fmt.Errorf("failed to decode module graph of subtask %s: %w", sub.SubTaskID, err)
+			}
+		}
+
+		encoded, ok := sub.Result[VulnFindingsKey]
+		if !ok {
+			continue
+		}
+
+		var subVulns []libmodel.Vulnerability
+		if err := json.Unmarshal([]byte(encoded), &subVulns); err != nil {
+			return fmt.Errorf("failed to decode vulnerabilities of subtask %s: %w", sub.SubTaskID, err)
+		}
+		if vulns == nil {
+			vulns = make([]libmodel.Vulnerability, 0, len(subVulns))
+		}
+		for _, vuln := range subVulns {
+			key := vuln.Module + "\x00" + vuln.ID
+			if !seen[key] {
+				seen[key] = true
+				vulns = append(vulns, vuln)
+			}
+		}
+	}
+
+	if graph == nil && vulns == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	if graph != nil {
+		s.graphs[taskID] = graph
+	}
+	if vulns != nil {
+		s.vulns[taskID] = vulns
+	}
+	s.mu.Unlock()
+
+	if graph != nil {
+		encoded, err := json.Marshal(graph)
+		if err != nil {
+			return fmt.Errorf("failed to encode module graph: %w", err)
+		}
+		result[DepsGraphKey] = string(encoded)
+	}
+
+	if vulns != nil {
+		sort.Slice(vulns, func(i, j int) bool {
+			if vulns[i].Module != vulns[j].Module {
+				return vulns[i].Module < vulns[j].Module
+			}
+			return vulns[i].ID < vulns[j].ID
+		})
+
+		encoded, err := json.Marshal(vulns)
+		if err != nil {
+			return fmt.Errorf("failed to encode vulnerabilities: %w", err)
+		}
+		result[VulnFindingsKey] = string(encoded)
+		result[VulnCountKey] = strconv.Itoa(len(vulns))
+		result[VulnReachedCountKey] = strconv.Itoa(countImported(vulns))
+	}
+
+	return nil
+}
+
+// GetModuleGraph retrieves the module graph of a task
+func (s *DepsService) GetModuleGraph(ctx context.Context, taskID string) (*libmodel.ModuleGraph, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	graph, ok := s.graphs[taskID]
+	if !ok {
+		return nil, ErrModuleGraphNotFound
+	}
+	return graph, nil
+}
+
+// GetVulnerabilities retrieves the vulnerabilities affecting the modules of a task
+func (s *DepsService) GetVulnerabilities(ctx context.Context, taskID string) ([]libmodel.Vulnerability, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vulns, ok := s.vulns[taskID]
+	if !ok {
+		return nil, ErrVulnerabilitiesNotFound
+	}
+	return vulns, nil
+}
+
+// countImported counts the vulnerabilities with a vulnerable package the main module depends on
+func countImported(vulns []libmodel.Vulnerability) int {
+	count := 0
+	for _, vuln := range vulns {
+		for _, pkg := range vuln.Packages {
+			if pkg.Imported {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/services/result-service/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PackageDurationsKey holds the run time in seconds of every tested package as JSON
+const PackageDurationsKey = "test.package_durations"
+
+// durationSmoothing is the weight of the latest run in the moving average of package durations
+const durationSmoothing = 0.5
+
+// ErrDurationsNotFound is returned when a project has no recorded package durations
+var ErrDurationsNotFound = errors.New("package durations not found")
+
+// DurationService keeps the historical run time of every tested package per tenant and project,
+// which the scheduler uses to build balanced test shards.
+type DurationService struct {
+	taskClient TaskServiceClient
+
+	// durations holds the smoothed run time in seconds, keyed by tenant and project and then package
+	durations map[string]map[string]float64
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*DurationService)(nil)
+
+// NewDurationService creates a new DurationService
+func NewDurationService(taskClient TaskServiceClient) *DurationService {
+	return &DurationService{
+		taskClient: taskClient,
+		durations:  make(map[string]map[string]float64),
+	}
+}
+
+// Process records the package durations reported by the subtasks of a task
+// and replaces the per-shard durations in the merged result with the combined ones.
+func (s *DurationService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	durations := make(map[string]float64)
+	for _, sub := range subResults {
+		encoded, ok := sub.Result[PackageDurationsKey]
+		if !ok {
+			continue
+		}
+
+		var subDurations map[string]float64
+		if err := json.Unmarshal([]byte(encoded), &subDurations); err != nil {
+			return fmt.Errorf("failed to decode package durations of subtask %s: %w", sub.SubTaskID, err)
+		}
+		for pkg, seconds := range subDurations {
+			durations[pkg] = max(durations[pkg], seconds)
+		}
+	}
+
+	if len(durations) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(durations)
+	if err != nil {
+		return fmt.Errorf("failed to encode package durations: %w", err)
+	}
+	result[PackageDurationsKey] = string(encoded)
+
+	task, err := s.taskClient.GetTask(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task %s: %w", taskID, err)
+	}
+
+	project := task.Input[TaskInputProjectKey]
+	if project == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := durationKey(task.TenantID, project)
+	history, ok := s.durations[key]
+	if !ok {
+		history = make(map[string]float64)
+		s.durations[key] = history
+	}
+
+	for pkg, seconds := range durations {
+		if previous, ok := history[pkg]; ok {
+			seconds = durationSmoothing*seconds + (1-durationSmoothing)*previous
+		}
+		history[pkg] = seconds
+	}
+
+	return nil
+}
+
+// GetPackageDurations retrieves the historical run time in seconds of every package of a project of a tenant
+func (s *DurationService) GetPackageDurations(ctx context.Context, tenantID, project string) (map[string]float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	history, ok := s.durations[durationKey(tenantID, project)]
+	if !ok {
+		return nil, ErrDurationsNotFound
+	}
+
+	durations := make(map[string]float64, len(history))
+	for pkg, seconds := range history {
+		durations[pkg] = seconds
+	}
+	return durations, nil
+}
+
+// durationKey builds the map key of the durations of a project of a tenant
+func durationKey(tenantID, project string) string {
+	return tenantID + "/" + project
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/services/result-service/internal/flaky"
+	"distributed-analyzer/services/result-service/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Result keys read and written by the flakiness processor
+const (
+	TestResultsKey     = "test.results"
+	FlakyTestsKey      = "flaky.tests"
+	FlakyTestCountKey  = "flaky.count"
+	StableFailCountKey = "flaky.stable_fail_count"
+)
+
+var (
+	// ErrFlakinessReportNotFound is returned when a task has no flakiness report
+	ErrFlakinessReportNotFound = errors.New("flakiness report not found")
+
+	// ErrFlakeIndexEntryNotFound is returned when a test has no flakiness history
+	ErrFlakeIndexEntryNotFound = errors.New("flake index entry not found")
+)
+
+// FlakinessService classifies tests run repeatedly by a task as stable or flaky
+// and keeps a historical flake index per test name.
+type FlakinessService struct {
+	// reports holds the flakiness reports, keyed by task ID
+	reports map[string]*model.FlakinessReport
+
+	// index holds the flakiness history, keyed by test name
+	index map[string]*model.FlakeIndexEntry
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*FlakinessService)(nil)
+
+// NewFlakinessService creates a new FlakinessService
+func NewFlakinessService() *FlakinessService {
+	return &FlakinessService{
+		reports: make(map[string]*model.FlakinessReport),
+		index:   make(map[string]*model.FlakeIndexEntry),
+	}
+}
+
+// Process classifies the tests of a task that ran them more than once.
+// Single runs cannot tell flaky tests apart and are ignored.
+func (s *FlakinessService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	runs := make([]map[string]string, 0, len(subResults))
+	for _, sub := range subResults {
+		encoded, ok := sub.Result[TestResultsKey]
+		if !ok {
+			continue
+		}
+
+		var run map[string]string
+		if err := json.Unmarshal([]byte(encoded), &run); err != nil {
+			return fmt.Errorf("failed to decode test results of subtask %s: %w", sub.SubTaskID, err)
+		}
+		runs = append(runs, run)
+	}
+
+	if len(runs) < 2 {
+		return nil
+	}
+
+	report := flaky.Classify(taskID, runs)
+
+	flakyTests := make([]string, 0)
+	stableFailures := 0
+	for _, test := range report.Tests {
+		switch test.Classification {
+		case flaky.Flaky:
+			flakyTests = append(flakyTests, test.Name)
+		case flaky.StableFail:
+			stableFailures++
+		}
+	}
+
+	encoded, err := json.Marshal(flakyTests)
+	if err != nil {
+		return fmt.Errorf("failed to encode flaky tests: %w", err)
+	}
+
+	delete(result, TestResultsKey)
+	result[FlakyTestsKey] = string(encoded)
+	result[FlakyTestCountKey] = strconv.Itoa(len(flakyTests))
+	result[StableFailCountKey] = strconv.Itoa(stableFailures)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reports[taskID] = report
+	for _, test := range report.Tests {
+		s.record(taskID, report, test)
+	}
+
+	return nil
+}
+
+// record adds the outcome of a test in a task to the flake index.
+// Consistent failures point at a broken test rather than a flaky one, so
+// their runs do not count towards the flake rate. The caller must hold the lock.
+func (s *FlakinessService) record(taskID string, report *model.FlakinessReport, test model.TestFlakiness) {
+	entry, ok := s.index[test.Name]
+	if !ok {
+		entry = &model.FlakeIndexEntry{Name: test.Name}
+		s.index[test.Name] = entry
+	}
+
+	entry.Tasks++
+	entry.LastSeenAt = report.CreatedAt
+
+	if test.Classification == flaky.StableFail {
+		return
+	}
+
+	entry.Runs += test.Runs
+	entry.Failures += test.Failures
+	entry.FlakeRate = float64(entry.Failures) / float64(entry.Runs)
+
+	if test.Classification == flaky.Flaky {
+		entry.FlakyTasks++
+		entry.LastFlakyTask = taskID
+	}
+}
+
+// GetReport retrieves the flakiness report of a task
+func (s *FlakinessService) GetReport(ctx context.Context, taskID string) (*model.FlakinessReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report, ok := s.reports[taskID]
+	if !ok {
+		return nil, ErrFlakinessReportNotFound
+	}
+
+	return report, nil
+}
+
+// GetIndexEntry retrieves the flakiness history of a test
+func (s *FlakinessService) GetIndexEntry(ctx context.Context, name string) (*model.FlakeIndexEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.index[name]
+	if !ok {
+		return nil, ErrFlakeIndexEntryNotFound
+	}
+
+	copied := *entry
+	return &copied, nil
+}
+
+// ListIndex retrieves the flakiness history of every test that was ever flaky,
+// most flaky first
+func (s *FlakinessService) ListIndex(ctx context.Context) ([]*model.FlakeIndexEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]*model.FlakeIndexEntry, 0)
+	for _, entry := range s.index {
+		if entry.FlakyTasks == 0 {
+			continue
+		}
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].FlakeRate != entries[j].FlakeRate {
+			return entries[i].FlakeRate > entries[j].FlakeRate
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
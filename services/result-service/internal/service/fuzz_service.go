@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/fuzz"
+	"distributed-analyzer/services/result-service/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result keys read and written by the fuzzing processor
+const (
+	FuzzCrashersKey          = "fuzz.crashers"
+	FuzzNewInputsKey         = "fuzz.new_inputs"
+	FuzzCrasherCountKey      = "fuzz.crasher_count"
+	FuzzCrasherSignaturesKey = "fuzz.crasher_signatures"
+)
+
+// ErrFuzzCampaignNotFound is returned when a task has no recorded fuzzing campaign
+var ErrFuzzCampaignNotFound = errors.New("fuzz campaign not found")
+
+// FuzzService de-duplicates the crashers found by fuzzing campaigns and keeps
+// the smallest reproducer of every distinct failure.
+type FuzzService struct {
+	// records holds every distinct failure, keyed by signature
+	records map[string]*libmodel.FuzzCrasherRecord
+
+	// campaigns holds the signatures found by each task, keyed by task ID
+	campaigns map[string][]string
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*FuzzService)(nil)
+
+// NewFuzzService creates a new FuzzService
+func NewFuzzService() *FuzzService {
+	return &FuzzService{
+		records:   make(map[string]*libmodel.FuzzCrasherRecord),
+		campaigns: make(map[string][]string),
+	}
+}
+
+// Process records the crashers of every fuzzed target and replaces the
+// per-target crasher lists in the merged result with the distinct signatures.
+func (s *FuzzService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	crashers := make([]libmodel.FuzzCrasher, 0)
+	newInputs := 0
+	found := false
+
+	for _, sub := range subResults {
+		encoded, ok := sub.Result[FuzzCrashersKey]
+		if !ok {
+			continue
+		}
+		found = true
+
+		var subCrashers []libmodel.FuzzCrasher
+		if err := json.Unmarshal([]byte(encoded), &subCrashers); err != nil {
+			return fmt.Errorf("failed to decode crashers of subtask %s: %w", sub.SubTaskID, err)
+		}
+		crashers = append(crashers, subCrashers...)
+
+		if n, err := strconv.Atoi(sub.Result[FuzzNewInputsKey]); err == nil {
+			newInputs += n
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	now := time.Now()
+	signatures := make([]string, 0)
+
+	s.mu.Lock()
+	for _, crasher := range crashers {
+		signature := fuzz.Signature(crasher)
+		if !containsString(signatures, signature) {
+			signatures = append(signatures, signature)
+		}
+
+		record, ok := s.records[signature]
+		if !ok {
+			record = &libmodel.FuzzCrasherRecord{
+				Signature:     signature,
+				Reproducer:    crasher,
+				FirstSeenTask: taskID,
+				FirstSeenAt:   now,
+			}
+			s.records[signature] = record
+		} else if len(crasher.Input) > 0 && len(crasher.Input) < len(record.Reproducer.Input) {
+			record.Reproducer = crasher
+		}
+
+		record.Count++
+		record.LastSeenTask = taskID
+		record.LastSeenAt = now
+	}
+	sort.Strings(signatures)
+	s.campaigns[taskID] = signatures
+	s.mu.Unlock()
+
+	delete(result, FuzzCrashersKey)
+	result[FuzzNewInputsKey] = strconv.Itoa(newInputs)
+	result[FuzzCrasherCountKey] = strconv.Itoa(len(signatures))
+	result[FuzzCrasherSignaturesKey] = strings.Join(signatures, ",")
+
+	return nil
+}
+
+// GetTaskCrashers retrieves the distinct failures found by a fuzzing task
+func (s *FuzzService) GetTaskCrashers(ctx context.Context, taskID string) ([]*libmodel.FuzzCrasherRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	signatures, ok := s.campaigns[taskID]
+	if !ok {
+		return nil, ErrFuzzCampaignNotFound
+	}
+
+	records := make([]*libmodel.FuzzCrasherRecord, len(signatures))
+	for i, signature := range signatures {
+		copied := *s.records[signature]
+		records[i] = &copied
+	}
+
+	return records, nil
+}
+
+// ListCrashers retrieves every distinct failure found so far, optionally
+// limited to one target, most frequent first
+func (s *FuzzService) ListCrashers(ctx context.Context, target string) ([]*libmodel.FuzzCrasherRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]*libmodel.FuzzCrasherRecord, 0)
+	for _, record := range s.records {
+		reproducer := record.Reproducer
+		if target != "" && target != reproducer.Target && target != reproducer.Package+"."+reproducer.Target {
+			continue
+		}
+		copied := *record
+		records = append(records, &copied)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Count != records[j].Count {
+			return records[i].Count > records[j].Count
+		}
+		return records[i].Signature < records[j].Signature
+	})
+
+	return records, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/model"
+	"errors"
+	"sync"
+	"time"
+)
+
+// maxTaskLogBytes bounds the output kept per task; the oldest chunks are dropped beyond it
+const maxTaskLogBytes = 16 << 20
+
+// logGracePeriod is how long streams keep following a finalized task. Output
+// travels on its own topic, so its last chunks may arrive after the result.
+const logGracePeriod = 2 * time.Second
+
+// ErrLogsNotFound is returned when no output was received for a task
+var ErrLogsNotFound = errors.New("logs not found")
+
+// taskLog is the output received for a task
+type taskLog struct {
+	taskID string
+	chunks []*libmodel.LogChunk
+	size   int
+
+	// offset is the number of chunks dropped from the front
+	offset int
+
+	// sequences holds the highest sequence number received per subtask
+	sequences map[string]int64
+
+	// finalizedAt is when the result of the task was finalized, zero before
+	finalizedAt time.Time
+
+	// changed is closed and replaced whenever the log changes
+	changed chan struct{}
+}
+
+// LogService keeps the output of the commands of tasks while they run and
+// streams it to clients following a task until its result is finalized. The
+// output of a task is kept for a retention period after its result was finalized.
+type LogService struct {
+	retention time.Duration
+	now       func() time.Time
+
+	// logs holds the output, keyed by task ID, and finalized holds the logs of
+	// finalized tasks in the order they expire
+	logs      map[string]*taskLog
+	finalized []*taskLog
+
+	mu sync.Mutex
+}
+
+var _ ResultProcessor = (*LogService)(nil)
+
+// NewLogService creates a new LogService that keeps the output of finalized tasks for retention
+func NewLogService(retention time.Duration) *LogService {
+	return &LogService{
+		retention: retention,
+		now:       time.Now,
+		logs:      make(map[string]*taskLog),
+	}
+}
+
+// Append adds a chunk of output to the log of its task. Chunks that repeat or
+// precede a chunk already received from the same subtask are ignored.
+func (s *LogService) Append(ctx context.Context, chunk *libmodel.LogChunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+	l := s.log(chunk.TaskID)
+	if chunk.Sequence <= l.sequences[chunk.SubTaskID] {
+		return nil
+	}
+	l.sequences[chunk.SubTaskID] = chunk.Sequence
+
+	l.chunks = append(l.chunks, chunk)
+	l.size += len(chunk.Data)
+	for l.size > maxTaskLogBytes && len(l.chunks) > 1 {
+		l.size -= len(l.chunks[0].Data)
+		l.chunks[0] = nil
+		l.chunks = l.chunks[1:]
+		l.offset++
+	}
+
+	l.notify()
+	return nil
+}
+
+// Process marks the log of a finalized task as complete, which ends the
+// streams following it once the grace period is over
+func (s *LogService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+	l := s.log(taskID)
+	if l.finalizedAt.IsZero() {
+		s.finalized = append(s.finalized, l)
+	}
+	l.finalizedAt = now
+	l.notify()
+	return nil
+}
+
+// StreamLogs calls send with the chunks of a task in the order they arrived.
+// With follow, it keeps sending new chunks until the task is finalized or ctx
+// ends. It returns ErrLogsNotFound if no output was received for the task.
+func (s *LogService) StreamLogs(ctx context.Context, taskID string, follow bool, send func(*libmodel.LogChunk) error) error {
+	s.mu.Lock()
+	s.expire(s.now())
+	l, ok := s.logs[taskID]
+	s.mu.Unlock()
+	if !ok {
+		return ErrLogsNotFound
+	}
+
+	next := 0
+	for {
+		s.mu.Lock()
+		// Chunks dropped meanwhile are skipped
+		next = max(next, l.offset)
+		chunks := append([]*libmodel.LogChunk(nil), l.chunks[next-l.offset:]...)
+		next += len(chunks)
+		finalizedAt, changed := l.finalizedAt, l.changed
+		s.mu.Unlock()
+
+		for _, chunk := range chunks {
+			if err := send(chunk); err != nil {
+				return err
+			}
+		}
+		if !follow {
+			return nil
+		}
+
+		var grace <-chan time.Time
+		if !finalizedAt.IsZero() {
+			remaining := time.Until(finalizedAt.Add(logGracePeriod))
+			if remaining <= 0 {
+				return nil
+			}
+			grace = time.After(remaining)
+		}
+
+		select {
+		case <-changed:
+		case <-grace:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// log returns the log of a task, creating it if needed. The caller must hold the lock.
+func (s *LogService) log(taskID string) *taskLog {
+	l, ok := s.logs[taskID]
+	if !ok {
+		l = &taskLog{
+			taskID:    taskID,
+			sequences: make(map[string]int64),
+			changed:   make(chan struct{}),
+		}
+		s.logs[taskID] = l
+	}
+	return l
+}
+
+// expire forgets the logs of tasks finalized longer than the retention ago.
+// Streams still following such a log keep it until they end. The caller must
+// hold the lock.
+func (s *LogService) expire(now time.Time) {
+	expired := 0
+	for _, l := range s.finalized {
+		if now.Before(l.finalizedAt.Add(s.retention)) {
+			break
+		}
+		if s.logs[l.taskID] == l {
+			delete(s.logs, l.taskID)
+		}
+		expired++
+	}
+	s.finalized = s.finalized[expired:]
+}
+
+// notify wakes the streams waiting for the log to change. The caller must hold the lock.
+func (l *taskLog) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/matrix"
+	"distributed-analyzer/services/result-service/internal/model"
+	"encoding/json"
+	"fmt"
+)
+
+// Result keys read and written by the matrix processor
+const (
+	MatrixCellKey    = "matrix.cell"
+	MatrixSummaryKey = "matrix.summary"
+	MatrixGridKey    = "matrix.grid"
+)
+
+// subTaskExitCodeKey is the result key of the exit code of the command a subtask ran
+const subTaskExitCodeKey = "exit_code"
+
+// MatrixService summarizes the cells of a build matrix into a grid
+type MatrixService struct{}
+
+var _ ResultProcessor = (*MatrixService)(nil)
+
+// NewMatrixService creates a new MatrixService
+func NewMatrixService() *MatrixService {
+	return &MatrixService{}
+}
+
+// Process replaces the per-cell matrix keys in the merged result with a
+// summary of every cell and a grid of their outcomes. A cell passes when its
+// subtask succeeded and its command exited with code 0.
+func (s *MatrixService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	cells := make([]libmodel.MatrixCellResult, 0)
+	for _, sub := range subResults {
+		encoded, ok := sub.Result[MatrixCellKey]
+		if !ok {
+			continue
+		}
+
+		cell := libmodel.MatrixCellResult{
+			SubTaskID: sub.SubTaskID,
+			ExitCode:  sub.Result[subTaskExitCodeKey],
+			Error:     sub.Result[SubTaskErrorKey],
+		}
+		if err := json.Unmarshal([]byte(encoded), &cell.MatrixCell); err != nil {
+			return fmt.Errorf("failed to decode matrix cell of subtask %s: %w", sub.SubTaskID, err)
+		}
+		cell.Passed = sub.Result[SubTaskStatusKey] != subTaskStatusFailed && cell.ExitCode == "0"
+
+		cells = append(cells, cell)
+	}
+
+	if len(cells) == 0 {
+		return nil
+	}
+
+	summary := matrix.Summarize(cells)
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode matrix summary: %w", err)
+	}
+
+	delete(result, MatrixCellKey)
+	result[MatrixSummaryKey] = string(encoded)
+	result[MatrixGridKey] = matrix.Grid(summary)
+
+	return nil
+}
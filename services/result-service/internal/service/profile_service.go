@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/model"
+	"distributed-analyzer/services/result-service/internal/pprof"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProfileKindsKey lists the kinds of profiles merged for a task
+const ProfileKindsKey = "profile.kinds"
+
+// profileArtifactPrefix is the prefix of the names of the profile artifacts
+// workers upload, named profiles/<import path>/<kind>.pprof
+const profileArtifactPrefix = "profiles/"
+
+// Errors returned by the profile service
+var (
+	ErrProfileNotFound     = errors.New("profile not found")
+	ErrInvalidProfileQuery = errors.New("invalid profile query")
+)
+
+// ObjectStore retrieves objects from the storage service
+type ObjectStore interface {
+	// GetObject retrieves the content of an object
+	GetObject(ctx context.Context, key string) ([]byte, error)
+}
+
+// taskProfile is a profile merged across the subtasks of a task
+type taskProfile struct {
+	profile *pprof.Profile
+
+	// count is the number of profiles merged
+	count int
+}
+
+// taskProfiles are the merged profiles of a task, keyed by kind, and when they expire
+type taskProfiles struct {
+	taskID    string
+	kinds     map[string]*taskProfile
+	expiresAt time.Time
+}
+
+// ProfileService merges the pprof profiles the subtasks of a task uploaded
+// as artifacts, per kind, and summarizes their hotspots. The profiles of a
+// task are kept for a retention period after its result was finalized.
+type ProfileService struct {
+	store     ObjectStore
+	retention time.Duration
+	now       func() time.Time
+
+	// profiles holds the merged profiles, keyed by task ID, and order holds
+	// them by expiry
+	profiles map[string]*taskProfiles
+	order    []*taskProfiles
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*ProfileService)(nil)
+
+// NewProfileService creates a new ProfileService that fetches profiles from
+// store and keeps them for retention
+func NewProfileService(store ObjectStore, retention time.Duration) *ProfileService {
+	return &ProfileService{
+		store:     store,
+		retention: retention,
+		now:       time.Now,
+		profiles:  make(map[string]*taskProfiles),
+	}
+}
+
+// Process fetches the profile artifacts of the subtasks and merges them per kind.
+// Profiles that cannot be fetched or decoded are skipped, as the result is
+// still useful without them.
+func (s *ProfileService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	byKind := make(map[string][]*pprof.Profile)
+	for _, sub := range subResults {
+		for _, artifact := range sub.Artifacts {
+			kind, ok := profileKind(artifact.Name)
+			if !ok {
+				continue
+			}
+
+			content, err := s.store.GetObject(ctx, artifact.StorageKey)
+			if err != nil {
+				log.Printf("Failed to fetch profile %s of subtask %s: %v", artifact.Name, sub.SubTaskID, err)
+				continue
+			}
+			profile, err := pprof.Parse(content)
+			if err != nil {
+				log.Printf("Failed to parse profile %s of subtask %s: %v", artifact.Name, sub.SubTaskID, err)
+				continue
+			}
+			byKind[kind] = append(byKind[kind], profile)
+		}
+	}
+
+	if len(byKind) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]*taskProfile, len(byKind))
+	kinds := make([]string, 0, len(byKind))
+	for kind, profiles := range byKind {
+		profile, err := pprof.Merge(profiles)
+		if err != nil {
+			log.Printf("Failed to merge %s profiles of task %s: %v", kind, taskID, err)
+			continue
+		}
+		merged[kind] = &taskProfile{profile: profile, count: len(profiles)}
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	s.mu.Lock()
+	now := s.now()
+	s.expire(now)
+	entry := &taskProfiles{taskID: taskID, kinds: merged, expiresAt: now.Add(s.retention)}
+	s.profiles[taskID] = entry
+	s.order = append(s.order, entry)
+	s.mu.Unlock()
+
+	result[ProfileKindsKey] = strings.Join(kinds, ",")
+	return nil
+}
+
+// GetHotspots retrieves the top functions of a merged profile of a task.
+// An empty sample type selects the default one of the profile, order is
+// pprof.SortFlat or pprof.SortCum and a non-positive limit returns all functions.
+func (s *ProfileService) GetHotspots(ctx context.Context, taskID, kind, sampleType, order string, limit int) (*libmodel.ProfileHotspots, error) {
+	if order == "" {
+		order = pprof.SortFlat
+	}
+	if order != pprof.SortFlat && order != pprof.SortCum {
+		return nil, fmt.Errorf("%w: unknown order %q", ErrInvalidProfileQuery, order)
+	}
+
+	s.mu.RLock()
+	var tp *taskProfile
+	if entry, ok := s.profiles[taskID]; ok && s.now().Before(entry.expiresAt) {
+		tp = entry.kinds[kind]
+	}
+	s.mu.RUnlock()
+	if tp == nil {
+		return nil, ErrProfileNotFound
+	}
+
+	index, err := tp.profile.SampleIndex(sampleType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfileQuery, err)
+	}
+
+	hotspots, total := tp.profile.Hotspots(index, order, limit)
+
+	sampleTypes := make([]string, len(tp.profile.SampleTypes))
+	for i, vt := range tp.profile.SampleTypes {
+		sampleTypes[i] = vt.Type
+	}
+
+	return &libmodel.ProfileHotspots{
+		TaskID:      taskID,
+		Kind:        kind,
+		SampleType:  tp.profile.SampleTypes[index].Type,
+		Unit:        tp.profile.SampleTypes[index].Unit,
+		SampleTypes: sampleTypes,
+		Profiles:    tp.count,
+		Total:       total,
+		Hotspots:    hotspots,
+	}, nil
+}
+
+// expire forgets the profiles whose retention ended. The caller must hold the write lock.
+func (s *ProfileService) expire(now time.Time) {
+	expired := 0
+	for _, entry := range s.order {
+		if now.Before(entry.expiresAt) {
+			break
+		}
+		// A task finalized again has a newer entry
+		if s.profiles[entry.taskID] == entry {
+			delete(s.profiles, entry.taskID)
+		}
+		expired++
+	}
+	s.order = s.order[expired:]
+}
+
+// profileKind returns the kind of a profile artifact, e.g. cpu for profiles/example.com/a/cpu.pprof
+func profileKind(name string) (string, bool) {
+	if !strings.HasPrefix(name, profileArtifactPrefix) {
+		return "", false
+	}
+	kind, ok := strings.CutSuffix(path.Base(name), ".pprof")
+	return kind, ok
+}
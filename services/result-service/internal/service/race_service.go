@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/model"
+	"distributed-analyzer/services/result-service/internal/race"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result keys read and written by the race processor
+const (
+	RaceReportsKey    = "race.reports"
+	RaceCountKey      = "race.count"
+	RaceSignaturesKey = "race.signatures"
+)
+
+// ErrRacesNotFound is returned when a task has no recorded race detector run
+var ErrRacesNotFound = errors.New("race reports not found")
+
+// RaceService deduplicates data races across shards and runs by stack signature
+// and keeps an index of every distinct race seen so far.
+type RaceService struct {
+	// records holds every distinct race, keyed by signature
+	records map[string]*libmodel.RaceRecord
+
+	// taskRaces holds the occurrences of each race per task, keyed by task ID and then signature
+	taskRaces map[string]map[string]int
+
+	mu sync.RWMutex
+}
+
+var _ ResultProcessor = (*RaceService)(nil)
+
+// NewRaceService creates a new RaceService
+func NewRaceService() *RaceService {
+	return &RaceService{
+		records:   make(map[string]*libmodel.RaceRecord),
+		taskRaces: make(map[string]map[string]int),
+	}
+}
+
+// Process records the race reports of every subtask and replaces the
+// per-shard reports in the merged result with the distinct signatures.
+func (s *RaceService) Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error {
+	reports := make([]libmodel.RaceReport, 0)
+	found := false
+
+	for _, sub := range subResults {
+		encoded, ok := sub.Result[RaceReportsKey]
+		if !ok {
+			continue
+		}
+		found = true
+
+		var subReports []libmodel.RaceReport
+		if err := json.Unmarshal([]byte(encoded), &subReports); err != nil {
+			return fmt.Errorf("failed to decode race reports of subtask %s: %w", sub.SubTaskID, err)
+		}
+		reports = append(reports, subReports...)
+	}
+
+	if !found {
+		return nil
+	}
+
+	now := time.Now()
+	occurrences := make(map[string]int)
+
+	s.mu.Lock()
+	for _, report := range reports {
+		signature := race.Signature(report)
+		occurrences[signature]++
+
+		record, ok := s.records[signature]
+		if !ok {
+			record = &libmodel.RaceRecord{
+				Signature:     signature,
+				Report:        report,
+				FirstSeenTask: taskID,
+				FirstSeenAt:   now,
+			}
+			s.records[signature] = record
+		}
+
+		record.Count++
+		record.LastSeenTask = taskID
+		record.LastSeenAt = now
+		if report.Test != "" && !containsString(record.Tests, report.Test) {
+			record.Tests = append(record.Tests, report.Test)
+			sort.Strings(record.Tests)
+		}
+	}
+	s.taskRaces[taskID] = occurrences
+	s.mu.Unlock()
+
+	signatures := make([]string, 0, len(occurrences))
+	for signature := range occurrences {
+		signatures = append(signatures, signature)
+	}
+	sort.Strings(signatures)
+
+	delete(result, RaceReportsKey)
+	result[RaceCountKey] = strconv.Itoa(len(signatures))
+	result[RaceSignaturesKey] = strings.Join(signatures, ",")
+
+	return nil
+}
+
+// GetTaskRaces retrieves the distinct races found by a task, most frequent first
+func (s *RaceService) GetTaskRaces(ctx context.Context, taskID string) ([]*model.TaskRace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	occurrences, ok := s.taskRaces[taskID]
+	if !ok {
+		return nil, ErrRacesNotFound
+	}
+
+	races := make([]*model.TaskRace, 0, len(occurrences))
+	for signature, count := range occurrences {
+		races = append(races, &model.TaskRace{
+			Record:      snapshotRace(s.records[signature]),
+			Occurrences: count,
+		})
+	}
+
+	sort.Slice(races, func(i, j int) bool {
+		if races[i].Occurrences != races[j].Occurrences {
+			return races[i].Occurrences > races[j].Occurrences
+		}
+		return races[i].Record.Signature < races[j].Record.Signature
+	})
+
+	return races, nil
+}
+
+// ListRaces retrieves every distinct race seen so far, most frequent first
+func (s *RaceService) ListRaces(ctx context.Context) ([]*libmodel.RaceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]*libmodel.RaceRecord, 0, len(s.records))
+	for _, record := range s.records {
+		copied := snapshotRace(record)
+		records = append(records, &copied)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Count != records[j].Count {
+			return records[i].Count > records[j].Count
+		}
+		return records[i].Signature < records[j].Signature
+	})
+
+	return records, nil
+}
+
+// snapshotRace copies a race record so it can be used outside the lock
+func snapshotRace(record *libmodel.RaceRecord) libmodel.RaceRecord {
+	copied := *record
+	copied.Tests = append([]string(nil), record.Tests...)
+	return copied
+}
@@ -2,13 +2,24 @@ package service
 
 import (
 	"context"
-	"github.com/distributedmarketplace/internal/result/model"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/model"
 )
 
 // ResultAggregatorService defines the interface for result aggregation operations
 type ResultAggregatorService interface {
-	// SavePartialResult saves a partial result for a task
-	SavePartialResult(ctx context.Context, taskID string, subtaskID string, result map[string]string) error
+	// RegisterSubTasks records the subtasks a task was divided into
+	RegisterSubTasks(ctx context.Context, taskID string, subtaskIDs []string) error
+
+	// SetTaskOwner records the user and tenant a task belongs to
+	SetTaskOwner(ctx context.Context, taskID, ownerID, tenantID string) error
+
+	// GetTaskOwner retrieves the user and tenant a task belongs to.
+	// It returns ErrResultNotFound if the owner of the task is unknown.
+	GetTaskOwner(ctx context.Context, taskID string) (ownerID, tenantID string, err error)
+
+	// SavePartialResult saves a partial result for a task with the artifacts the subtask produced and the resources it used
+	SavePartialResult(ctx context.Context, taskID string, subtaskID string, result map[string]string, artifacts []libmodel.Artifact, usage libmodel.ResourceUsage) error
 
 	// FinalizeResult finalizes the result when all subtasks are completed
 	FinalizeResult(ctx context.Context, taskID string) error
@@ -21,4 +32,13 @@ type ResultAggregatorService interface {
 
 	// GetSubTaskResults retrieves all subtask results for a task
 	GetSubTaskResults(ctx context.Context, taskID string) ([]*model.SubTaskResult, error)
+
+	// ListArtifacts lists the artifacts the subtasks of a task produced, ordered by subtask
+	ListArtifacts(ctx context.Context, taskID string) ([]libmodel.Artifact, error)
+}
+
+// ResultProcessor enriches the merged result of a task when it is finalized
+type ResultProcessor interface {
+	// Process inspects the subtask results and may add keys to the merged result
+	Process(ctx context.Context, taskID string, subResults []*model.SubTaskResult, result map[string]string) error
 }
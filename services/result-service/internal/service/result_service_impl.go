@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/services/result-service/internal/model"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrResultNotFound is returned when no result exists for the requested task
+	ErrResultNotFound = errors.New("result not found")
+
+	// ErrResultNotReady is returned when a task still has pending subtasks
+	ErrResultNotReady = errors.New("result not ready")
+
+	// ErrResultAlreadyFinalized is returned when a task result has already been finalized
+	ErrResultAlreadyFinalized = errors.New("result already finalized")
+)
+
+const (
+	resultStatusPartial    = "PARTIAL"
+	resultStatusFinalizing = "FINALIZING"
+	resultStatusCompleted  = "COMPLETED"
+)
+
+// Result keys a worker writes to report how a subtask ended
+const (
+	SubTaskStatusKey = "status"
+	SubTaskErrorKey  = "error"
+)
+
+// ResultArtifactSuffix is appended by workers to a result key to name the
+// artifact holding its value, when the value was too large to be sent inline
+const ResultArtifactSuffix = ".artifact"
+
+// subTaskStatusFailed is the status of a subtask that failed on its worker
+const subTaskStatusFailed = "failed"
+
+// taskOwner is the user and tenant a task belongs to
+type taskOwner struct {
+	ownerID  string
+	tenantID string
+}
+
+// ResultAggregatorServiceImpl implements the ResultAggregatorService interface
+type ResultAggregatorServiceImpl struct {
+	// results holds the task-level results, keyed by task ID
+	results map[string]*model.TaskResult
+
+	// subResults holds the subtask results, keyed by task ID and then subtask ID
+	subResults map[string]map[string]*model.SubTaskResult
+
+	// expected holds the subtask IDs each task was divided into
+	expected map[string][]string
+
+	// owners holds the owner of each task, keyed by task ID
+	owners map[string]taskOwner
+
+	// processors enrich the merged result on finalization
+	processors []ResultProcessor
+
+	mu sync.RWMutex
+}
+
+// NewResultAggregatorServiceImpl creates a new instance of ResultAggregatorServiceImpl
+func NewResultAggregatorServiceImpl(processors ...ResultProcessor) *ResultAggregatorServiceImpl {
+	return &ResultAggregatorServiceImpl{
+		results:    make(map[string]*model.TaskResult),
+		subResults: make(map[string]map[string]*model.SubTaskResult),
+		expected:   make(map[string][]string),
+		owners:     make(map[string]taskOwner),
+		processors: processors,
+	}
+}
+
+// RegisterSubTasks records the subtasks a task was divided into
+func (s *ResultAggregatorServiceImpl) RegisterSubTasks(ctx context.Context, taskID string, subtaskIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expected[taskID] = append([]string(nil), subtaskIDs...)
+	return nil
+}
+
+// SetTaskOwner records the user and tenant a task belongs to
+func (s *ResultAggregatorServiceImpl) SetTaskOwner(ctx context.Context, taskID, ownerID, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.owners[taskID] = taskOwner{ownerID: ownerID, tenantID: tenantID}
+	if taskResult, ok := s.results[taskID]; ok {
+		taskResult.OwnerID, taskResult.TenantID = ownerID, tenantID
+	}
+	return nil
+}
+
+// GetTaskOwner retrieves the user and tenant a task belongs to.
+// It returns ErrResultNotFound if the owner of the task is unknown.
+func (s *ResultAggregatorServiceImpl) GetTaskOwner(ctx context.Context, taskID string) (string, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	owner, ok := s.owners[taskID]
+	if !ok {
+		return "", "", ErrResultNotFound
+	}
+	return owner.ownerID, owner.tenantID, nil
+}
+
+// SavePartialResult saves a partial result for a task with the artifacts the subtask produced and the resources it used
+func (s *ResultAggregatorServiceImpl) SavePartialResult(ctx context.Context, taskID string, subtaskID string, result map[string]string, artifacts []libmodel.Artifact, usage libmodel.ResourceUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Subtask results are fixed once finalization began, as processors read them
+	if existing, ok := s.results[taskID]; ok && existing.Status != resultStatusPartial {
+		return ErrResultAlreadyFinalized
+	}
+
+	now := time.Now()
+
+	subs, ok := s.subResults[taskID]
+	if !ok {
+		subs = make(map[string]*model.SubTaskResult)
+		s.subResults[taskID] = subs
+	}
+
+	sub, ok := subs[subtaskID]
+	if !ok {
+		sub = &model.SubTaskResult{
+			SubTaskID: subtaskID,
+			TaskID:    taskID,
+			CreatedAt: now,
+		}
+		subs[subtaskID] = sub
+	}
+	sub.Status = resultStatusCompleted
+	sub.Result = result
+	sub.Artifacts = artifacts
+	sub.Usage = usage
+	sub.UpdatedAt = now
+	sub.FinishedAt = now
+
+	if _, ok := s.results[taskID]; !ok {
+		owner := s.owners[taskID]
+		s.results[taskID] = &model.TaskResult{
+			TaskID:    taskID,
+			Status:    resultStatusPartial,
+			OwnerID:   owner.ownerID,
+			TenantID:  owner.tenantID,
+			CreatedAt: now,
+		}
+	}
+	s.results[taskID].UpdatedAt = now
+
+	return nil
+}
+
+// FinalizeResult finalizes the result when all subtasks are completed.
+// It returns ErrResultNotReady while subtasks are still pending or before
+// RegisterSubTasks recorded them. The
+// processors run without holding the lock, since they may fetch artifacts or
+// call other services; meanwhile the result is finalizing and takes no more
+// subtask results. A failing processor is logged and does not keep the task
+// from completing.
+func (s *ResultAggregatorServiceImpl) FinalizeResult(ctx context.Context, taskID string) error {
+	taskResult, subResults, err := s.beginFinalize(taskID)
+	if err != nil {
+		return err
+	}
+
+	merged := mergeResults(subResults)
+	for _, processor := range s.processors {
+		if err := processor.Process(ctx, taskID, subResults, merged); err != nil {
+			// Processors only enrich the result, so the task still completes
+			log.Printf("Failed to process result of task %s: %v", taskID, err)
+		}
+	}
+
+	var usage libmodel.ResourceUsage
+	for _, sub := range subResults {
+		usage.Add(sub.Usage)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	taskResult.Result = merged
+	taskResult.Usage = usage
+	taskResult.Status = resultStatusCompleted
+	taskResult.UpdatedAt = now
+	taskResult.FinishedAt = now
+
+	return nil
+}
+
+// beginFinalize marks a result whose registered subtasks all completed as
+// finalizing and returns it with the subtask results
+func (s *ResultAggregatorServiceImpl) beginFinalize(taskID string) (*model.TaskResult, []*model.SubTaskResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taskResult, ok := s.results[taskID]
+	if !ok {
+		return nil, nil, ErrResultNotFound
+	}
+	if taskResult.Status != resultStatusPartial {
+		return nil, nil, ErrResultAlreadyFinalized
+	}
+
+	// Without the subtasks of the task, a result could look complete too early
+	expected, registered := s.expected[taskID]
+	if !registered {
+		return nil, nil, fmt.Errorf("%w: subtasks are not registered yet", ErrResultNotReady)
+	}
+	for _, subtaskID := range expected {
+		if _, done := s.subResults[taskID][subtaskID]; !done {
+			return nil, nil, fmt.Errorf("%w: subtask %s is still pending", ErrResultNotReady, subtaskID)
+		}
+	}
+
+	taskResult.Status = resultStatusFinalizing
+	return taskResult, s.sortedSubResults(taskID), nil
+}
+
+// GetResult retrieves the result of a completed task
+func (s *ResultAggregatorServiceImpl) GetResult(ctx context.Context, taskID string) (map[string]string, error) {
+	taskResult, err := s.GetTaskResult(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if taskResult.Status != resultStatusCompleted {
+		return nil, ErrResultNotReady
+	}
+
+	return taskResult.Result, nil
+}
+
+// GetTaskResult retrieves the full task result object
+func (s *ResultAggregatorServiceImpl) GetTaskResult(ctx context.Context, taskID string) (*model.TaskResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	taskResult, ok := s.results[taskID]
+	if !ok {
+		return nil, ErrResultNotFound
+	}
+
+	return taskResult, nil
+}
+
+// GetSubTaskResults retrieves all subtask results for a task
+func (s *ResultAggregatorServiceImpl) GetSubTaskResults(ctx context.Context, taskID string) ([]*model.SubTaskResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.subResults[taskID]; !ok {
+		return nil, ErrResultNotFound
+	}
+
+	return s.sortedSubResults(taskID), nil
+}
+
+// ListArtifacts lists the artifacts the subtasks of a task produced, ordered by subtask
+func (s *ResultAggregatorServiceImpl) ListArtifacts(ctx context.Context, taskID string) ([]libmodel.Artifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.subResults[taskID]; !ok {
+		return nil, ErrResultNotFound
+	}
+
+	artifacts := make([]libmodel.Artifact, 0)
+	for _, sub := range s.sortedSubResults(taskID) {
+		artifacts = append(artifacts, sub.Artifacts...)
+	}
+	return artifacts, nil
+}
+
+// sortedSubResults returns the subtask results of a task ordered by subtask ID.
+// The caller must hold the lock.
+func (s *ResultAggregatorServiceImpl) sortedSubResults(taskID string) []*model.SubTaskResult {
+	subs := s.subResults[taskID]
+	results := make([]*model.SubTaskResult, 0, len(subs))
+	for _, sub := range subs {
+		results = append(results, sub)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].SubTaskID < results[j].SubTaskID
+	})
+
+	return results
+}
+
+// FailureReason reports whether a merged result contains a failed subtask,
+// and the errors the failed subtasks reported
+func FailureReason(result map[string]string) (string, bool) {
+	for _, status := range strings.Split(result[SubTaskStatusKey], "\n") {
+		if status == subTaskStatusFailed {
+			return strings.ReplaceAll(result[SubTaskErrorKey], "\n", "; "), true
+		}
+	}
+	return "", false
+}
+
+// mergeResults combines subtask results into a single map.
+// Values that differ between subtasks are joined with newlines so that
+// textual outputs from several shards are preserved.
+func mergeResults(subResults []*model.SubTaskResult) map[string]string {
+	values := make(map[string][]string)
+	for _, sub := range subResults {
+		for key, value := range sub.Result {
+			if !containsString(values[key], value) {
+				values[key] = append(values[key], value)
+			}
+		}
+	}
+
+	merged := make(map[string]string, len(values))
+	for key, vals := range values {
+		merged[key] = strings.Join(vals, "\n")
+	}
+
+	return merged
+}
+
+// resultValue returns the value of a result key of a subtask. Workers send the
+// name of the artifact holding the value instead when it is too large, in
+// which case the artifact is fetched from the store.
+func resultValue(ctx context.Context, store ObjectStore, sub *model.SubTaskResult, key string) (string, bool, error) {
+	if value, ok := sub.Result[key]; ok {
+		return value, true, nil
+	}
+
+	name, ok := sub.Result[key+ResultArtifactSuffix]
+	if !ok {
+		return "", false, nil
+	}
+	for _, artifact := range sub.Artifacts {
+		if artifact.Name == name {
+			content, err := store.GetObject(ctx, artifact.StorageKey)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to fetch artifact %s: %w", name, err)
+			}
+			return string(content), true, nil
+		}
+	}
+	return "", false, fmt.Errorf("artifact %s was not uploaded", name)
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -22,7 +22,7 @@ func StartApplication(cfg *config.Config) {
 
 	// Initialize components
 	producer := kafka.NewProducer(cfg.Kafka.Brokers)
-	schedulerService, err := service.NewSchedulerServiceImpl(cfg.GrpcPort, producer)
+	schedulerService, err := service.NewSchedulerServiceImpl(cfg.Services.WorkerManager.GRPCAddr, cfg.Services.Task.GRPCAddr, cfg.Services.Result.GRPCAddr, producer)
 	if err != nil {
 		log.Fatalf("Failed to create scheduler service: %v", err)
 	}
@@ -40,7 +40,7 @@ func StartApplication(cfg *config.Config) {
 
 func initKafka(cfg *config.Config, schedulerService service.SchedulerService, producer *kafka.Producer) (*app.ConsumerComponent, *app.ProducerComponent) {
 	taskHandler := handler.NewSchedulerHandler(schedulerService)
-	topics := []string{"task-created"}
+	topics := []string{"task-created", "affected-packages-computed"}
 	consumer := kafka.NewConsumer(topics, cfg.Kafka.Brokers, cfg.Kafka.GroupID, taskHandler)
 	return app.NewKafkaComponent(consumer), app.NewKafkaProducerComponent(producer)
 }
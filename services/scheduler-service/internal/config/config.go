@@ -9,6 +9,7 @@ type Config struct {
 
 	Kafka           KafkaConfig            `yaml:"kafka"`
 	Scheduling      SchedulingConfig       `yaml:"scheduling"`
+	Services        ServicesConfig         `yaml:"services"`
 	Log             configloader.LogConfig `yaml:"log"`
 	ShutdownTimeout string                 `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
 }
@@ -30,3 +31,10 @@ type SchedulingConfig struct {
 	RetryDelay     string `yaml:"retry_delay"      env:"SCHEDULING_RETRY_DELAY"      env-default:"5s"`
 	DefaultTimeout string `yaml:"default_timeout"  env:"SCHEDULING_DEFAULT_TIMEOUT"  env-default:"300s"`
 }
+
+// ServicesConfig holds the connections to the services the scheduler depends on
+type ServicesConfig struct {
+	WorkerManager configloader.ServiceConnectionConfig `yaml:"worker_manager"`
+	Task          configloader.ServiceConnectionConfig `yaml:"task"`
+	Result        configloader.ServiceConnectionConfig `yaml:"result"`
+}
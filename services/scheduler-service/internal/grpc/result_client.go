@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/network/client"
+	pbR "distributed-analyzer/libs/proto/result"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ResultServiceClient is a gRPC client for the result aggregator service
+type ResultServiceClient struct {
+	client pbR.ResultAggregatorServiceClient
+	conn   *grpc.ClientConn
+}
+
+// NewResultServiceClient creates a new ResultServiceClient
+func NewResultServiceClient(address string) (*ResultServiceClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Result service: %w", err)
+	}
+
+	return &ResultServiceClient{
+		client: pbR.NewResultAggregatorServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (c *ResultServiceClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetPackageDurations retrieves the historical run time in seconds of every package of a project of a tenant.
+// It returns an empty map if the project has no recorded durations yet.
+func (c *ResultServiceClient) GetPackageDurations(ctx context.Context, tenantID, project string) (map[string]float64, error) {
+	resp, err := c.client.GetPackageDurations(ctx, &pbR.GetPackageDurationsRequest{TenantId: tenantID, Project: project})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return map[string]float64{}, nil
+		}
+		return nil, err
+	}
+
+	return resp.Seconds, nil
+}
@@ -90,6 +90,8 @@ func convertModelTaskToPbTask(task *model.Task) *pb.Task {
 		Status:      convertModelStatusToPbStatus(task.Status),
 		Input:       task.Input,
 		Output:      task.Output,
+		OwnerId:     task.OwnerID,
+		TenantId:    task.TenantID,
 		CreatedAt:   timestamppb.New(task.CreatedAt),
 		UpdatedAt:   timestamppb.New(task.UpdatedAt),
 	}
@@ -120,6 +122,8 @@ func convertPbTaskToModelTask(pbTask *pb.Task) *model.Task {
 		Status:      convertPbStatusToModelStatus(pbTask.Status),
 		Input:       pbTask.Input,
 		Output:      pbTask.Output,
+		OwnerID:     pbTask.OwnerId,
+		TenantID:    pbTask.TenantId,
 	}
 
 	if pbTask.CreatedAt != nil {
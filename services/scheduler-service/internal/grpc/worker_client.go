@@ -87,11 +87,13 @@ func (c *WorkerManagerClient) FindAvailableWorkers(ctx context.Context, capabili
 		}
 
 		workers[i] = &model.Worker{
-			ID:           pbWorker.Id,
-			Name:         pbWorker.Name,
-			Status:       pbWorker.Status,
-			Capabilities: capabilities,
-			Resources:    resources,
+			ID:               pbWorker.Id,
+			Name:             pbWorker.Name,
+			Status:           pbWorker.Status,
+			Capabilities:     capabilities,
+			Resources:        resources,
+			QueuedTasks:      int(pbWorker.QueuedTasks),
+			WarmRepositories: pbWorker.WarmRepositories,
 		}
 	}
 
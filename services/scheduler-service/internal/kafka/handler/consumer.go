@@ -27,6 +27,8 @@ func (c *SchedulerMessageHandler) HandleMessage(ctx context.Context, topic strin
 	switch topic {
 	case "task-created":
 		return c.handleTaskCreated(ctx, message)
+	case "affected-packages-computed":
+		return c.handleAffectedPackagesComputed(ctx, message)
 	default:
 		return fmt.Errorf("unknown topic: %s", topic)
 	}
@@ -47,3 +49,18 @@ func (c *SchedulerMessageHandler) handleTaskCreated(ctx context.Context, message
 	log.Printf("Task %s scheduled successfully", event.TaskId)
 	return nil
 }
+
+// handleAffectedPackagesComputed handles an AffectedPackagesComputedEvent
+func (c *SchedulerMessageHandler) handleAffectedPackagesComputed(ctx context.Context, message kafka.Message) error {
+	var event pb.AffectedPackagesComputedEvent
+	if err := json.Unmarshal(message.Value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal AffectedPackagesComputedEvent: %w", err)
+	}
+
+	if err := c.schedulerService.ScheduleAffected(ctx, event.TaskId, event.Packages, event.Error); err != nil {
+		return fmt.Errorf("failed to schedule affected packages: %w", err)
+	}
+
+	log.Printf("Affected packages of task %s scheduled successfully", event.TaskId)
+	return nil
+}
@@ -3,11 +3,16 @@ package producer
 import (
 	"context"
 	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/model"
 	pb "distributed-analyzer/libs/proto/kafka"
+	taskpb "distributed-analyzer/libs/proto/task"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"time"
 )
 
+// toolchainInputKey is the subtask input key naming the Go toolchain version a subtask needs
+const toolchainInputKey = "go_version"
+
 // SchedulerProducer is a Kafka producer for scheduler events
 type SchedulerProducer struct {
 	*kafka.Producer
@@ -19,24 +24,60 @@ func NewSchedulerProducer(pr *kafka.Producer) *SchedulerProducer {
 	}
 }
 
-// PublishTaskAssigned publishes a TaskAssignedEvent to Kafka
-func (p *SchedulerProducer) PublishTaskAssigned(ctx context.Context, taskID string, workerID string) error {
-	event := &pb.TaskAssignedEvent{
-		TaskId:     taskID,
+// PublishTaskAssigned publishes a TaskAssignedEvent to Kafka on the topic of the
+// toolchain the subtask needs, where any worker with it may pick the subtask up
+func (p *SchedulerProducer) PublishTaskAssigned(ctx context.Context, subTask *model.SubTask, workerID string) error {
+	return p.Producer.PublishEvent(ctx, kafka.TaskAssignedTopic(subTask.Input[toolchainInputKey]), subTask.ParentID, taskAssignedEvent(subTask, workerID))
+}
+
+// PublishTaskAssignedToWorker publishes a TaskAssignedEvent to Kafka on the
+// topic of the worker, so that no other worker picks the subtask up
+func (p *SchedulerProducer) PublishTaskAssignedToWorker(ctx context.Context, subTask *model.SubTask, workerID string) error {
+	return p.Producer.PublishEvent(ctx, kafka.WorkerTaskAssignedTopic(workerID), subTask.ParentID, taskAssignedEvent(subTask, workerID))
+}
+
+// taskAssignedEvent creates the TaskAssignedEvent of a subtask
+func taskAssignedEvent(subTask *model.SubTask, workerID string) *pb.TaskAssignedEvent {
+	return &pb.TaskAssignedEvent{
+		TaskId:     subTask.ParentID,
 		WorkerId:   workerID,
 		AssignedAt: timestamppb.New(time.Now()),
+		Subtask: &taskpb.SubTask{
+			Id:        subTask.ID,
+			ParentId:  subTask.ParentID,
+			Name:      subTask.Name,
+			Status:    taskpb.Status_STATUS_SCHEDULED,
+			Input:     subTask.Input,
+			WorkerId:  workerID,
+			OwnerId:   subTask.OwnerID,
+			TenantId:  subTask.TenantID,
+			CreatedAt: timestamppb.New(subTask.CreatedAt),
+			UpdatedAt: timestamppb.New(subTask.UpdatedAt),
+		},
 	}
-
-	return p.Producer.PublishEvent(ctx, "task-assigned", taskID, event)
 }
 
 // PublishTaskScheduled publishes a TaskScheduledEvent to Kafka
-func (p *SchedulerProducer) PublishTaskScheduled(ctx context.Context, taskID string, workerIDs []string) error {
+func (p *SchedulerProducer) PublishTaskScheduled(ctx context.Context, task *model.Task, workerIDs []string, subtaskIDs []string) error {
 	event := &pb.TaskScheduledEvent{
-		TaskId:      taskID,
+		TaskId:      task.ID,
 		WorkerIds:   workerIDs,
 		ScheduledAt: timestamppb.New(time.Now()),
+		SubtaskIds:  subtaskIDs,
+		OwnerId:     task.OwnerID,
+		TenantId:    task.TenantID,
+	}
+
+	return p.Producer.PublishEvent(ctx, "task-scheduled", task.ID, event)
+}
+
+// PublishAffectedPackagesRequested publishes an AffectedPackagesRequestedEvent to Kafka
+func (p *SchedulerProducer) PublishAffectedPackagesRequested(ctx context.Context, taskID string, input map[string]string) error {
+	event := &pb.AffectedPackagesRequestedEvent{
+		TaskId:      taskID,
+		Input:       input,
+		RequestedAt: timestamppb.New(time.Now()),
 	}
 
-	return p.Producer.PublishEvent(ctx, "task-scheduled", taskID, event)
+	return p.Producer.PublishEvent(ctx, "affected-packages-requested", taskID, event)
 }
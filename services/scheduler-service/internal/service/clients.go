@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+)
+
+// TaskClient reads and updates the tasks of the task service
+type TaskClient interface {
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+	UpdateTask(ctx context.Context, task *model.Task) (*model.Task, error)
+	Close() error
+}
+
+// WorkerFinder finds the workers of the worker manager that can run a subtask
+type WorkerFinder interface {
+	FindAvailableWorkers(ctx context.Context, capabilities []model.Capability, resources []model.Resource) ([]*model.Worker, error)
+	Close() error
+}
+
+// DurationSource provides the historical run times of the packages of a project of a tenant
+type DurationSource interface {
+	GetPackageDurations(ctx context.Context, tenantID, project string) (map[string]float64, error)
+	Close() error
+}
+
+// EventPublisher publishes the events of the scheduler
+type EventPublisher interface {
+	PublishTaskAssigned(ctx context.Context, subTask *model.SubTask, workerID string) error
+	PublishTaskAssignedToWorker(ctx context.Context, subTask *model.SubTask, workerID string) error
+	PublishTaskScheduled(ctx context.Context, task *model.Task, workerIDs []string, subtaskIDs []string) error
+	PublishAffectedPackagesRequested(ctx context.Context, taskID string, input map[string]string) error
+	Close() error
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Task input keys that control how a task is divided
+const (
+	inputModeKey        = "mode"
+	inputRepeatKey      = "repeat"
+	inputCountKey       = "count"
+	inputPackagesKey    = "packages"
+	inputFuzzTargetsKey = "fuzz_targets"
+	inputFuzzKey        = "fuzz"
+	inputFuzzTimeKey    = "fuzztime"
+	inputShardsKey      = "shards"
+	inputShardIndexKey  = "shard_index"
+	inputShardCountKey  = "shard_count"
+	inputShardPlanKey   = "shard_plan"
+	inputProjectKey     = "project"
+	inputRepositoryKey  = "repository"
+
+	inputMatrixGoKey        = "matrix_go"
+	inputMatrixPlatformsKey = "matrix_platforms"
+	inputMatrixTagsKey      = "matrix_tags"
+	inputGoVersionKey       = "go_version"
+	inputGOOSKey            = "goos"
+	inputGOARCHKey          = "goarch"
+	inputTagsKey            = "tags"
+	inputMatrixCellKey      = "matrix_cell"
+
+	inputBaseKey             = "base"
+	inputDiffKey             = "diff"
+	inputAffectedPackagesKey = "affected_packages"
+)
+
+// Task output keys the scheduler writes when it finishes a task itself
+const (
+	outputErrorKey   = "error"
+	outputMessageKey = "message"
+)
+
+// Task modes the scheduler divides specially
+const (
+	modeTest  = "go_test"
+	modeCover = "go_cover"
+	modeRace  = "go_race"
+	modeFlaky = "go_flaky"
+	modeFuzz  = "go_fuzz"
+	modeBuild = "go_build"
+)
+
+// incrementalModes are the modes an incremental task is limited to the affected packages for
+var incrementalModes = map[string]bool{
+	modeBuild: true,
+	modeTest:  true,
+	modeCover: true,
+	modeRace:  true,
+	modeFlaky: true,
+}
+
+// matrixModes are the modes a task can expand into a build matrix for
+var matrixModes = map[string]bool{
+	modeBuild: true,
+	modeTest:  true,
+}
+
+// goVersionPattern matches a Go toolchain version as workers advertise it, e.g. 1.22
+var goVersionPattern = regexp.MustCompile(`^[1-9][0-9]*\.[0-9]+$`)
+
+// maxMatrixCells limits the number of subtasks a build matrix can expand into
+const maxMatrixCells = 256
+
+// maxShards limits the number of shards a test task can be split into
+const maxShards = 64
+
+// Limits of the number of repeated runs of a flakiness task
+const (
+	defaultFlakyRepeat = 10
+	maxFlakyRepeat     = 100
+)
+
+// divideTask splits a task into subtasks.
+// A task that is not divided runs as a single subtask with the task's input.
+func (s *SchedulerServiceImpl) divideTask(ctx context.Context, task *model.Task) ([]*model.SubTask, error) {
+	log.Printf("Dividing task %s", task.ID)
+
+	if affectedResolved(task) {
+		task = limitToAffected(task)
+	}
+
+	if matrixModes[task.Input[inputModeKey]] && isMatrix(task) {
+		return divideMatrixTask(task)
+	}
+
+	switch task.Input[inputModeKey] {
+	case modeTest, modeCover, modeRace:
+		if _, ok := task.Input[inputShardsKey]; ok {
+			return s.divideShardedTask(ctx, task)
+		}
+		return []*model.SubTask{newSubTask(task, 0, task.Input)}, nil
+	case modeFlaky:
+		return divideFlakyTask(task)
+	case modeFuzz:
+		return divideFuzzTask(task)
+	default:
+		return []*model.SubTask{newSubTask(task, 0, task.Input)}, nil
+	}
+}
+
+// isIncremental reports whether a task only analyzes the packages affected by
+// a change, given as a base revision or a unified diff
+func isIncremental(task *model.Task) bool {
+	if !incrementalModes[task.Input[inputModeKey]] {
+		return false
+	}
+	return task.Input[inputBaseKey] != "" || task.Input[inputDiffKey] != ""
+}
+
+// affectedResolved reports whether the affected packages of an incremental task are known
+func affectedResolved(task *model.Task) bool {
+	_, ok := task.Input[inputAffectedPackagesKey]
+	return ok
+}
+
+// limitToAffected returns a copy of an incremental task that analyzes only its affected packages
+func limitToAffected(task *model.Task) *model.Task {
+	limited := *task
+	limited.Input = copyInput(task.Input)
+	limited.Input[inputPackagesKey] = task.Input[inputAffectedPackagesKey]
+	delete(limited.Input, inputAffectedPackagesKey)
+	return &limited
+}
+
+// isMatrix reports whether a task asks for a build matrix
+func isMatrix(task *model.Task) bool {
+	return task.Input[inputMatrixGoKey] != "" || task.Input[inputMatrixPlatformsKey] != "" || task.Input[inputMatrixTagsKey] != ""
+}
+
+// divideMatrixTask expands a task into one subtask per combination of Go
+// toolchain version, GOOS/GOARCH platform and build tag set. Versions and
+// platforms are space separated; tag sets are separated by semicolons, and an
+// empty tag set builds without tags. A missing dimension keeps the default of
+// the worker, so the subtask is not pinned to a toolchain or platform.
+func divideMatrixTask(task *model.Task) ([]*model.SubTask, error) {
+	versions := []string{""}
+	if value := task.Input[inputMatrixGoKey]; value != "" {
+		versions = strings.Fields(value)
+		for i, version := range versions {
+			versions[i] = strings.TrimPrefix(version, "go")
+			if !goVersionPattern.MatchString(versions[i]) {
+				return nil, fmt.Errorf("invalid Go version %q: expected a release such as 1.22", version)
+			}
+		}
+	}
+
+	platforms := [][2]string{{"", ""}}
+	if value := task.Input[inputMatrixPlatformsKey]; value != "" {
+		platforms = nil
+		for _, platform := range strings.Fields(value) {
+			goos, goarch, ok := strings.Cut(platform, "/")
+			if !ok || goos == "" || goarch == "" {
+				return nil, fmt.Errorf("invalid platform %q: expected GOOS/GOARCH", platform)
+			}
+			platforms = append(platforms, [2]string{goos, goarch})
+		}
+	}
+
+	tagSets := []string{task.Input[inputTagsKey]}
+	if value, ok := task.Input[inputMatrixTagsKey]; ok && value != "" {
+		tagSets = strings.Split(value, ";")
+		for i, tags := range tagSets {
+			tagSets[i] = strings.TrimSpace(tags)
+		}
+	}
+
+	if cells := len(versions) * len(platforms) * len(tagSets); cells > maxMatrixCells {
+		return nil, fmt.Errorf("build matrix of %d cells exceeds the limit of %d", cells, maxMatrixCells)
+	}
+
+	subtasks := make([]*model.SubTask, 0, len(versions)*len(platforms)*len(tagSets))
+	for _, version := range versions {
+		for _, platform := range platforms {
+			for _, tags := range tagSets {
+				cell := model.MatrixCell{GoVersion: version, GOOS: platform[0], GOARCH: platform[1], Tags: tags}
+				encoded, err := json.Marshal(cell)
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode matrix cell: %w", err)
+				}
+
+				input := copyInput(task.Input)
+				delete(input, inputMatrixGoKey)
+				delete(input, inputMatrixPlatformsKey)
+				delete(input, inputMatrixTagsKey)
+				setOrDelete(input, inputGoVersionKey, cell.GoVersion)
+				setOrDelete(input, inputGOOSKey, cell.GOOS)
+				setOrDelete(input, inputGOARCHKey, cell.GOARCH)
+				setOrDelete(input, inputTagsKey, cell.Tags)
+				input[inputMatrixCellKey] = string(encoded)
+
+				subtask := newSubTask(task, len(subtasks), input)
+				subtask.Name = fmt.Sprintf("%s [%s]", subtask.Name, matrixCellLabel(cell))
+				subtasks = append(subtasks, subtask)
+			}
+		}
+	}
+
+	return subtasks, nil
+}
+
+// matrixCellLabel describes a matrix cell, e.g. go1.22 linux/amd64 tags=netgo
+func matrixCellLabel(cell model.MatrixCell) string {
+	parts := make([]string, 0, 3)
+	if cell.GoVersion != "" {
+		parts = append(parts, "go"+cell.GoVersion)
+	}
+	if platform := cell.Platform(); platform != "" {
+		parts = append(parts, platform)
+	}
+	if cell.Tags != "" {
+		parts = append(parts, "tags="+cell.Tags)
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, " ")
+}
+
+// setOrDelete sets an input key, or removes it when the value is empty
+func setOrDelete(input map[string]string, key, value string) {
+	if value == "" {
+		delete(input, key)
+		return
+	}
+	input[key] = value
+}
+
+// divideFlakyTask fans a flakiness task out into repeated go_test runs.
+// Test caching is disabled so that every run actually executes the tests.
+func divideFlakyTask(task *model.Task) ([]*model.SubTask, error) {
+	repeat := defaultFlakyRepeat
+	if value, ok := task.Input[inputRepeatKey]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxFlakyRepeat {
+			return nil, fmt.Errorf("invalid repeat %q: must be between 1 and %d", value, maxFlakyRepeat)
+		}
+		repeat = n
+	}
+
+	subtasks := make([]*model.SubTask, repeat)
+	for i := range subtasks {
+		input := copyInput(task.Input)
+		input[inputModeKey] = modeTest
+		input[inputCountKey] = "1"
+		delete(input, inputRepeatKey)
+
+		subtasks[i] = newSubTask(task, i, input)
+	}
+
+	return subtasks, nil
+}
+
+// divideFuzzTask splits a fuzzing campaign into one subtask per fuzz target.
+// Targets are listed as space separated package:FuzzName pairs in the
+// fuzz_targets input, and each target gets the fuzztime budget.
+func divideFuzzTask(task *model.Task) ([]*model.SubTask, error) {
+	targets := strings.Fields(task.Input[inputFuzzTargetsKey])
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%s input is required for %s tasks", inputFuzzTargetsKey, modeFuzz)
+	}
+
+	if fuzzTime, ok := task.Input[inputFuzzTimeKey]; ok {
+		if _, err := time.ParseDuration(fuzzTime); err != nil && !strings.HasSuffix(fuzzTime, "x") {
+			return nil, fmt.Errorf("invalid fuzztime %q: %w", fuzzTime, err)
+		}
+	}
+
+	subtasks := make([]*model.SubTask, len(targets))
+	for i, target := range targets {
+		pkg, name, ok := strings.Cut(target, ":")
+		if !ok || pkg == "" || !strings.HasPrefix(name, "Fuzz") {
+			return nil, fmt.Errorf("invalid fuzz target %q: expected package:FuzzName", target)
+		}
+
+		input := copyInput(task.Input)
+		input[inputPackagesKey] = pkg
+		input[inputFuzzKey] = name
+		delete(input, inputFuzzTargetsKey)
+
+		subtasks[i] = newSubTask(task, i, input)
+	}
+
+	return subtasks, nil
+}
+
+// divideShardedTask splits a test run into the requested number of shards.
+// The packages of the task's project with a historical run time are planned
+// over the shards by planShards, and every shard gets the package list of
+// every shard. The worker of each shard runs its own list and its share of
+// the packages without a run time, which it finds missing from all lists.
+func (s *SchedulerServiceImpl) divideShardedTask(ctx context.Context, task *model.Task) ([]*model.SubTask, error) {
+	value := task.Input[inputShardsKey]
+	shards, err := strconv.Atoi(value)
+	if err != nil || shards < 1 || shards > maxShards {
+		return nil, fmt.Errorf("invalid shards %q: must be between 1 and %d", value, maxShards)
+	}
+
+	durations := map[string]float64{}
+	if project := task.Input[inputProjectKey]; project != "" {
+		durations, err = s.resultClient.GetPackageDurations(ctx, task.TenantID, project)
+		if err != nil {
+			log.Printf("Failed to get package durations of project %s, sharding by package count: %v", project, err)
+			durations = map[string]float64{}
+		}
+	}
+
+	plan, err := json.Marshal(planShards(durations, shards))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode shard plan: %w", err)
+	}
+
+	subtasks := make([]*model.SubTask, shards)
+	for i := range subtasks {
+		input := copyInput(task.Input)
+		input[inputShardIndexKey] = strconv.Itoa(i)
+		input[inputShardCountKey] = strconv.Itoa(shards)
+		input[inputShardPlanKey] = string(plan)
+		delete(input, inputShardsKey)
+
+		subtasks[i] = newSubTask(task, i, input)
+	}
+
+	return subtasks, nil
+}
+
+// planShards assigns packages to shards with the longest processing time first
+// heuristic: the slowest remaining package goes to the least loaded shard.
+// It returns the packages of every shard in the order they were assigned.
+func planShards(durations map[string]float64, shards int) [][]string {
+	pkgs := make([]string, 0, len(durations))
+	for pkg := range durations {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		if durations[pkgs[i]] != durations[pkgs[j]] {
+			return durations[pkgs[i]] > durations[pkgs[j]]
+		}
+		return pkgs[i] < pkgs[j]
+	})
+
+	loads := make([]float64, shards)
+	plan := make([][]string, shards)
+	for i := range plan {
+		plan[i] = []string{}
+	}
+	for _, pkg := range pkgs {
+		shard := 0
+		for i, load := range loads {
+			if load < loads[shard] {
+				shard = i
+			}
+		}
+		plan[shard] = append(plan[shard], pkg)
+		loads[shard] += durations[pkg]
+	}
+
+	return plan
+}
+
+// newSubTask creates the index-th subtask of a task with the given input
+func newSubTask(task *model.Task, index int, input map[string]string) *model.SubTask {
+	now := time.Now()
+	return &model.SubTask{
+		ID:        fmt.Sprintf("%s-%d", task.ID, index),
+		ParentID:  task.ID,
+		Name:      fmt.Sprintf("%s #%d", task.Name, index),
+		Status:    model.StatusPending,
+		Input:     input,
+		OwnerID:   task.OwnerID,
+		TenantID:  task.TenantID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// copyInput returns a copy of a task input
+func copyInput(input map[string]string) map[string]string {
+	copied := make(map[string]string, len(input))
+	for key, value := range input {
+		copied[key] = value
+	}
+	return copied
+}
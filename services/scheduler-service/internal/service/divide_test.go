@@ -0,0 +1,120 @@
+package service
+
+import (
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDivideMatrixTaskExpandsEveryCell(t *testing.T) {
+	task := &model.Task{ID: "t1", Name: "matrix", Input: map[string]string{
+		inputModeKey:            modeTest,
+		inputTagsKey:            "integration",
+		inputMatrixGoKey:        "go1.21 1.22",
+		inputMatrixPlatformsKey: "linux/amd64 darwin/arm64",
+		inputMatrixTagsKey:      "; netgo",
+	}}
+
+	subtasks, err := divideMatrixTask(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subtasks) != 8 {
+		t.Fatalf("Expected 8 subtasks, got %d", len(subtasks))
+	}
+
+	first := subtasks[0]
+	if first.ID != "t1-0" || first.Name != "matrix #0 [go1.21 linux/amd64]" {
+		t.Errorf("Expected the first cell to be named after its Go version and platform, got %s %q", first.ID, first.Name)
+	}
+	if first.Input[inputGoVersionKey] != "1.21" || first.Input[inputGOOSKey] != "linux" || first.Input[inputGOARCHKey] != "amd64" {
+		t.Errorf("Expected the first cell to pin go1.21 on linux/amd64, got %v", first.Input)
+	}
+	if _, ok := first.Input[inputTagsKey]; ok {
+		t.Errorf("Expected the empty tag set to build without tags, got %q", first.Input[inputTagsKey])
+	}
+	for _, key := range []string{inputMatrixGoKey, inputMatrixPlatformsKey, inputMatrixTagsKey} {
+		if _, ok := first.Input[key]; ok {
+			t.Errorf("Expected the subtask input to drop %s", key)
+		}
+	}
+
+	var cell model.MatrixCell
+	if err := json.Unmarshal([]byte(subtasks[7].Input[inputMatrixCellKey]), &cell); err != nil {
+		t.Fatalf("Failed to decode matrix cell: %v", err)
+	}
+	expected := model.MatrixCell{GoVersion: "1.22", GOOS: "darwin", GOARCH: "arm64", Tags: "netgo"}
+	if cell != expected {
+		t.Errorf("Expected the last cell to be %+v, got %+v", expected, cell)
+	}
+	if subtasks[7].Input[inputTagsKey] != "netgo" {
+		t.Errorf("Expected the last cell to build with netgo, got %q", subtasks[7].Input[inputTagsKey])
+	}
+}
+
+func TestDivideMatrixTaskKeepsMissingDimensions(t *testing.T) {
+	task := &model.Task{ID: "t1", Name: "matrix", Input: map[string]string{
+		inputTagsKey:     "integration",
+		inputMatrixGoKey: "1.22",
+	}}
+
+	subtasks, err := divideMatrixTask(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subtasks) != 1 {
+		t.Fatalf("Expected 1 subtask, got %d", len(subtasks))
+	}
+
+	input := subtasks[0].Input
+	if _, ok := input[inputGOOSKey]; ok {
+		t.Errorf("Expected the subtask not to be pinned to a platform, got %v", input)
+	}
+	if input[inputTagsKey] != "integration" {
+		t.Errorf("Expected the subtask to keep the tags of the task, got %q", input[inputTagsKey])
+	}
+}
+
+func TestDivideMatrixTaskRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]string
+		err   string
+	}{
+		{"go version", map[string]string{inputMatrixGoKey: "1.22 latest"}, "invalid Go version"},
+		{"patch release", map[string]string{inputMatrixGoKey: "1.22.1"}, "invalid Go version"},
+		{"platform", map[string]string{inputMatrixPlatformsKey: "linux"}, "invalid platform"},
+		{"too many cells", map[string]string{
+			inputMatrixGoKey:        "1.18 1.19 1.20 1.21 1.22 1.23 1.24 1.25",
+			inputMatrixPlatformsKey: "linux/amd64 linux/arm64 darwin/amd64 darwin/arm64 windows/amd64 windows/arm64 freebsd/amd64 freebsd/arm64",
+			inputMatrixTagsKey:      "a;b;c;d;e",
+		}, "exceeds the limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := divideMatrixTask(&model.Task{ID: "t1", Input: tt.input})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPlanShardsBalancesDurations(t *testing.T) {
+	durations := map[string]float64{"a": 8, "b": 4, "c": 4, "d": 3, "e": 1}
+
+	plan := planShards(durations, 2)
+
+	expected := [][]string{{"a", "d"}, {"b", "c", "e"}}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Errorf("Expected %v, got %v", expected, plan)
+	}
+
+	plan = planShards(map[string]float64{"a": 1}, 3)
+	if len(plan) != 3 || len(plan[1]) != 0 || len(plan[2]) != 0 {
+		t.Errorf("Expected the shards without packages to stay empty, got %v", plan)
+	}
+}
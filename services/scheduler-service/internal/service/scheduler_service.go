@@ -10,9 +10,13 @@ type SchedulerService interface {
 	// ScheduleTask assigns a task to appropriate workers
 	ScheduleTask(ctx context.Context, taskID string) error
 
+	// ScheduleAffected schedules an incremental task once a worker has computed
+	// the packages affected by its change, or fails it if that was not possible
+	ScheduleAffected(ctx context.Context, taskID string, packages []string, errMsg string) error
+
 	// DivideTask splits a task into subtasks if needed
 	DivideTask(ctx context.Context, taskID string) ([]*model.SubTask, error)
 
-	// AssignTask assigns a task or subtask to a specific worker
-	AssignTask(ctx context.Context, taskID string, workerID string) error
+	// AssignTask assigns a subtask to a specific worker
+	AssignTask(ctx context.Context, subTask *model.SubTask, workerID string) error
 }
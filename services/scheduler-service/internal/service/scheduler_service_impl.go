@@ -7,35 +7,60 @@ import (
 	"distributed-analyzer/services/scheduler-service/internal/grpc"
 	"distributed-analyzer/services/scheduler-service/internal/kafka/producer"
 	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 )
 
+// toolchainCapability is the worker capability whose value is the Go toolchain version of the worker
+const toolchainCapability = "go"
+
+// warmBonus is how many queued tasks a worker whose cache is warm for the
+// repository of a subtask may have over a cold worker and still get the subtask
+const warmBonus = 2
+
 // ErrTaskNotFound is returned when a task with the specified ID doesn't exist
 var ErrTaskNotFound = errors.New("task not found")
 
 // SchedulerServiceImpl implements the SchedulerService interface
 type SchedulerServiceImpl struct {
-	workerClient  *grpc.WorkerManagerClient
-	taskClient    *grpc.TaskServiceGrpcClient
-	kafkaProducer *producer.SchedulerProducer
+	workerClient  WorkerFinder
+	taskClient    TaskClient
+	resultClient  DurationSource
+	kafkaProducer EventPublisher
 }
 
 // NewSchedulerServiceImpl creates a new instance of SchedulerServiceImpl
-func NewSchedulerServiceImpl(workerServiceAddr string, pr *kafka.Producer) (*SchedulerServiceImpl, error) {
+func NewSchedulerServiceImpl(workerServiceAddr, taskServiceAddr, resultServiceAddr string, pr *kafka.Producer) (*SchedulerServiceImpl, error) {
 	workerClient, err := grpc.NewWorkerManagerClient(workerServiceAddr)
-	taskServiceGrpcClient, err := grpc.NewTaskServiceGrpcClient(workerServiceAddr)
+	if err != nil {
+		return nil, err
+	}
 
+	taskServiceGrpcClient, err := grpc.NewTaskServiceGrpcClient(taskServiceAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	resultClient, err := grpc.NewResultServiceClient(resultServiceAddr)
 	if err != nil {
 		return nil, err
 	}
 
 	kafkaProducer := producer.NewSchedulerProducer(pr)
 
+	return newSchedulerService(workerClient, taskServiceGrpcClient, resultClient, kafkaProducer), nil
+}
+
+// newSchedulerService creates a SchedulerServiceImpl on the given clients
+func newSchedulerService(workerClient WorkerFinder, taskClient TaskClient, resultClient DurationSource, publisher EventPublisher) *SchedulerServiceImpl {
 	return &SchedulerServiceImpl{
 		workerClient:  workerClient,
-		taskClient:    taskServiceGrpcClient,
-		kafkaProducer: kafkaProducer,
-	}, nil
+		taskClient:    taskClient,
+		resultClient:  resultClient,
+		kafkaProducer: publisher,
+	}
 }
 
 // Close closes all connections
@@ -43,6 +68,12 @@ func (s *SchedulerServiceImpl) Close() error {
 	if err := s.workerClient.Close(); err != nil {
 		return err
 	}
+	if err := s.taskClient.Close(); err != nil {
+		return err
+	}
+	if err := s.resultClient.Close(); err != nil {
+		return err
+	}
 	if err := s.kafkaProducer.Close(); err != nil {
 		return err
 	}
@@ -60,86 +91,209 @@ func (s *SchedulerServiceImpl) ScheduleTask(ctx context.Context, taskID string)
 		return err
 	}
 
-	// 2. Find available workers using the worker manager
+	// An incremental task is only divided once a worker has computed the
+	// packages its change affects, see ScheduleAffected
+	if isIncremental(task) && !affectedResolved(task) {
+		log.Printf("Requesting affected packages of task %s", taskID)
+		return s.kafkaProducer.PublishAffectedPackagesRequested(ctx, taskID, task.Input)
+	}
+
+	return s.schedule(ctx, task)
+}
+
+// schedule divides a task and assigns its subtasks to workers
+func (s *SchedulerServiceImpl) schedule(ctx context.Context, task *model.Task) error {
+	// 2. Divide the task into subtasks
+	subtasks, err := s.divideTask(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	// 3. Pick a worker for every subtask among the workers with the toolchain
+	// it needs. Repeated runs of the same tests go round-robin, so they land on
+	// different workers; other subtasks go to the least loaded worker, favoring
+	// workers whose cache is warm for the repository.
+	candidates := make(map[string][]*model.Worker)
+	next := make(map[string]int)
+	assigned := make(map[string]int)
+	workerIDs := make([]string, 0)
+	subtaskIDs := make([]string, len(subtasks))
+	assignees := make([]string, len(subtasks))
+	warm := make([]bool, len(subtasks))
+	for i, subtask := range subtasks {
+		toolchain := subtask.Input[inputGoVersionKey]
+		workers, ok := candidates[toolchain]
+		if !ok {
+			if workers, err = s.findWorkers(ctx, toolchain); err != nil {
+				return err
+			}
+			candidates[toolchain] = workers
+		}
+
+		var worker *model.Worker
+		if subtask.Input[inputModeKey] == modeFlaky {
+			worker = workers[next[toolchain]%len(workers)]
+			next[toolchain]++
+		} else {
+			worker, warm[i] = pickWorker(workers, assigned, subtask.Input[inputRepositoryKey])
+		}
+
+		assigned[worker.ID]++
+		if !containsString(workerIDs, worker.ID) {
+			workerIDs = append(workerIDs, worker.ID)
+		}
+		assignees[i] = worker.ID
+		subtaskIDs[i] = subtask.ID
+	}
+
+	// 4. Update the task status to SCHEDULED
+	task.Status = model.StatusScheduled
+	if _, err := s.taskClient.UpdateTask(ctx, task); err != nil {
+		return err
+	}
+
+	// 5. Publish TaskScheduledEvent to Kafka before any subtask can complete,
+	// so the result service knows how many results to wait for
+	if err := s.kafkaProducer.PublishTaskScheduled(ctx, task, workerIDs, subtaskIDs); err != nil {
+		return err
+	}
+
+	// 6. Assign the subtasks to their workers
+	for i, subtask := range subtasks {
+		if err := s.assign(ctx, subtask, assignees[i], warm[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pickWorker picks the worker with the lowest score for a subtask of the given
+// repository, and reports whether its cache is warm for the repository. The
+// score of a worker is the number of tasks in its queue plus those assigned to
+// it while scheduling this task, less warmBonus if its cache is warm.
+func pickWorker(workers []*model.Worker, assigned map[string]int, repository string) (*model.Worker, bool) {
+	var best *model.Worker
+	var bestScore int
+	var bestWarm bool
+	for _, worker := range workers {
+		score := worker.QueuedTasks + assigned[worker.ID]
+		warm := repository != "" && containsString(worker.WarmRepositories, repository)
+		if warm {
+			score -= warmBonus
+		}
+		if best == nil || score < bestScore {
+			best, bestScore, bestWarm = worker, score, warm
+		}
+	}
+	return best, bestWarm
+}
+
+// findWorkers finds the available workers, limited to the workers that
+// advertise the given Go toolchain version as their go capability if set
+func (s *SchedulerServiceImpl) findWorkers(ctx context.Context, toolchain string) ([]*model.Worker, error) {
 	capabilities := []model.Capability{
 		{Name: "default", Value: "1.0"},
 	}
+	if toolchain != "" {
+		capabilities = append(capabilities, model.Capability{Name: toolchainCapability, Value: toolchain})
+	}
 	resources := []model.Resource{
 		{Type: "CPU", Value: 1},
 	}
 
 	workers, err := s.workerClient.FindAvailableWorkers(ctx, capabilities, resources)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(workers) == 0 {
-		return errors.New("no available workers found")
+		if toolchain != "" {
+			return nil, fmt.Errorf("no available workers found with Go %s", toolchain)
+		}
+		return nil, errors.New("no available workers found")
 	}
 
-	// 3. Divide the task into subtasks if needed
-	subtasks, err := s.DivideTask(ctx, taskID)
+	return workers, nil
+}
+
+// ScheduleAffected schedules an incremental task once a worker has computed
+// the packages affected by its change. A task whose change affects no package
+// is completed right away, and a task whose packages could not be computed fails.
+func (s *SchedulerServiceImpl) ScheduleAffected(ctx context.Context, taskID string, packages []string, errMsg string) error {
+	task, err := s.taskClient.GetTask(ctx, taskID)
 	if err != nil {
 		return err
 	}
 
-	// 4. Assign the task or subtasks to workers
-	workerIDs := make([]string, 0, len(workers))
-	for i, worker := range workers {
-		if i < len(subtasks) {
-			// Assign subtask to worker
-			if err := s.AssignTask(ctx, subtasks[i].ID, worker.ID); err != nil {
-				return err
-			}
+	if errMsg != "" || len(packages) == 0 {
+		if task.Output == nil {
+			task.Output = make(map[string]string)
+		}
+		if errMsg != "" {
+			task.Status = model.StatusFailed
+			task.Output[outputErrorKey] = "failed to compute affected packages: " + errMsg
 		} else {
-			// Assign the main task to worker if no subtasks
-			if len(subtasks) == 0 {
-				if err := s.AssignTask(ctx, taskID, worker.ID); err != nil {
-					return err
-				}
-			}
+			task.Status = model.StatusCompleted
+			task.Output[outputMessageKey] = "no packages are affected by the change"
+			task.CompletedAt = time.Now()
 		}
-		workerIDs = append(workerIDs, worker.ID)
-	}
 
-	// 5. Update the task status to SCHEDULED
-	task.Status = model.StatusScheduled
-	if _, err := s.taskClient.UpdateTask(ctx, task); err != nil {
+		_, err := s.taskClient.UpdateTask(ctx, task)
 		return err
 	}
 
-	// 6. Publish TaskScheduledEvent to Kafka
-	if err := s.kafkaProducer.PublishTaskScheduled(ctx, taskID, workerIDs); err != nil {
-		return err
-	}
+	// The packages go straight into the division: the task service keeps the
+	// input the task was created with, so reading the task back would request
+	// the affected packages again
+	resolved := *task
+	resolved.Input = copyInput(task.Input)
+	resolved.Input[inputAffectedPackagesKey] = strings.Join(packages, " ")
 
-	return nil
+	return s.schedule(ctx, &resolved)
 }
 
 // DivideTask splits a task into subtasks if needed
 func (s *SchedulerServiceImpl) DivideTask(ctx context.Context, taskID string) ([]*model.SubTask, error) {
-	log.Printf("Dividing task %s", taskID)
-	// In a real implementation, this would:
-	// 1. Get the task details from the task service
-	// 2. Analyze the task to determine how to divide it
-	// 3. Create subtasks
-	// 4. Return the subtasks
-	return nil, nil
+	task, err := s.taskClient.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.divideTask(ctx, task)
 }
 
-// AssignTask assigns a task or subtask to a specific worker
-func (s *SchedulerServiceImpl) AssignTask(ctx context.Context, taskID string, workerID string) error {
-	log.Printf("Assigning task %s to worker %s", taskID, workerID)
-	// In a real implementation, this would:
-	// 1. Get the task details from the task service
-	// 2. Verify the worker is available
-	// 3. Assign the task to the worker
-	// 4. Update the task status
+// AssignTask assigns a subtask to a specific worker
+func (s *SchedulerServiceImpl) AssignTask(ctx context.Context, subTask *model.SubTask, workerID string) error {
+	return s.assign(ctx, subTask, workerID, false)
+}
+
+// assign assigns a subtask to a worker. Workers share the toolchain topics, so
+// the worker is only a hint there; a subtask for a worker whose cache is warm
+// is published on the topic of that worker, so that it runs there.
+func (s *SchedulerServiceImpl) assign(ctx context.Context, subTask *model.SubTask, workerID string, warm bool) error {
+	log.Printf("Assigning subtask %s of task %s to worker %s", subTask.ID, subTask.ParentID, workerID)
+
+	subTask.WorkerID = workerID
+	subTask.Status = model.StatusScheduled
 
-	// 5. Publish TaskAssignedEvent to Kafka
-	if err := s.kafkaProducer.PublishTaskAssigned(ctx, taskID, workerID); err != nil {
+	publish := s.kafkaProducer.PublishTaskAssigned
+	if warm {
+		publish = s.kafkaProducer.PublishTaskAssignedToWorker
+	}
+	if err := publish(ctx, subTask, workerID); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
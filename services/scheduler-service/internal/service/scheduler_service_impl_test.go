@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"sync"
+	"testing"
+)
+
+// fakeTaskClient keeps tasks the way the task service does: updates keep the
+// input the task was created with
+type fakeTaskClient struct {
+	mu    sync.Mutex
+	tasks map[string]*model.Task
+}
+
+func (c *fakeTaskClient) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	task, ok := c.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	copied := *task
+	copied.Input = copyInput(task.Input)
+	return &copied, nil
+}
+
+func (c *fakeTaskClient) UpdateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	existing, ok := c.tasks[task.ID]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	existing.Status = task.Status
+	if task.Output != nil {
+		existing.Output = task.Output
+	}
+	return existing, nil
+}
+
+func (c *fakeTaskClient) Close() error { return nil }
+
+// fakeWorkers finds the workers that have all requested capabilities
+type fakeWorkers []*model.Worker
+
+func (w fakeWorkers) FindAvailableWorkers(ctx context.Context, capabilities []model.Capability, resources []model.Resource) ([]*model.Worker, error) {
+	var found []*model.Worker
+	for _, worker := range w {
+		matches := true
+		for _, capability := range capabilities {
+			if !hasCapability(worker, capability) {
+				matches = false
+			}
+		}
+		if matches {
+			found = append(found, worker)
+		}
+	}
+	return found, nil
+}
+
+func (w fakeWorkers) Close() error { return nil }
+
+func hasCapability(worker *model.Worker, capability model.Capability) bool {
+	for _, c := range worker.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeDurations map[string]float64
+
+func (d fakeDurations) GetPackageDurations(ctx context.Context, tenantID, project string) (map[string]float64, error) {
+	return d, nil
+}
+
+func (d fakeDurations) Close() error { return nil }
+
+// fakePublisher records the events of the scheduler
+type fakePublisher struct {
+	affectedRequests int
+	scheduled        []string
+	assigned         []*model.SubTask
+}
+
+func (p *fakePublisher) PublishTaskAssigned(ctx context.Context, subTask *model.SubTask, workerID string) error {
+	p.assigned = append(p.assigned, subTask)
+	return nil
+}
+
+func (p *fakePublisher) PublishTaskAssignedToWorker(ctx context.Context, subTask *model.SubTask, workerID string) error {
+	return p.PublishTaskAssigned(ctx, subTask, workerID)
+}
+
+func (p *fakePublisher) PublishTaskScheduled(ctx context.Context, task *model.Task, workerIDs []string, subtaskIDs []string) error {
+	p.scheduled = append(p.scheduled, task.ID)
+	return nil
+}
+
+func (p *fakePublisher) PublishAffectedPackagesRequested(ctx context.Context, taskID string, input map[string]string) error {
+	p.affectedRequests++
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+var defaultWorker = &model.Worker{ID: "w1", Capabilities: []model.Capability{{Name: "default", Value: "1.0"}}}
+
+func newTestScheduler(tasks ...*model.Task) (*SchedulerServiceImpl, *fakeTaskClient, *fakePublisher) {
+	taskClient := &fakeTaskClient{tasks: make(map[string]*model.Task)}
+	for _, task := range tasks {
+		taskClient.tasks[task.ID] = task
+	}
+	publisher := &fakePublisher{}
+	return newSchedulerService(fakeWorkers{defaultWorker}, taskClient, fakeDurations{}, publisher), taskClient, publisher
+}
+
+func TestScheduleAffectedSchedulesIncrementalTaskOnce(t *testing.T) {
+	ctx := context.Background()
+	s, taskClient, publisher := newTestScheduler(&model.Task{
+		ID:    "t1",
+		Name:  "incremental",
+		Input: map[string]string{inputModeKey: modeTest, inputBaseKey: "main"},
+	})
+
+	if err := s.ScheduleTask(ctx, "t1"); err != nil {
+		t.Fatal(err)
+	}
+	if publisher.affectedRequests != 1 || len(publisher.scheduled) != 0 {
+		t.Fatalf("Expected the affected packages to be requested, got %+v", publisher)
+	}
+
+	if err := s.ScheduleAffected(ctx, "t1", []string{"./a", "./b"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if publisher.affectedRequests != 1 {
+		t.Errorf("Expected no second request of the affected packages, got %d", publisher.affectedRequests)
+	}
+	if len(publisher.scheduled) != 1 || len(publisher.assigned) != 1 {
+		t.Fatalf("Expected the task to be scheduled once, got %+v", publisher)
+	}
+	if got := publisher.assigned[0].Input[inputPackagesKey]; got != "./a ./b" {
+		t.Errorf("Expected the subtask to test the affected packages, got %q", got)
+	}
+	if taskClient.tasks["t1"].Status != model.StatusScheduled {
+		t.Errorf("Expected the task to be scheduled, got %s", taskClient.tasks["t1"].Status)
+	}
+}
+
+func TestScheduleAffectedFinishesTasksWithoutPackages(t *testing.T) {
+	ctx := context.Background()
+	s, taskClient, publisher := newTestScheduler(
+		&model.Task{ID: "none", Input: map[string]string{inputModeKey: modeTest, inputBaseKey: "main"}},
+		&model.Task{ID: "failed", Input: map[string]string{inputModeKey: modeTest, inputBaseKey: "main"}},
+	)
+
+	if err := s.ScheduleAffected(ctx, "none", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ScheduleAffected(ctx, "failed", nil, "bad diff"); err != nil {
+		t.Fatal(err)
+	}
+
+	if task := taskClient.tasks["none"]; task.Status != model.StatusCompleted || task.Output[outputMessageKey] == "" {
+		t.Errorf("Expected the task to complete with a message, got %+v", task)
+	}
+	if task := taskClient.tasks["failed"]; task.Status != model.StatusFailed || task.Output[outputErrorKey] == "" {
+		t.Errorf("Expected the task to fail with an error, got %+v", task)
+	}
+	if len(publisher.scheduled) != 0 || publisher.affectedRequests != 0 {
+		t.Errorf("Expected nothing to be scheduled, got %+v", publisher)
+	}
+}
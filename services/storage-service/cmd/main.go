@@ -0,0 +1,12 @@
+package main
+
+import (
+	configloader "distributed-analyzer/libs/config"
+	"distributed-analyzer/services/storage-service/internal/bootstrap"
+	"distributed-analyzer/services/storage-service/internal/config"
+)
+
+func main() {
+	var cfg = configloader.LoadApplicationConfig[config.Config]("storage-service")
+	bootstrap.StartApplication(&cfg)
+}
@@ -0,0 +1,64 @@
+// Package bootstrap provides functionality to initialize and start the application components.
+package bootstrap
+
+import (
+	"distributed-analyzer/libs/application"
+	grpcApp "distributed-analyzer/libs/application/grpc"
+	"distributed-analyzer/libs/network/logging"
+	pb "distributed-analyzer/libs/proto/storage"
+	"distributed-analyzer/services/storage-service/internal/config"
+	"distributed-analyzer/services/storage-service/internal/grpc"
+	"distributed-analyzer/services/storage-service/internal/service"
+	"distributed-analyzer/services/storage-service/internal/storage"
+	"fmt"
+	stdgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"log"
+)
+
+// grpcMessageOverhead leaves room for the request envelope next to the object content
+const grpcMessageOverhead = 1 << 20
+
+// StartApplication initializes and starts all application components.
+// It sets up the storage backend and the gRPC server.
+func StartApplication(cfg *config.Config) {
+	maxSize, err := cfg.Files.MaxSizeBytes()
+	if err != nil {
+		log.Fatalf("Invalid max file size: %v", err)
+	}
+
+	backend, err := initBackend(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage backend: %v", err)
+	}
+
+	storageService := service.NewStorageServiceImpl(backend, maxSize)
+
+	runner := application.NewApplicationRunner(initGrpc(cfg, storageService, maxSize))
+	runner.DefaultStart()
+}
+
+// initBackend creates the configured storage backend
+func initBackend(cfg *config.Config) (storage.Backend, error) {
+	switch cfg.Storage.Backend {
+	case "filesystem":
+		return storage.NewFileSystemBackend(cfg.Storage.Root)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", cfg.Storage.Backend)
+	}
+}
+
+// initGrpc initializes the gRPC component with the configured server, which limits its callers.
+func initGrpc(cfg *config.Config, storageService service.StorageService, maxSize int64) *grpcApp.Component {
+	limiter := grpcApp.NewServerLimiter(cfg.GrpcLimit)
+	grpcServer := stdgrpc.NewServer(
+		stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor(), limiter.UnaryServerInterceptor()),
+		stdgrpc.MaxRecvMsgSize(int(maxSize+grpcMessageOverhead)),
+		stdgrpc.MaxSendMsgSize(int(maxSize+grpcMessageOverhead)),
+	)
+
+	pb.RegisterStorageServiceServer(grpcServer, grpc.NewStorageServer(storageService))
+	reflection.Register(grpcServer)
+
+	return grpcApp.NewGrpcComponent(grpcServer, &cfg.ServerConfig)
+}
@@ -2,20 +2,26 @@ package config
 
 import (
 	configloader "distributed-analyzer/libs/config"
+	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Config is the main configuration for the storage service
 type Config struct {
 	configloader.ServerConfig `yaml:",inline"`
 
-	Storage StorageConfig          `yaml:"storage"`
-	Files   FilesConfig            `yaml:"files"`
-	Cache   CacheConfig            `yaml:"cache"`
-	Log     configloader.LogConfig `yaml:"log"`
+	Storage   StorageConfig                `yaml:"storage"`
+	Files     FilesConfig                  `yaml:"files"`
+	Cache     CacheConfig                  `yaml:"cache"`
+	Log       configloader.LogConfig       `yaml:"log"`
+	GrpcLimit configloader.GrpcLimitConfig `yaml:"grpc_limit"`
 }
 
 // StorageConfig holds MinIO/S3-related settings
 type StorageConfig struct {
+	Backend   string `yaml:"backend"     env:"STORAGE_BACKEND"     env-default:"filesystem"`
+	Root      string `yaml:"root"        env:"STORAGE_ROOT"        env-default:"/var/lib/storage-service"`
 	Endpoint  string `yaml:"endpoint"    env:"STORAGE_ENDPOINT"    env-default:"localhost:9000"`
 	AccessKey string `yaml:"access_key"  env:"STORAGE_ACCESS_KEY"  env-default:"minioadmin"`
 	SecretKey string `yaml:"secret_key"  env:"STORAGE_SECRET_KEY"  env-default:"minioadmin"`
@@ -37,3 +43,34 @@ type CacheConfig struct {
 	TTL     string `yaml:"ttl"        env:"CACHE_TTL"        env-default:"1h"`
 	MaxSize string `yaml:"max_size"   env:"CACHE_MAX_SIZE"   env-default:"1GB"`
 }
+
+// sizeUnits maps the size suffixes accepted in the configuration to bytes
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// MaxSizeBytes returns the maximum upload size in bytes
+func (c FilesConfig) MaxSizeBytes() (int64, error) {
+	return parseSize(c.MaxSize)
+}
+
+// parseSize parses a size such as 100MB
+func parseSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	for _, unit := range sizeUnits {
+		if value, ok := strings.CutSuffix(size, unit.suffix); ok {
+			n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q: %w", size, err)
+			}
+			return n * unit.bytes, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid size %q: missing unit", size)
+}
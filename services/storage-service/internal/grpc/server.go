@@ -0,0 +1,94 @@
+package grpc
+
+import (
+	"context"
+	pb "distributed-analyzer/libs/proto/storage"
+	"distributed-analyzer/services/storage-service/internal/model"
+	"distributed-analyzer/services/storage-service/internal/service"
+	"distributed-analyzer/services/storage-service/internal/storage"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// StorageServer implements the StorageServiceServer interface
+type StorageServer struct {
+	pb.UnimplementedStorageServiceServer
+	storageService service.StorageService
+}
+
+// NewStorageServer creates a new StorageServer
+func NewStorageServer(storageService service.StorageService) *StorageServer {
+	return &StorageServer{
+		storageService: storageService,
+	}
+}
+
+// PutObject stores an object, replacing any object with the same key
+func (s *StorageServer) PutObject(ctx context.Context, req *pb.PutObjectRequest) (*pb.PutObjectResponse, error) {
+	info, err := s.storageService.PutObject(ctx, req.Key, req.Content)
+	if err != nil {
+		return nil, toStatusError(err, "failed to put object")
+	}
+
+	return &pb.PutObjectResponse{Object: convertObjectInfoToPb(info)}, nil
+}
+
+// GetObject retrieves an object by its key
+func (s *StorageServer) GetObject(ctx context.Context, req *pb.GetObjectRequest) (*pb.GetObjectResponse, error) {
+	info, content, err := s.storageService.GetObject(ctx, req.Key)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get object")
+	}
+
+	return &pb.GetObjectResponse{Object: convertObjectInfoToPb(info), Content: content}, nil
+}
+
+// ListObjects lists the objects whose keys start with a prefix
+func (s *StorageServer) ListObjects(ctx context.Context, req *pb.ListObjectsRequest) (*pb.ListObjectsResponse, error) {
+	objects, err := s.storageService.ListObjects(ctx, req.Prefix)
+	if err != nil {
+		return nil, toStatusError(err, "failed to list objects")
+	}
+
+	pbObjects := make([]*pb.ObjectInfo, len(objects))
+	for i, info := range objects {
+		pbObjects[i] = convertObjectInfoToPb(info)
+	}
+
+	return &pb.ListObjectsResponse{Objects: pbObjects}, nil
+}
+
+// DeleteObject removes an object
+func (s *StorageServer) DeleteObject(ctx context.Context, req *pb.DeleteObjectRequest) (*pb.DeleteObjectResponse, error) {
+	if err := s.storageService.DeleteObject(ctx, req.Key); err != nil {
+		return nil, toStatusError(err, "failed to delete object")
+	}
+
+	return &pb.DeleteObjectResponse{Success: true}, nil
+}
+
+// toStatusError maps service errors to gRPC status errors
+func toStatusError(err error, message string) error {
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", message, err)
+	case errors.Is(err, service.ErrInvalidKey):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	case errors.Is(err, service.ErrObjectTooLarge):
+		return status.Errorf(codes.ResourceExhausted, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// convertObjectInfoToPb converts a model.ObjectInfo to a pb.ObjectInfo
+func convertObjectInfoToPb(info *model.ObjectInfo) *pb.ObjectInfo {
+	return &pb.ObjectInfo{
+		Key:        info.Key,
+		Size:       info.Size,
+		Digest:     info.Digest,
+		ModifiedAt: timestamppb.New(info.ModifiedAt),
+	}
+}
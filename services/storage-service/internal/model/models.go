@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key        string    `json:"key"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/services/storage-service/internal/model"
+)
+
+// StorageService defines the interface for object storage operations
+type StorageService interface {
+	// PutObject stores an object, replacing any object with the same key
+	PutObject(ctx context.Context, key string, content []byte) (*model.ObjectInfo, error)
+
+	// GetObject retrieves an object by its key
+	GetObject(ctx context.Context, key string) (*model.ObjectInfo, []byte, error)
+
+	// ListObjects lists the objects whose keys start with prefix
+	ListObjects(ctx context.Context, prefix string) ([]*model.ObjectInfo, error)
+
+	// DeleteObject removes an object
+	DeleteObject(ctx context.Context, key string) error
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/services/storage-service/internal/model"
+	"distributed-analyzer/services/storage-service/internal/storage"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrInvalidKey is returned for keys that are empty, absolute or escape the key space
+	ErrInvalidKey = errors.New("invalid object key")
+
+	// ErrObjectTooLarge is returned when an object exceeds the configured size limit
+	ErrObjectTooLarge = errors.New("object too large")
+)
+
+// StorageServiceImpl implements the StorageService interface
+type StorageServiceImpl struct {
+	backend storage.Backend
+	maxSize int64
+}
+
+var _ StorageService = (*StorageServiceImpl)(nil)
+
+// NewStorageServiceImpl creates a new instance of StorageServiceImpl
+func NewStorageServiceImpl(backend storage.Backend, maxSize int64) *StorageServiceImpl {
+	return &StorageServiceImpl{
+		backend: backend,
+		maxSize: maxSize,
+	}
+}
+
+// PutObject stores an object, replacing any object with the same key
+func (s *StorageServiceImpl) PutObject(ctx context.Context, key string, content []byte) (*model.ObjectInfo, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	if s.maxSize > 0 && int64(len(content)) > s.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", ErrObjectTooLarge, len(content), s.maxSize)
+	}
+
+	return s.backend.Put(ctx, key, content)
+}
+
+// GetObject retrieves an object by its key
+func (s *StorageServiceImpl) GetObject(ctx context.Context, key string) (*model.ObjectInfo, []byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	return s.backend.Get(ctx, key)
+}
+
+// ListObjects lists the objects whose keys start with prefix
+func (s *StorageServiceImpl) ListObjects(ctx context.Context, prefix string) ([]*model.ObjectInfo, error) {
+	if prefix != "" {
+		// A prefix may end in a partial segment, so validate it as if it were a key
+		if err := validateKey(strings.TrimSuffix(prefix, "/")); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.backend.List(ctx, prefix)
+}
+
+// DeleteObject removes an object
+func (s *StorageServiceImpl) DeleteObject(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	return s.backend.Delete(ctx, key)
+}
+
+// validateKey checks that a key is a clean, relative, slash separated path
+// without the names the file system backend keeps its own files under
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) || path.Clean(key) != key {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." || strings.HasPrefix(segment, ".upload-") || strings.HasPrefix(segment, ".digest-") {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
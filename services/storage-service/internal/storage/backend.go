@@ -0,0 +1,26 @@
+// Package storage provides the backends objects are persisted in.
+package storage
+
+import (
+	"context"
+	"distributed-analyzer/services/storage-service/internal/model"
+	"errors"
+)
+
+// ErrObjectNotFound is returned when no object exists under the requested key
+var ErrObjectNotFound = errors.New("object not found")
+
+// Backend persists objects under validated keys
+type Backend interface {
+	// Put stores an object, replacing any object with the same key
+	Put(ctx context.Context, key string, content []byte) (*model.ObjectInfo, error)
+
+	// Get retrieves an object by its key
+	Get(ctx context.Context, key string) (*model.ObjectInfo, []byte, error)
+
+	// List lists the objects whose keys start with prefix
+	List(ctx context.Context, prefix string) ([]*model.ObjectInfo, error)
+
+	// Delete removes an object
+	Delete(ctx context.Context, key string) error
+}
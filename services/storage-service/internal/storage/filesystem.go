@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"distributed-analyzer/services/storage-service/internal/model"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// digestPrefix starts the name of the file next to an object that holds its digest
+const digestPrefix = ".digest-"
+
+// FileSystemBackend stores every object as a file below a root directory.
+// The digest of every object is kept in a file next to it, so that listing
+// objects does not read them.
+type FileSystemBackend struct {
+	root string
+}
+
+var _ Backend = (*FileSystemBackend)(nil)
+
+// NewFileSystemBackend creates a new FileSystemBackend rooted at root
+func NewFileSystemBackend(root string) (*FileSystemBackend, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+	return &FileSystemBackend{root: root}, nil
+}
+
+// Put stores an object, replacing any object with the same key.
+// The content is written to a temporary file first so readers never see partial objects.
+func (b *FileSystemBackend) Put(ctx context.Context, key string, content []byte) (*model.ObjectInfo, error) {
+	path := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(path, content); err != nil {
+		return nil, err
+	}
+	digest := contentDigest(content)
+	if err := writeFile(digestPath(path), []byte(digest)); err != nil {
+		return nil, err
+	}
+
+	return b.stat(key, digest)
+}
+
+// Get retrieves an object by its key
+func (b *FileSystemBackend) Get(ctx context.Context, key string) (*model.ObjectInfo, []byte, error) {
+	content, err := os.ReadFile(b.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := b.stat(key, contentDigest(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return info, content, nil
+}
+
+// List lists the objects whose keys start with prefix, ordered by key
+func (b *FileSystemBackend) List(ctx context.Context, prefix string) ([]*model.ObjectInfo, error) {
+	// Only walk the deepest directory that can contain matching keys
+	dir := b.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = b.path(prefix[:i])
+	}
+
+	objects := make([]*model.ObjectInfo, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") || strings.HasPrefix(d.Name(), digestPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		digest, err := b.digest(path)
+		if err != nil {
+			return err
+		}
+		info, err := b.stat(key, digest)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+
+	return objects, nil
+}
+
+// Delete removes an object
+func (b *FileSystemBackend) Delete(ctx context.Context, key string) error {
+	path := b.path(key)
+	err := os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrObjectNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(digestPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file an object is stored in
+func (b *FileSystemBackend) path(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(key))
+}
+
+// stat describes the object stored under key with the given digest
+func (b *FileSystemBackend) stat(key, digest string) (*model.ObjectInfo, error) {
+	fileInfo, err := os.Stat(b.path(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ObjectInfo{
+		Key:        key,
+		Size:       fileInfo.Size(),
+		Digest:     digest,
+		ModifiedAt: fileInfo.ModTime(),
+	}, nil
+}
+
+// digest returns the digest of the object stored in path from the file next
+// to it. Objects whose digest file is missing or older than the object, such
+// as objects stored before digests were kept, are read once to restore it.
+func (b *FileSystemBackend) digest(path string) (string, error) {
+	objectInfo, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	digestInfo, err := os.Stat(digestPath(path))
+	if err == nil && !digestInfo.ModTime().Before(objectInfo.ModTime()) {
+		digest, err := os.ReadFile(digestPath(path))
+		if err != nil {
+			return "", err
+		}
+		return string(digest), nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	digest := contentDigest(content)
+	if err := writeFile(digestPath(path), []byte(digest)); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// digestPath returns the file the digest of the object stored in path is kept in
+func digestPath(path string) string {
+	return filepath.Join(filepath.Dir(path), digestPrefix+filepath.Base(path))
+}
+
+// contentDigest returns the digest of an object's content
+func contentDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// writeFile replaces a file by writing a temporary file first and renaming it
+func writeFile(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
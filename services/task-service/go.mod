@@ -2,4 +2,7 @@ module distributed-analyzer/services/task-service
 
 go 1.24
 
-require google.golang.org/grpc v1.73.0
+require (
+	github.com/google/uuid v1.6.0
+	google.golang.org/grpc v1.73.0
+)
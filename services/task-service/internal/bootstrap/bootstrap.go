@@ -6,7 +6,9 @@ import (
 	grpcApp "distributed-analyzer/libs/application/grpc"
 	kafkaApp "distributed-analyzer/libs/application/kafka"
 	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/network/identity"
 	"distributed-analyzer/libs/network/logging"
+	"distributed-analyzer/libs/network/ratelimit"
 	pb "distributed-analyzer/libs/proto/task"
 	"distributed-analyzer/services/task-service/internal/config"
 	"distributed-analyzer/services/task-service/internal/grpc"
@@ -28,8 +30,13 @@ func StartApplication(cfg *config.Config) {
 		log.Fatalf("Invalid shutdown timeout: %v", err)
 	}
 
+	requestRetention, err := time.ParseDuration(cfg.Idempotency.Retention)
+	if err != nil || requestRetention <= 0 {
+		log.Fatalf("Invalid idempotency retention %q: %v", cfg.Idempotency.Retention, err)
+	}
+
 	// Initialize service and components
-	taskService := service.NewTaskServiceImpl()
+	taskService := service.NewTaskServiceImpl(requestRetention)
 	kafkaConsumerComponent, kafkaProducerComponent := initKafka(cfg, taskService)
 	grpcComponent := initGrpc(cfg, kafkaProducerComponent.Producer(), taskService)
 
@@ -81,20 +88,22 @@ func initGrpc(cfg *config.Config, kafkaProducer *kafka.Producer, service service
 		log.Fatalf("Task service is nil")
 	}
 
-	grpcServer := registerGrpcServer(kafkaProducer, service)
+	grpcServer := registerGrpcServer(kafkaProducer, service, grpcApp.NewServerLimiter(cfg.GrpcLimit), grpcApp.IdentityKey(cfg.Identity))
 
 	return grpcApp.NewGrpcComponent(grpcServer, &cfg.ServerConfig)
 }
 
-// registerGrpcServer creates a new gRPC server and registers the task service.
+// registerGrpcServer creates a new gRPC server verifying the identities of users with identityKey
+// and limiting its callers, and registers the task service.
 // It also enables server reflection for debugging purposes.
-func registerGrpcServer(kafkaProducer *kafka.Producer, service service.TaskService) *stdgrpc.Server {
+func registerGrpcServer(kafkaProducer *kafka.Producer, service service.TaskService, limiter *ratelimit.GRPCLimiter, identityKey []byte) *stdgrpc.Server {
 	// Create a server with appropriate options
-	grpcServer := stdgrpc.NewServer(stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor()))
+	grpcServer := stdgrpc.NewServer(stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor(), identity.ServerInterceptor(identityKey), limiter.UnaryServerInterceptor()))
 
 	// Create task producer and server
 	taskProducer := producer.NewTaskProducer(kafkaProducer)
-	taskGrpcServer := grpc.NewTaskServer(service, taskProducer)
+	auditPublisher := kafka.NewAuditPublisher(kafkaProducer, "task-service")
+	taskGrpcServer := grpc.NewTaskServer(service, taskProducer, auditPublisher)
 
 	// Register services
 	pb.RegisterTaskServiceServer(grpcServer, taskGrpcServer)
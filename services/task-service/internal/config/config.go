@@ -5,11 +5,20 @@ import (
 )
 
 type Config struct {
-	ServerConfig    configloader.ServerConfig   `yaml:",inline"`
-	Kafka           KafkaConfig                 `yaml:"kafka"`
-	Database        configloader.DatabaseConfig `yaml:"database"`
-	Log             configloader.LogConfig      `yaml:"log"`
-	ShutdownTimeout string                      `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+	ServerConfig    configloader.ServerConfig    `yaml:",inline"`
+	Kafka           KafkaConfig                  `yaml:"kafka"`
+	Database        configloader.DatabaseConfig  `yaml:"database"`
+	Log             configloader.LogConfig       `yaml:"log"`
+	GrpcLimit       configloader.GrpcLimitConfig `yaml:"grpc_limit"`
+	Identity        configloader.IdentityConfig  `yaml:"identity"`
+	Idempotency     IdempotencyConfig            `yaml:"idempotency"`
+	ShutdownTimeout string                       `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+}
+
+// IdempotencyConfig holds the settings of request IDs of task submissions
+type IdempotencyConfig struct {
+	// Retention is how long a request ID returns the task it created
+	Retention string `yaml:"retention" env:"IDEMPOTENCY_RETENTION" env-default:"24h"`
 }
 
 // KafkaConfig extends the common KafkaConfig with task-specific topics
@@ -2,10 +2,12 @@ package grpc
 
 import (
 	"context"
+	"distributed-analyzer/libs/kafka"
 	"distributed-analyzer/libs/model"
 	pb "distributed-analyzer/libs/proto/task"
 	"distributed-analyzer/services/task-service/internal/kafka/producer"
 	"distributed-analyzer/services/task-service/internal/service"
+	"errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -16,12 +18,14 @@ type TaskServer struct {
 	pb.UnimplementedTaskServiceServer
 	taskService service.TaskService
 	producer    *producer.TaskProducer
+	audit       *kafka.AuditPublisher
 }
 
-func NewTaskServer(taskService service.TaskService, producer *producer.TaskProducer) *TaskServer {
+func NewTaskServer(taskService service.TaskService, producer *producer.TaskProducer, audit *kafka.AuditPublisher) *TaskServer {
 	return &TaskServer{
 		taskService: taskService,
 		producer:    producer,
+		audit:       audit,
 	}
 }
 
@@ -44,11 +48,25 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest)
 		}
 	}
 
-	createdTask, err := s.taskService.CreateTask(ctx, task)
-	if err != nil {
+	createdTask, created, err := s.taskService.CreateTask(ctx, task, req.RequestId)
+	switch {
+	case errors.Is(err, service.ErrInvalidRequestID):
+		return nil, status.Errorf(codes.InvalidArgument, "failed to create task: %v", err)
+	case errors.Is(err, service.ErrRequestIDReused):
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to create task: %v", err)
+	case err != nil:
 		return nil, status.Errorf(codes.Internal, "failed to create task: %v", err)
 	}
 
+	// A repeated request was published when the task was created
+	if !created {
+		log.Printf("Request %s repeats the creation of task %s", req.RequestId, createdTask.ID)
+		return &pb.TaskResponse{
+			Task:     convertModelTaskToPbTask(createdTask),
+			Replayed: true,
+		}, nil
+	}
+
 	// Publish TaskCreatedEvent to Kafka
 	if s.producer != nil {
 		if err := s.producer.PublishTaskCreated(ctx, createdTask); err != nil {
@@ -56,6 +74,7 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest)
 			// Continue even if publishing fails
 		}
 	}
+	s.publishAudit(ctx, model.AuditActionCreate, createdTask.ID, map[string]string{"name": createdTask.Name})
 
 	return &pb.TaskResponse{
 		Task: convertModelTaskToPbTask(createdTask),
@@ -82,9 +101,13 @@ func (s *TaskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest)
 
 	modelTask := convertPbTaskToModelTask(req.Task)
 	updatedTask, err := s.taskService.UpdateTask(ctx, modelTask)
+	if errors.Is(err, service.ErrTaskNotFound) {
+		return nil, status.Errorf(codes.NotFound, "failed to update task: %v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to update task: %v", err)
 	}
+	s.publishAudit(ctx, model.AuditActionUpdate, updatedTask.ID, map[string]string{"status": string(updatedTask.Status)})
 
 	return &pb.TaskResponse{
 		Task: convertModelTaskToPbTask(updatedTask),
@@ -94,9 +117,13 @@ func (s *TaskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest)
 // DeleteTask removes a task from the system
 func (s *TaskServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
 	err := s.taskService.DeleteTask(ctx, req.Id)
+	if errors.Is(err, service.ErrTaskNotFound) {
+		return nil, status.Errorf(codes.NotFound, "failed to delete task: %v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to delete task: %v", err)
 	}
+	s.publishAudit(ctx, model.AuditActionDelete, req.Id, nil)
 
 	return &pb.DeleteTaskResponse{
 		Success: true,
@@ -120,6 +147,17 @@ func (s *TaskServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*
 	}, nil
 }
 
+// publishAudit publishes an action on a task to the audit log. Failures are
+// logged, since the action already happened.
+func (s *TaskServer) publishAudit(ctx context.Context, action, taskID string, details map[string]string) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Publish(ctx, action, "task", taskID, details); err != nil {
+		log.Printf("Failed to publish audit event for task %s: %v", taskID, err)
+	}
+}
+
 // convertModelTaskToPbTask converts a model.Task to a pb.Task
 func convertModelTaskToPbTask(task *model.Task) *pb.Task {
 	pbTask := &pb.Task{
@@ -129,6 +167,8 @@ func convertModelTaskToPbTask(task *model.Task) *pb.Task {
 		Status:      convertModelStatusToPbStatus(task.Status),
 		Input:       task.Input,
 		Output:      task.Output,
+		OwnerId:     task.OwnerID,
+		TenantId:    task.TenantID,
 		CreatedAt:   timestamppb.New(task.CreatedAt),
 		UpdatedAt:   timestamppb.New(task.UpdatedAt),
 	}
@@ -159,6 +199,8 @@ func convertPbTaskToModelTask(pbTask *pb.Task) *model.Task {
 		Status:      convertPbStatusToModelStatus(pbTask.Status),
 		Input:       pbTask.Input,
 		Output:      pbTask.Output,
+		OwnerID:     pbTask.OwnerId,
+		TenantID:    pbTask.TenantId,
 	}
 
 	if pbTask.CreatedAt != nil {
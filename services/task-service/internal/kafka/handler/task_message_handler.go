@@ -94,6 +94,9 @@ func (c *TaskMessageHandler) handleTaskFailed(ctx context.Context, message kafka
 	}
 
 	task.Status = model.StatusFailed
+	if event.Result != nil {
+		task.Output = event.Result
+	}
 	task.UpdatedAt = time.Now()
 
 	_, err = c.taskService.UpdateTask(ctx, task)
@@ -20,10 +20,20 @@ func NewTaskProducer(pr *kafka.Producer) *TaskProducer {
 	}
 }
 
-// PublishTaskCreated publishes a TaskCreatedEvent to Kafka
+// PublishTaskCreated publishes a TaskCreatedEvent to Kafka. The event carries
+// the owner and tenant of the task, so consumers need not look them up.
 func (p *TaskProducer) PublishTaskCreated(ctx context.Context, task *model.Task) error {
 	event := &pb.TaskCreatedEvent{
-		TaskId:    task.ID,
+		TaskId: task.ID,
+		Task: &taskpb.Task{
+			Id:        task.ID,
+			Name:      task.Name,
+			Status:    convertModelStatusToPbStatus(task.Status),
+			Input:     task.Input,
+			OwnerId:   task.OwnerID,
+			TenantId:  task.TenantID,
+			CreatedAt: timestamppb.New(task.CreatedAt),
+		},
 		CreatedAt: timestamppb.New(time.Now()),
 	}
 
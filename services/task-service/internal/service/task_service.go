@@ -7,8 +7,10 @@ import (
 
 // TaskService defines the interface for task-related operations
 type TaskService interface {
-	// CreateTask creates a new task in the system
-	CreateTask(ctx context.Context, task *model.Task) (*model.Task, error)
+	// CreateTask creates a new task in the system, owned by the caller. A
+	// repeat of a request ID of the caller within the retention window returns
+	// the task of the first request and false instead of creating another.
+	CreateTask(ctx context.Context, task *model.Task, requestID string) (*model.Task, bool, error)
 
 	// GetTask retrieves a task by its ID
 	GetTask(ctx context.Context, id string) (*model.Task, error)
@@ -19,6 +21,6 @@ type TaskService interface {
 	// DeleteTask removes a task from the system
 	DeleteTask(ctx context.Context, id string) error
 
-	// ListTasks retrieves all tasks with optional filtering
+	// ListTasks retrieves all tasks the caller may access
 	ListTasks(ctx context.Context) ([]*model.Task, error)
 }
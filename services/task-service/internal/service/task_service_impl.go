@@ -2,43 +2,139 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/identity"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"github.com/google/uuid"
 	"sync"
 	"time"
 )
 
-var ErrTaskNotFound = errors.New("task not found")
+// MaxRequestIDLength bounds the length of request IDs
+const MaxRequestIDLength = 255
 
-// TaskServiceImpl implements the TaskService interface
+var (
+	ErrTaskNotFound     = errors.New("task not found")
+	ErrInvalidRequestID = errors.New("invalid request ID")
+	ErrRequestIDReused  = errors.New("request ID was used for a different task")
+)
+
+// TaskServiceImpl implements the TaskService interface. Calls on behalf of a
+// user only see the tasks the user may access; tasks of other tenants are
+// reported as not found. Calls of other services carry no identity and see
+// every task.
 type TaskServiceImpl struct {
 	tasks  map[string]*model.Task
 	taskMu sync.RWMutex
+
+	// requests remembers the tasks created for request IDs, keyed by caller
+	// and request ID, and requestOrder holds them by expiry
+	requests         map[string]*taskRequest
+	requestOrder     []*taskRequest
+	requestRetention time.Duration
+	now              func() time.Time
 }
 
-// NewTaskServiceImpl creates a new instance of TaskServiceImpl
-func NewTaskServiceImpl() *TaskServiceImpl {
+// taskRequest is a request ID of a caller and the task it created
+type taskRequest struct {
+	key         string
+	taskID      string
+	fingerprint string
+	expiresAt   time.Time
+}
+
+// NewTaskServiceImpl creates a new instance of TaskServiceImpl remembering
+// request IDs for requestRetention
+func NewTaskServiceImpl(requestRetention time.Duration) *TaskServiceImpl {
 	return &TaskServiceImpl{
-		tasks: make(map[string]*model.Task),
+		tasks:            make(map[string]*model.Task),
+		requests:         make(map[string]*taskRequest),
+		requestRetention: requestRetention,
+		now:              time.Now,
 	}
 }
 
-// CreateTask creates a new task in the system
-func (t *TaskServiceImpl) CreateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+// CreateTask creates a new task in the system. A repeated request ID must come
+// with the same task; if its task was deleted since, a new task is created.
+func (t *TaskServiceImpl) CreateTask(ctx context.Context, task *model.Task, requestID string) (*model.Task, bool, error) {
+	if len(requestID) > MaxRequestIDLength {
+		return nil, false, ErrInvalidRequestID
+	}
+
 	t.taskMu.Lock()
 	defer t.taskMu.Unlock()
 
-	// In a real implementation, we would generate a proper UUID
-	if task.ID == "" {
-		task.ID = time.Now().Format("20060102150405")
+	now := t.now()
+	t.expireRequests(now)
+
+	// The owner is whoever submits the task, never what the request claims
+	task.OwnerID, task.TenantID = "", ""
+	if id, ok := identity.FromContext(ctx); ok {
+		task.OwnerID, task.TenantID = id.UserID, id.TenantID
 	}
 
+	var request *taskRequest
+	if requestID != "" {
+		// Request IDs are scoped to the caller, so callers cannot see each other's tasks
+		request = &taskRequest{
+			key:         task.TenantID + "\x00" + task.OwnerID + "\x00" + requestID,
+			fingerprint: fingerprint(task),
+			expiresAt:   now.Add(t.requestRetention),
+		}
+		if earlier, ok := t.requests[request.key]; ok {
+			if existing, exists := t.tasks[earlier.taskID]; exists {
+				if earlier.fingerprint != request.fingerprint {
+					return nil, false, ErrRequestIDReused
+				}
+				return existing, false, nil
+			}
+		}
+	}
+
+	// IDs are generated here, never taken from the request
+	task.ID = uuid.NewString()
 	task.Status = model.StatusPending
-	task.CreatedAt = time.Now()
-	task.UpdatedAt = time.Now()
+	task.CreatedAt = now
+	task.UpdatedAt = now
 
 	t.tasks[task.ID] = task
-	return task, nil
+	if request != nil {
+		request.taskID = task.ID
+		t.requests[request.key] = request
+		t.requestOrder = append(t.requestOrder, request)
+	}
+	return task, true, nil
+}
+
+// expireRequests forgets the request IDs whose retention ended
+func (t *TaskServiceImpl) expireRequests(now time.Time) {
+	expired := 0
+	for _, request := range t.requestOrder {
+		if now.Before(request.expiresAt) {
+			break
+		}
+		// A request ID reused after its task was deleted has a newer entry
+		if t.requests[request.key] == request {
+			delete(t.requests, request.key)
+		}
+		expired++
+	}
+	t.requestOrder = t.requestOrder[expired:]
+}
+
+// fingerprint returns a hash of what a request asks of a task
+func fingerprint(task *model.Task) string {
+	data, _ := json.Marshal(struct {
+		Name        string
+		Description string
+		Input       map[string]string
+		Resources   []model.Resource
+	}{task.Name, task.Description, task.Input, task.Resources})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // GetTask retrieves a task by its ID
@@ -47,7 +143,7 @@ func (t *TaskServiceImpl) GetTask(ctx context.Context, id string) (*model.Task,
 	defer t.taskMu.RUnlock()
 
 	task, exists := t.tasks[id]
-	if !exists {
+	if !exists || !canAccess(ctx, task) {
 		return nil, ErrTaskNotFound
 	}
 
@@ -60,7 +156,7 @@ func (t *TaskServiceImpl) UpdateTask(ctx context.Context, task *model.Task) (*mo
 	defer t.taskMu.Unlock()
 
 	existingTask, exists := t.tasks[task.ID]
-	if !exists {
+	if !exists || !canAccess(ctx, existingTask) {
 		return nil, ErrTaskNotFound
 	}
 
@@ -70,6 +166,11 @@ func (t *TaskServiceImpl) UpdateTask(ctx context.Context, task *model.Task) (*mo
 	existingTask.Status = task.Status
 	existingTask.UpdatedAt = time.Now()
 
+	// The input stays what the task was created with; the output is replaced if given
+	if task.Output != nil {
+		existingTask.Output = task.Output
+	}
+
 	if task.Status == model.StatusCompleted && existingTask.CompletedAt.IsZero() {
 		existingTask.CompletedAt = time.Now()
 	}
@@ -82,7 +183,7 @@ func (t *TaskServiceImpl) DeleteTask(ctx context.Context, id string) error {
 	t.taskMu.Lock()
 	defer t.taskMu.Unlock()
 
-	if _, exists := t.tasks[id]; !exists {
+	if task, exists := t.tasks[id]; !exists || !canAccess(ctx, task) {
 		return ErrTaskNotFound
 	}
 
@@ -97,8 +198,16 @@ func (t *TaskServiceImpl) ListTasks(ctx context.Context) ([]*model.Task, error)
 
 	tasks := make([]*model.Task, 0, len(t.tasks))
 	for _, task := range t.tasks {
-		tasks = append(tasks, task)
+		if canAccess(ctx, task) {
+			tasks = append(tasks, task)
+		}
 	}
 
 	return tasks, nil
 }
+
+// canAccess reports whether the user behind ctx, if any, may access a task
+func canAccess(ctx context.Context, task *model.Task) bool {
+	id, ok := identity.FromContext(ctx)
+	return !ok || id.CanAccess(task.OwnerID, task.TenantID)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/identity"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateTaskWithRequestID(t *testing.T) {
+	now := time.Now()
+	s := NewTaskServiceImpl(time.Hour)
+	s.now = func() time.Time { return now }
+	alice := identity.NewContext(context.Background(), &identity.Identity{UserID: "alice", TenantID: "team-a"})
+	bob := identity.NewContext(context.Background(), &identity.Identity{UserID: "bob", TenantID: "team-a"})
+
+	first, created, err := s.CreateTask(alice, &model.Task{Name: "lint"}, "ci-42")
+	if err != nil || !created {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	// A retry returns the first task
+	again, created, err := s.CreateTask(alice, &model.Task{Name: "lint"}, "ci-42")
+	if err != nil || created || again.ID != first.ID {
+		t.Errorf("Expected the first task, got %+v %v %v", again, created, err)
+	}
+
+	// The same request ID with another task is refused
+	if _, _, err := s.CreateTask(alice, &model.Task{Name: "test"}, "ci-42"); !errors.Is(err, ErrRequestIDReused) {
+		t.Errorf("Expected ErrRequestIDReused, got %v", err)
+	}
+
+	// Request IDs of other callers do not collide
+	other, created, err := s.CreateTask(bob, &model.Task{Name: "lint"}, "ci-42")
+	if err != nil || !created || other.ID == first.ID {
+		t.Errorf("Expected a task of another caller, got %+v %v %v", other, created, err)
+	}
+
+	// Without a request ID, every call creates a task with its own ID
+	a, _, _ := s.CreateTask(alice, &model.Task{Name: "lint"}, "")
+	b, _, _ := s.CreateTask(alice, &model.Task{Name: "lint"}, "")
+	if a.ID == b.ID || len(a.ID) != 36 {
+		t.Errorf("Expected distinct UUIDs, got %q and %q", a.ID, b.ID)
+	}
+
+	// Request IDs are forgotten after the retention window
+	now = now.Add(time.Hour)
+	later, created, err := s.CreateTask(alice, &model.Task{Name: "lint"}, "ci-42")
+	if err != nil || !created || later.ID == first.ID {
+		t.Errorf("Expected a new task after the retention window, got %+v %v %v", later, created, err)
+	}
+	if len(s.requests) != 1 || len(s.requestOrder) != 1 {
+		t.Errorf("Expected expired request IDs to be dropped, got %d and %d", len(s.requests), len(s.requestOrder))
+	}
+
+	// A request ID whose task was deleted creates a new task
+	if err := s.DeleteTask(alice, later.ID); err != nil {
+		t.Fatal(err)
+	}
+	recreated, created, err := s.CreateTask(alice, &model.Task{Name: "lint"}, "ci-42")
+	if err != nil || !created || recreated.ID == later.ID {
+		t.Errorf("Expected a new task for a deleted one, got %+v %v %v", recreated, created, err)
+	}
+
+	if _, _, err := s.CreateTask(alice, &model.Task{Name: "lint"}, strings.Repeat("x", MaxRequestIDLength+1)); !errors.Is(err, ErrInvalidRequestID) {
+		t.Errorf("Expected ErrInvalidRequestID, got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	configloader "distributed-analyzer/libs/config"
+	"distributed-analyzer/services/user-service/internal/bootstrap"
+	"distributed-analyzer/services/user-service/internal/config"
+)
+
+func main() {
+	var cfg = configloader.LoadApplicationConfig[config.Config]("user-service")
+	bootstrap.StartApplication(&cfg)
+}
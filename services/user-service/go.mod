@@ -0,0 +1,3 @@
+module distributed-analyzer/services/user-service
+
+go 1.24
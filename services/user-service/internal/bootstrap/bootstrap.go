@@ -0,0 +1,62 @@
+// Package bootstrap provides functionality to initialize and start the application components.
+package bootstrap
+
+import (
+	"distributed-analyzer/libs/application"
+	grpcApp "distributed-analyzer/libs/application/grpc"
+	kafkaApp "distributed-analyzer/libs/application/kafka"
+	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/network/identity"
+	"distributed-analyzer/libs/network/logging"
+	pb "distributed-analyzer/libs/proto/user"
+	"distributed-analyzer/libs/store"
+	"distributed-analyzer/services/user-service/internal/config"
+	"distributed-analyzer/services/user-service/internal/grpc"
+	"distributed-analyzer/services/user-service/internal/model"
+	"distributed-analyzer/services/user-service/internal/service"
+	stdgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"log"
+	"time"
+)
+
+// StartApplication initializes and starts all application components.
+// It loads the user store and sets up the gRPC server, which publishes its changes to the audit log.
+func StartApplication(cfg *config.Config) {
+	maxKeyTTL, err := time.ParseDuration(cfg.APIKeys.MaxTTL)
+	if err != nil {
+		log.Fatalf("Invalid API key max TTL: %v", err)
+	}
+
+	userStore, err := store.NewSnapshotFile[model.Snapshot](cfg.Store.Path)
+	if err != nil {
+		log.Fatalf("Failed to initialize user store: %v", err)
+	}
+
+	userService, err := service.NewUserServiceImpl(userStore, maxKeyTTL, cfg.APIKeys.MaxPerUser)
+	if err != nil {
+		log.Fatalf("Failed to initialize user service: %v", err)
+	}
+
+	kafkaProducer := kafka.NewProducer(cfg.Kafka.Brokers)
+	auditPublisher := kafka.NewAuditPublisher(kafkaProducer, "user-service")
+
+	runner := application.NewApplicationRunner(
+		initGrpc(cfg, userService, auditPublisher),
+		kafkaApp.NewKafkaProducerComponent(kafkaProducer),
+	)
+	runner.DefaultStart()
+}
+
+// initGrpc initializes the gRPC component with the configured server, which limits its callers.
+func initGrpc(cfg *config.Config, userService service.UserService, auditPublisher *kafka.AuditPublisher) *grpcApp.Component {
+	limiter := grpcApp.NewServerLimiter(cfg.GrpcLimit)
+	grpcServer := stdgrpc.NewServer(
+		stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor(), identity.ServerInterceptor(grpcApp.IdentityKey(cfg.Identity)), limiter.UnaryServerInterceptor()),
+	)
+
+	pb.RegisterUserServiceServer(grpcServer, grpc.NewUserServer(userService, auditPublisher))
+	reflection.Register(grpcServer)
+
+	return grpcApp.NewGrpcComponent(grpcServer, &cfg.ServerConfig)
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	configloader "distributed-analyzer/libs/config"
+)
+
+// Config is the main configuration for the user service
+type Config struct {
+	configloader.ServerConfig `yaml:",inline"`
+
+	Kafka     configloader.KafkaConfig     `yaml:"kafka"`
+	Store     StoreConfig                  `yaml:"store"`
+	APIKeys   APIKeysConfig                `yaml:"api_keys"`
+	Log       configloader.LogConfig       `yaml:"log"`
+	GrpcLimit configloader.GrpcLimitConfig `yaml:"grpc_limit"`
+	Identity  configloader.IdentityConfig  `yaml:"identity"`
+}
+
+// StoreConfig holds the settings of the persistent user store
+type StoreConfig struct {
+	Path string `yaml:"path" env:"STORE_PATH" env-default:"/var/lib/user-service/users.json"`
+}
+
+// APIKeysConfig holds the settings of API keys
+type APIKeysConfig struct {
+	// MaxTTL bounds the lifetime of new keys, 0 allows keys that never expire
+	MaxTTL string `yaml:"max_ttl" env:"API_KEYS_MAX_TTL" env-default:"0s"`
+
+	// MaxPerUser bounds the number of active keys of a user
+	MaxPerUser int `yaml:"max_per_user" env:"API_KEYS_MAX_PER_USER" env-default:"20"`
+}
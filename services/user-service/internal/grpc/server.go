@@ -0,0 +1,246 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/kafka"
+	libmodel "distributed-analyzer/libs/model"
+	pb "distributed-analyzer/libs/proto/user"
+	"distributed-analyzer/services/user-service/internal/service"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"time"
+)
+
+// UserServer implements the UserServiceServer interface
+type UserServer struct {
+	pb.UnimplementedUserServiceServer
+	userService service.UserService
+	audit       *kafka.AuditPublisher
+}
+
+// NewUserServer creates a new UserServer publishing its changes to audit
+func NewUserServer(userService service.UserService, audit *kafka.AuditPublisher) *UserServer {
+	return &UserServer{
+		userService: userService,
+		audit:       audit,
+	}
+}
+
+// CreateUser creates a new user in the system
+func (s *UserServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.UserResponse, error) {
+	user, err := s.userService.CreateUser(ctx, req.Username, req.Email, req.TenantId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to create user")
+	}
+
+	s.publishAudit(ctx, libmodel.AuditActionCreate, "user", user.ID, map[string]string{"username": user.Username, "tenant_id": user.TenantID})
+	return &pb.UserResponse{User: convertUserToPb(user)}, nil
+}
+
+// GetUser retrieves a user by their ID
+func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.UserResponse, error) {
+	user, err := s.userService.GetUser(ctx, req.Id)
+	if err != nil {
+		return nil, toStatusError(err, "failed to get user")
+	}
+
+	return &pb.UserResponse{User: convertUserToPb(user)}, nil
+}
+
+// UpdateUser updates the username, email and tenant of an existing user
+func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UserResponse, error) {
+	if req.User == nil {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	user, err := s.userService.UpdateUser(ctx, &libmodel.User{
+		ID:       req.User.Id,
+		Username: req.User.Username,
+		Email:    req.User.Email,
+		TenantID: req.User.TenantId,
+	})
+	if err != nil {
+		return nil, toStatusError(err, "failed to update user")
+	}
+
+	s.publishAudit(ctx, libmodel.AuditActionUpdate, "user", user.ID, map[string]string{"username": user.Username, "tenant_id": user.TenantID})
+	return &pb.UserResponse{User: convertUserToPb(user)}, nil
+}
+
+// DeleteUser removes a user from the system
+func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if err := s.userService.DeleteUser(ctx, req.Id); err != nil {
+		return nil, toStatusError(err, "failed to delete user")
+	}
+
+	s.publishAudit(ctx, libmodel.AuditActionDelete, "user", req.Id, nil)
+	return &pb.DeleteUserResponse{Success: true}, nil
+}
+
+// ListUsers retrieves all users
+func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	users, err := s.userService.ListUsers(ctx)
+	if err != nil {
+		return nil, toStatusError(err, "failed to list users")
+	}
+
+	pbUsers := make([]*pb.User, len(users))
+	for i, user := range users {
+		pbUsers[i] = convertUserToPb(user)
+	}
+
+	return &pb.ListUsersResponse{Users: pbUsers}, nil
+}
+
+// AssignRole assigns a role to a user
+func (s *UserServer) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.AssignRoleResponse, error) {
+	if err := s.userService.AssignRole(ctx, req.UserId, req.Role.String()); err != nil {
+		return nil, toStatusError(err, "failed to assign role")
+	}
+
+	s.publishAudit(ctx, libmodel.AuditActionGrant, "role", req.UserId, map[string]string{"role": req.Role.String()})
+	return &pb.AssignRoleResponse{Success: true}, nil
+}
+
+// GrantPermission grants a permission to a user
+func (s *UserServer) GrantPermission(ctx context.Context, req *pb.GrantPermissionRequest) (*pb.GrantPermissionResponse, error) {
+	permission, err := s.userService.GrantPermission(ctx, req.UserId, req.Resource, req.Permission)
+	if err != nil {
+		return nil, toStatusError(err, "failed to grant permission")
+	}
+
+	s.publishAudit(ctx, libmodel.AuditActionGrant, "permission", req.UserId, map[string]string{"resource": req.Resource, "permission": req.Permission})
+	return &pb.GrantPermissionResponse{
+		Success: true,
+		Permission: &pb.UserPermission{
+			UserId:     permission.UserID,
+			Resource:   permission.Resource,
+			Permission: permission.Permission,
+			CreatedAt:  timestamppb.New(permission.CreatedAt),
+		},
+	}, nil
+}
+
+// CreateAPIKey issues a new API key for a user
+func (s *UserServer) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	if req.TtlSeconds < 0 {
+		return nil, toStatusError(service.ErrInvalidKeyTTL, "failed to create api key")
+	}
+
+	apiKey, key, err := s.userService.CreateAPIKey(ctx, req.UserId, req.Name, time.Duration(req.TtlSeconds)*time.Second)
+	if err != nil {
+		return nil, toStatusError(err, "failed to create api key")
+	}
+
+	s.publishAudit(ctx, libmodel.AuditActionCreate, "api_key", apiKey.ID, map[string]string{"user_id": req.UserId, "name": req.Name})
+	return &pb.CreateAPIKeyResponse{ApiKey: convertAPIKeyToPb(apiKey), Key: key}, nil
+}
+
+// ListAPIKeys lists the API keys of a user
+func (s *UserServer) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	keys, err := s.userService.ListAPIKeys(ctx, req.UserId)
+	if err != nil {
+		return nil, toStatusError(err, "failed to list api keys")
+	}
+
+	pbKeys := make([]*pb.APIKey, len(keys))
+	for i, key := range keys {
+		pbKeys[i] = convertAPIKeyToPb(key)
+	}
+
+	return &pb.ListAPIKeysResponse{ApiKeys: pbKeys}, nil
+}
+
+// RevokeAPIKey revokes an API key of a user
+func (s *UserServer) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	if err := s.userService.RevokeAPIKey(ctx, req.UserId, req.KeyId); err != nil {
+		return nil, toStatusError(err, "failed to revoke api key")
+	}
+
+	s.publishAudit(ctx, libmodel.AuditActionRevoke, "api_key", req.KeyId, map[string]string{"user_id": req.UserId})
+	return &pb.RevokeAPIKeyResponse{Success: true}, nil
+}
+
+// VerifyAPIKey resolves an API key to the user it was issued for
+func (s *UserServer) VerifyAPIKey(ctx context.Context, req *pb.VerifyAPIKeyRequest) (*pb.VerifyAPIKeyResponse, error) {
+	user, key, err := s.userService.VerifyAPIKey(ctx, req.Key)
+	if err != nil {
+		return nil, toStatusError(err, "failed to verify api key")
+	}
+
+	return &pb.VerifyAPIKeyResponse{User: convertUserToPb(user), KeyId: key.ID}, nil
+}
+
+// publishAudit publishes a change to the audit log. Failures are logged, since
+// the change already happened.
+func (s *UserServer) publishAudit(ctx context.Context, action, resource, resourceID string, details map[string]string) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Publish(ctx, action, resource, resourceID, details); err != nil {
+		log.Printf("Failed to publish audit event for %s %s: %v", resource, resourceID, err)
+	}
+}
+
+// toStatusError maps service errors to gRPC status errors
+func toStatusError(err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrAPIKeyNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", message, err)
+	case errors.Is(err, service.ErrUserExists):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", message, err)
+	case errors.Is(err, service.ErrInvalidUser), errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrInvalidKeyTTL):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	case errors.Is(err, service.ErrInvalidAPIKey), errors.Is(err, service.ErrInvalidKeyFormat):
+		return status.Errorf(codes.Unauthenticated, "%s: %v", message, err)
+	case errors.Is(err, service.ErrPermissionDenied):
+		return status.Errorf(codes.PermissionDenied, "%s: %v", message, err)
+	case errors.Is(err, service.ErrTooManyAPIKeys):
+		return status.Errorf(codes.ResourceExhausted, "%s: %v", message, err)
+	default:
+		return status.Errorf(codes.Internal, "%s: %v", message, err)
+	}
+}
+
+// convertUserToPb converts a libmodel.User to a pb.User
+func convertUserToPb(user *libmodel.User) *pb.User {
+	roles := make([]pb.UserRole, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		if value, ok := pb.UserRole_value[role]; ok {
+			roles = append(roles, pb.UserRole(value))
+		}
+	}
+
+	return &pb.User{
+		Id:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		Roles:     roles,
+		TenantId:  user.TenantID,
+	}
+}
+
+// convertAPIKeyToPb converts a libmodel.APIKey to a pb.APIKey, leaving unset times out
+func convertAPIKeyToPb(key *libmodel.APIKey) *pb.APIKey {
+	return &pb.APIKey{
+		Id:         key.ID,
+		UserId:     key.UserID,
+		Name:       key.Name,
+		CreatedAt:  timestamppb.New(key.CreatedAt),
+		ExpiresAt:  optionalTimestamp(key.ExpiresAt),
+		LastUsedAt: optionalTimestamp(key.LastUsedAt),
+		RevokedAt:  optionalTimestamp(key.RevokedAt),
+	}
+}
+
+// optionalTimestamp converts a time to a timestamp, or nil if it is zero
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	libmodel "distributed-analyzer/libs/model"
+)
+
+// APIKey is a stored API key
+type APIKey struct {
+	libmodel.APIKey
+
+	// Hash is the hex encoded SHA-256 of the secret of the key
+	Hash string `json:"hash"`
+}
+
+// Snapshot is the state of the user service as persisted by the store
+type Snapshot struct {
+	Users       []*libmodel.User           `json:"users"`
+	Permissions []*libmodel.UserPermission `json:"permissions"`
+	APIKeys     []*APIKey                  `json:"api_keys"`
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"errors"
+	"time"
+)
+
+// Errors returned by the user service
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrUserExists       = errors.New("user already exists")
+	ErrInvalidUser      = errors.New("invalid user")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrAPIKeyNotFound   = errors.New("api key not found")
+	ErrInvalidAPIKey    = errors.New("invalid api key")
+	ErrInvalidKeyTTL    = errors.New("invalid api key lifetime")
+	ErrTooManyAPIKeys   = errors.New("too many api keys")
+	ErrInvalidKeyFormat = errors.New("malformed api key")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// UserService defines the interface for managing users, their roles and permissions, and their API keys
+type UserService interface {
+	// CreateUser creates a new user with the user role in a tenant, which may be empty
+	CreateUser(ctx context.Context, username, email, tenantID string) (*libmodel.User, error)
+
+	// GetUser retrieves a user by their ID
+	GetUser(ctx context.Context, id string) (*libmodel.User, error)
+
+	// UpdateUser updates the username, email and tenant of a user
+	UpdateUser(ctx context.Context, user *libmodel.User) (*libmodel.User, error)
+
+	// DeleteUser removes a user together with their permissions and API keys
+	DeleteUser(ctx context.Context, id string) error
+
+	// ListUsers retrieves all users, ordered by username
+	ListUsers(ctx context.Context) ([]*libmodel.User, error)
+
+	// AssignRole adds a role to a user. Only admins and other services may assign roles.
+	AssignRole(ctx context.Context, userID, role string) error
+
+	// GrantPermission grants a user a permission on a resource. Only admins and
+	// other services may grant permissions.
+	GrantPermission(ctx context.Context, userID, resource, permission string) (*libmodel.UserPermission, error)
+
+	// CreateAPIKey issues an API key for a user, which expires after ttl if it
+	// is positive. It returns the secret key, which is not stored.
+	CreateAPIKey(ctx context.Context, userID, name string, ttl time.Duration) (*libmodel.APIKey, string, error)
+
+	// ListAPIKeys lists the API keys of a user, newest first
+	ListAPIKeys(ctx context.Context, userID string) ([]*libmodel.APIKey, error)
+
+	// RevokeAPIKey revokes an API key of a user
+	RevokeAPIKey(ctx context.Context, userID, keyID string) error
+
+	// VerifyAPIKey returns the user an active API key belongs to, and the key.
+	// It returns ErrInvalidAPIKey for unknown, revoked or expired keys.
+	VerifyAPIKey(ctx context.Context, key string) (*libmodel.User, *libmodel.APIKey, error)
+}
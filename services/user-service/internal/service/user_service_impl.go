@@ -0,0 +1,500 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/identity"
+	"distributed-analyzer/libs/store"
+	"distributed-analyzer/services/user-service/internal/model"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiKeyPrefix starts every API key, so keys are told apart from JWTs and found by secret scanners
+const apiKeyPrefix = "dak_"
+
+// lastUsedResolution is how often the last use of a key is persisted
+const lastUsedResolution = time.Minute
+
+// validRoles holds the roles users can be assigned
+var validRoles = []string{libmodel.RoleAdmin, libmodel.RoleUser, libmodel.RoleWorker}
+
+// UserServiceImpl implements the UserService interface. The state is kept in
+// memory and saved to the store after every change.
+type UserServiceImpl struct {
+	store store.SnapshotStore[model.Snapshot]
+
+	users       map[string]*libmodel.User
+	permissions map[string][]*libmodel.UserPermission
+	apiKeys     map[string]*model.APIKey
+
+	maxKeyTTL  time.Duration
+	maxPerUser int
+
+	mu sync.RWMutex
+}
+
+var _ UserService = (*UserServiceImpl)(nil)
+
+// NewUserServiceImpl creates a new UserServiceImpl with the state saved in store.
+// New API keys live at most maxKeyTTL if it is positive; a user holds at most maxPerUser active keys.
+func NewUserServiceImpl(userStore store.SnapshotStore[model.Snapshot], maxKeyTTL time.Duration, maxPerUser int) (*UserServiceImpl, error) {
+	snapshot, err := userStore.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	s := &UserServiceImpl{
+		store:      userStore,
+		maxKeyTTL:  maxKeyTTL,
+		maxPerUser: maxPerUser,
+	}
+	s.restore(snapshot)
+	return s, nil
+}
+
+// CreateUser creates a new user with the user role in a tenant, which may be empty
+func (s *UserServiceImpl) CreateUser(ctx context.Context, username, email, tenantID string) (*libmodel.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidUser)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findUsername(username) != nil {
+		return nil, ErrUserExists
+	}
+
+	user := &libmodel.User{
+		ID:        store.NewID(),
+		Username:  username,
+		Email:     strings.TrimSpace(email),
+		TenantID:  strings.TrimSpace(tenantID),
+		Roles:     []string{libmodel.RoleUser},
+		CreatedAt: time.Now(),
+	}
+
+	err := s.update(func() error {
+		s.users[user.ID] = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyUser(user), nil
+}
+
+// GetUser retrieves a user by their ID
+func (s *UserServiceImpl) GetUser(ctx context.Context, id string) (*libmodel.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return copyUser(user), nil
+}
+
+// UpdateUser updates the username, email and tenant of a user
+func (s *UserServiceImpl) UpdateUser(ctx context.Context, update *libmodel.User) (*libmodel.User, error) {
+	username := strings.TrimSpace(update.Username)
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidUser)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[update.ID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	if other := s.findUsername(username); other != nil && other.ID != user.ID {
+		return nil, ErrUserExists
+	}
+
+	err := s.update(func() error {
+		user.Username = username
+		user.Email = strings.TrimSpace(update.Email)
+		user.TenantID = strings.TrimSpace(update.TenantID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyUser(user), nil
+}
+
+// DeleteUser removes a user together with their permissions and API keys
+func (s *UserServiceImpl) DeleteUser(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[id]; !ok {
+		return ErrUserNotFound
+	}
+
+	return s.update(func() error {
+		delete(s.users, id)
+		delete(s.permissions, id)
+		for keyID, key := range s.apiKeys {
+			if key.UserID == id {
+				delete(s.apiKeys, keyID)
+			}
+		}
+		return nil
+	})
+}
+
+// ListUsers retrieves all users, ordered by username
+func (s *UserServiceImpl) ListUsers(ctx context.Context) ([]*libmodel.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*libmodel.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, copyUser(user))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return users, nil
+}
+
+// AssignRole adds a role to a user
+func (s *UserServiceImpl) AssignRole(ctx context.Context, userID, role string) error {
+	if !canGrant(ctx) {
+		return ErrPermissionDenied
+	}
+	if !slices.Contains(validRoles, role) {
+		return fmt.Errorf("%w: %q", ErrInvalidRole, role)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if slices.Contains(user.Roles, role) {
+		return nil
+	}
+
+	return s.update(func() error {
+		user.Roles = append(user.Roles, role)
+		sort.Strings(user.Roles)
+		return nil
+	})
+}
+
+// GrantPermission grants a user a permission on a resource
+func (s *UserServiceImpl) GrantPermission(ctx context.Context, userID, resource, permission string) (*libmodel.UserPermission, error) {
+	if !canGrant(ctx) {
+		return nil, ErrPermissionDenied
+	}
+	if resource == "" || permission == "" {
+		return nil, fmt.Errorf("%w: resource and permission are required", ErrInvalidUser)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+	for _, granted := range s.permissions[userID] {
+		if granted.Resource == resource && granted.Permission == permission {
+			copied := *granted
+			return &copied, nil
+		}
+	}
+
+	granted := &libmodel.UserPermission{
+		UserID:     userID,
+		Resource:   resource,
+		Permission: permission,
+		CreatedAt:  time.Now(),
+	}
+	err := s.update(func() error {
+		s.permissions[userID] = append(s.permissions[userID], granted)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *granted
+	return &copied, nil
+}
+
+// CreateAPIKey issues an API key for a user. The key reads dak_<id>_<secret>;
+// only a hash of the secret is stored.
+func (s *UserServiceImpl) CreateAPIKey(ctx context.Context, userID, name string, ttl time.Duration) (*libmodel.APIKey, string, error) {
+	if ttl < 0 {
+		return nil, "", ErrInvalidKeyTTL
+	}
+	if s.maxKeyTTL > 0 && (ttl == 0 || ttl > s.maxKeyTTL) {
+		return nil, "", fmt.Errorf("%w: keys must expire within %s", ErrInvalidKeyTTL, s.maxKeyTTL)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, "", ErrUserNotFound
+	}
+
+	now := time.Now()
+	active := 0
+	for _, key := range s.apiKeys {
+		if key.UserID == userID && keyActive(key, now) {
+			active++
+		}
+	}
+	if s.maxPerUser > 0 && active >= s.maxPerUser {
+		return nil, "", fmt.Errorf("%w: at most %d active keys per user", ErrTooManyAPIKeys, s.maxPerUser)
+	}
+
+	key := &model.APIKey{
+		APIKey: libmodel.APIKey{
+			ID:        store.NewID(),
+			UserID:    userID,
+			Name:      strings.TrimSpace(name),
+			CreatedAt: now,
+		},
+		Hash: hashSecret(encodedSecret),
+	}
+	if ttl > 0 {
+		key.ExpiresAt = now.Add(ttl)
+	}
+
+	err := s.update(func() error {
+		s.apiKeys[key.ID] = key
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	copied := key.APIKey
+	return &copied, apiKeyPrefix + key.ID + "_" + encodedSecret, nil
+}
+
+// ListAPIKeys lists the API keys of a user, newest first
+func (s *UserServiceImpl) ListAPIKeys(ctx context.Context, userID string) ([]*libmodel.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	keys := make([]*libmodel.APIKey, 0)
+	for _, key := range s.apiKeys {
+		if key.UserID == userID {
+			copied := key.APIKey
+			keys = append(keys, &copied)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of a user. Revoking a revoked key has no effect.
+func (s *UserServiceImpl) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.apiKeys[keyID]
+	if !ok || key.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+	if !key.RevokedAt.IsZero() {
+		return nil
+	}
+
+	return s.update(func() error {
+		key.RevokedAt = time.Now()
+		return nil
+	})
+}
+
+// VerifyAPIKey returns the user an active API key belongs to, and the key
+func (s *UserServiceImpl) VerifyAPIKey(ctx context.Context, apiKey string) (*libmodel.User, *libmodel.APIKey, error) {
+	rest, ok := strings.CutPrefix(apiKey, apiKeyPrefix)
+	if !ok {
+		return nil, nil, ErrInvalidKeyFormat
+	}
+	keyID, secret, ok := strings.Cut(rest, "_")
+	if !ok || keyID == "" || secret == "" {
+		return nil, nil, ErrInvalidKeyFormat
+	}
+
+	now := time.Now()
+	user, key, err := s.verifyAPIKey(keyID, secret, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Recording every use would rewrite the store on every request
+	if now.Sub(key.LastUsedAt) >= lastUsedResolution {
+		s.recordUse(keyID, now)
+		key.LastUsedAt = now
+	}
+
+	return user, key, nil
+}
+
+// verifyAPIKey checks the secret of a key under the read lock and returns
+// copies of the key and the user it belongs to
+func (s *UserServiceImpl) verifyAPIKey(keyID, secret string, now time.Time) (*libmodel.User, *libmodel.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.apiKeys[keyID]
+	if !ok || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashSecret(secret))) != 1 || !keyActive(key, now) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, ok := s.users[key.UserID]
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	copied := key.APIKey
+	return copyUser(user), &copied, nil
+}
+
+// recordUse persists the last use of a key unless a concurrent request
+// already did, or the key was removed in the meantime
+func (s *UserServiceImpl) recordUse(keyID string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.apiKeys[keyID]
+	if !ok || now.Sub(key.LastUsedAt) < lastUsedResolution {
+		return
+	}
+
+	err := s.update(func() error {
+		key.LastUsedAt = now
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to record use of API key %s: %v", keyID, err)
+	}
+}
+
+// update applies a change and saves the new state. If saving fails, the
+// change is undone. The caller must hold the write lock.
+func (s *UserServiceImpl) update(change func() error) error {
+	if err := store.Update(s.store, s.snapshot, s.restore, change); err != nil {
+		return fmt.Errorf("failed to save users: %w", err)
+	}
+	return nil
+}
+
+// snapshot copies the state. The caller must hold the lock.
+func (s *UserServiceImpl) snapshot() *model.Snapshot {
+	snapshot := &model.Snapshot{
+		Users:       make([]*libmodel.User, 0, len(s.users)),
+		Permissions: make([]*libmodel.UserPermission, 0),
+		APIKeys:     make([]*model.APIKey, 0, len(s.apiKeys)),
+	}
+	for _, user := range s.users {
+		snapshot.Users = append(snapshot.Users, copyUser(user))
+	}
+	for _, permissions := range s.permissions {
+		for _, permission := range permissions {
+			copied := *permission
+			snapshot.Permissions = append(snapshot.Permissions, &copied)
+		}
+	}
+	for _, key := range s.apiKeys {
+		copied := *key
+		snapshot.APIKeys = append(snapshot.APIKeys, &copied)
+	}
+
+	sort.Slice(snapshot.Users, func(i, j int) bool { return snapshot.Users[i].ID < snapshot.Users[j].ID })
+	sort.Slice(snapshot.APIKeys, func(i, j int) bool { return snapshot.APIKeys[i].ID < snapshot.APIKeys[j].ID })
+	sort.SliceStable(snapshot.Permissions, func(i, j int) bool { return snapshot.Permissions[i].UserID < snapshot.Permissions[j].UserID })
+	return snapshot
+}
+
+// restore replaces the state with a snapshot. The caller must hold the write lock.
+func (s *UserServiceImpl) restore(snapshot *model.Snapshot) {
+	s.users = make(map[string]*libmodel.User, len(snapshot.Users))
+	s.permissions = make(map[string][]*libmodel.UserPermission)
+	s.apiKeys = make(map[string]*model.APIKey, len(snapshot.APIKeys))
+
+	for _, user := range snapshot.Users {
+		s.users[user.ID] = user
+	}
+	for _, permission := range snapshot.Permissions {
+		s.permissions[permission.UserID] = append(s.permissions[permission.UserID], permission)
+	}
+	for _, key := range snapshot.APIKeys {
+		s.apiKeys[key.ID] = key
+	}
+}
+
+// findUsername returns the user with a username, if any. The caller must hold the lock.
+func (s *UserServiceImpl) findUsername(username string) *libmodel.User {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Username, username) {
+			return user
+		}
+	}
+	return nil
+}
+
+// keyActive reports whether a key is neither revoked nor expired
+func keyActive(key *model.APIKey, now time.Time) bool {
+	return key.RevokedAt.IsZero() && (key.ExpiresAt.IsZero() || now.Before(key.ExpiresAt))
+}
+
+// hashSecret returns the hex encoded SHA-256 of the secret of a key. Secrets
+// are random, so a fast hash suffices where passwords would need a slow one.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// copyUser copies a user so it can be used outside the lock
+func copyUser(user *libmodel.User) *libmodel.User {
+	copied := *user
+	copied.Roles = append([]string(nil), user.Roles...)
+	return &copied
+}
+
+// canGrant reports whether the caller may grant roles and permissions: admins,
+// and other services, whose calls carry no identity
+func canGrant(ctx context.Context) bool {
+	id, ok := identity.FromContext(ctx)
+	return !ok || id.IsAdmin()
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	libmodel "distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/identity"
+	"distributed-analyzer/libs/store/storetest"
+	"distributed-analyzer/services/user-service/internal/model"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, path string) *UserServiceImpl {
+	t.Helper()
+	s, err := NewUserServiceImpl(storetest.SnapshotFile[model.Snapshot](t, path), 0, 2)
+	if err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	return s
+}
+
+func TestAPIKeysPersist(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "users.json")
+	s := newTestService(t, path)
+
+	user, err := s.CreateUser(ctx, "alice", "alice@example.com", "team-a")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if _, err := s.CreateUser(ctx, "Alice", "", ""); !errors.Is(err, ErrUserExists) {
+		t.Errorf("Expected a duplicate username to be rejected, got %v", err)
+	}
+	if err := s.AssignRole(ctx, user.ID, libmodel.RoleAdmin); err != nil {
+		t.Fatalf("Failed to assign role: %v", err)
+	}
+
+	apiKey, key, err := s.CreateAPIKey(ctx, user.ID, "ci", time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+	if !strings.HasPrefix(key, apiKeyPrefix+apiKey.ID+"_") {
+		t.Errorf("Unexpected key format %q", key)
+	}
+
+	// A restarted service reads the keys back from the store
+	restarted := newTestService(t, path)
+	verified, verifiedKey, err := restarted.VerifyAPIKey(ctx, key)
+	if err != nil {
+		t.Fatalf("Failed to verify api key: %v", err)
+	}
+	if verified.ID != user.ID || verified.TenantID != "team-a" || verifiedKey.ID != apiKey.ID || len(verified.Roles) != 2 {
+		t.Errorf("Unexpected user %+v for key %+v", verified, verifiedKey)
+	}
+
+	if _, _, err := restarted.VerifyAPIKey(ctx, key+"x"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected a wrong secret to be rejected, got %v", err)
+	}
+	if _, _, err := restarted.VerifyAPIKey(ctx, "not-a-key"); !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Errorf("Expected a malformed key to be rejected, got %v", err)
+	}
+
+	if err := restarted.RevokeAPIKey(ctx, "someone-else", apiKey.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected keys of other users not to be revoked, got %v", err)
+	}
+	if err := restarted.RevokeAPIKey(ctx, user.ID, apiKey.ID); err != nil {
+		t.Fatalf("Failed to revoke api key: %v", err)
+	}
+	if _, _, err := newTestService(t, path).VerifyAPIKey(ctx, key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected a revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestAPIKeyLimits(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, filepath.Join(t.TempDir(), "users.json"))
+
+	user, err := s.CreateUser(ctx, "bob", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := s.CreateAPIKey(ctx, user.ID, "", 0); err != nil {
+			t.Fatalf("Failed to create api key: %v", err)
+		}
+	}
+	if _, _, err := s.CreateAPIKey(ctx, user.ID, "", 0); !errors.Is(err, ErrTooManyAPIKeys) {
+		t.Errorf("Expected the key limit to be enforced, got %v", err)
+	}
+
+	expiring, key, err := newTestService(t, filepath.Join(t.TempDir(), "users.json")).CreateAPIKey(ctx, user.ID, "", time.Hour)
+	if !errors.Is(err, ErrUserNotFound) || expiring != nil || key != "" {
+		t.Errorf("Expected keys of unknown users to be rejected, got %v", err)
+	}
+
+	s.maxKeyTTL = time.Hour
+	if _, _, err := s.CreateAPIKey(ctx, user.ID, "", 0); !errors.Is(err, ErrInvalidKeyTTL) {
+		t.Errorf("Expected keys without expiry to be rejected with a max TTL, got %v", err)
+	}
+}
+
+func TestAssignRoleRequiresAdmin(t *testing.T) {
+	s := newTestService(t, filepath.Join(t.TempDir(), "users.json"))
+	user, err := s.CreateUser(context.Background(), "mallory", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	self := identity.NewContext(context.Background(), &identity.Identity{UserID: user.ID, Roles: []string{libmodel.RoleUser}})
+	if err := s.AssignRole(self, user.ID, libmodel.RoleAdmin); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("Expected a user not to make themselves admin, got %v", err)
+	}
+
+	admin := identity.NewContext(context.Background(), &identity.Identity{UserID: "root", Roles: []string{libmodel.RoleAdmin}})
+	if err := s.AssignRole(admin, user.ID, libmodel.RoleAdmin); err != nil {
+		t.Errorf("Expected an admin to assign roles, got %v", err)
+	}
+}
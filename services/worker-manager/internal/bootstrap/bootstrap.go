@@ -3,7 +3,10 @@ package bootstrap
 import (
 	app "distributed-analyzer/libs/application"
 	grpcApp "distributed-analyzer/libs/application/grpc"
+	kafkaApp "distributed-analyzer/libs/application/kafka"
+	"distributed-analyzer/libs/kafka"
 	"distributed-analyzer/libs/network/logging"
+	"distributed-analyzer/libs/network/ratelimit"
 	"distributed-analyzer/libs/proto/worker"
 	"distributed-analyzer/services/worker-manager/internal/config"
 	workerGrpc "distributed-analyzer/services/worker-manager/internal/grpc"
@@ -22,29 +25,33 @@ func StartApplication(cfg *config.Config) {
 		log.Fatalf("Failed to create worker manager: %v", err)
 	}
 
+	// Initialize the Kafka producer publishing to the audit log
+	kafkaProducer := kafka.NewProducer(cfg.Kafka.Brokers)
+	auditPublisher := kafka.NewAuditPublisher(kafkaProducer, "worker-manager")
+
 	// Initialize gRPC server
-	grpcComponent := initGrpc(cfg, workerManager)
+	grpcComponent := initGrpc(cfg, workerManager, auditPublisher)
 
 	// Create and configure the application runner
-	runner := app.NewApplicationRunner(grpcComponent)
+	runner := app.NewApplicationRunner(grpcComponent, kafkaApp.NewKafkaProducerComponent(kafkaProducer))
 
 	runner.DefaultStart()
 }
 
 // initGrpc initializes the gRPC component with the configured server.
-func initGrpc(cfg *config.Config, workerManager *service.WorkerManager) *grpcApp.Component {
-	grpcServer := registerGrpcServer(workerManager)
+func initGrpc(cfg *config.Config, workerManager *service.WorkerManager, auditPublisher *kafka.AuditPublisher) *grpcApp.Component {
+	grpcServer := registerGrpcServer(workerManager, auditPublisher, grpcApp.NewServerLimiter(cfg.GrpcLimit))
 	return grpcApp.NewGrpcComponent(grpcServer, &cfg.ServerConfig)
 }
 
-// registerGrpcServer creates a new gRPC server and registers the worker manager service.
+// registerGrpcServer creates a new gRPC server limiting its callers and registers the worker manager service.
 // It also enables server reflection for debugging purposes.
-func registerGrpcServer(workerManager *service.WorkerManager) *stdgrpc.Server {
+func registerGrpcServer(workerManager *service.WorkerManager, auditPublisher *kafka.AuditPublisher, limiter *ratelimit.GRPCLimiter) *stdgrpc.Server {
 	// Create a server with appropriate options
-	grpcServer := stdgrpc.NewServer(stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor()))
+	grpcServer := stdgrpc.NewServer(stdgrpc.ChainUnaryInterceptor(logging.ServerInterceptor(), limiter.UnaryServerInterceptor()))
 
 	// Create a worker manager server
-	workerManagerServer := workerGrpc.NewWorkerManagerServer(workerManager)
+	workerManagerServer := workerGrpc.NewWorkerManagerServer(workerManager, auditPublisher)
 
 	// Register services
 	worker.RegisterWorkerManagerServiceServer(grpcServer, workerManagerServer)
@@ -6,11 +6,17 @@ import configloader "distributed-analyzer/libs/config"
 type Config struct {
 	configloader.ServerConfig `yaml:",inline"`
 
+	// Kafka settings, where changes of workers are published to the audit log
+	Kafka configloader.KafkaConfig `yaml:"kafka"`
+
 	// Worker management settings
 	WorkerManagement WorkerManagementConfig `yaml:"worker_management"`
 
 	// Graceful shutdown timeout
 	ShutdownTimeout string `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+
+	// Limits of gRPC callers
+	GrpcLimit configloader.GrpcLimitConfig `yaml:"grpc_limit"`
 }
 
 // WorkerManagementConfig holds worker management configuration
@@ -2,11 +2,18 @@ package grpc
 
 import (
 	"context"
+	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/model"
 	"distributed-analyzer/libs/proto/worker"
 	"distributed-analyzer/services/worker-manager/internal/service"
+	"errors"
 	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"log"
+	"sort"
+	"strings"
 )
 
 // WorkerManagerServer implements the WorkerManagerServiceServer interface
@@ -15,12 +22,16 @@ type WorkerManagerServer struct {
 
 	// workerManager is the worker manager service
 	workerManager *service.WorkerManager
+
+	// audit publishes the changes of workers to the audit log
+	audit *kafka.AuditPublisher
 }
 
 // NewWorkerManagerServer creates a new worker manager server
-func NewWorkerManagerServer(workerManager *service.WorkerManager) *WorkerManagerServer {
+func NewWorkerManagerServer(workerManager *service.WorkerManager, audit *kafka.AuditPublisher) *WorkerManagerServer {
 	return &WorkerManagerServer{
 		workerManager: workerManager,
+		audit:         audit,
 	}
 }
 
@@ -31,7 +42,7 @@ func (s *WorkerManagerServer) RegisterWorker(ctx context.Context, req *worker.Re
 	// Extract capabilities from the request
 	capabilities := make([]string, 0, len(req.GetCapabilities()))
 	for _, capability := range req.GetCapabilities() {
-		capabilities = append(capabilities, capability.GetName())
+		capabilities = append(capabilities, service.CapabilityKey(capability.GetName(), capability.GetValue()))
 	}
 
 	// Register the worker
@@ -39,6 +50,7 @@ func (s *WorkerManagerServer) RegisterWorker(ctx context.Context, req *worker.Re
 	if err != nil {
 		return nil, fmt.Errorf("failed to register worker: %w", err)
 	}
+	s.publishAudit(ctx, model.AuditActionCreate, w.ID, map[string]string{"capabilities": strings.Join(capabilities, ",")})
 
 	// Convert the worker to a proto worker
 	protoWorker := convertWorkerToProto(w)
@@ -78,12 +90,41 @@ func (s *WorkerManagerServer) UpdateWorkerStatus(ctx context.Context, req *worke
 	if err != nil {
 		return nil, fmt.Errorf("failed to update worker status: %w", err)
 	}
+	s.publishAudit(ctx, model.AuditActionUpdate, req.GetId(), map[string]string{"status": req.GetStatus()})
 
 	return &worker.UpdateWorkerStatusResponse{
 		Success: true,
 	}, nil
 }
 
+// publishAudit publishes a change of a worker to the audit log. Failures are
+// logged, since the change already happened.
+func (s *WorkerManagerServer) publishAudit(ctx context.Context, action, workerID string, details map[string]string) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.Publish(ctx, action, "worker", workerID, details); err != nil {
+		log.Printf("Failed to publish audit event for worker %s: %v", workerID, err)
+	}
+}
+
+// ReportWorkerLoad records the load a worker reported
+func (s *WorkerManagerServer) ReportWorkerLoad(ctx context.Context, req *worker.ReportWorkerLoadRequest) (*worker.ReportWorkerLoadResponse, error) {
+	// Record the load
+	err := s.workerManager.ReportWorkerLoad(req.GetId(), int(req.GetRunningTasks()), int(req.GetQueuedTasks()), int(req.GetCapacity()), req.GetWarmRepositories())
+	if errors.Is(err, service.ErrWorkerNotFound) {
+		// Workers register again when the manager forgot them, e.g. after it restarted
+		return nil, status.Errorf(codes.NotFound, "failed to report worker load: %v", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to report worker load: %w", err)
+	}
+
+	return &worker.ReportWorkerLoadResponse{
+		Success: true,
+	}, nil
+}
+
 // ListWorkers retrieves all workers
 func (s *WorkerManagerServer) ListWorkers(ctx context.Context, req *worker.ListWorkersRequest) (*worker.ListWorkersResponse, error) {
 	log.Printf("Listing workers")
@@ -106,12 +147,6 @@ func (s *WorkerManagerServer) ListWorkers(ctx context.Context, req *worker.ListW
 func (s *WorkerManagerServer) FindAvailableWorkers(ctx context.Context, req *worker.FindAvailableWorkersRequest) (*worker.ListWorkersResponse, error) {
 	log.Printf("Finding available workers")
 
-	// Extract capabilities from the request
-	capabilities := make([]string, 0, len(req.GetCapabilities()))
-	for _, capability := range req.GetCapabilities() {
-		capabilities = append(capabilities, capability.GetName())
-	}
-
 	// Get active workers
 	activeWorkers := s.workerManager.GetWorkersByStatus(service.WorkerStatusActive)
 
@@ -119,8 +154,8 @@ func (s *WorkerManagerServer) FindAvailableWorkers(ctx context.Context, req *wor
 	availableWorkers := make([]*service.Worker, 0)
 	for _, w := range activeWorkers {
 		hasAllCapabilities := true
-		for _, capability := range capabilities {
-			if !w.HasCapability(capability) {
+		for _, capability := range req.GetCapabilities() {
+			if !w.HasCapabilityValue(capability.GetName(), capability.GetValue()) {
 				hasAllCapabilities = false
 				break
 			}
@@ -130,6 +165,11 @@ func (s *WorkerManagerServer) FindAvailableWorkers(ctx context.Context, req *wor
 		}
 	}
 
+	// Prefer workers with the shortest local queue
+	sort.SliceStable(availableWorkers, func(i, j int) bool {
+		return availableWorkers[i].QueuedTasks < availableWorkers[j].QueuedTasks
+	})
+
 	// Convert the workers to proto workers
 	protoWorkers := make([]*worker.Worker, 0, len(availableWorkers))
 	for _, w := range availableWorkers {
@@ -146,9 +186,10 @@ func convertWorkerToProto(w *service.Worker) *worker.Worker {
 	// Convert capabilities to proto capabilities
 	capabilities := make([]*worker.Capability, 0, len(w.Capabilities))
 	for _, capability := range w.Capabilities {
+		name, value, _ := strings.Cut(capability, "=")
 		capabilities = append(capabilities, &worker.Capability{
-			Name:  capability,
-			Value: "",
+			Name:  name,
+			Value: value,
 		})
 	}
 
@@ -160,5 +201,10 @@ func convertWorkerToProto(w *service.Worker) *worker.Worker {
 		Capabilities: capabilities,
 		Resources:    nil, // @TODO We don't track resources in our implementation
 		LastSeen:     timestamppb.New(w.LastHeartbeat),
+		RunningTasks: int32(w.RunningTasks),
+		QueuedTasks:  int32(w.QueuedTasks),
+		Capacity:     int32(w.Capacity),
+
+		WarmRepositories: w.WarmRepositories,
 	}
 }
@@ -33,7 +33,8 @@ type Worker struct {
 	// Status is the current status of the worker
 	Status WorkerStatus
 
-	// Capabilities is a list of capabilities that the worker supports
+	// Capabilities is a list of capabilities that the worker supports,
+	// either a bare name or name=value, e.g. go=1.22
 	Capabilities []string
 
 	// LastHeartbeat is the timestamp of the last heartbeat received from the worker
@@ -45,6 +46,18 @@ type Worker struct {
 	// CurrentLoad is the current load of the worker (0-100)
 	CurrentLoad int
 
+	// RunningTasks is the number of tasks the worker is running
+	RunningTasks int
+
+	// QueuedTasks is the number of tasks waiting in the worker's local queue
+	QueuedTasks int
+
+	// Capacity is the number of tasks the worker runs concurrently
+	Capacity int
+
+	// WarmRepositories lists the repositories the worker's go cache is warm for
+	WarmRepositories []string
+
 	// Error is the last error reported by the worker
 	Error string
 
@@ -64,6 +77,16 @@ func NewWorker(id, address string, capabilities []string) *Worker {
 	}
 }
 
+// Reregister replaces the address and capabilities of a worker that registered again
+func (w *Worker) Reregister(address string, capabilities []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.Address = address
+	w.Capabilities = capabilities
+	w.Status = WorkerStatusActive
+	w.LastHeartbeat = time.Now()
+}
+
 // UpdateStatus updates the status of the worker
 func (w *Worker) UpdateStatus(status WorkerStatus) {
 	w.mu.Lock()
@@ -90,6 +113,26 @@ func (w *Worker) UpdateLoad(load int) {
 	w.CurrentLoad = load
 }
 
+// UpdateQueue updates the running and queued tasks of the worker and derives its load
+func (w *Worker) UpdateQueue(running, queued, capacity int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.RunningTasks = running
+	w.QueuedTasks = queued
+	w.Capacity = capacity
+	w.CurrentLoad = 0
+	if capacity > 0 {
+		w.CurrentLoad = min(running*100/capacity, 100)
+	}
+}
+
+// UpdateWarmRepositories updates the repositories the worker's go cache is warm for
+func (w *Worker) UpdateWarmRepositories(repositories []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.WarmRepositories = repositories
+}
+
 // SetError sets the error message and updates the status to error
 func (w *Worker) SetError(err string) {
 	w.mu.Lock()
@@ -131,3 +174,25 @@ func (w *Worker) HasCapability(capability string) bool {
 	}
 	return false
 }
+
+// CapabilityKey returns how a capability with an optional value is stored
+func CapabilityKey(name, value string) string {
+	if value == "" {
+		return name
+	}
+	return name + "=" + value
+}
+
+// HasCapabilityValue returns true if the worker has the specified capability
+// with the given value. A capability the worker advertises without a value
+// matches any value.
+func (w *Worker) HasCapabilityValue(name, value string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, cap := range w.Capabilities {
+		if cap == name || (value != "" && cap == CapabilityKey(name, value)) {
+			return true
+		}
+	}
+	return false
+}
@@ -12,13 +12,8 @@ import (
 
 const WorkerServiceName = "worker"
 
-var (
-	// ErrWorkerNotFound is returned when a worker is not found in the registry
-	ErrWorkerNotFound = errors.New("worker not found")
-
-	// ErrWorkerAlreadyRegistered is returned when a worker with the same ID is already registered
-	ErrWorkerAlreadyRegistered = errors.New("worker already registered")
-)
+// ErrWorkerNotFound is returned when a worker is not found in the registry
+var ErrWorkerNotFound = errors.New("worker not found")
 
 // WorkerRegistry is responsible for managing worker registrations
 type WorkerRegistry struct {
@@ -90,14 +85,15 @@ func (r *WorkerRegistry) RegisterServices(services []*discovery.Service) {
 	}
 }
 
-// Register registers a new worker
+// Register registers a new worker. A worker registering again, e.g. after a
+// restart, replaces its address and capabilities and counts as active.
 func (r *WorkerRegistry) Register(id, address string, capabilities []string) (*Worker, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Check if the worker is already registered
-	if _, exists := r.workers[id]; exists {
-		return nil, ErrWorkerAlreadyRegistered
+	if worker, exists := r.workers[id]; exists {
+		worker.Reregister(address, capabilities)
+		return worker, nil
 	}
 
 	// Create a new worker
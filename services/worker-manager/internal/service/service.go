@@ -160,6 +160,24 @@ func (m *WorkerManager) UpdateWorkerLoad(id string, load int) error {
 	return nil
 }
 
+// ReportWorkerLoad records the running and queued tasks a worker reported, and
+// the repositories its go cache is warm for.
+// A report is a sign of life, so it counts as a heartbeat too.
+func (m *WorkerManager) ReportWorkerLoad(id string, running, queued, capacity int, warm []string) error {
+	// Get the worker
+	worker, err := m.registry.Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to get worker: %w", err)
+	}
+
+	// Update the worker's queue and heartbeat
+	worker.UpdateQueue(running, queued, capacity)
+	worker.UpdateWarmRepositories(warm)
+	worker.UpdateHeartbeat()
+
+	return nil
+}
+
 // SetWorkerError sets an error for a worker
 func (m *WorkerManager) SetWorkerError(id string, errorMsg string) error {
 	// Get the worker
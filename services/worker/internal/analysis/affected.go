@@ -0,0 +1,195 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Input keys describing the change an incremental analysis is limited to
+const (
+	InputBaseKey = "base"
+	InputHeadKey = "head"
+	InputDiffKey = "diff"
+)
+
+// Result keys written by AffectedMode
+const (
+	AffectedPackagesKey = "affected.packages"
+	AffectedFilesKey    = "affected.files"
+)
+
+// moduleFiles are files whose change affects every package of the module
+var moduleFiles = map[string]bool{
+	"go.mod":      true,
+	"go.sum":      true,
+	"go.work":     true,
+	"go.work.sum": true,
+}
+
+// AffectedMode computes the packages affected by a change from the import graph
+type AffectedMode struct{}
+
+// Name returns the mode name
+func (m *AffectedMode) Name() string {
+	return "go_affected"
+}
+
+// Run lists the files changed between the base revision and the workspace
+// and every package of the main module that contains or depends on them,
+// including packages whose tests depend on them.
+func (m *AffectedMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	files, err := ws.ChangedFiles(ctx, input[InputBaseKey])
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"list", "-e", "-deps", "-test", "-json"}, buildFlags(input)...)
+	res, err := ws.Go(ctx, append(args, packages(input)...)...)
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("go list: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	listed, err := decodeListedPackages(strings.NewReader(res.Stdout))
+	if err != nil {
+		return nil, err
+	}
+
+	affected := AffectedPackages(listed, ws.Dir, files)
+
+	encodedPackages, err := json.Marshal(affected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode affected packages: %w", err)
+	}
+	encodedFiles, err := json.Marshal(files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode changed files: %w", err)
+	}
+
+	return map[string]string{
+		ResultModeKey:       m.Name(),
+		ResultExitCodeKey:   "0",
+		AffectedPackagesKey: string(encodedPackages),
+		AffectedFilesKey:    string(encodedFiles),
+	}, nil
+}
+
+// ListedPackage is the part of the go list -json output the import graph is built from
+type ListedPackage struct {
+	ImportPath string
+	Dir        string
+	ForTest    string
+	Deps       []string
+	Module     *struct {
+		Main bool
+	}
+}
+
+// decodeListedPackages decodes the stream of JSON objects printed by go list -json
+func decodeListedPackages(r io.Reader) ([]ListedPackage, error) {
+	var listed []ListedPackage
+	decoder := json.NewDecoder(r)
+	for {
+		var pkg ListedPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return listed, nil
+			}
+			return nil, fmt.Errorf("failed to decode go list output: %w", err)
+		}
+		listed = append(listed, pkg)
+	}
+}
+
+// AffectedPackages returns the sorted import paths of the main module packages
+// affected by the changed files, given relative to root. A package is affected
+// when it, one of its dependencies or one of the dependencies of its tests
+// contains a changed file. Changing a module file affects every package.
+func AffectedPackages(listed []ListedPackage, root string, files []string) []string {
+	dirs := make(map[string]string)
+	modulePackages := make(map[string]bool)
+	for _, pkg := range listed {
+		if pkg.Module == nil || !pkg.Module.Main || pkg.ForTest != "" || pkg.Dir == "" || strings.HasSuffix(pkg.ImportPath, ".test") {
+			continue
+		}
+		rel, err := filepath.Rel(root, pkg.Dir)
+		if err != nil {
+			continue
+		}
+		dirs[filepath.ToSlash(rel)] = pkg.ImportPath
+		modulePackages[pkg.ImportPath] = true
+	}
+
+	all := false
+	changed := make(map[string]bool)
+	for _, file := range files {
+		if moduleFiles[path.Base(file)] {
+			all = true
+			break
+		}
+		if pkg, ok := owningPackage(dirs, file); ok {
+			changed[pkg] = true
+		}
+	}
+
+	affected := make(map[string]bool)
+	for _, pkg := range listed {
+		owner := testedPackage(pkg)
+		if !modulePackages[owner] {
+			continue
+		}
+		if all || changed[graphNode(pkg.ImportPath)] {
+			affected[owner] = true
+			continue
+		}
+		for _, dep := range pkg.Deps {
+			if changed[graphNode(dep)] {
+				affected[owner] = true
+				break
+			}
+		}
+	}
+
+	result := make([]string, 0, len(affected))
+	for pkg := range affected {
+		result = append(result, pkg)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// owningPackage returns the package with the deepest directory containing a file
+func owningPackage(dirs map[string]string, file string) (string, bool) {
+	for dir := path.Dir(file); ; dir = path.Dir(dir) {
+		if pkg, ok := dirs[dir]; ok {
+			return pkg, true
+		}
+		if dir == "." || dir == "/" {
+			return "", false
+		}
+	}
+}
+
+// testedPackage returns the package a go list entry belongs to:
+// test variants and test binaries belong to the package under test
+func testedPackage(pkg ListedPackage) string {
+	if pkg.ForTest != "" {
+		return pkg.ForTest
+	}
+	return strings.TrimSuffix(graphNode(pkg.ImportPath), ".test")
+}
+
+// graphNode strips the test variant suffix, e.g. " [p.test]", from an import path
+func graphNode(importPath string) string {
+	node, _, _ := strings.Cut(importPath, " [")
+	return node
+}
@@ -0,0 +1,382 @@
+package analysis
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const raceOutput = `=== RUN   TestCounter
+==================
+WARNING: DATA RACE
+Read at 0x00c000014148 by goroutine 8:
+  example.com/project/counter.(*Counter).Inc()
+      /tmp/worker/task-1-0/counter/counter.go:10 +0x3a
+  example.com/project/counter.TestCounter.func1()
+      /tmp/worker/task-1-0/counter/counter_test.go:15 +0x30
+
+Previous write at 0x00c000014148 by goroutine 7:
+  example.com/project/counter.(*Counter).Inc()
+      /tmp/worker/task-1-0/counter/counter.go:10 +0x4c
+
+Goroutine 8 (running) created at:
+  example.com/project/counter.TestCounter()
+      /tmp/worker/task-1-0/counter/counter_test.go:14 +0x84
+
+Goroutine 7 (finished) created at:
+  example.com/project/counter.TestCounter()
+      /tmp/worker/task-1-0/counter/counter_test.go:14 +0x84
+==================
+    testing.go:1465: race detected during execution of test
+--- FAIL: TestCounter (0.00s)
+FAIL
+`
+
+func TestParseRaceReports(t *testing.T) {
+	reports := ParseRaceReports(raceOutput, "/tmp/worker/task-1-0")
+
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 race report, got %d", len(reports))
+	}
+
+	report := reports[0]
+	if report.Test != "TestCounter" {
+		t.Errorf("Expected test TestCounter, got %q", report.Test)
+	}
+
+	if len(report.Accesses) != 2 || len(report.Goroutines) != 2 {
+		t.Fatalf("Expected 2 accesses and 2 goroutines, got %+v", report)
+	}
+
+	read := report.Accesses[0]
+	if read.Operation != "Read" || read.Goroutine != 8 || len(read.Stack) != 2 {
+		t.Errorf("Unexpected read access %+v", read)
+	}
+
+	frame := read.Stack[0]
+	if frame.Function != "example.com/project/counter.(*Counter).Inc" || frame.File != "counter/counter.go" || frame.Line != 10 {
+		t.Errorf("Unexpected stack frame %+v", frame)
+	}
+
+	if report.Accesses[1].Operation != "Previous write" || report.Goroutines[1].State != "finished" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+const testOutput = `=== RUN   TestEncode
+--- PASS: TestEncode (0.00s)
+=== RUN   TestDecode
+=== RUN   TestDecode/empty
+    codec_test.go:20: unexpected EOF
+--- FAIL: TestDecode (0.00s)
+    --- FAIL: TestDecode/empty (0.00s)
+FAIL
+FAIL	example.com/project/codec	0.012s
+=== RUN   TestSlow
+--- SKIP: TestSlow (0.00s)
+PASS
+ok  	example.com/project/util	0.004s
+`
+
+func TestParseTestResults(t *testing.T) {
+	results := ParseTestResults(testOutput)
+
+	expected := map[string]string{
+		"example.com/project/codec.TestEncode":       TestOutcomePass,
+		"example.com/project/codec.TestDecode":       TestOutcomeFail,
+		"example.com/project/codec.TestDecode/empty": TestOutcomeFail,
+		"example.com/project/util.TestSlow":          TestOutcomeSkip,
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %v", len(expected), results)
+	}
+
+	for name, outcome := range expected {
+		if results[name] != outcome {
+			t.Errorf("Expected %s to be %s, got %q", name, outcome, results[name])
+		}
+	}
+}
+
+func TestParsePackageDurations(t *testing.T) {
+	durations := ParsePackageDurations(testOutput)
+	if len(durations) != 2 || durations["example.com/project/codec"] != 0.012 || durations["example.com/project/util"] != 0.004 {
+		t.Errorf("Unexpected package durations %v", durations)
+	}
+}
+
+func TestShardPackages(t *testing.T) {
+	pkgs := []string{"a", "b", "c", "d", "e", "f"}
+	plan := [][]string{{"a", "gone"}, {"b", "c"}}
+
+	got := [][]string{shardPackages(pkgs, plan, 0, 2), shardPackages(pkgs, plan, 1, 2)}
+
+	// d, e and f are not planned, so they are dealt out in order
+	expected := [][]string{{"a", "d", "f"}, {"b", "c", "e"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+const fuzzOutput = `fuzz: elapsed: 0s, gathering baseline coverage: 0/12 completed
+fuzz: elapsed: 1s, execs: 5123 (5120/sec), new interesting: 2 (total: 14)
+--- FAIL: FuzzParse (1.21s)
+    --- FAIL: FuzzParse (0.00s)
+        testing.go:1591: panic: runtime error: index out of range [3] with length 3
+            goroutine 34 [running]:
+            runtime/debug.Stack()
+            	/usr/local/go/src/runtime/debug/stack.go:24 +0x9e
+            testing.tRunner.func1()
+            	/usr/local/go/src/testing/testing.go:1591 +0x1c8
+            example.com/project/parser.Parse({0xc0000a6000, 0x3})
+            	/tmp/worker/task-1-0/parser/parser.go:10 +0x1d
+            example.com/project/parser.FuzzParse.func1(0x0?, {0xc0000a6000, 0x3})
+            	/tmp/worker/task-1-0/parser/parser_test.go:12 +0x2a
+
+    Failing input written to testdata/fuzz/FuzzParse/582528ddfad69eb5
+    To re-run:
+    go test -run=FuzzParse/582528ddfad69eb5
+FAIL
+exit status 1
+FAIL	example.com/project/parser	1.254s
+`
+
+func TestParseFuzzFailure(t *testing.T) {
+	crasher := ParseFuzzFailure(fuzzOutput, "FuzzParse")
+	if crasher == nil {
+		t.Fatal("Expected a crasher")
+	}
+
+	if crasher.Error != "runtime error: index out of range [3] with length 3" {
+		t.Errorf("Unexpected error %q", crasher.Error)
+	}
+
+	if len(crasher.Stack) != 2 {
+		t.Fatalf("Expected 2 frames outside the runtime, got %+v", crasher.Stack)
+	}
+
+	if crasher.Stack[0].Function != "example.com/project/parser.Parse" || crasher.Stack[0].File != "parser.go" || crasher.Stack[0].Line != 10 {
+		t.Errorf("Unexpected frame %+v", crasher.Stack[0])
+	}
+
+	if ParseFuzzFailure(fuzzOutput, "FuzzOther") != nil {
+		t.Error("Expected no crasher for a target that did not fail")
+	}
+}
+
+const affectedListOutput = `{"ImportPath": "example.com/project/util", "Dir": "/ws/util", "Module": {"Main": true}}
+{"ImportPath": "example.com/project/api", "Dir": "/ws/api", "Deps": ["example.com/project/util"], "Module": {"Main": true}}
+{"ImportPath": "example.com/project/store", "Dir": "/ws/store", "Module": {"Main": true}}
+{"ImportPath": "example.com/project/store [example.com/project/store.test]", "Dir": "/ws/store", "ForTest": "example.com/project/store", "Module": {"Main": true}}
+{"ImportPath": "example.com/project/store_test [example.com/project/store.test]", "Dir": "/ws/store", "ForTest": "example.com/project/store", "Deps": ["example.com/project/store [example.com/project/store.test]", "example.com/project/util"], "Module": {"Main": true}}
+{"ImportPath": "example.com/project/cmd", "Dir": "/ws/cmd", "Deps": ["example.com/project/api", "example.com/project/util"], "Module": {"Main": true}}
+{"ImportPath": "strings", "Dir": "/usr/local/go/src/strings"}
+`
+
+func TestAffectedPackages(t *testing.T) {
+	listed, err := decodeListedPackages(strings.NewReader(affectedListOutput))
+	if err != nil {
+		t.Fatalf("Failed to decode go list output: %v", err)
+	}
+
+	affected := AffectedPackages(listed, "/ws", []string{"util/util.go", "README.md"})
+	expected := []string{"example.com/project/api", "example.com/project/cmd", "example.com/project/store", "example.com/project/util"}
+	if !reflect.DeepEqual(affected, expected) {
+		t.Errorf("Expected %v, got %v", expected, affected)
+	}
+
+	affected = AffectedPackages(listed, "/ws", []string{"api/testdata/golden.json"})
+	expected = []string{"example.com/project/api", "example.com/project/cmd"}
+	if !reflect.DeepEqual(affected, expected) {
+		t.Errorf("Expected %v, got %v", expected, affected)
+	}
+
+	if affected := AffectedPackages(listed, "/ws", []string{"go.mod"}); len(affected) != 4 {
+		t.Errorf("Expected a go.mod change to affect all 4 packages, got %v", affected)
+	}
+}
+
+const osvEntryJSON = `{
+  "id": "GO-2024-0001",
+  "aliases": ["CVE-2024-0001"],
+  "summary": "Unbounded allocation in example.com/lib/parse",
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "example.com/lib"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.4.2"}, {"introduced": "1.5.0"}, {"fixed": "1.5.1"}]}],
+    "ecosystem_specific": {"imports": [{"path": "example.com/lib/parse", "symbols": ["Parse", "Decoder.Decode"]}]}
+  }]
+}`
+
+func TestVulnDBMatch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "GO-2024-0001.json"), []byte(osvEntryJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "modules.json"), []byte(`[{"path": "example.com/lib"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := LoadVulnDB(dir)
+	if err != nil {
+		t.Fatalf("Failed to load vulnerability database: %v", err)
+	}
+
+	for version, expected := range map[string]bool{
+		"v1.4.1":                             true,
+		"v1.4.2":                             false,
+		"v1.5.0":                             true,
+		"v1.5.1":                             false,
+		"v1.4.2-0.20240101000000-abcdef0123": true,
+	} {
+		vulns := db.Match([]model.Module{{Path: "example.com/lib", Version: version}}, "")
+		if (len(vulns) == 1) != expected {
+			t.Errorf("Version %s: expected affected=%v, got %+v", version, expected, vulns)
+		}
+	}
+
+	vulns := db.Match([]model.Module{{Path: "example.com/lib", Version: "v1.5.0"}}, "")
+	if vulns[0].FixedVersion != "1.5.1" || vulns[0].Packages[0].Path != "example.com/lib/parse" {
+		t.Errorf("Unexpected vulnerability %+v", vulns[0])
+	}
+
+	use := &packageUse{symbols: map[string]bool{"Decoder": true}, selectors: map[string]bool{"Decode": true}}
+	if reached := reachedSymbols([]string{"Parse", "Decoder.Decode"}, use); !reflect.DeepEqual(reached, []string{"Decoder.Decode"}) {
+		t.Errorf("Expected Decoder.Decode to be reached, got %v", reached)
+	}
+}
+
+func TestDepsModeReloadsChangedVulnDB(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "modules.json"), []byte(`[]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDepsMode(dir)
+	first, err := m.loadVulnDB()
+	if err != nil {
+		t.Fatalf("Failed to load vulnerability database: %v", err)
+	}
+	if again, _ := m.loadVulnDB(); again != first {
+		t.Error("Expected an unchanged database not to be loaded again")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "GO-2024-0001.json"), []byte(osvEntryJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := m.loadVulnDB()
+	if err != nil {
+		t.Fatalf("Failed to reload vulnerability database: %v", err)
+	}
+	if vulns := reloaded.Match([]model.Module{{Path: "example.com/lib", Version: "v1.4.1"}}, ""); len(vulns) != 1 {
+		t.Errorf("Expected the new entry to be loaded, got %+v", vulns)
+	}
+}
+
+func TestClassifyLicense(t *testing.T) {
+	// testdata/licenses holds the full texts of licenses, named by their SPDX identifiers
+	files, err := filepath.Glob(filepath.Join("testdata", "licenses", "*"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Failed to find license texts: %v", err)
+	}
+	for _, file := range files {
+		text, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if license := ClassifyLicense(string(text)); license != filepath.Base(file) {
+			t.Errorf("Expected %s, got %s for the text of %s", filepath.Base(file), license, file)
+		}
+	}
+
+	for text, expected := range map[string]string{
+		"Copyright (C) 2023 Example\n\n                    GNU AFFERO GENERAL PUBLIC LICENSE\n                       Version 3, 19 November 2007": "AGPL-3.0",
+		"Mozilla Public License, version 2.0": "MPL-2.0",
+		"All rights reserved.":                model.UnknownLicense,
+	} {
+		if license := ClassifyLicense(text); license != expected {
+			t.Errorf("Expected %s, got %s for %q", expected, license, text)
+		}
+	}
+
+	if !violatesPolicy("GPL-3.0", nil, []string{"GPL"}) || violatesPolicy("LGPL-2.1", nil, []string{"GPL"}) {
+		t.Error("Expected the GPL family to deny GPL-3.0 but not LGPL-2.1")
+	}
+	if violatesPolicy("BSD-3-Clause", []string{"MIT", "BSD"}, nil) || !violatesPolicy(model.UnknownLicense, []string{"MIT", "BSD"}, nil) {
+		t.Error("Expected the allow list to admit BSD-3-Clause and reject unknown licenses")
+	}
+}
+
+func TestProfileKinds(t *testing.T) {
+	kinds, err := profileKinds(map[string]string{InputProfileKey: "cpu, mem,trace"})
+	if err != nil {
+		t.Fatalf("Failed to read profile kinds: %v", err)
+	}
+	if !reflect.DeepEqual(kinds, []string{model.ProfileCPU, model.ProfileMem, model.ProfileTrace}) {
+		t.Errorf("Unexpected profile kinds %v", kinds)
+	}
+
+	if _, err := profileKinds(map[string]string{InputProfileKey: "cpu,goroutine"}); err == nil {
+		t.Error("Expected an unsupported profile kind to be rejected")
+	}
+}
+
+func TestPrepareWorkspaceRejectsOptions(t *testing.T) {
+	inputs := []map[string]string{
+		{InputRepositoryKey: "--upload-pack=touch /tmp/pwned"},
+		{InputRepositoryKey: "https://example.com/project.git", InputRevisionKey: "--orphan=main"},
+		{InputRepositoryKey: "https://example.com/project.git", InputHeadKey: "-b"},
+	}
+	for _, input := range inputs {
+		_, err := PrepareWorkspace(context.Background(), nil, t.TempDir(), "task-1-0", input)
+		if err == nil || !strings.Contains(err.Error(), "must not start with a dash") {
+			t.Errorf("Expected %v to be rejected, got %v", input, err)
+		}
+	}
+}
+
+func TestCheckRepository(t *testing.T) {
+	tests := []struct {
+		repository string
+		valid      bool
+	}{
+		{"https://example.com/project.git", true},
+		{"ssh://git@example.com/project.git", true},
+		{"git://example.com/project.git", true},
+		{"git@example.com:project.git", true},
+		{"file:///var/lib/worker/task-2-0", false},
+		{"ext::sh -c touch% /tmp/pwned", false},
+		{"/var/lib/worker/task-2-0", false},
+		{"../task-2-0", false},
+		{"./a:b", false},
+	}
+	for _, tt := range tests {
+		err := checkRepository(tt.repository)
+		if (err == nil) != tt.valid {
+			t.Errorf("checkRepository(%q) = %v, expected valid %v", tt.repository, err, tt.valid)
+		}
+	}
+}
+
+func TestTailBufferKeepsTail(t *testing.T) {
+	var b tailBuffer
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for i := 0; i < 3*maxCommandOutputBytes/len(line); i++ {
+		b.Write(line)
+	}
+	b.Write([]byte("FAIL"))
+
+	out := b.String()
+	note := fmt.Sprintf("[%d bytes of output truncated]\n", 2*maxCommandOutputBytes+4)
+	if !strings.HasPrefix(out, note) {
+		t.Errorf("Expected the output to start with %q, got %q", note, out[:64])
+	}
+	if !strings.HasSuffix(out, "\nFAIL") || len(out) != len(note)+maxCommandOutputBytes {
+		t.Errorf("Expected the last %d bytes to be kept, got %d", maxCommandOutputBytes, len(out)-len(note))
+	}
+}
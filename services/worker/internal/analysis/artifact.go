@@ -0,0 +1,58 @@
+package analysis
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Content types of artifacts
+const (
+	ContentTypeBinary = "application/octet-stream"
+	ContentTypeText   = "text/plain; charset=utf-8"
+)
+
+// artifactDir is the directory of the workspace modes write artifacts to.
+// The go command ignores directories starting with a dot.
+const artifactDir = ".artifacts"
+
+// Artifact is a file of the workspace that is uploaded with the result of the subtask
+type Artifact struct {
+	// Name identifies the artifact among those of the subtask, e.g. bin/server
+	Name string
+
+	ContentType string
+
+	// Path is the absolute path of the file
+	Path string
+}
+
+// ArtifactDir returns the directory modes write artifacts to, creating it if needed
+func (w *Workspace) ArtifactDir() (string, error) {
+	dir := w.Path(artifactDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// AddArtifact records a file of the workspace as an artifact of the subtask
+func (w *Workspace) AddArtifact(name, contentType, path string) {
+	w.Artifacts = append(w.Artifacts, Artifact{Name: name, ContentType: contentType, Path: path})
+}
+
+// addArtifactDir records every regular file below dir as an artifact, named
+// by prefix followed by its path relative to dir
+func (w *Workspace) addArtifactDir(prefix, contentType, dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		w.AddArtifact(prefix+filepath.ToSlash(rel), contentType, path)
+		return nil
+	})
+}
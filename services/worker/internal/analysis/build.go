@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// BuildMode compiles the packages of the workspace
+type BuildMode struct{}
+
+// Name returns the mode name
+func (m *BuildMode) Name() string {
+	return "go_build"
+}
+
+// Run executes go build and keeps the binaries of the main packages as artifacts
+func (m *BuildMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	args := append([]string{"build"}, buildFlags(input)...)
+	args = append(args, packages(input)...)
+
+	res, err := ws.Go(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.ExitCode == 0 {
+		if err := m.buildBinaries(ctx, ws, input); err != nil {
+			return nil, err
+		}
+	}
+	return commandResult(m.Name(), res), nil
+}
+
+// buildBinaries links the main packages into the artifact directory. The
+// packages were just built, so only linking is left to do. go build -o
+// rejects a directory when no package is a main package, so they are listed first.
+func (m *BuildMode) buildBinaries(ctx context.Context, ws *Workspace, input map[string]string) error {
+	args := append([]string{"list", "-f", `{{if eq .Name "main"}}{{.ImportPath}}{{end}}`}, buildFlags(input)...)
+	res, err := ws.Go(ctx, append(args, packages(input)...)...)
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("go list: %s", strings.TrimSpace(res.Stderr))
+	}
+	mains := strings.Fields(res.Stdout)
+	if len(mains) == 0 {
+		return nil
+	}
+
+	dir, err := ws.ArtifactDir()
+	if err != nil {
+		return err
+	}
+	bin := filepath.Join(dir, "bin")
+
+	args = append([]string{"build", "-o", bin + string(filepath.Separator)}, buildFlags(input)...)
+	res, err = ws.Go(ctx, append(args, mains...)...)
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("go build: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	ws.addArtifactDir("bin/", ContentTypeBinary, bin)
+	return nil
+}
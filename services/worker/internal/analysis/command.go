@@ -0,0 +1,169 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"distributed-analyzer/libs/model"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// maxCommandOutputBytes bounds the output kept of every stream of a command.
+// Beyond it only the tail is kept, which holds the failures and summaries.
+const maxCommandOutputBytes = 16 << 20
+
+// CommandResult is the outcome of a finished command
+type CommandResult struct {
+	// Output holds the combined stdout and stderr
+	Output string
+
+	// Stdout holds stdout only
+	Stdout string
+
+	// Stderr holds stderr only
+	Stderr string
+
+	ExitCode int
+}
+
+// Command is a command to run on behalf of a subtask
+type Command struct {
+	Dir  string
+	Env  []string
+	Name string
+	Args []string
+
+	// Network allows the command to reach the network, e.g. to download modules.
+	// Commands that run code of the analyzed repository never get it.
+	Network bool
+
+	// Mounts lists the directories the command may use. Executors that isolate
+	// the file system make no other directory of the worker available.
+	Mounts []Mount
+
+	// Log receives the output while the command runs, if set
+	Log LogSink
+}
+
+// Mount is a directory of the worker a command may use
+type Mount struct {
+	Dir      string
+	ReadOnly bool
+}
+
+// LogSink receives the output of commands while they run, e.g. to stream it to clients.
+// It is called concurrently for stdout and stderr.
+type LogSink interface {
+	WriteLog(stream string, p []byte)
+}
+
+// Executor runs the commands of a subtask, isolating them from the worker
+// as far as its backend allows.
+// A non-zero exit code is reported in the result; an error is only
+// returned when the command could not be run to completion.
+type Executor interface {
+	Run(ctx context.Context, cmd *Command) (*CommandResult, error)
+}
+
+// LocalExecutor runs commands as plain child processes of the worker, without isolation
+type LocalExecutor struct{}
+
+// Run runs the command with the worker's environment
+func (LocalExecutor) Run(ctx context.Context, cmd *Command) (*CommandResult, error) {
+	return runCommand(ctx, cmd.Dir, cmd.Env, cmd.Log, cmd.Name, cmd.Args...)
+}
+
+// runCommand runs a command in dir with the worker's environment and waits for it to finish
+func runCommand(ctx context.Context, dir string, env []string, log LogSink, name string, args ...string) (*CommandResult, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	started := time.Now()
+	res, err := RunProcess(ctx, cmd, log)
+	if cmd.ProcessState != nil {
+		RecordUsage(ctx, processUsage(cmd.ProcessState, time.Since(started)))
+	}
+	return res, err
+}
+
+// RunProcess starts a prepared command, captures its output and waits for it to finish.
+// Executors use it to run the processes they have set up for isolation.
+// The output is also written to log as it arrives, if log is not nil.
+// Only the last maxCommandOutputBytes of every output are kept.
+// Executors record the resources the process used with RecordUsage.
+func RunProcess(ctx context.Context, cmd *exec.Cmd, log LogSink) (*CommandResult, error) {
+	var output, stdout, stderr tailBuffer
+
+	cmd.Stdout = &teeWriter{primary: &output, secondary: &stdout, log: log, stream: model.LogStreamStdout}
+	cmd.Stderr = &teeWriter{primary: &output, secondary: &stderr, log: log, stream: model.LogStreamStderr}
+
+	err := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	// A process left behind by the command may hold its output open;
+	// the command itself has finished once the wait delay expired
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		return nil, err
+	}
+
+	return &CommandResult{
+		Output:   output.String(),
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: cmd.ProcessState.ExitCode(),
+	}, nil
+}
+
+// teeWriter writes to two buffers and, if set, to a log sink
+type teeWriter struct {
+	primary   *tailBuffer
+	secondary *tailBuffer
+	log       LogSink
+	stream    string
+}
+
+func (w *teeWriter) Write(p []byte) (int, error) {
+	w.primary.Write(p)
+	if w.log != nil {
+		w.log.WriteLog(w.stream, p)
+	}
+	return w.secondary.Write(p)
+}
+
+// tailBuffer keeps the last maxCommandOutputBytes written to it
+type tailBuffer struct {
+	buf     bytes.Buffer
+	dropped int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf.Write(p)
+	// Drop the head only once it has grown as large as the tail, so that
+	// every byte is copied a bounded number of times
+	if excess := b.buf.Len() - maxCommandOutputBytes; excess >= maxCommandOutputBytes {
+		b.buf.Next(excess)
+		b.dropped += excess
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, preceded by a note if its head was dropped
+func (b *tailBuffer) String() string {
+	data := b.buf.Bytes()
+	dropped := b.dropped
+	if excess := len(data) - maxCommandOutputBytes; excess > 0 {
+		data = data[excess:]
+		dropped += excess
+	}
+	if dropped == 0 {
+		return string(data)
+	}
+	return fmt.Sprintf("[%d bytes of output truncated]\n%s", dropped, data)
+}
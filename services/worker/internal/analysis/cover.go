@@ -0,0 +1,216 @@
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Input keys understood by the coverage mode
+const (
+	InputCoverPkgKey  = "coverpkg"
+	InputCoverModeKey = "covermode"
+)
+
+// Result keys written by the coverage mode
+const (
+	CoverProfileKey   = "cover.profile"
+	CoverFunctionsKey = "cover.functions"
+)
+
+// coverProfileFile is the name of the coverage profile inside the workspace
+const coverProfileFile = "coverage.out"
+
+// CoverProfileArtifact is the artifact holding the full coverage profile
+const CoverProfileArtifact = coverProfileFile
+
+// CoverMode runs the tests of the workspace with coverage enabled.
+// Next to the raw profile it reports the extents of every covered function,
+// so the result service can attribute coverage without the sources.
+type CoverMode struct{}
+
+// Name returns the mode name
+func (m *CoverMode) Name() string {
+	return "go_cover"
+}
+
+// Run executes go test -coverprofile
+func (m *CoverMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	pkgs, err := selectPackages(ctx, ws, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return emptyShardResult(m.Name()), nil
+	}
+
+	args := append([]string{"test", "-coverprofile", coverProfileFile}, testFlags(input)...)
+	if coverMode := input[InputCoverModeKey]; coverMode != "" {
+		args = append(args, "-covermode", coverMode)
+	}
+	if coverPkg := input[InputCoverPkgKey]; coverPkg != "" {
+		args = append(args, "-coverpkg", coverPkg)
+	}
+	args = append(args, pkgs...)
+
+	res, err := ws.Go(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := commandResult(m.Name(), res)
+
+	profile, err := os.ReadFile(ws.Path(coverProfileFile))
+	if os.IsNotExist(err) {
+		// The tests did not build, the output explains why
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+	result[CoverProfileKey] = string(profile)
+	ws.AddArtifact(CoverProfileArtifact, ContentTypeText, ws.Path(coverProfileFile))
+
+	extents, err := functionExtents(ctx, ws, string(profile))
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(extents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode function extents: %w", err)
+	}
+	result[CoverFunctionsKey] = string(encoded)
+
+	return result, nil
+}
+
+// functionExtents locates the functions of every file named in a coverage profile
+func functionExtents(ctx context.Context, ws *Workspace, profile string) ([]model.FunctionExtent, error) {
+	files := profileFiles(profile)
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	importPaths := make(map[string]bool)
+	for _, file := range files {
+		importPaths[path.Dir(file)] = true
+	}
+
+	dirs, err := packageDirs(ctx, ws, importPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	extents := make([]model.FunctionExtent, 0)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		dir, ok := dirs[path.Dir(file)]
+		if !ok {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(fset, filepath.Join(dir, path.Base(file)), nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+			extents = append(extents, model.FunctionExtent{
+				File:      file,
+				Function:  funcName(fn),
+				StartLine: start.Line,
+				StartCol:  start.Column,
+				EndLine:   end.Line,
+				EndCol:    end.Column,
+			})
+		}
+	}
+
+	return extents, nil
+}
+
+// profileFiles returns the distinct file names referenced by a coverage profile
+func profileFiles(profile string) []string {
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(profile))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		if i := strings.LastIndex(line, ":"); i > 0 {
+			seen[line[:i]] = true
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// packageDirs resolves import paths to directories using go list
+func packageDirs(ctx context.Context, ws *Workspace, importPaths map[string]bool) (map[string]string, error) {
+	args := []string{"list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}"}
+	for importPath := range importPaths {
+		args = append(args, importPath)
+	}
+
+	res, err := ws.Go(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("go list: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	dirs := make(map[string]string)
+	for _, line := range strings.Split(res.Output, "\n") {
+		importPath, dir, ok := strings.Cut(line, "\t")
+		if ok && dir != "" {
+			dirs[importPath] = dir
+		}
+	}
+	return dirs, nil
+}
+
+// funcName returns the name of a function, qualified with its receiver type
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	}
+	if index, ok := recv.(*ast.IndexListExpr); ok {
+		recv = index.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
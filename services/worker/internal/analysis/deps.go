@@ -0,0 +1,345 @@
+package analysis
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Result keys written by DepsMode
+const (
+	DepsGraphKey        = "deps.graph"
+	VulnFindingsKey     = "vuln.findings"
+	VulnCountKey        = "vuln.count"
+	VulnReachedCountKey = "vuln.reached_count"
+)
+
+// DepsMode reports the module graph of the workspace and matches its build
+// list against a local mirror of the OSV vulnerability database. The database
+// is loaded on first use and loaded again once the mirror changed.
+type DepsMode struct {
+	vulnDB string
+
+	db        *VulnDB
+	dbVersion vulnDBVersion
+	mu        sync.Mutex
+}
+
+// NewDepsMode creates a new DepsMode.
+// Without a vulnerability database directory only the module graph is reported.
+func NewDepsMode(vulnDB string) *DepsMode {
+	return &DepsMode{vulnDB: vulnDB}
+}
+
+// Name returns the mode name
+func (m *DepsMode) Name() string {
+	return "go_deps"
+}
+
+// Run executes go list -m and go mod graph and matches the modules against the vulnerability database
+func (m *DepsMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	graph, err := moduleGraph(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedGraph, err := json.Marshal(graph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode module graph: %w", err)
+	}
+
+	result := map[string]string{
+		ResultModeKey:     m.Name(),
+		ResultExitCodeKey: "0",
+		DepsGraphKey:      string(encodedGraph),
+	}
+
+	if m.vulnDB == "" {
+		return result, nil
+	}
+
+	db, err := m.loadVulnDB()
+	if err != nil {
+		return nil, err
+	}
+
+	goVersion, err := ws.Go(ctx, "env", "GOVERSION")
+	if err != nil {
+		return nil, err
+	}
+
+	vulns := db.Match(graph.Modules, goVersionToSemver(strings.TrimSpace(goVersion.Stdout)))
+	reached, err := markReached(ctx, ws, input, vulns)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedVulns, err := json.Marshal(vulns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode vulnerabilities: %w", err)
+	}
+
+	result[VulnFindingsKey] = string(encodedVulns)
+	result[VulnCountKey] = strconv.Itoa(len(vulns))
+	result[VulnReachedCountKey] = strconv.Itoa(reached)
+	return result, nil
+}
+
+// loadVulnDB returns the vulnerability database, loading it unless the
+// mirror is unchanged since it was last loaded
+func (m *DepsMode) loadVulnDB() (*VulnDB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	version, err := statVulnDB(m.vulnDB)
+	if err != nil {
+		return nil, err
+	}
+	if m.db != nil && version == m.dbVersion {
+		return m.db, nil
+	}
+
+	db, err := LoadVulnDB(m.vulnDB)
+	if err != nil {
+		return nil, err
+	}
+	m.db, m.dbVersion = db, version
+	return db, nil
+}
+
+// moduleGraph lists the build list with go list -m and its requirements with go mod graph.
+// Both may fetch go.mod files of modules the build does not need.
+func moduleGraph(ctx context.Context, ws *Workspace) (*model.ModuleGraph, error) {
+	res, err := ws.GoWithNetwork(ctx, "list", "-m", "-e", "-json", "all")
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("go list -m: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	graph := &model.ModuleGraph{Modules: []model.Module{}, Edges: []model.ModuleEdge{}}
+
+	decoder := json.NewDecoder(strings.NewReader(res.Stdout))
+	for {
+		var module model.Module
+		if err := decoder.Decode(&module); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode go list -m output: %w", err)
+		}
+		graph.Modules = append(graph.Modules, module)
+	}
+
+	res, err = ws.GoWithNetwork(ctx, "mod", "graph")
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("go mod graph: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	for _, line := range strings.Split(res.Stdout, "\n") {
+		from, to, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok {
+			graph.Edges = append(graph.Edges, model.ModuleEdge{From: from, To: to})
+		}
+	}
+
+	return graph, nil
+}
+
+// markReached marks the vulnerable packages the main module depends on and
+// the vulnerable symbols its non-test code references directly, and returns
+// the number of vulnerabilities with a vulnerable package in the dependencies.
+// References through dependencies are not traced, so reached symbols are a
+// lower bound while imported packages are an upper bound of what is reachable.
+func markReached(ctx context.Context, ws *Workspace, input map[string]string, vulns []model.Vulnerability) (int, error) {
+	if len(vulns) == 0 {
+		return 0, nil
+	}
+
+	args := append([]string{"list", "-e", "-deps", "-f", "{{.ImportPath}} {{.Name}}"}, buildFlags(input)...)
+	res, err := ws.Go(ctx, append(args, packages(input)...)...)
+	if err != nil {
+		return 0, err
+	}
+	if res.ExitCode != 0 {
+		return 0, fmt.Errorf("go list: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	// names holds the package name of every dependency, keyed by import path
+	names := make(map[string]string)
+	for _, line := range strings.Split(res.Stdout, "\n") {
+		if path, name, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			names[path] = name
+		}
+	}
+
+	references, err := packageReferences(ws.Dir, names)
+	if err != nil {
+		return 0, err
+	}
+
+	reached := 0
+	for i := range vulns {
+		imported := false
+		for j := range vulns[i].Packages {
+			pkg := &vulns[i].Packages[j]
+			if _, ok := names[pkg.Path]; !ok {
+				continue
+			}
+			pkg.Imported = true
+			imported = true
+			pkg.ReachedSymbols = reachedSymbols(pkg.Symbols, references[pkg.Path])
+		}
+		if imported {
+			reached++
+		}
+	}
+	return reached, nil
+}
+
+// packageUse is how the main module uses an imported package
+type packageUse struct {
+	// symbols holds the package-level identifiers selected on the package
+	symbols map[string]bool
+
+	// selectors holds every selector in the files using the package,
+	// which approximates the methods called on its types
+	selectors map[string]bool
+}
+
+// packageReferences collects how the non-test Go files of the main module
+// use every imported package, keyed by import path
+func packageReferences(root string, names map[string]string) (map[string]*packageUse, error) {
+	uses := make(map[string]*packageUse)
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || isModuleRoot(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			// Files that do not parse cannot be part of a successful build
+			return nil
+		}
+		collectReferences(file, names, uses)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan sources: %w", err)
+	}
+
+	return uses, nil
+}
+
+// collectReferences records the package selectors of one file
+func collectReferences(file *ast.File, names map[string]string, uses map[string]*packageUse) {
+	// imports maps the local name of every import to its path
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := names[path]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "" && name != "_" && name != "." {
+			imports[name] = path
+		}
+	}
+	if len(imports) == 0 {
+		return
+	}
+
+	selectors := make(map[string]bool)
+	used := make(map[string][]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		selectors[sel.Sel.Name] = true
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if path, ok := imports[ident.Name]; ok {
+				used[path] = append(used[path], sel.Sel.Name)
+			}
+		}
+		return true
+	})
+
+	for path, symbols := range used {
+		use, ok := uses[path]
+		if !ok {
+			use = &packageUse{symbols: make(map[string]bool), selectors: make(map[string]bool)}
+			uses[path] = use
+		}
+		for _, symbol := range symbols {
+			use.symbols[symbol] = true
+		}
+		for selector := range selectors {
+			use.selectors[selector] = true
+		}
+	}
+}
+
+// reachedSymbols returns the vulnerable symbols the main module references.
+// A method Type.Method counts when the type is referenced and the method name
+// is selected somewhere. A package without listed symbols is affected as a
+// whole, so every referenced symbol counts.
+func reachedSymbols(symbols []string, use *packageUse) []string {
+	reached := make([]string, 0)
+	if use == nil {
+		return reached
+	}
+
+	if len(symbols) == 0 {
+		for symbol := range use.symbols {
+			reached = append(reached, symbol)
+		}
+	}
+	for _, symbol := range symbols {
+		typeName, method, isMethod := strings.Cut(symbol, ".")
+		if use.symbols[typeName] && (!isMethod || use.selectors[method]) {
+			reached = append(reached, symbol)
+		}
+	}
+
+	sort.Strings(reached)
+	return reached
+}
+
+// isModuleRoot reports whether a directory holds a nested module
+func isModuleRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
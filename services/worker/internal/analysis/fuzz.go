@@ -0,0 +1,331 @@
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Input keys understood by the fuzz mode
+const (
+	InputFuzzKey     = "fuzz"
+	InputFuzzTimeKey = "fuzztime"
+	InputProjectKey  = "project"
+)
+
+// Result keys written by the fuzz mode
+const (
+	FuzzTargetKey     = "fuzz.target"
+	FuzzCorpusSizeKey = "fuzz.corpus_size"
+	FuzzNewInputsKey  = "fuzz.new_inputs"
+	FuzzCrashersKey   = "fuzz.crashers"
+)
+
+// defaultFuzzTime is the fuzzing budget of a target when the input sets none
+const defaultFuzzTime = "60s"
+
+var (
+	fuzzTargetPattern    = regexp.MustCompile(`^Fuzz\w*$`)
+	failingInputPattern  = regexp.MustCompile(`Failing input written to (\S+)`)
+	fuzzFailurePattern   = regexp.MustCompile(`^--- FAIL: (\S+)`)
+	fuzzLogPrefixPattern = regexp.MustCompile(`^\S+\.go:\d+: `)
+	fuzzLocationPattern  = regexp.MustCompile(`^(\S+\.go):(\d+)`)
+)
+
+// ObjectStore stores objects shared between workers, such as fuzz corpora
+type ObjectStore interface {
+	// List lists the keys of the objects whose keys start with prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Get retrieves the content of an object
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores an object
+	Put(ctx context.Context, key string, content []byte) error
+}
+
+// FuzzMode runs a single fuzz target for a time budget.
+// The corpus is shared between workers through the object store: it is
+// pulled before fuzzing, and new interesting inputs and crashers are pushed
+// back afterwards.
+type FuzzMode struct {
+	store ObjectStore
+}
+
+// NewFuzzMode creates a new FuzzMode backed by store
+func NewFuzzMode(store ObjectStore) *FuzzMode {
+	return &FuzzMode{store: store}
+}
+
+// Name returns the mode name
+func (m *FuzzMode) Name() string {
+	return "go_fuzz"
+}
+
+// Run executes go test -fuzz for the target named in the input
+func (m *FuzzMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	target := input[InputFuzzKey]
+	if !fuzzTargetPattern.MatchString(target) {
+		return nil, fmt.Errorf("invalid fuzz target %q", target)
+	}
+
+	pkgs := packages(input)
+	if len(pkgs) != 1 || strings.Contains(pkgs[0], "...") {
+		return nil, fmt.Errorf("fuzzing requires exactly one package, got %q", input[InputPackagesKey])
+	}
+
+	fuzzTime := input[InputFuzzTimeKey]
+	if fuzzTime == "" {
+		fuzzTime = defaultFuzzTime
+	}
+
+	importPath, pkgDir, err := resolvePackage(ctx, ws, pkgs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := path.Join("fuzz", corpusNamespace(input), importPath, target)
+	seedDir := filepath.Join(pkgDir, "testdata", "fuzz", target)
+
+	corpusSize, err := m.pullCorpus(ctx, prefix+"/corpus/", seedDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull corpus: %w", err)
+	}
+
+	args := append([]string{"test", "-run", "^$", "-fuzz", "^" + target + "$", "-fuzztime", fuzzTime}, buildFlags(input)...)
+	args = append(args, pkgs[0])
+
+	res, err := ws.Go(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	newInputs, err := m.pushCorpus(ctx, ws, prefix+"/corpus/", importPath, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to push corpus: %w", err)
+	}
+
+	crashers := make([]model.FuzzCrasher, 0)
+	if crasher := ParseFuzzFailure(res.Output, target); crasher != nil {
+		crasher.Package = importPath
+		if err := m.pushCrasher(ctx, prefix+"/crashers/", pkgDir, res.Output, crasher); err != nil {
+			return nil, fmt.Errorf("failed to push crasher: %w", err)
+		}
+		crashers = append(crashers, *crasher)
+	}
+
+	encoded, err := json.Marshal(crashers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode crashers: %w", err)
+	}
+
+	result := commandResult(m.Name(), res)
+	result[FuzzTargetKey] = importPath + "." + target
+	result[FuzzCorpusSizeKey] = strconv.Itoa(corpusSize + newInputs)
+	result[FuzzNewInputsKey] = strconv.Itoa(newInputs)
+	result[FuzzCrashersKey] = string(encoded)
+	return result, nil
+}
+
+// pullCorpus downloads the shared corpus into the seed corpus directory of the target
+func (m *FuzzMode) pullCorpus(ctx context.Context, prefix, seedDir string) (int, error) {
+	keys, err := m.store.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(seedDir, 0o755); err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		content, err := m.store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(filepath.Join(seedDir, path.Base(key)), content, 0o644); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), nil
+}
+
+// pushCorpus uploads the inputs the fuzzer found interesting during this run.
+// The fuzzing engine keeps them in the build cache, named by their content hash.
+func (m *FuzzMode) pushCorpus(ctx context.Context, ws *Workspace, prefix, importPath, target string) (int, error) {
+	res, err := ws.Go(ctx, "env", "GOCACHE")
+	if err != nil {
+		return 0, err
+	}
+
+	cacheDir := filepath.Join(strings.TrimSpace(res.Output), "fuzz", filepath.FromSlash(importPath), target)
+	entries, err := os.ReadDir(cacheDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	known, err := m.store.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]bool, len(known))
+	for _, key := range known {
+		existing[path.Base(key)] = true
+	}
+
+	pushed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || existing[entry.Name()] {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(cacheDir, entry.Name()))
+		if err != nil {
+			return pushed, err
+		}
+		if err := m.store.Put(ctx, prefix+entry.Name(), content); err != nil {
+			return pushed, err
+		}
+		pushed++
+	}
+
+	return pushed, nil
+}
+
+// pushCrasher reads the minimized failing input written by the fuzzer and uploads it
+func (m *FuzzMode) pushCrasher(ctx context.Context, prefix, pkgDir, output string, crasher *model.FuzzCrasher) error {
+	match := failingInputPattern.FindStringSubmatch(output)
+	if match == nil {
+		return nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(pkgDir, filepath.FromSlash(match[1])))
+	if err != nil {
+		return err
+	}
+
+	crasher.Input = content
+	crasher.InputKey = prefix + path.Base(match[1])
+	return m.store.Put(ctx, crasher.InputKey, content)
+}
+
+// ParseFuzzFailure extracts the failure of a fuzz target from go test output.
+// It returns nil if the target did not fail.
+func ParseFuzzFailure(output, target string) *model.FuzzCrasher {
+	var (
+		crasher *model.FuzzCrasher
+		frame   *model.StackFrame
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if crasher == nil {
+			if match := fuzzFailurePattern.FindStringSubmatch(line); match != nil && match[1] == target {
+				crasher = &model.FuzzCrasher{Target: target}
+			}
+			continue
+		}
+
+		switch {
+		case failingInputPattern.MatchString(line), line == "FAIL":
+			return crasher
+
+		case fuzzFailurePattern.MatchString(line):
+			// Nested failure of the same target
+
+		case crasher.Error == "":
+			crasher.Error = strings.TrimPrefix(fuzzLogPrefixPattern.ReplaceAllString(line, ""), "panic: ")
+
+		case frame != nil && fuzzLocationPattern.MatchString(line):
+			match := fuzzLocationPattern.FindStringSubmatch(line)
+			frame.File = path.Base(match[1])
+			frame.Line, _ = strconv.Atoi(match[2])
+			frame = nil
+
+		case strings.HasSuffix(line, ")") && strings.LastIndex(line, "(") > 0:
+			function := line[:strings.LastIndex(line, "(")]
+			if isRuntimeFrame(function) {
+				frame = nil
+				continue
+			}
+			crasher.Stack = append(crasher.Stack, model.StackFrame{Function: function})
+			frame = &crasher.Stack[len(crasher.Stack)-1]
+		}
+	}
+
+	return crasher
+}
+
+// isRuntimeFrame reports whether a stack frame belongs to the runtime or the testing machinery
+func isRuntimeFrame(function string) bool {
+	for _, prefix := range []string{"runtime.", "runtime/", "testing.", "internal/fuzz.", "reflect."} {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePackage returns the import path and directory of a package pattern
+func resolvePackage(ctx context.Context, ws *Workspace, pattern string) (string, string, error) {
+	res, err := ws.Go(ctx, "list", "-f", "{{.ImportPath}}\t{{.Dir}}", pattern)
+	if err != nil {
+		return "", "", err
+	}
+	if res.ExitCode != 0 {
+		return "", "", fmt.Errorf("go list: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	importPath, dir, ok := strings.Cut(strings.TrimSpace(res.Stdout), "\t")
+	if !ok {
+		return "", "", fmt.Errorf("go list: unexpected output %q", res.Stdout)
+	}
+	return importPath, dir, nil
+}
+
+// corpusNamespace returns the storage namespace of a project's fuzz corpora
+func corpusNamespace(input map[string]string) string {
+	namespace := input[InputProjectKey]
+	if namespace == "" {
+		namespace = input[InputRepositoryKey]
+		if _, rest, ok := strings.Cut(namespace, "://"); ok {
+			namespace = rest
+		}
+		namespace = strings.TrimSuffix(namespace, ".git")
+	}
+
+	namespace = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '/':
+			return r
+		default:
+			return '_'
+		}
+	}, namespace)
+
+	namespace = strings.Trim(path.Clean("/"+namespace), "/")
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
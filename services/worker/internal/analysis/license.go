@@ -0,0 +1,249 @@
+package analysis
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Input keys holding the license policy, as comma or space separated SPDX
+// identifiers or families such as BSD or GPL
+const (
+	InputLicenseAllowKey = "license_allow"
+	InputLicenseDenyKey  = "license_deny"
+)
+
+// Result keys written by LicenseMode
+const (
+	LicenseReportKey     = "license.report"
+	LicenseViolationsKey = "license.violations"
+)
+
+// licenseFilePattern matches the names of license files in a module root
+var licenseFilePattern = regexp.MustCompile(`(?i)^(license|licence|copying)([.-].*)?$`)
+
+// titleLines is the number of non-empty lines at the top of a license text
+// that hold its title, including copyright lines before it
+const titleLines = 8
+
+// licenseSignature identifies a license by phrases that all occur in its normalized text
+type licenseSignature struct {
+	id      string
+	phrases []string
+}
+
+// titleSignatures identify licenses by their title lines. The full texts of these
+// licenses refer to each other, e.g. GPL-3.0 and MPL-2.0 name the AGPL, so their
+// bodies must not be matched.
+var titleSignatures = []licenseSignature{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license", "version 2.0"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+}
+
+// textSignatures identify the licenses without a standard title by their text,
+// ordered from most to least specific
+var textSignatures = []licenseSignature{
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "endorse or promote products derived from this software"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// ErrLicenseViolation is returned when a dependency license breaks the policy of the task
+var ErrLicenseViolation = errors.New("license policy violated")
+
+// LicenseMode classifies the license of every dependency module and checks it against the task policy
+type LicenseMode struct{}
+
+// Name returns the mode name
+func (m *LicenseMode) Name() string {
+	return "go_license"
+}
+
+// Run downloads the dependencies into the module cache and classifies their license files.
+// The report is returned together with ErrLicenseViolation when the policy is broken,
+// so the subtask fails but keeps its report.
+func (m *LicenseMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	res, err := ws.GoWithNetwork(ctx, "mod", "download", "-json", "all")
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode != 0 && res.Stdout == "" {
+		return nil, fmt.Errorf("go mod download: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	report := &model.LicenseReport{
+		Modules: make([]model.ModuleLicense, 0),
+		Allow:   policyList(input[InputLicenseAllowKey]),
+		Deny:    policyList(input[InputLicenseDenyKey]),
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(res.Stdout))
+	for {
+		var module struct {
+			Path    string
+			Version string
+			Dir     string
+		}
+		if err := decoder.Decode(&module); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode go mod download output: %w", err)
+		}
+
+		license, file := classifyModule(module.Dir)
+		violation := violatesPolicy(license, report.Allow, report.Deny)
+		if violation {
+			report.Violations++
+		}
+
+		report.Modules = append(report.Modules, model.ModuleLicense{
+			Module:    module.Path,
+			Version:   module.Version,
+			License:   license,
+			File:      file,
+			Violation: violation,
+		})
+	}
+
+	sort.Slice(report.Modules, func(i, j int) bool {
+		return report.Modules[i].Module < report.Modules[j].Module
+	})
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode license report: %w", err)
+	}
+
+	result := map[string]string{
+		ResultModeKey:        m.Name(),
+		ResultExitCodeKey:    "0",
+		LicenseReportKey:     string(encoded),
+		LicenseViolationsKey: strconv.Itoa(report.Violations),
+	}
+
+	if report.Violations > 0 {
+		return result, fmt.Errorf("%w by %d modules", ErrLicenseViolation, report.Violations)
+	}
+	return result, nil
+}
+
+// classifyModule classifies the first recognized license file in a module root
+func classifyModule(dir string) (string, string) {
+	if dir == "" {
+		return model.UnknownLicense, ""
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return model.UnknownLicense, ""
+	}
+
+	first := ""
+	for _, entry := range entries {
+		if entry.IsDir() || !licenseFilePattern.MatchString(entry.Name()) {
+			continue
+		}
+		if first == "" {
+			first = entry.Name()
+		}
+
+		text, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if license := ClassifyLicense(string(text)); license != model.UnknownLicense {
+			return license, entry.Name()
+		}
+	}
+
+	return model.UnknownLicense, first
+}
+
+// ClassifyLicense returns the SPDX identifier of a license text, or UnknownLicense.
+// Licenses with a title are recognized by it, the others by their text.
+func ClassifyLicense(text string) string {
+	var title []string
+	for _, line := range strings.Split(text, "\n") {
+		if len(title) == titleLines {
+			break
+		}
+		if strings.TrimSpace(line) != "" {
+			title = append(title, line)
+		}
+	}
+
+	if license, ok := matchSignature(titleSignatures, strings.Join(title, "\n")); ok {
+		return license
+	}
+	if license, ok := matchSignature(textSignatures, text); ok {
+		return license
+	}
+	return model.UnknownLicense
+}
+
+// matchSignature returns the first signature whose phrases all occur in a text
+func matchSignature(signatures []licenseSignature, text string) (string, bool) {
+	// Case, line breaks and punctuation around version numbers vary between copies
+	normalized := strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(text, ",", " "))), " ")
+	for _, signature := range signatures {
+		matched := true
+		for _, phrase := range signature.phrases {
+			if !strings.Contains(normalized, strings.ReplaceAll(phrase, ",", "")) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return signature.id, true
+		}
+	}
+	return "", false
+}
+
+// violatesPolicy reports whether a license is denied, or not allowed when an allow list is given
+func violatesPolicy(license string, allow, deny []string) bool {
+	for _, entry := range deny {
+		if licenseMatches(license, entry) {
+			return true
+		}
+	}
+	if len(allow) == 0 {
+		return false
+	}
+	for _, entry := range allow {
+		if licenseMatches(license, entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// licenseMatches reports whether a license is the policy entry or belongs to its family,
+// e.g. BSD-3-Clause matches BSD but LGPL-2.1 does not match GPL
+func licenseMatches(license, entry string) bool {
+	return strings.EqualFold(license, entry) || strings.HasPrefix(strings.ToLower(license), strings.ToLower(entry)+"-")
+}
+
+// policyList splits a comma or space separated policy setting
+func policyList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
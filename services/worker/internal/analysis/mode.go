@@ -0,0 +1,102 @@
+// Package analysis runs Go toolchain analyses inside a task workspace.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Input keys understood by every mode
+const (
+	InputModeKey       = "mode"
+	InputRepositoryKey = "repository"
+	InputRevisionKey   = "revision"
+	InputPackagesKey   = "packages"
+	InputTagsKey       = "tags"
+	InputGoVersionKey  = "go_version"
+	InputGOOSKey       = "goos"
+	InputGOARCHKey     = "goarch"
+)
+
+// Result keys written by every mode
+const (
+	ResultModeKey     = "mode"
+	ResultExitCodeKey = "exit_code"
+	ResultOutputKey   = "output"
+)
+
+// Mode runs one kind of analysis (go_build, go_test, ...) in a workspace
+type Mode interface {
+	// Name returns the mode name used in the task input and worker capabilities
+	Name() string
+
+	// Run executes the analysis and returns its result
+	Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error)
+}
+
+// Registry holds the modes a worker can execute, keyed by name
+type Registry struct {
+	modes map[string]Mode
+}
+
+// NewRegistry creates a new Registry with the given modes
+func NewRegistry(modes ...Mode) *Registry {
+	registry := &Registry{modes: make(map[string]Mode, len(modes))}
+	for _, mode := range modes {
+		registry.modes[mode.Name()] = mode
+	}
+	return registry
+}
+
+// DefaultModes returns all modes implemented by the worker.
+// The go_deps mode matches vulnerabilities against the OSV database mirrored in vulnDB, if set.
+func DefaultModes(store ObjectStore, vulnDB string) []Mode {
+	return []Mode{
+		&BuildMode{},
+		&TestMode{},
+		&BenchmarkMode{},
+		&CoverMode{},
+		&RaceMode{},
+		NewFuzzMode(store),
+		&AffectedMode{},
+		NewDepsMode(vulnDB),
+		&LicenseMode{},
+	}
+}
+
+// Get returns the mode with the given name
+func (r *Registry) Get(name string) (Mode, error) {
+	mode, ok := r.modes[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported mode: %q", name)
+	}
+	return mode, nil
+}
+
+// packages returns the package patterns of the input, defaulting to ./...
+func packages(input map[string]string) []string {
+	pkgs := strings.Fields(input[InputPackagesKey])
+	if len(pkgs) == 0 {
+		return []string{"./..."}
+	}
+	return pkgs
+}
+
+// buildFlags returns the flags shared by go build and go test
+func buildFlags(input map[string]string) []string {
+	if tags := input[InputTagsKey]; tags != "" {
+		return []string{"-tags", tags}
+	}
+	return nil
+}
+
+// commandResult converts a command result into the common result keys
+func commandResult(mode string, res *CommandResult) map[string]string {
+	return map[string]string{
+		ResultModeKey:     mode,
+		ResultExitCodeKey: strconv.Itoa(res.ExitCode),
+		ResultOutputKey:   res.Output,
+	}
+}
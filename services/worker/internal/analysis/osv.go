@@ -0,0 +1,319 @@
+package analysis
+
+import (
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stdlibModule is the module name the Go vulnerability database uses for the standard library
+const stdlibModule = "stdlib"
+
+// osvEntry is the part of an OSV entry that vulnerability matching needs
+type osvEntry struct {
+	ID        string        `json:"id"`
+	Aliases   []string      `json:"aliases"`
+	Summary   string        `json:"summary"`
+	Withdrawn string        `json:"withdrawn"`
+	Affected  []osvAffected `json:"affected"`
+}
+
+// osvAffected describes the affected versions and packages of one module
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges            []osvRange `json:"ranges"`
+	EcosystemSpecific struct {
+		Imports []osvImport `json:"imports"`
+	} `json:"ecosystem_specific"`
+}
+
+// osvRange is a list of introduced and fixed events ordered by version
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced string `json:"introduced"`
+	Fixed      string `json:"fixed"`
+}
+
+// osvImport names an affected package and its vulnerable symbols
+type osvImport struct {
+	Path    string   `json:"path"`
+	Symbols []string `json:"symbols"`
+}
+
+// VulnDB is a local mirror of the OSV database of the Go ecosystem,
+// a directory tree of one JSON file per entry
+type VulnDB struct {
+	// entries holds the entries affecting each module, keyed by module path
+	entries map[string][]*osvEntry
+}
+
+// vulnDBVersion tells apart the states of a vulnerability database directory
+// without reading its entries
+type vulnDBVersion struct {
+	files    int
+	modified time.Time
+}
+
+// statVulnDB returns the version of the vulnerability database below dir: the
+// number of entry files and the time the latest of them was modified
+func statVulnDB(dir string) (vulnDBVersion, error) {
+	var version vulnDBVersion
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		version.files++
+		if info.ModTime().After(version.modified) {
+			version.modified = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return vulnDBVersion{}, fmt.Errorf("failed to read vulnerability database %s: %w", dir, err)
+	}
+	return version, nil
+}
+
+// LoadVulnDB reads every OSV entry below dir.
+// Files that are not OSV entries, such as database indexes, are skipped.
+func LoadVulnDB(dir string) (*VulnDB, error) {
+	db := &VulnDB{entries: make(map[string][]*osvEntry)}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var entry osvEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.ID == "" || entry.Withdrawn != "" {
+			return nil
+		}
+
+		for _, affected := range entry.Affected {
+			if affected.Package.Ecosystem == "Go" {
+				db.entries[affected.Package.Name] = append(db.entries[affected.Package.Name], &entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vulnerability database %s: %w", dir, err)
+	}
+
+	return db, nil
+}
+
+// Match returns the vulnerabilities affecting the modules of a build list and
+// the standard library of the given Go version, ordered by module and ID
+func (db *VulnDB) Match(modules []model.Module, goVersion string) []model.Vulnerability {
+	versions := make(map[string]string, len(modules)+1)
+	for _, module := range modules {
+		if module.Main {
+			continue
+		}
+		path, version := module.Path, module.Version
+		if module.Replace != nil {
+			if module.Replace.Version == "" {
+				// Replaced by a local directory, which has no version to match
+				continue
+			}
+			path, version = module.Replace.Path, module.Replace.Version
+		}
+		if version != "" {
+			versions[path] = version
+		}
+	}
+	if goVersion != "" {
+		versions[stdlibModule] = goVersion
+	}
+
+	vulns := make([]model.Vulnerability, 0)
+	for path, version := range versions {
+		for _, entry := range db.entries[path] {
+			for _, affected := range entry.Affected {
+				if affected.Package.Name != path {
+					continue
+				}
+
+				fixed, ok := affectedVersion(affected.Ranges, version)
+				if !ok {
+					continue
+				}
+
+				vuln := model.Vulnerability{
+					ID:           entry.ID,
+					Aliases:      entry.Aliases,
+					Summary:      entry.Summary,
+					Module:       path,
+					Version:      version,
+					FixedVersion: fixed,
+				}
+				for _, imp := range affected.EcosystemSpecific.Imports {
+					vuln.Packages = append(vuln.Packages, model.VulnerablePackage{
+						Path:    imp.Path,
+						Symbols: imp.Symbols,
+					})
+				}
+				vulns = append(vulns, vuln)
+				break
+			}
+		}
+	}
+
+	sort.Slice(vulns, func(i, j int) bool {
+		if vulns[i].Module != vulns[j].Module {
+			return vulns[i].Module < vulns[j].Module
+		}
+		return vulns[i].ID < vulns[j].ID
+	})
+	return vulns
+}
+
+// affectedVersion reports whether a version lies in one of the semver ranges,
+// and returns the version that fixes it, if any
+func affectedVersion(ranges []osvRange, version string) (string, bool) {
+	for _, r := range ranges {
+		if r.Type != "SEMVER" {
+			continue
+		}
+
+		affected := false
+		fixed := ""
+		for _, event := range r.Events {
+			switch {
+			case event.Introduced != "":
+				if event.Introduced == "0" || compareSemver(version, event.Introduced) >= 0 {
+					affected = true
+				}
+			case event.Fixed != "":
+				if compareSemver(version, event.Fixed) >= 0 {
+					affected = false
+				} else if affected && fixed == "" {
+					fixed = event.Fixed
+				}
+			}
+		}
+		if affected {
+			return fixed, true
+		}
+	}
+	return "", false
+}
+
+// goVersionToSemver converts a Go release name such as go1.22rc1 into the
+// semver form the vulnerability database uses for the standard library
+func goVersionToSemver(goVersion string) string {
+	version := strings.TrimPrefix(goVersion, "go")
+	prerelease := ""
+	for _, tag := range []string{"rc", "beta"} {
+		if i := strings.Index(version, tag); i > 0 {
+			version, prerelease = version[:i], "-"+tag+"."+version[i+len(tag):]
+			break
+		}
+	}
+	if strings.Count(version, ".") == 1 {
+		version += ".0"
+	}
+	return version + prerelease
+}
+
+// compareSemver compares two semantic versions, with or without a v prefix,
+// returning -1, 0 or +1. Build metadata such as +incompatible is ignored.
+func compareSemver(a, b string) int {
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "+")
+
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < 3; i++ {
+		if c := compareIdentifier(part(aParts, i), part(bParts, i)); c != 0 {
+			return c
+		}
+	}
+
+	// A version without a prerelease is greater than one with a prerelease
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aIDs, bIDs := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if c := compareIdentifier(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(aIDs), len(bIDs))
+}
+
+// part returns the i-th version part, defaulting to 0
+func part(parts []string, i int) string {
+	if i < len(parts) && parts[i] != "" {
+		return parts[i]
+	}
+	return "0"
+}
+
+// compareIdentifier compares version identifiers numerically when both are
+// numbers; numeric identifiers sort before alphanumeric ones
+func compareIdentifier(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
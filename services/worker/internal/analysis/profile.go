@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InputProfileKey lists the profiles test and benchmark runs collect, e.g. "cpu,mem,trace"
+const InputProfileKey = "profile"
+
+// profileFlags maps the profile kinds to the go test flag writing them and the file name they are stored under
+var profileFlags = map[string][2]string{
+	model.ProfileCPU:   {"-cpuprofile", "cpu.pprof"},
+	model.ProfileMem:   {"-memprofile", "mem.pprof"},
+	model.ProfileBlock: {"-blockprofile", "block.pprof"},
+	model.ProfileMutex: {"-mutexprofile", "mutex.pprof"},
+	model.ProfileTrace: {"-trace", "trace.out"},
+}
+
+// profileArtifactDir is the directory below the artifact directory profiles are written to
+const profileArtifactDir = "profiles"
+
+// profileBinaryDir holds the test binaries go test keeps when profiling
+const profileBinaryDir = ".profiles"
+
+// profileKinds returns the profile kinds requested in the input
+func profileKinds(input map[string]string) ([]string, error) {
+	var kinds []string
+	for _, kind := range strings.Split(input[InputProfileKey], ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		if _, ok := profileFlags[kind]; !ok {
+			return nil, fmt.Errorf("unsupported profile %q", kind)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// goTest runs go test with args on pkgs, collecting the profiles requested in
+// the input. go test writes profiles of a single package only, so with
+// profiles every package runs on its own and its profiles are recorded as
+// artifacts named profiles/<import path>/<kind>.pprof.
+func goTest(ctx context.Context, ws *Workspace, input map[string]string, args, pkgs []string) (*CommandResult, error) {
+	kinds, err := profileKinds(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(kinds) == 0 {
+		return ws.Go(ctx, append(args, pkgs...)...)
+	}
+
+	pkgs, err = listPackages(ctx, ws, input, pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts, err := ws.ArtifactDir()
+	if err != nil {
+		return nil, err
+	}
+	profiles := filepath.Join(artifacts, profileArtifactDir)
+	if err := os.MkdirAll(ws.Path(profileBinaryDir), 0o755); err != nil {
+		return nil, err
+	}
+
+	results := make([]*CommandResult, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		dir := filepath.Join(profiles, filepath.FromSlash(pkg))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+
+		pkgArgs := append([]string(nil), args...)
+		for _, kind := range kinds {
+			pkgArgs = append(pkgArgs, profileFlags[kind][0], filepath.Join(dir, profileFlags[kind][1]))
+		}
+		binary := filepath.Join(ws.Path(profileBinaryDir), strings.ReplaceAll(pkg, "/", "_")+".test")
+		pkgArgs = append(pkgArgs, "-o", binary, pkg)
+
+		res, err := ws.Go(ctx, pkgArgs...)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+
+	ws.addArtifactDir(profileArtifactDir+"/", ContentTypeBinary, profiles)
+	return joinResults(results), nil
+}
+
+// joinResults combines the results of commands run one after another.
+// The exit code is the first non-zero one.
+func joinResults(results []*CommandResult) *CommandResult {
+	var output, stdout, stderr strings.Builder
+	joined := &CommandResult{}
+	for _, res := range results {
+		output.WriteString(res.Output)
+		stdout.WriteString(res.Stdout)
+		stderr.WriteString(res.Stderr)
+		if joined.ExitCode == 0 {
+			joined.ExitCode = res.ExitCode
+		}
+	}
+	joined.Output = output.String()
+	joined.Stdout = stdout.String()
+	joined.Stderr = stderr.String()
+	return joined
+}
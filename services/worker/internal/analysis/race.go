@@ -0,0 +1,147 @@
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"distributed-analyzer/libs/model"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Result keys written by the race mode
+const (
+	RaceReportsKey = "race.reports"
+	RaceCountKey   = "race.count"
+)
+
+var (
+	raceAccessPattern    = regexp.MustCompile(`^(.+) at 0x[0-9a-f]+ by (?:goroutine (\d+)|main goroutine):$`)
+	raceGoroutinePattern = regexp.MustCompile(`^Goroutine (\d+) \((\w+)\) created at:$`)
+	raceLocationPattern  = regexp.MustCompile(`^(.+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	testRunPattern       = regexp.MustCompile(`^=== (?:RUN|CONT)\s+(\S+)`)
+)
+
+// raceBlockStart and raceBlockEnd delimit a race detector report
+const (
+	raceBlockStart = "WARNING: DATA RACE"
+	raceBlockEnd   = "=================="
+)
+
+// RaceMode runs the tests of the workspace with the race detector enabled
+type RaceMode struct{}
+
+// Name returns the mode name
+func (m *RaceMode) Name() string {
+	return "go_race"
+}
+
+// Run executes go test -race and parses the reported data races
+func (m *RaceMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	pkgs, err := selectPackages(ctx, ws, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return emptyShardResult(m.Name()), nil
+	}
+
+	args := append([]string{"test", "-race", "-v"}, testFlags(input)...)
+	args = append(args, pkgs...)
+
+	res, err := ws.Go(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := ParseRaceReports(res.Output, ws.Dir)
+
+	encoded, err := json.Marshal(reports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode race reports: %w", err)
+	}
+
+	result := commandResult(m.Name(), res)
+	result[RaceReportsKey] = string(encoded)
+	result[RaceCountKey] = strconv.Itoa(len(reports))
+	return result, nil
+}
+
+// ParseRaceReports extracts the data race reports from verbose go test output.
+// File paths below root are made relative so that reports from different
+// workspaces compare equal.
+func ParseRaceReports(output, root string) []model.RaceReport {
+	reports := make([]model.RaceReport, 0)
+
+	var (
+		test    string
+		current *model.RaceReport
+		stack   *[]model.StackFrame
+		frame   *model.StackFrame
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if current == nil {
+			if match := testRunPattern.FindStringSubmatch(line); match != nil {
+				test = match[1]
+			}
+			if line == raceBlockStart {
+				current = &model.RaceReport{Test: test}
+			}
+			continue
+		}
+
+		switch {
+		case line == raceBlockEnd:
+			reports = append(reports, *current)
+			current, stack, frame = nil, nil, nil
+
+		case line == "":
+			stack, frame = nil, nil
+
+		case raceAccessPattern.MatchString(line):
+			match := raceAccessPattern.FindStringSubmatch(line)
+			goroutine, _ := strconv.Atoi(match[2])
+			current.Accesses = append(current.Accesses, model.RaceAccess{Operation: match[1], Goroutine: goroutine})
+			stack, frame = &current.Accesses[len(current.Accesses)-1].Stack, nil
+
+		case raceGoroutinePattern.MatchString(line):
+			match := raceGoroutinePattern.FindStringSubmatch(line)
+			id, _ := strconv.Atoi(match[1])
+			current.Goroutines = append(current.Goroutines, model.RaceGoroutine{ID: id, State: match[2]})
+			stack, frame = &current.Goroutines[len(current.Goroutines)-1].Stack, nil
+
+		case stack == nil:
+			// Unknown section, ignore until the next header
+
+		case frame != nil && raceLocationPattern.MatchString(line):
+			match := raceLocationPattern.FindStringSubmatch(line)
+			frame.File = relativePath(match[1], root)
+			frame.Line, _ = strconv.Atoi(match[2])
+			frame = nil
+
+		default:
+			*stack = append(*stack, model.StackFrame{Function: strings.TrimSuffix(line, "()")})
+			frame = &(*stack)[len(*stack)-1]
+		}
+	}
+
+	return reports
+}
+
+// relativePath strips root from path
+func relativePath(path, root string) string {
+	if root == "" {
+		return path
+	}
+	if rel, ok := strings.CutPrefix(path, strings.TrimSuffix(root, "/")+"/"); ok {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,14 @@
+package analysis
+
+import (
+	"os"
+	"syscall"
+)
+
+// maxRSS returns the peak resident memory of a finished process in bytes
+func maxRSS(state *os.ProcessState) int64 {
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return rusage.Maxrss * 1024 // reported in KiB
+	}
+	return 0
+}
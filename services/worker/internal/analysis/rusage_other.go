@@ -0,0 +1,10 @@
+//go:build !linux
+
+package analysis
+
+import "os"
+
+// maxRSS is not measured on this platform, so only CPU time is metered
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
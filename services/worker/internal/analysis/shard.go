@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Input keys describing the shard of a sharded test run
+const (
+	InputShardIndexKey = "shard_index"
+	InputShardCountKey = "shard_count"
+	InputShardPlanKey  = "shard_plan"
+)
+
+// ShardPackagesKey lists the packages a shard ran
+const ShardPackagesKey = "shard.packages"
+
+// selectPackages returns the packages a subtask tests.
+// Without sharding these are the input patterns. With sharding the patterns
+// are expanded and narrowed to the packages of this shard in the plan the
+// scheduler built from the historical run times, plus its share of the
+// packages the plan does not know.
+func selectPackages(ctx context.Context, ws *Workspace, input map[string]string) ([]string, error) {
+	patterns := packages(input)
+
+	indexValue, ok := input[InputShardIndexKey]
+	if !ok {
+		return patterns, nil
+	}
+
+	index, err := strconv.Atoi(indexValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard index %q: %w", indexValue, err)
+	}
+	count, err := strconv.Atoi(input[InputShardCountKey])
+	if err != nil || count < 1 || index < 0 || index >= count {
+		return nil, fmt.Errorf("invalid shard %q of %q", indexValue, input[InputShardCountKey])
+	}
+
+	var plan [][]string
+	if encoded := input[InputShardPlanKey]; encoded != "" {
+		if err := json.Unmarshal([]byte(encoded), &plan); err != nil {
+			return nil, fmt.Errorf("invalid shard plan: %w", err)
+		}
+	}
+
+	pkgs, err := listPackages(ctx, ws, input, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return shardPackages(pkgs, plan, index, count), nil
+}
+
+// shardPackages returns the listed packages the plan assigns to a shard.
+// The packages missing from the plan, such as new ones, are dealt out over
+// the shards in listing order; every shard lists the same packages and has
+// the same plan, so every package runs in exactly one shard.
+func shardPackages(pkgs []string, plan [][]string, index, count int) []string {
+	assigned := make(map[string]int)
+	for shard, planned := range plan {
+		for _, pkg := range planned {
+			assigned[pkg] = shard
+		}
+	}
+
+	selected := make([]string, 0)
+	var unplanned int
+	for _, pkg := range pkgs {
+		shard, ok := assigned[pkg]
+		if !ok {
+			shard = unplanned % count
+			unplanned++
+		}
+		if shard == index {
+			selected = append(selected, pkg)
+		}
+	}
+	return selected
+}
+
+// listPackages expands package patterns into the import paths of the packages they match
+func listPackages(ctx context.Context, ws *Workspace, input map[string]string, patterns []string) ([]string, error) {
+	args := append([]string{"list", "-e", "-f", "{{.ImportPath}}"}, buildFlags(input)...)
+	res, err := ws.Go(ctx, append(args, patterns...)...)
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("go list: %s", strings.TrimSpace(res.Stderr))
+	}
+	return strings.Fields(res.Stdout), nil
+}
+
+// emptyShardResult is the result of a shard the plan assigned no packages to
+func emptyShardResult(mode string) map[string]string {
+	return map[string]string{
+		ResultModeKey:     mode,
+		ResultExitCodeKey: "0",
+		ResultOutputKey:   "no packages in shard\n",
+		ShardPackagesKey:  "",
+	}
+}
@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Input keys understood by the test modes
+const (
+	InputRunKey   = "run"
+	InputBenchKey = "bench"
+	InputCountKey = "count"
+)
+
+// BenchmarkOutputKey holds the benchmark results consumed by the result service:
+// the benchmark lines of the output with the packages they belong to
+const BenchmarkOutputKey = "benchmark.output"
+
+// TestMode runs the tests of the workspace
+type TestMode struct{}
+
+// Name returns the mode name
+func (m *TestMode) Name() string {
+	return "go_test"
+}
+
+// Run executes go test and reports the outcome of every test and the run time of every package.
+// Profiles requested in the input are uploaded as artifacts.
+func (m *TestMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	pkgs, err := selectPackages(ctx, ws, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return emptyShardResult(m.Name()), nil
+	}
+
+	args := append([]string{"test", "-v"}, testFlags(input)...)
+
+	res, err := goTest(ctx, ws, input, args, pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes, err := json.Marshal(ParseTestResults(res.Output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode test results: %w", err)
+	}
+
+	durations, err := json.Marshal(ParsePackageDurations(res.Output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package durations: %w", err)
+	}
+
+	result := commandResult(m.Name(), res)
+	result[TestResultsKey] = string(outcomes)
+	result[PackageDurationsKey] = string(durations)
+	if _, sharded := input[InputShardIndexKey]; sharded {
+		result[ShardPackagesKey] = strings.Join(pkgs, " ")
+	}
+	return result, nil
+}
+
+// BenchmarkMode runs the benchmarks of the workspace
+type BenchmarkMode struct{}
+
+// Name returns the mode name
+func (m *BenchmarkMode) Name() string {
+	return "go_benchmark"
+}
+
+// Run executes go test -bench and skips regular tests.
+// Profiles requested in the input are uploaded as artifacts.
+func (m *BenchmarkMode) Run(ctx context.Context, ws *Workspace, input map[string]string) (map[string]string, error) {
+	bench := input[InputBenchKey]
+	if bench == "" {
+		bench = "."
+	}
+
+	args := append([]string{"test", "-run", "^$", "-bench", bench, "-benchmem"}, buildFlags(input)...)
+	if count := input[InputCountKey]; count != "" {
+		args = append(args, "-count", count)
+	}
+
+	res, err := goTest(ctx, ws, input, args, packages(input))
+	if err != nil {
+		return nil, err
+	}
+
+	result := commandResult(m.Name(), res)
+	result[BenchmarkOutputKey] = benchmarkLines(res.Output)
+	return result, nil
+}
+
+// benchmarkLines returns the lines of go test output that name a package or
+// report a benchmark, leaving out test logs and everything else
+func benchmarkLines(output string) string {
+	var b strings.Builder
+	for line := range strings.Lines(output) {
+		if strings.HasPrefix(line, "pkg: ") || strings.HasPrefix(line, "Benchmark") {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// testFlags returns the go test flags requested in the input
+func testFlags(input map[string]string) []string {
+	flags := buildFlags(input)
+	if run := input[InputRunKey]; run != "" {
+		flags = append(flags, "-run", run)
+	}
+	if count := input[InputCountKey]; count != "" {
+		flags = append(flags, "-count", count)
+	}
+	return flags
+}
@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Result keys written by the test modes
+const (
+	// TestResultsKey holds the per-test outcomes of a test run as JSON
+	TestResultsKey = "test.results"
+
+	// PackageDurationsKey holds the run time in seconds of every tested package as JSON
+	PackageDurationsKey = "test.package_durations"
+)
+
+// Test outcomes reported under TestResultsKey
+const (
+	TestOutcomePass = "pass"
+	TestOutcomeFail = "fail"
+	TestOutcomeSkip = "skip"
+)
+
+var (
+	testOutcomePattern = regexp.MustCompile(`^--- (PASS|FAIL|SKIP): (\S+)`)
+	packageEndPattern  = regexp.MustCompile(`^(?:ok|FAIL)\s+(\S+)\s`)
+	packageTimePattern = regexp.MustCompile(`^(?:ok|FAIL)\s+(\S+)\s+(\d+(?:\.\d+)?)s\b`)
+)
+
+// ParseTestResults extracts the outcome of every test from verbose go test output.
+// Test names are qualified with their package, e.g. example.com/pkg.TestFoo/sub.
+func ParseTestResults(output string) map[string]string {
+	results := make(map[string]string)
+	pending := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if match := testOutcomePattern.FindStringSubmatch(line); match != nil {
+			pending[match[2]] = strings.ToLower(match[1])
+			continue
+		}
+
+		if match := packageEndPattern.FindStringSubmatch(line + " "); match != nil && len(pending) > 0 {
+			for name, outcome := range pending {
+				results[match[1]+"."+name] = outcome
+			}
+			pending = make(map[string]string)
+		}
+	}
+
+	// Output without a package summary, e.g. a killed run
+	for name, outcome := range pending {
+		results[name] = outcome
+	}
+
+	return results
+}
+
+// ParsePackageDurations extracts the run time in seconds of every tested package
+// from go test output. Cached results report no time and are skipped.
+func ParsePackageDurations(output string) map[string]float64 {
+	durations := make(map[string]float64)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		match := packageTimePattern.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		if seconds, err := strconv.ParseFloat(match[2], 64); err == nil {
+			durations[match[1]] = seconds
+		}
+	}
+
+	return durations
+}
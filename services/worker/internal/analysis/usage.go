@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"os"
+	"sync"
+	"time"
+)
+
+// UsageMeter adds up the resources used by the commands run with its context
+type UsageMeter struct {
+	mu    sync.Mutex
+	usage model.ResourceUsage
+}
+
+type usageMeterKey struct{}
+
+// WithUsageMeter returns a context whose commands are metered by the returned meter
+func WithUsageMeter(ctx context.Context) (context.Context, *UsageMeter) {
+	meter := &UsageMeter{}
+	return context.WithValue(ctx, usageMeterKey{}, meter), meter
+}
+
+// Usage returns the resources used so far
+func (m *UsageMeter) Usage() model.ResourceUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// CommandUsage returns the usage of a command that used the given CPU time and
+// memory at its peak over its run time. The memory of a command is billed at
+// its peak for all of its run time.
+func CommandUsage(cpu time.Duration, maxMemory int64, elapsed time.Duration) model.ResourceUsage {
+	return model.ResourceUsage{
+		CPUSeconds:      cpu.Seconds(),
+		MemoryGBSeconds: float64(maxMemory) / (1 << 30) * elapsed.Seconds(),
+		MaxMemoryBytes:  maxMemory,
+	}
+}
+
+// RecordUsage adds the usage of a finished command to the meter of the context, if any.
+// Executors record the usage of every command they ran, however it ended.
+func RecordUsage(ctx context.Context, usage model.ResourceUsage) {
+	if meter, ok := ctx.Value(usageMeterKey{}).(*UsageMeter); ok {
+		meter.mu.Lock()
+		defer meter.mu.Unlock()
+		meter.usage.Add(usage)
+	}
+}
+
+// processUsage returns the usage of a finished process and the children it
+// waited for, which is all an executor without cgroups can measure
+func processUsage(state *os.ProcessState, elapsed time.Duration) model.ResourceUsage {
+	return CommandUsage(state.UserTime()+state.SystemTime(), maxRSS(state), elapsed)
+}
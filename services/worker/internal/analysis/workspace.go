@@ -0,0 +1,253 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Workspace is a checkout of the repository a subtask analyzes
+type Workspace struct {
+	Dir string
+
+	// Env holds the environment of go commands, such as the target platform
+	Env []string
+
+	// Executor runs the go commands, which may run code of the repository
+	Executor Executor
+
+	// Mounts lists the directories the go commands may use besides the checkout,
+	// such as the go caches
+	Mounts []Mount
+
+	// Log receives the output of the go commands while they run, if set
+	Log LogSink
+
+	// Artifacts lists the files the mode produced for upload
+	Artifacts []Artifact
+}
+
+// PrepareWorkspace clones the repository and revision named in the input into baseDir/id
+func PrepareWorkspace(ctx context.Context, executor Executor, baseDir, id string, input map[string]string) (*Workspace, error) {
+	repository := input[InputRepositoryKey]
+	if repository == "" {
+		return nil, errors.New("repository input is required")
+	}
+	revision := input[InputRevisionKey]
+	if revision == "" {
+		revision = input[InputHeadKey]
+	}
+	if err := checkGitArgument("repository", repository); err != nil {
+		return nil, err
+	}
+	if err := checkGitArgument("revision", revision); err != nil {
+		return nil, err
+	}
+	if err := checkRepository(repository); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(baseDir, id)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to clean workspace: %w", err)
+	}
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	ws := &Workspace{Dir: dir, Env: goEnv(input), Executor: executor}
+
+	// The file protocol is refused as well, so that no redirect or submodule
+	// reaches the checkouts of other subtasks on the worker
+	if err := ws.git(ctx, baseDir, "-c", "protocol.file.allow=never", "clone", "--quiet", "--", repository, dir); err != nil {
+		return nil, err
+	}
+
+	if revision != "" {
+		if err := ws.git(ctx, dir, "checkout", "--quiet", revision, "--"); err != nil {
+			ws.Cleanup()
+			return nil, err
+		}
+	}
+
+	if diff := input[InputDiffKey]; diff != "" {
+		if err := ws.applyDiff(ctx, baseDir, id, diff); err != nil {
+			ws.Cleanup()
+			return nil, err
+		}
+	}
+
+	return ws, nil
+}
+
+// DownloadModules fills the module cache with the dependencies of the checkout,
+// so that the go commands that build its code need no network access.
+// Repositories without a go.mod at their root have nothing to download.
+func (w *Workspace) DownloadModules(ctx context.Context) error {
+	if _, err := os.Stat(w.Path("go.mod")); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	res, err := w.GoWithNetwork(ctx, "mod", "download")
+	if err != nil {
+		return fmt.Errorf("go mod download: %w", err)
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("go mod download: %s", strings.TrimSpace(res.Stderr))
+	}
+	return nil
+}
+
+// applyDiff applies a unified diff to the checkout and stages it,
+// so that files the diff adds show up as changes too
+func (w *Workspace) applyDiff(ctx context.Context, baseDir, id, diff string) error {
+	patch := filepath.Join(baseDir, id+".diff")
+	if err := os.WriteFile(patch, []byte(diff), 0o644); err != nil {
+		return fmt.Errorf("failed to write diff: %w", err)
+	}
+	defer os.Remove(patch)
+
+	return w.git(ctx, w.Dir, "apply", "--index", "--", patch)
+}
+
+// ChangedFiles lists the files that differ between the base revision and the
+// staged checkout, relative to the repository root. Without a base revision
+// the checked out revision is the base, so only an applied diff counts.
+func (w *Workspace) ChangedFiles(ctx context.Context, base string) ([]string, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+	if err := checkGitArgument("base revision", base); err != nil {
+		return nil, err
+	}
+
+	res, err := runCommand(ctx, w.Dir, []string{"GIT_TERMINAL_PROMPT=0"}, nil, "git", "diff", "--cached", "--name-only", "--no-renames", base, "--")
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %w", err)
+	}
+	if res.ExitCode != 0 {
+		return nil, fmt.Errorf("git diff: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	files := strings.Fields(res.Stdout)
+	if files == nil {
+		files = []string{}
+	}
+	return files, nil
+}
+
+// Path returns the absolute path of a file inside the workspace
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.Dir, name)
+}
+
+// Cleanup removes the workspace from disk
+func (w *Workspace) Cleanup() error {
+	return os.RemoveAll(w.Dir)
+}
+
+// Go runs the go command inside the workspace without network access
+func (w *Workspace) Go(ctx context.Context, args ...string) (*CommandResult, error) {
+	mounts := append([]Mount{{Dir: w.Dir}}, w.Mounts...)
+	return w.Executor.Run(ctx, &Command{Dir: w.Dir, Env: w.Env, Name: "go", Args: args, Mounts: mounts, Log: w.Log})
+}
+
+// GoWithNetwork runs a go command that needs the network, such as module downloads.
+// It must not be used for commands that build or run code of the repository.
+// Such commands run the go command alone, so they may write read-only mounts,
+// e.g. to fill the module cache the other commands only read.
+func (w *Workspace) GoWithNetwork(ctx context.Context, args ...string) (*CommandResult, error) {
+	mounts := []Mount{{Dir: w.Dir}}
+	for _, mount := range w.Mounts {
+		mounts = append(mounts, Mount{Dir: mount.Dir})
+	}
+	return w.Executor.Run(ctx, &Command{Dir: w.Dir, Env: w.Env, Name: "go", Args: args, Network: true, Mounts: mounts, Log: w.Log})
+}
+
+// goEnv returns the go command environment for the target platform and
+// toolchain of the input. Cross-compiling disables cgo, and a pinned
+// toolchain must not be switched for the one go.mod asks for.
+func goEnv(input map[string]string) []string {
+	var env []string
+	goos, goarch := input[InputGOOSKey], input[InputGOARCHKey]
+	if goos != "" {
+		env = append(env, "GOOS="+goos)
+	}
+	if goarch != "" {
+		env = append(env, "GOARCH="+goarch)
+	}
+	if (goos != "" && goos != runtime.GOOS) || (goarch != "" && goarch != runtime.GOARCH) {
+		env = append(env, "CGO_ENABLED=0")
+	}
+	if input[InputGoVersionKey] != "" {
+		env = append(env, "GOTOOLCHAIN=local")
+	}
+	return env
+}
+
+// ToolchainVersion returns the release of the Go toolchain the executor runs, e.g. 1.22
+func ToolchainVersion(ctx context.Context, executor Executor) (string, error) {
+	res, err := executor.Run(ctx, &Command{Env: []string{"GOTOOLCHAIN=local"}, Name: "go", Args: []string{"env", "GOVERSION"}})
+	if err != nil {
+		return "", fmt.Errorf("go env: %w", err)
+	}
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("go env: %s", strings.TrimSpace(res.Stderr))
+	}
+
+	version := strings.TrimPrefix(strings.TrimSpace(res.Stdout), "go")
+	if major, rest, ok := strings.Cut(version, "."); ok {
+		minor := strings.FieldsFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if len(minor) > 0 {
+			return major + "." + minor[0], nil
+		}
+	}
+	return "", fmt.Errorf("unexpected Go version %q", version)
+}
+
+// checkGitArgument rejects an input value git would parse as an option. A
+// revision cannot follow "--", which only ends the options of some commands.
+func checkGitArgument(name, value string) error {
+	if strings.HasPrefix(value, "-") {
+		return fmt.Errorf("invalid %s %q: must not start with a dash", name, value)
+	}
+	return nil
+}
+
+// remoteSchemes lists the URL schemes a repository may be cloned with
+var remoteSchemes = map[string]bool{"https": true, "ssh": true, "git": true}
+
+// checkRepository rejects a repository that is not a remote URL, such as a local
+// path or a file:// URL, which would clone the checkouts of other subtasks
+func checkRepository(repository string) error {
+	if scheme, _, ok := strings.Cut(repository, "://"); ok {
+		if !remoteSchemes[strings.ToLower(scheme)] {
+			return fmt.Errorf("invalid repository %q: scheme must be https, ssh or git", repository)
+		}
+		return nil
+	}
+
+	// git reads host:path as an ssh URL when no slash precedes the colon,
+	// and transport::address as a URL of a remote helper
+	host, path, ok := strings.Cut(repository, ":")
+	if !ok || host == "" || strings.Contains(host, "/") || strings.HasPrefix(path, ":") {
+		return fmt.Errorf("invalid repository %q: must be a remote URL", repository)
+	}
+	return nil
+}
+
+// git runs a git command and fails on a non-zero exit code
+func (w *Workspace) git(ctx context.Context, dir string, args ...string) error {
+	res, err := runCommand(ctx, dir, []string{"GIT_TERMINAL_PROMPT=0"}, nil, "git", args...)
+	if err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(res.Stderr))
+	}
+	return nil
+}
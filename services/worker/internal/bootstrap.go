@@ -1,11 +1,152 @@
 package bootstrap
 
 import (
+	"context"
 	app "distributed-analyzer/libs/application"
+	kafkaApp "distributed-analyzer/libs/application/kafka"
+	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"distributed-analyzer/services/worker/internal/cache"
 	"distributed-analyzer/services/worker/internal/config"
+	"distributed-analyzer/services/worker/internal/grpc"
+	workerKafka "distributed-analyzer/services/worker/internal/kafka"
+	"distributed-analyzer/services/worker/internal/sandbox"
+	"distributed-analyzer/services/worker/internal/service"
+	"log"
+	"os"
+	"time"
 )
 
+// registerTimeout bounds the registration with the worker manager at startup
+const registerTimeout = 10 * time.Second
+
+// StartApplication initializes and starts all application components.
+// It sets up the worker node service and the Kafka components.
 func StartApplication(cfg *config.Config) {
-	runner := app.NewApplicationRunner()
+	taskTimeout, err := time.ParseDuration(cfg.Worker.TaskTimeout)
+	if err != nil {
+		log.Fatalf("Invalid task timeout: %v", err)
+	}
+
+	reportInterval, err := time.ParseDuration(cfg.Worker.LoadReportInterval)
+	if err != nil || reportInterval <= 0 {
+		log.Fatalf("Invalid load report interval %q", cfg.Worker.LoadReportInterval)
+	}
+
+	workerID := cfg.Worker.ID
+	if workerID == "" {
+		if workerID, err = os.Hostname(); err != nil {
+			log.Fatalf("Failed to determine worker ID: %v", err)
+		}
+	}
+
+	executor, err := sandbox.New(cfg.Worker.Sandbox, taskTimeout)
+	if err != nil {
+		log.Fatalf("Failed to create sandbox: %v", err)
+	}
+
+	toolchain := cfg.Worker.Toolchain
+	if toolchain == "" {
+		if toolchain, err = analysis.ToolchainVersion(context.Background(), executor); err != nil {
+			log.Fatalf("Failed to determine Go toolchain: %v", err)
+		}
+	}
+	log.Printf("Worker %s runs Go %s", workerID, toolchain)
+
+	producer := kafka.NewProducer(cfg.Kafka.Brokers)
+	workerProducer := workerKafka.NewWorkerProducer(producer)
+
+	storageClient, err := grpc.NewStorageServiceGrpcClient(cfg.Services.Storage.GRPCAddr)
+	if err != nil {
+		log.Fatalf("Failed to create storage service client: %v", err)
+	}
+
+	workerManagerClient, err := grpc.NewWorkerManagerGrpcClient(cfg.Services.WorkerManager.GRPCAddr)
+	if err != nil {
+		log.Fatalf("Failed to create worker manager client: %v", err)
+	}
+
+	goCache := initCache(cfg, toolchain, storageClient)
+	artifacts := initArtifacts(cfg, storageClient)
+
+	modes := analysis.NewRegistry(analysis.DefaultModes(storageClient, cfg.Worker.VulnDB)...)
+	workerService := service.NewWorkerNodeServiceImpl(workerID, toolchain, cfg.Worker.WorkDir, taskTimeout, modes, executor, goCache, artifacts, workerProducer, workerManagerClient)
+	// A worker the manager does not know registers again with its next load report
+	registerCtx, cancel := context.WithTimeout(context.Background(), registerTimeout)
+	if err := workerService.Register(registerCtx); err != nil {
+		log.Printf("Failed to register with the worker manager: %v", err)
+	}
+	cancel()
+
+	pool := service.NewTaskPool(cfg.Worker.MaxConcurrentTasks, cfg.Worker.QueueSize, reportInterval, workerService.ReportLoad)
+
+	// Components stop in order: the consumer stops handing out tasks, the pool drains,
+	// the cache saves its index, and the producer publishes the results of the
+	// drained tasks before it closes
+	consumer := initKafka(cfg, workerID, toolchain, workerService, pool)
+	runner := app.NewApplicationRunner(consumer, pool, goCache, kafkaApp.NewKafkaProducerComponent(producer))
+	runner.Defer(storageClient.Close)
+	runner.Defer(workerManagerClient.Close)
 	runner.DefaultStart()
 }
+
+// initCache opens the persistent go cache, which exchanges bundles through the
+// storage service if enabled. Only workers whose sandbox keeps tasks from
+// writing the module cache publish bundles.
+func initCache(cfg *config.Config, toolchain string, store analysis.ObjectStore) *cache.Cache {
+	buildMax, err := sandbox.ParseSize(cfg.Worker.Cache.BuildMaxSize)
+	if err != nil {
+		log.Fatalf("Invalid build cache size: %v", err)
+	}
+	modMax, err := sandbox.ParseSize(cfg.Worker.Cache.ModMaxSize)
+	if err != nil {
+		log.Fatalf("Invalid module cache size: %v", err)
+	}
+	trimInterval, err := time.ParseDuration(cfg.Worker.Cache.TrimInterval)
+	if err != nil || trimInterval <= 0 {
+		log.Fatalf("Invalid cache trim interval %q", cfg.Worker.Cache.TrimInterval)
+	}
+
+	if !cfg.Worker.Cache.Bundles {
+		store = nil
+	}
+	publish := sandbox.IsolatesFiles(cfg.Worker.Sandbox)
+	if store != nil && !publish {
+		log.Printf("Cache bundles are not published, since the %q sandbox does not isolate the module cache", cfg.Worker.Sandbox.Type)
+	}
+
+	goCache, err := cache.NewCache(cfg.Worker.Cache.Dir, toolchain, cache.Limits{BuildBytes: buildMax, ModBytes: modMax}, trimInterval, store, publish)
+	if err != nil {
+		log.Fatalf("Failed to open go cache: %v", err)
+	}
+	return goCache
+}
+
+// initArtifacts creates the uploader of the artifacts subtasks produce, or
+// returns nil if artifacts are disabled
+func initArtifacts(cfg *config.Config, store analysis.ObjectStore) *service.ArtifactUploader {
+	if !cfg.Worker.Artifacts.Enabled {
+		return nil
+	}
+
+	maxSize, err := sandbox.ParseSize(cfg.Worker.Artifacts.MaxSize)
+	if err != nil {
+		log.Fatalf("Invalid artifact size limit: %v", err)
+	}
+	return service.NewArtifactUploader(store, maxSize)
+}
+
+// initKafka creates the consumer component for task assignments, including
+// those for the worker's Go toolchain and those picked for the worker itself,
+// and affected package requests
+func initKafka(cfg *config.Config, workerID, toolchain string, workerService service.WorkerNodeService, pool *service.TaskPool) *kafkaApp.ConsumerComponent {
+	workerHandler := workerKafka.NewWorkerHandler(workerService, pool)
+	topics := []string{
+		kafka.TaskAssignedTopic(""),
+		kafka.TaskAssignedTopic(toolchain),
+		kafka.WorkerTaskAssignedTopic(workerID),
+		"affected-packages-requested",
+	}
+	consumer := kafka.NewConsumer(topics, cfg.Kafka.Brokers, cfg.Kafka.GroupID, workerHandler)
+	return kafkaApp.NewKafkaComponent(consumer)
+}
@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// bundlePrefix is the prefix of the keys of cache bundles in the object store
+const bundlePrefix = "cache-bundles/"
+
+// maxBundleBytes bounds the files of a bundle, which must fit in a single storage message
+const maxBundleBytes = 96 << 20
+
+// BundleKey returns the key of the bundle of a scope for a go.sum and toolchain
+// release. Checkouts with the same go.sum need the same modules, so they share
+// a bundle, but scopes never share bundles.
+func BundleKey(scope string, sum []byte, toolchain string) string {
+	hash := sha256.Sum256(sum)
+	return bundlePrefix + scopeDir(scope) + "/go" + toolchain + "/" + hex.EncodeToString(hash[:]) + ".tar.gz"
+}
+
+// Publish uploads a bundle of the scope for the go.sum of the checkout in dir,
+// unless one exists. It holds the go.mod and zip files of the modules the
+// go.sum lists that match their hashes in the go.sum, up to maxBundleBytes.
+// A bundle the cache was seeded from is replaced if the cache now has more
+// matching files, so that incomplete or tampered bundles do not stay forever.
+// The build cache is never published, and only caches the sandbox keeps
+// commands from writing publish bundles.
+func (c *Cache) Publish(ctx context.Context, scope, dir string) error {
+	if c.store == nil || !c.publish {
+		return nil
+	}
+
+	sum, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil {
+		return nil
+	}
+
+	key := BundleKey(scope, sum, c.toolchain)
+	existing, err := c.store.List(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to look up bundle: %w", err)
+	}
+	c.mu.Lock()
+	held, seeded := c.seeded[key]
+	c.mu.Unlock()
+	if len(existing) > 0 && !seeded {
+		return nil
+	}
+
+	bundle, n, err := c.bundle(sumHashes(sum))
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if n == 0 || (len(existing) > 0 && n <= held) {
+		return nil
+	}
+	if err := c.store.Put(ctx, key, bundle); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.seeded[key] = n
+	c.mu.Unlock()
+	return nil
+}
+
+// bundle archives the downloaded go.mod and zip files whose hashes match the
+// given ones. It returns the archive and the number of files in it.
+func (c *Cache) bundle(hashes map[string]string) ([]byte, int, error) {
+	files := make([]string, 0, len(hashes))
+	for file := range hashes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	var size int64
+	var n int
+	for _, file := range files {
+		module, ext := splitSumFile(file)
+		name := c.downloadPath(module, ext)
+		info, err := os.Stat(name)
+		if err != nil || !info.Mode().IsRegular() || size+info.Size() > maxBundleBytes {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil || !verifyFile(ext, data, hashes[file]) {
+			continue
+		}
+
+		rel, err := filepath.Rel(c.dir, name)
+		if err != nil {
+			continue
+		}
+		if err := addFile(tw, filepath.ToSlash(rel), data, info.ModTime()); err != nil {
+			return nil, 0, err
+		}
+		size += info.Size()
+		n++
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), n, nil
+}
+
+// addFile adds a file to a tar archive under the given name
+func addFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// seed fetches the bundle of the scope for a go.sum, if there is one, and adds
+// the files the cache lacks. It returns the number of files added, and
+// remembers how many files of the bundle matched the go.sum.
+func (c *Cache) seed(ctx context.Context, scope string, sum []byte) (int, error) {
+	key := BundleKey(scope, sum, c.toolchain)
+	existing, err := c.store.List(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up bundle: %w", err)
+	}
+	if len(existing) == 0 {
+		return 0, nil
+	}
+
+	bundle, err := c.store.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch bundle: %w", err)
+	}
+	added, valid, err := c.extract(bundle, sumHashes(sum))
+	c.mu.Lock()
+	c.seeded[key] = valid
+	c.mu.Unlock()
+	return added, err
+}
+
+// extract adds the files of a bundle to the cache. Only the go.mod and zip files
+// of the download cache whose hashes match the given ones are accepted, and
+// existing files are kept. It returns the number of files added and the number
+// of files that matched their hashes.
+func (c *Cache) extract(bundle []byte, hashes map[string]string) (int, int, error) {
+	// The hashes are keyed by module@version and extension, the bundle by path
+	expected := make(map[string]string, len(hashes))
+	for file := range hashes {
+		rel, err := filepath.Rel(c.dir, c.downloadPath(splitSumFile(file)))
+		if err == nil {
+			expected[filepath.ToSlash(rel)] = file
+		}
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(bundle))
+	if err != nil {
+		return 0, 0, err
+	}
+	tr := tar.NewReader(gz)
+
+	var added, valid int
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return added, valid, nil
+		}
+		if err != nil {
+			return added, valid, err
+		}
+
+		name := path.Clean(header.Name)
+		sumFile, ok := expected[name]
+		if header.Typeflag != tar.TypeReg || !ok || header.Size > maxBundleBytes {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleBytes))
+		if err != nil {
+			return added, valid, err
+		}
+		if _, ext := splitSumFile(sumFile); !verifyFile(ext, data, hashes[sumFile]) {
+			log.Printf("Ignoring %s of cache bundle, which does not match go.sum", name)
+			continue
+		}
+		valid++
+
+		file := filepath.Join(c.dir, filepath.FromSlash(name))
+		if _, err := os.Stat(file); err == nil {
+			continue
+		}
+		if err := writeFile(file, bytes.NewReader(data)); err != nil {
+			return added, valid, err
+		}
+		added++
+	}
+}
+
+// writeFile writes the content of r to file through a temporary file, so that
+// the go command never sees a partially written cache entry. The file counts
+// as just used, so that seeded entries are not the first to be evicted.
+func writeFile(file string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".bundle-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
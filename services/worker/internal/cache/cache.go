@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxRepositories bounds the repositories a cache remembers as warm
+const maxRepositories = 32
+
+// indexFile records when the modules in the cache were last used
+const indexFile = "index.json"
+
+// Limits bound the size of a cache on disk
+type Limits struct {
+	// BuildBytes bounds the build cache, GOCACHE
+	BuildBytes int64
+
+	// ModBytes bounds the module cache, GOMODCACHE
+	ModBytes int64
+}
+
+// Cache is the persistent build and module cache the tasks of a worker share.
+// Each scope, such as a tenant, has a build cache of its own, while the module
+// cache is shared and only written by trusted go commands. It evicts the least recently used entries once it outgrows its limits, and
+// exchanges bundles of entries with other workers through the object store.
+type Cache struct {
+	dir          string
+	toolchain    string
+	limits       Limits
+	trimInterval time.Duration
+
+	// store holds the cache bundles; nil disables bundles
+	store analysis.ObjectStore
+
+	// publish enables publishing bundles, which is only safe if tasks cannot write the module cache
+	publish bool
+
+	// seeded maps the keys of the bundles the cache was seeded from to the
+	// number of files in them that matched the go.sum
+	seeded map[string]int
+
+	// modules maps module@version to when a task last used it
+	modules map[string]time.Time
+
+	// leases counts the running tasks using each module@version, which are never evicted
+	leases map[string]int
+
+	// repositories lists the repositories analyzed most recently first
+	repositories []string
+
+	stop chan struct{}
+	done chan struct{}
+	mu   sync.Mutex
+}
+
+// index is the persisted part of a cache
+type index struct {
+	Modules      map[string]time.Time `json:"modules"`
+	Repositories []string             `json:"repositories"`
+}
+
+// NewCache opens the cache in dir for the given toolchain release, creating it if needed.
+// It seeds itself from the bundles in store, and publishes bundles there if publish is set.
+func NewCache(dir, toolchain string, limits Limits, trimInterval time.Duration, store analysis.ObjectStore, publish bool) (*Cache, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cache{
+		dir:          dir,
+		toolchain:    toolchain,
+		limits:       limits,
+		trimInterval: trimInterval,
+		store:        store,
+		publish:      publish,
+		seeded:       make(map[string]int),
+		modules:      make(map[string]time.Time),
+		leases:       make(map[string]int),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	for _, d := range []string{c.buildDir(), c.modDir()} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cache: %w", err)
+		}
+	}
+	c.removeSharedBuildCache()
+
+	content, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+	if err == nil {
+		var idx index
+		if err := json.Unmarshal(content, &idx); err != nil {
+			log.Printf("Ignoring corrupt cache index: %v", err)
+		} else {
+			if idx.Modules != nil {
+				c.modules = idx.Modules
+			}
+			c.repositories = idx.Repositories
+		}
+	}
+
+	return c, nil
+}
+
+// Env returns the go command environment that points it at the cache of a scope
+func (c *Cache) Env(scope string) []string {
+	return []string{"GOCACHE=" + c.scopeBuildDir(scope), "GOMODCACHE=" + c.modDir()}
+}
+
+// Mounts returns the directories of the cache the go commands of a scope use.
+// The module cache is read-only, since only trusted go commands may fill it.
+func (c *Cache) Mounts(scope string) []analysis.Mount {
+	return []analysis.Mount{{Dir: c.scopeBuildDir(scope)}, {Dir: c.modDir(), ReadOnly: true}}
+}
+
+// Prepare creates the build cache of the scope and leases the modules the
+// go.sum of the checkout in dir lists, so they are not evicted while the task
+// runs. A cache that lacks some of them is seeded with the bundle of the scope
+// for the go.sum first, if another worker published one. The returned function
+// ends the lease.
+func (c *Cache) Prepare(ctx context.Context, scope, dir string) func() {
+	if err := os.MkdirAll(c.scopeBuildDir(scope), 0o755); err != nil {
+		log.Printf("Failed to create build cache: %v", err)
+	}
+
+	sum, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil {
+		return func() {}
+	}
+	modules := sumModules(sum)
+
+	c.mu.Lock()
+	now := time.Now()
+	for _, module := range modules {
+		c.leases[module]++
+		c.modules[module] = now
+	}
+	c.mu.Unlock()
+
+	if c.store != nil && !c.hasModules(modules) {
+		if n, err := c.seed(ctx, scope, sum); err != nil {
+			log.Printf("Failed to seed cache from bundle: %v", err)
+		} else if n > 0 {
+			log.Printf("Seeded cache with %d files from bundle", n)
+		}
+	}
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, module := range modules {
+			if c.leases[module]--; c.leases[module] <= 0 {
+				delete(c.leases, module)
+			}
+		}
+	}
+}
+
+// UseRepository records that a task analyzed the repository with this cache
+func (c *Cache) UseRepository(repository string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	repositories := []string{repository}
+	for _, r := range c.repositories {
+		if r != repository && len(repositories) < maxRepositories {
+			repositories = append(repositories, r)
+		}
+	}
+	c.repositories = repositories
+}
+
+// Repositories returns the repositories the cache is warm for, most recent first
+func (c *Cache) Repositories() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.repositories...)
+}
+
+// Start trims the cache and keeps trimming it at the trim interval
+func (c *Cache) Start(ctx context.Context) error {
+	go func() {
+		defer close(c.done)
+
+		ticker := time.NewTicker(c.trimInterval)
+		defer ticker.Stop()
+
+		for {
+			c.Trim()
+			select {
+			case <-ticker.C:
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops trimming and saves the index
+func (c *Cache) Stop(ctx context.Context) error {
+	close(c.stop)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return c.save()
+}
+
+// Name returns the component name for logging and identification
+func (c *Cache) Name() string {
+	return "GoCache"
+}
+
+// save persists the index
+func (c *Cache) save() error {
+	c.mu.Lock()
+	content, err := json.Marshal(index{Modules: c.modules, Repositories: c.repositories})
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(c.dir, indexFile+".tmp")
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache index: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(c.dir, indexFile))
+}
+
+// hasModules reports whether the module cache holds the go.mod of every module
+func (c *Cache) hasModules(modules []string) bool {
+	for _, module := range modules {
+		if _, err := os.Stat(c.downloadPath(module, ".mod")); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// removeSharedBuildCache removes what the build directory holds besides the
+// build caches of scopes, such as a build cache all tasks used to share
+func (c *Cache) removeSharedBuildCache() {
+	entries, err := os.ReadDir(c.buildDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !isScopeDir(entry.Name()) {
+			os.RemoveAll(filepath.Join(c.buildDir(), entry.Name()))
+		}
+	}
+}
+
+// scopeDir returns the directory name of the build cache of a scope, which
+// keeps scopes from naming paths
+func scopeDir(scope string) string {
+	sum := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(sum[:16])
+}
+
+// isScopeDir reports whether name is the name scopeDir returns for some scope
+func isScopeDir(name string) bool {
+	_, err := hex.DecodeString(name)
+	return err == nil && len(name) == 32
+}
+
+func (c *Cache) buildDir() string {
+	return filepath.Join(c.dir, "build")
+}
+
+func (c *Cache) scopeBuildDir(scope string) string {
+	return filepath.Join(c.buildDir(), scopeDir(scope))
+}
+
+func (c *Cache) modDir() string {
+	return filepath.Join(c.dir, "mod")
+}
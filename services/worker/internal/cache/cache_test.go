@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryStore is an in-memory object store
+type memoryStore map[string][]byte
+
+func (s memoryStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range s {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s[key], nil
+}
+
+func (s memoryStore) Put(ctx context.Context, key string, content []byte) error {
+	s[key] = content
+	return nil
+}
+
+func writeTestFile(t *testing.T, file string, size int, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSumModules(t *testing.T) {
+	sum := []byte(`github.com/BurntSushi/toml v1.3.2 h1:o7IhLm0Msx3BaB+n3Ag7L8EVlByGnpq14C4YWiu/gL8=
+github.com/BurntSushi/toml v1.3.2/go.mod h1:CxXYINrC8qIiEnFrOxCa7Jy5BFHlXnUU2pbicEuybxQ=
+golang.org/x/mod v0.14.0/go.mod h1:hTbmBsO62+eylJbnUtE2MGJUyE7QWk4xUqPFrRgJ+7c=
+`)
+	expected := []string{"github.com/BurntSushi/toml@v1.3.2", "golang.org/x/mod@v0.14.0"}
+	if got := sumModules(sum); !reflect.DeepEqual(got, expected) {
+		t.Errorf("sumModules() = %v, expected %v", got, expected)
+	}
+
+	c := &Cache{dir: "/cache"}
+	if got := c.downloadPath("github.com/BurntSushi/toml@v1.3.2", ".zip"); got != "/cache/mod/cache/download/github.com/!burnt!sushi/toml/@v/v1.3.2.zip" {
+		t.Errorf("Unexpected download path %q", got)
+	}
+}
+
+func TestTrimBuild(t *testing.T) {
+	c, err := NewCache(t.TempDir(), "1.24", Limits{BuildBytes: 300}, time.Minute, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	writeTestFile(t, filepath.Join(c.scopeBuildDir("a"), "trim.txt"), 1000, now.Add(-time.Hour))
+	for i, name := range []string{"00/a-d", "01/b-d", "02/c-d", "03/d-d"} {
+		// The entries alternate between the build caches of two scopes
+		scope := []string{"a", "b"}[i%2]
+		writeTestFile(t, filepath.Join(c.scopeBuildDir(scope), name), 100, now.Add(time.Duration(i-4)*time.Minute))
+	}
+
+	if n, freed := c.trimBuild(); n != 2 || freed != 200 {
+		t.Errorf("trimBuild() = %d, %d, expected 2, 200", n, freed)
+	}
+	for name, kept := range map[string]bool{"a/trim.txt": true, "a/00/a-d": false, "b/01/b-d": false, "a/02/c-d": true, "b/03/d-d": true} {
+		scope, name, _ := strings.Cut(name, "/")
+		if _, err := os.Stat(filepath.Join(c.scopeBuildDir(scope), name)); (err == nil) != kept {
+			t.Errorf("Expected %s to be kept: %v", name, kept)
+		}
+	}
+}
+
+// writeTestModule writes the go.mod and zip of example.com/m@v1.0.0 with the
+// given source to a cache and returns the go.sum lines for them
+func writeTestModule(t *testing.T, c *Cache, source string) []byte {
+	t.Helper()
+	mod := []byte("module example.com/m\n")
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for name, content := range map[string][]byte{"go.mod": mod, "m.go": []byte(source)} {
+		w, err := zw.Create("example.com/m@v1.0.0/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for ext, content := range map[string][]byte{".mod": mod, ".zip": zipped.Bytes()} {
+		file := c.downloadPath("example.com/m@v1.0.0", ext)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zipHash, err := hashZip(zipped.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	modHash, err := hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(mod)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte("example.com/m v1.0.0 " + zipHash + "\nexample.com/m v1.0.0/go.mod " + modHash + "\n")
+}
+
+func TestVerifyFile(t *testing.T) {
+	// The go.mod the go command synthesizes for golang.org/x/text v0.3.0
+	if !verifyFile(".mod", []byte("module golang.org/x/text\n"), "h1:NqM8EUOU14njkJ3fqMW+pc6Ldnwhi/IjpwHt7yyuwOQ=") {
+		t.Error("Expected the go.mod to match its go.sum hash")
+	}
+	if verifyFile(".mod", []byte("module golang.org/x/text\n\nrequire evil.com/x v1.0.0\n"), "h1:NqM8EUOU14njkJ3fqMW+pc6Ldnwhi/IjpwHt7yyuwOQ=") {
+		t.Error("Expected a modified go.mod not to match")
+	}
+}
+
+func TestBundle(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore{}
+
+	src, err := NewCache(t.TempDir(), "1.24", Limits{}, time.Minute, store, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := writeTestModule(t, src, "package m\n")
+	writeTestFile(t, src.downloadPath("example.com/m@v1.0.0", ".info"), 10, time.Now())
+	writeTestFile(t, filepath.Join(src.scopeBuildDir("tenant-a"), "aa", "new-d"), 10, time.Now())
+
+	checkout := t.TempDir()
+	if err := os.WriteFile(filepath.Join(checkout, "go.sum"), sum, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := src.Publish(ctx, "tenant-a", checkout); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store[BundleKey("tenant-a", sum, "1.24")]; !ok || len(store) != 1 {
+		t.Fatalf("Expected a bundle of the tenant in the store, got %v", store)
+	}
+
+	other, err := NewCache(t.TempDir(), "1.24", Limits{}, time.Minute, store, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Prepare(ctx, "tenant-b", checkout)()
+	if other.hasModules([]string{"example.com/m@v1.0.0"}) {
+		t.Error("Expected the bundle of another tenant not to seed the module")
+	}
+
+	dst, err := NewCache(t.TempDir(), "1.24", Limits{}, time.Minute, store, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := dst.Prepare(ctx, "tenant-a", checkout)
+	defer release()
+
+	for _, ext := range []string{".mod", ".zip"} {
+		if _, err := os.Stat(dst.downloadPath("example.com/m@v1.0.0", ext)); err != nil {
+			t.Errorf("Expected the bundle to seed the %s file", ext)
+		}
+	}
+	if _, err := os.Stat(dst.downloadPath("example.com/m@v1.0.0", ".info")); err == nil {
+		t.Error("Expected the bundle to leave out the .info file")
+	}
+	if _, err := os.Stat(filepath.Join(dst.scopeBuildDir("tenant-a"), "aa", "new-d")); err == nil {
+		t.Error("Expected the bundle to leave out the build cache")
+	}
+}
+
+func TestBundleRejectsTamperedModules(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore{}
+
+	src, err := NewCache(t.TempDir(), "1.24", Limits{}, time.Minute, store, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := writeTestModule(t, src, "package m\n\nfunc init() { panic(\"tampered\") }\n")
+	bundle, _, err := src.bundle(sumHashes(tampered))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := NewCache(t.TempDir(), "1.24", Limits{}, time.Minute, store, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkout := t.TempDir()
+	sum := writeTestModule(t, &Cache{dir: checkout}, "package m\n")
+	if err := os.WriteFile(filepath.Join(checkout, "go.sum"), sum, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bundle for the go.sum holds a zip that does not match it
+	key := BundleKey("tenant-a", sum, "1.24")
+	store[key] = bundle
+	dst.Prepare(ctx, "tenant-a", checkout)()
+	if _, err := os.Stat(dst.downloadPath("example.com/m@v1.0.0", ".zip")); err == nil {
+		t.Error("Expected the tampered zip not to be seeded")
+	}
+
+	// Once the module is downloaded, the tampered bundle is replaced
+	writeTestModule(t, dst, "package m\n")
+	if err := dst.Publish(ctx, "tenant-a", checkout); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(store[key], bundle) {
+		t.Error("Expected the tampered bundle to be replaced")
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// sumModules returns the module@version pairs a go.sum lists, including
+// those it only has the go.mod of, in sorted order
+func sumModules(sum []byte) []string {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(sum), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		version := strings.TrimSuffix(fields[1], "/go.mod")
+		seen[fields[0]+"@"+version] = true
+	}
+
+	modules := make([]string, 0, len(seen))
+	for module := range seen {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// escapePath escapes a module path or version the way the module cache stores it:
+// upper-case letters become an exclamation mark followed by the letter in lower case
+func escapePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// downloadPath returns the path of a file of a module@version in the download
+// cache, with ext one of .info, .mod, .zip or .ziphash
+func (c *Cache) downloadPath(module, ext string) string {
+	path, version, _ := strings.Cut(module, "@")
+	return filepath.Join(c.modDir(), "cache", "download", filepath.FromSlash(escapePath(path)), "@v", escapePath(version)+ext)
+}
+
+// extractedPath returns the directory a module@version is extracted to
+func (c *Cache) extractedPath(module string) string {
+	path, version, _ := strings.Cut(module, "@")
+	return filepath.Join(c.modDir(), filepath.FromSlash(escapePath(path))+"@"+escapePath(version))
+}
+
+// downloadExts are the files of a module@version in the download cache
+var downloadExts = []string{".info", ".mod", ".zip", ".ziphash"}
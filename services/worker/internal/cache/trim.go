@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// trimTarget is the share of its limit a cache is trimmed down to,
+// so that it is not trimmed again right after the next task
+const trimTarget = 0.9
+
+// Trim evicts the least recently used entries of the build and module
+// caches until both are within their limits, and saves the index
+func (c *Cache) Trim() {
+	if c.limits.BuildBytes > 0 {
+		if n, freed := c.trimBuild(); n > 0 {
+			log.Printf("Evicted %d build cache entries, %d bytes", n, freed)
+		}
+	}
+	if c.limits.ModBytes > 0 {
+		if n, freed := c.trimModules(); n > 0 {
+			log.Printf("Evicted %d modules from the module cache, %d bytes", n, freed)
+		}
+	}
+
+	if err := c.save(); err != nil {
+		log.Printf("Failed to save cache index: %v", err)
+	}
+}
+
+// cacheEntry is a file of the build cache
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// trimBuild removes the build cache entries used least recently across the
+// build caches of all scopes. The go command refreshes the modification time
+// of the entries it uses, so it serves as the time of last use.
+func (c *Cache) trimBuild() (int, int64) {
+	var entries []cacheEntry
+	var total int64
+	filepath.WalkDir(c.buildDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Dir(filepath.Dir(path)) == c.buildDir() {
+			// Files at the top of a build cache, such as README and trim.txt, are not entries
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, cacheEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if total <= c.limits.BuildBytes {
+		return 0, 0
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+
+	target := int64(float64(c.limits.BuildBytes) * trimTarget)
+	var n int
+	var freed int64
+	for _, entry := range entries {
+		if total-freed <= target {
+			break
+		}
+		if err := os.Remove(entry.path); err == nil {
+			n++
+			freed += entry.size
+		}
+	}
+	return n, freed
+}
+
+// trimModules removes the modules used least recently that no running task uses
+func (c *Cache) trimModules() (int, int64) {
+	total := dirSize(c.modDir())
+	if total <= c.limits.ModBytes {
+		return 0, 0
+	}
+
+	c.mu.Lock()
+	candidates := make([]string, 0, len(c.modules))
+	for module := range c.modules {
+		if c.leases[module] == 0 {
+			candidates = append(candidates, module)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return c.modules[candidates[i]].Before(c.modules[candidates[j]])
+	})
+	c.mu.Unlock()
+
+	target := int64(float64(c.limits.ModBytes) * trimTarget)
+	var n int
+	var freed int64
+	for _, module := range candidates {
+		if total-freed <= target {
+			break
+		}
+
+		c.mu.Lock()
+		if c.leases[module] > 0 {
+			// A task started using the module meanwhile
+			c.mu.Unlock()
+			continue
+		}
+		delete(c.modules, module)
+		c.mu.Unlock()
+
+		freed += c.removeModule(module)
+		n++
+	}
+	return n, freed
+}
+
+// removeModule removes the extracted directory and the downloaded files of a module@version
+func (c *Cache) removeModule(module string) int64 {
+	var freed int64
+
+	dir := c.extractedPath(module)
+	if size := dirSize(dir); size > 0 {
+		// The module cache makes extracted directories read-only
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				os.Chmod(path, 0o755)
+			}
+			return nil
+		})
+		if err := os.RemoveAll(dir); err == nil {
+			freed += size
+		}
+	}
+
+	for _, ext := range downloadExts {
+		path := c.downloadPath(module, ext)
+		if info, err := os.Stat(path); err == nil && os.Remove(path) == nil {
+			freed += info.Size()
+		}
+	}
+	return freed
+}
+
+// dirSize returns the total size of the files below dir
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxModuleZipBytes bounds the uncompressed size of a module zip, as the go command does
+const maxModuleZipBytes = 500 << 20
+
+// sumHashes returns the hashes a go.sum lists, keyed by the module@version
+// followed by the extension of the file they are the hash of, .mod or .zip
+func sumHashes(sum []byte) map[string]string {
+	hashes := make(map[string]string)
+	for _, line := range strings.Split(string(sum), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "h1:") {
+			continue
+		}
+		if version, ok := strings.CutSuffix(fields[1], "/go.mod"); ok {
+			hashes[fields[0]+"@"+version+".mod"] = fields[2]
+		} else {
+			hashes[fields[0]+"@"+fields[1]+".zip"] = fields[2]
+		}
+	}
+	return hashes
+}
+
+// splitSumFile splits a key of sumHashes into the module@version and the extension
+func splitSumFile(file string) (string, string) {
+	ext := path.Ext(file)
+	return strings.TrimSuffix(file, ext), ext
+}
+
+// verifyFile reports whether the content of a .mod or .zip file of the
+// download cache has the hash the go.sum lists for it
+func verifyFile(ext string, data []byte, want string) bool {
+	var got string
+	var err error
+	switch ext {
+	case ".mod":
+		got, err = hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		})
+	case ".zip":
+		got, err = hashZip(data)
+	default:
+		return false
+	}
+	return err == nil && got == want
+}
+
+// hashZip returns the h1 hash of the files in a module zip
+func hashZip(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var size uint64
+	files := make(map[string]*zip.File, len(zr.File))
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		if size += f.UncompressedSize64; size > maxModuleZipBytes {
+			return "", errors.New("module zip too large")
+		}
+		files[f.Name] = f
+		names = append(names, f.Name)
+	}
+
+	return hash1(names, func(name string) (io.ReadCloser, error) {
+		rc, err := files[name].Open()
+		if err != nil {
+			return nil, err
+		}
+		// The declared sizes may lie, so the content is limited as well
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(rc, maxModuleZipBytes), rc}, nil
+	})
+}
+
+// hash1 computes the h1 hash go.sum lists for a set of files: the SHA-256 of
+// a summary listing the SHA-256 and name of each file, sorted by name
+func hash1(names []string, open func(string) (io.ReadCloser, error)) (string, error) {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	summary := sha256.New()
+	for _, name := range names {
+		if strings.Contains(name, "\n") {
+			return "", errors.New("file name contains a newline")
+		}
+		r, err := open(name)
+		if err != nil {
+			return "", err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(summary, "%x  %s\n", h.Sum(nil), name)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil)), nil
+}
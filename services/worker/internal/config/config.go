@@ -25,25 +25,50 @@ type KafkaTopicsConfig struct {
 }
 
 type ServicesConfig struct {
-	Storage configloader.ServiceConnectionConfig `yaml:"storage"`
-	Result  configloader.ServiceConnectionConfig `yaml:"result"`
+	Storage       configloader.ServiceConnectionConfig `yaml:"storage"`
+	Result        configloader.ServiceConnectionConfig `yaml:"result"`
+	WorkerManager configloader.ServiceConnectionConfig `yaml:"worker_manager"`
 }
 
 type WorkerConfig struct {
-	Capabilities       []string      `yaml:"capabilities"          env:"WORKER_CAPABILITIES"          env-default:"go_build,go_test,go_lint,go_benchmark,go_race"`
-	MaxConcurrentTasks int           `yaml:"max_concurrent_tasks"  env:"WORKER_MAX_CONCURRENT_TASKS"  env-default:"5"`
-	TaskTimeout        string        `yaml:"task_timeout"          env:"WORKER_TASK_TIMEOUT"          env-default:"300s"`
-	Sandbox            SandboxConfig `yaml:"sandbox"`
+	ID                 string          `yaml:"id"                    env:"WORKER_ID"`
+	WorkDir            string          `yaml:"work_dir"              env:"WORKER_WORK_DIR"              env-default:"/tmp/worker"`
+	Capabilities       []string        `yaml:"capabilities"          env:"WORKER_CAPABILITIES"          env-default:"go_build,go_test,go_lint,go_benchmark,go_race,go_cover,go_fuzz,go_affected,go_deps,go_license"`
+	MaxConcurrentTasks int             `yaml:"max_concurrent_tasks"  env:"WORKER_MAX_CONCURRENT_TASKS"  env-default:"5"`
+	QueueSize          int             `yaml:"queue_size"            env:"WORKER_QUEUE_SIZE"            env-default:"100"`
+	LoadReportInterval string          `yaml:"load_report_interval"  env:"WORKER_LOAD_REPORT_INTERVAL"  env-default:"15s"`
+	TaskTimeout        string          `yaml:"task_timeout"          env:"WORKER_TASK_TIMEOUT"          env-default:"300s"`
+	VulnDB             string          `yaml:"vuln_db"               env:"WORKER_VULN_DB"`
+	Toolchain          string          `yaml:"toolchain"             env:"WORKER_TOOLCHAIN"`
+	Sandbox            SandboxConfig   `yaml:"sandbox"`
+	Cache              CacheConfig     `yaml:"cache"`
+	Artifacts          ArtifactsConfig `yaml:"artifacts"`
 }
 
 type SandboxConfig struct {
-	Enabled   bool            `yaml:"enabled" env:"SANDBOX_ENABLED" env-default:"true"`
-	Type      string          `yaml:"type"    env:"SANDBOX_TYPE"    env-default:"docker"`
-	Image     string          `yaml:"image"   env:"SANDBOX_IMAGE"   env-default:"golang:1.20-alpine"`
-	Resources ResourcesConfig `yaml:"resources"`
+	Enabled    bool            `yaml:"enabled"     env:"SANDBOX_ENABLED"     env-default:"true"`
+	Type       string          `yaml:"type"        env:"SANDBOX_TYPE"        env-default:"docker"`
+	Image      string          `yaml:"image"       env:"SANDBOX_IMAGE"       env-default:"golang:1.20-alpine"`
+	CgroupRoot string          `yaml:"cgroup_root" env:"SANDBOX_CGROUP_ROOT" env-default:"/sys/fs/cgroup/distributed-analyzer"`
+	Resources  ResourcesConfig `yaml:"resources"`
+}
+
+type CacheConfig struct {
+	Dir          string `yaml:"dir"            env:"CACHE_DIR"            env-default:"/tmp/worker-cache"`
+	BuildMaxSize string `yaml:"build_max_size" env:"CACHE_BUILD_MAX_SIZE" env-default:"10GB"`
+	ModMaxSize   string `yaml:"mod_max_size"   env:"CACHE_MOD_MAX_SIZE"   env-default:"5GB"`
+	TrimInterval string `yaml:"trim_interval"  env:"CACHE_TRIM_INTERVAL"  env-default:"10m"`
+	Bundles      bool   `yaml:"bundles"        env:"CACHE_BUNDLES"        env-default:"false"`
+}
+
+type ArtifactsConfig struct {
+	Enabled bool   `yaml:"enabled"  env:"ARTIFACTS_ENABLED"  env-default:"true"`
+	MaxSize string `yaml:"max_size" env:"ARTIFACTS_MAX_SIZE" env-default:"64MB"`
 }
 
 type ResourcesConfig struct {
 	CPULimit    int    `yaml:"cpu_limit"     env:"RESOURCES_CPU_LIMIT"     env-default:"1"`
 	MemoryLimit string `yaml:"memory_limit"  env:"RESOURCES_MEMORY_LIMIT"  env-default:"512MB"`
+	PidsLimit   int    `yaml:"pids_limit"    env:"RESOURCES_PIDS_LIMIT"    env-default:"512"`
+	OpenFiles   int    `yaml:"open_files"    env:"RESOURCES_OPEN_FILES"    env-default:"4096"`
 }
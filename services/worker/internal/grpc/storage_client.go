@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/storage"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"google.golang.org/grpc"
+)
+
+// maxObjectMessageSize bounds the size of objects exchanged with the storage service
+const maxObjectMessageSize = 128 << 20
+
+// StorageServiceGrpcClient is a gRPC client for the storage service
+type StorageServiceGrpcClient struct {
+	client pb.StorageServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ analysis.ObjectStore = (*StorageServiceGrpcClient)(nil)
+
+// NewStorageServiceGrpcClient creates a new StorageServiceGrpcClient
+func NewStorageServiceGrpcClient(serverAddr string) (*StorageServiceGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StorageServiceGrpcClient{
+		client: pb.NewStorageServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+// Close closes the connection
+func (s *StorageServiceGrpcClient) Close() error {
+	return s.conn.Close()
+}
+
+// List lists the keys of the objects whose keys start with prefix
+func (s *StorageServiceGrpcClient) List(ctx context.Context, prefix string) ([]string, error) {
+	resp, err := s.client.ListObjects(ctx, &pb.ListObjectsRequest{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(resp.Objects))
+	for i, object := range resp.Objects {
+		keys[i] = object.Key
+	}
+	return keys, nil
+}
+
+// Get retrieves the content of an object
+func (s *StorageServiceGrpcClient) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.client.GetObject(ctx, &pb.GetObjectRequest{Key: key}, grpc.MaxCallRecvMsgSize(maxObjectMessageSize))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Content, nil
+}
+
+// Put stores an object
+func (s *StorageServiceGrpcClient) Put(ctx context.Context, key string, content []byte) error {
+	_, err := s.client.PutObject(ctx, &pb.PutObjectRequest{Key: key, Content: content}, grpc.MaxCallSendMsgSize(maxObjectMessageSize))
+	return err
+}
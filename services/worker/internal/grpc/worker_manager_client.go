@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/libs/network/client"
+	pb "distributed-analyzer/libs/proto/worker"
+	"distributed-analyzer/services/worker/internal/service"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WorkerManagerGrpcClient is a gRPC client for the worker manager
+type WorkerManagerGrpcClient struct {
+	client pb.WorkerManagerServiceClient
+	conn   *grpc.ClientConn
+}
+
+var _ service.LoadReporter = (*WorkerManagerGrpcClient)(nil)
+
+// NewWorkerManagerGrpcClient creates a new WorkerManagerGrpcClient
+func NewWorkerManagerGrpcClient(serverAddr string) (*WorkerManagerGrpcClient, error) {
+	conn, err := client.NewGrpcResilientClient(nil, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WorkerManagerGrpcClient{
+		client: pb.NewWorkerManagerServiceClient(conn),
+		conn:   conn,
+	}, nil
+}
+
+// Close closes the connection
+func (c *WorkerManagerGrpcClient) Close() error {
+	return c.conn.Close()
+}
+
+// Register registers a worker under its ID with the capabilities and resources it offers
+func (c *WorkerManagerGrpcClient) Register(ctx context.Context, workerID string, capabilities []model.Capability, resources []model.Resource) error {
+	req := &pb.RegisterWorkerRequest{Name: workerID}
+	for _, capability := range capabilities {
+		req.Capabilities = append(req.Capabilities, &pb.Capability{Name: capability.Name, Value: capability.Value})
+	}
+	for _, resource := range resources {
+		req.Resources = append(req.Resources, &pb.Resource{Type: resource.Type, Value: int32(resource.Value)})
+	}
+
+	_, err := c.client.RegisterWorker(ctx, req)
+	return err
+}
+
+// ReportLoad reports the running and queued tasks of a worker and the repositories its cache is warm for
+func (c *WorkerManagerGrpcClient) ReportLoad(ctx context.Context, workerID string, running, queued, capacity int, warm []string) error {
+	_, err := c.client.ReportWorkerLoad(ctx, &pb.ReportWorkerLoadRequest{
+		Id:               workerID,
+		RunningTasks:     int32(running),
+		QueuedTasks:      int32(queued),
+		Capacity:         int32(capacity),
+		WarmRepositories: warm,
+	})
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %v", service.ErrWorkerNotRegistered, err)
+	}
+	return err
+}
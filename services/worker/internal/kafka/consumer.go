@@ -2,30 +2,38 @@ package kafka
 
 import (
 	"context"
+	"distributed-analyzer/libs/model"
 	pb "distributed-analyzer/libs/proto/kafka"
 	"distributed-analyzer/services/worker/internal/service"
 	"encoding/json"
 	"fmt"
 	"github.com/segmentio/kafka-go"
 	"log"
+	"strings"
 )
 
-// WorkerHandler is a Kafka consumer for worker events
+// WorkerHandler is a Kafka consumer for worker events.
+// It queues the work an event asks for on the task pool, so that reading
+// further events does not wait for it.
 type WorkerHandler struct {
 	workerService service.WorkerNodeService
+	pool          *service.TaskPool
 }
 
 // NewWorkerHandler creates a new WorkerHandler
-func NewWorkerHandler(workerService service.WorkerNodeService) *WorkerHandler {
+func NewWorkerHandler(workerService service.WorkerNodeService, pool *service.TaskPool) *WorkerHandler {
 	return &WorkerHandler{
 		workerService: workerService,
+		pool:          pool,
 	}
 }
 
 func (c *WorkerHandler) HandleMessage(ctx context.Context, topic string, message kafka.Message) error {
-	switch topic {
-	case "task-assigned":
+	switch {
+	case topic == "task-assigned", strings.HasPrefix(topic, "task-assigned."):
 		return c.handleTaskAssigned(ctx, message)
+	case topic == "affected-packages-requested":
+		return c.handleAffectedPackagesRequested(ctx, message)
 	default:
 		return fmt.Errorf("unknown topic: %s", topic)
 	}
@@ -38,11 +46,46 @@ func (c *WorkerHandler) handleTaskAssigned(ctx context.Context, message kafka.Me
 		return fmt.Errorf("failed to unmarshal TaskAssignedEvent: %w", err)
 	}
 
-	// Execute the task
-	if err := c.workerService.ExecuteTask(ctx, event.TaskId); err != nil {
-		return fmt.Errorf("failed to execute task: %w", err)
+	if event.Subtask == nil {
+		return fmt.Errorf("TaskAssignedEvent for task %s has no subtask", event.TaskId)
 	}
 
-	log.Printf("Task %s executed successfully", event.TaskId)
-	return nil
+	subTask := &model.SubTask{
+		ID:       event.Subtask.Id,
+		ParentID: event.Subtask.ParentId,
+		Name:     event.Subtask.Name,
+		Input:    event.Subtask.Input,
+		WorkerID: event.WorkerId,
+		OwnerID:  event.Subtask.OwnerId,
+		TenantID: event.Subtask.TenantId,
+	}
+
+	// Queue the subtask. The message is committed once the subtask is queued,
+	// since a queued subtask always reports its result, even if the worker
+	// shuts down before it ran.
+	return c.pool.Submit(ctx, "subtask "+subTask.ID, func(ctx context.Context) error {
+		if err := c.workerService.ExecuteTask(ctx, subTask); err != nil {
+			return fmt.Errorf("failed to execute task: %w", err)
+		}
+
+		log.Printf("Subtask %s of task %s executed successfully", subTask.ID, event.TaskId)
+		return nil
+	})
+}
+
+// handleAffectedPackagesRequested handles an AffectedPackagesRequestedEvent
+func (c *WorkerHandler) handleAffectedPackagesRequested(ctx context.Context, message kafka.Message) error {
+	var event pb.AffectedPackagesRequestedEvent
+	if err := json.Unmarshal(message.Value, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal AffectedPackagesRequestedEvent: %w", err)
+	}
+
+	return c.pool.Submit(ctx, "affected packages of task "+event.TaskId, func(ctx context.Context) error {
+		if err := c.workerService.ComputeAffectedPackages(ctx, event.TaskId, event.Input); err != nil {
+			return fmt.Errorf("failed to compute affected packages: %w", err)
+		}
+
+		log.Printf("Affected packages of task %s computed successfully", event.TaskId)
+		return nil
+	})
 }
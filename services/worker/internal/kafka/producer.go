@@ -2,45 +2,85 @@ package kafka
 
 import (
 	"context"
+	"distributed-analyzer/libs/kafka"
+	"distributed-analyzer/libs/model"
 	pb "distributed-analyzer/libs/proto/kafka"
-	"encoding/json"
-	"github.com/segmentio/kafka-go"
+	resultpb "distributed-analyzer/libs/proto/result"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"time"
 )
 
 // WorkerProducer is a Kafka producer for worker events
 type WorkerProducer struct {
-	writer *kafka.Writer
+	*kafka.Producer
 }
 
 // NewWorkerProducer creates a new WorkerProducer
-func NewWorkerProducer(brokers []string) *WorkerProducer {
-	writer := &kafka.Writer{
-		Addr:     kafka.TCP(brokers...),
-		Balancer: &kafka.LeastBytes{},
-	}
+func NewWorkerProducer(pr *kafka.Producer) *WorkerProducer {
 	return &WorkerProducer{
-		writer: writer,
+		Producer: pr,
 	}
 }
 
-// Close closes the Kafka writer
-func (p *WorkerProducer) Close() error {
-	return p.writer.Close()
-}
-
 // PublishSubTaskCompleted publishes a SubTaskCompletedEvent to Kafka
-func (p *WorkerProducer) PublishSubTaskCompleted(ctx context.Context, subtaskID string, taskID string, workerID string, result map[string]string) error {
+func (p *WorkerProducer) PublishSubTaskCompleted(ctx context.Context, subTask *model.SubTask, workerID string, result map[string]string, artifacts []model.Artifact) error {
 	event := &pb.SubTaskCompletedEvent{
-		SubtaskId:   subtaskID,
-		TaskId:      taskID,
+		SubtaskId:   subTask.ID,
+		TaskId:      subTask.ParentID,
 		WorkerId:    workerID,
 		Result:      result,
 		CompletedAt: timestamppb.New(time.Now()),
+		Artifacts:   make([]*resultpb.Artifact, len(artifacts)),
+		OwnerId:     subTask.OwnerID,
+		TenantId:    subTask.TenantID,
+		Usage: &pb.ResourceUsage{
+			CpuSeconds:      subTask.Usage.CPUSeconds,
+			MemoryGbSeconds: subTask.Usage.MemoryGBSeconds,
+			MaxMemoryBytes:  subTask.Usage.MaxMemoryBytes,
+		},
+	}
+	for i, artifact := range artifacts {
+		event.Artifacts[i] = &resultpb.Artifact{
+			SubtaskId:   artifact.SubTaskID,
+			Name:        artifact.Name,
+			ContentType: artifact.ContentType,
+			Size:        artifact.Size,
+			Digest:      artifact.Digest,
+			StorageKey:  artifact.StorageKey,
+		}
+	}
+
+	return p.Producer.PublishEvent(ctx, "subtask-completed", subTask.ID, event)
+}
+
+// PublishTaskLog publishes a TaskLogEvent to Kafka, keyed by subtask so the
+// chunks of a subtask stay in order
+func (p *WorkerProducer) PublishTaskLog(ctx context.Context, chunk *model.LogChunk) error {
+	event := &pb.TaskLogEvent{
+		TaskId:    chunk.TaskID,
+		SubtaskId: chunk.SubTaskID,
+		WorkerId:  chunk.WorkerID,
+		Stream:    chunk.Stream,
+		Sequence:  chunk.Sequence,
+		Data:      chunk.Data,
+		WrittenAt: timestamppb.New(chunk.WrittenAt),
 	}
 
-	return p.publishEvent(ctx, "subtask-completed", subtaskID, event)
+	return p.Producer.PublishEvent(ctx, "task-logs", chunk.SubTaskID, event)
+}
+
+// PublishAffectedPackagesComputed publishes an AffectedPackagesComputedEvent to Kafka
+func (p *WorkerProducer) PublishAffectedPackagesComputed(ctx context.Context, taskID string, workerID string, packages []string, files []string, errMsg string) error {
+	event := &pb.AffectedPackagesComputedEvent{
+		TaskId:     taskID,
+		WorkerId:   workerID,
+		Packages:   packages,
+		Files:      files,
+		Error:      errMsg,
+		ComputedAt: timestamppb.New(time.Now()),
+	}
+
+	return p.Producer.PublishEvent(ctx, "affected-packages-computed", taskID, event)
 }
 
 // PublishWorkerStatusChanged publishes a WorkerStatusChangedEvent to Kafka
@@ -52,19 +92,5 @@ func (p *WorkerProducer) PublishWorkerStatusChanged(ctx context.Context, workerI
 		ChangedAt: timestamppb.New(time.Now()),
 	}
 
-	return p.publishEvent(ctx, "worker-status-changed", workerID, event)
-}
-
-// Helper function to publish an event to Kafka
-func (p *WorkerProducer) publishEvent(ctx context.Context, topic, key string, event interface{}) error {
-	value, err := json.Marshal(event)
-	if err != nil {
-		return err
-	}
-
-	return p.writer.WriteMessages(ctx, kafka.Message{
-		Topic: topic,
-		Key:   []byte(key),
-		Value: value,
-	})
+	return p.Producer.PublishEvent(ctx, "worker-status-changed", workerID, event)
 }
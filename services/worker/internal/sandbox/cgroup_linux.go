@@ -0,0 +1,163 @@
+package sandbox
+
+import (
+	"bufio"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// cpuPeriod is the cgroup CPU accounting period in microseconds
+const cpuPeriod = 100000
+
+// enableControllers lets the cgroups below dir limit and account CPU, memory and pids.
+// dir must be a delegated cgroup of a cgroups v2 hierarchy.
+func enableControllers(dir string) error {
+	if err := os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte("+cpu +memory +pids"), 0o644); err != nil {
+		return fmt.Errorf("failed to enable cgroup controllers in %s, is it a delegated cgroups v2 hierarchy: %w", dir, err)
+	}
+	return nil
+}
+
+// cgroup is the cgroups v2 group of a single command
+type cgroup struct {
+	dir string
+	fd  int
+}
+
+// newCgroup creates a cgroup below root that enforces the limits
+func newCgroup(root string, limits Limits) (*cgroup, error) {
+	dir := filepath.Join(root, randomName())
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	cg := &cgroup{dir: dir, fd: -1}
+
+	settings := [][2]string{}
+	if limits.CPUs > 0 {
+		settings = append(settings, [2]string{"cpu.max", fmt.Sprintf("%d %d", limits.CPUs*cpuPeriod, cpuPeriod)})
+	}
+	if limits.MemoryBytes > 0 {
+		settings = append(settings, [2]string{"memory.max", strconv.FormatInt(limits.MemoryBytes, 10)})
+	}
+	if limits.Pids > 0 {
+		settings = append(settings, [2]string{"pids.max", strconv.Itoa(limits.Pids)})
+	}
+	for _, setting := range settings {
+		if err := os.WriteFile(filepath.Join(dir, setting[0]), []byte(setting[1]), 0o644); err != nil {
+			cg.remove()
+			return nil, fmt.Errorf("failed to set %s: %w", setting[0], err)
+		}
+	}
+
+	// Kernels without swap accounting have no swap limit to set
+	if limits.MemoryBytes > 0 {
+		err := os.WriteFile(filepath.Join(dir, "memory.swap.max"), []byte("0"), 0o644)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			cg.remove()
+			return nil, fmt.Errorf("failed to set memory.swap.max: %w", err)
+		}
+	}
+
+	fd, err := syscall.Open(dir, syscall.O_DIRECTORY|syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		cg.remove()
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+	cg.fd = fd
+
+	return cg, nil
+}
+
+// kill kills every process in the cgroup.
+// Kernels before 5.14 lack cgroup.kill, so the processes are signalled one by one.
+func (c *cgroup) kill() {
+	if err := os.WriteFile(filepath.Join(c.dir, "cgroup.kill"), []byte("1"), 0o644); err == nil {
+		return
+	}
+	for _, pid := range c.pids() {
+		syscall.Kill(pid, syscall.SIGKILL)
+	}
+}
+
+// pids lists the processes in the cgroup
+func (c *cgroup) pids() []int {
+	content, err := os.ReadFile(filepath.Join(c.dir, "cgroup.procs"))
+	if err != nil {
+		return nil
+	}
+
+	var pids []int
+	for _, field := range strings.Fields(string(content)) {
+		if pid, err := strconv.Atoi(field); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// oomKilled reports whether the kernel killed a process for exceeding the memory limit
+func (c *cgroup) oomKilled() bool {
+	f, err := os.Open(filepath.Join(c.dir, "memory.events"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, count, _ := strings.Cut(scanner.Text(), " ")
+		if name == "oom_kill" {
+			return count != "0"
+		}
+	}
+	return false
+}
+
+// usage returns the CPU time and the peak memory of every process that ran
+// in the cgroup or in the cgroups below it, including those already removed.
+// Kernels before 5.19 lack memory.peak, so only CPU time is metered on them.
+func (c *cgroup) usage(elapsed time.Duration) model.ResourceUsage {
+	var cpu time.Duration
+	if content, err := os.ReadFile(filepath.Join(c.dir, "cpu.stat")); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			if value, ok := strings.CutPrefix(line, "usage_usec "); ok {
+				usec, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+				cpu = time.Duration(usec) * time.Microsecond
+				break
+			}
+		}
+	}
+
+	var peak int64
+	if content, err := os.ReadFile(filepath.Join(c.dir, "memory.peak")); err == nil {
+		peak, _ = strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	}
+
+	return analysis.CommandUsage(cpu, peak, elapsed)
+}
+
+// remove kills the processes a command left behind and deletes the cgroup.
+// A cgroup can only be deleted once its killed processes have exited.
+func (c *cgroup) remove() {
+	if c.fd >= 0 {
+		syscall.Close(c.fd)
+	}
+	c.kill()
+
+	deadline := time.Now().Add(killWait)
+	for {
+		err := syscall.Rmdir(c.dir)
+		if err == nil || errors.Is(err, syscall.ENOENT) || time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
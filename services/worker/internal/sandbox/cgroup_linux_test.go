@@ -0,0 +1,25 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCgroupUsage(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("usage_usec 2500000\nuser_usec 2000000\nsystem_usec 500000\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "memory.peak"), []byte("1073741824\n"), 0o644)
+
+	usage := (&cgroup{dir: dir, fd: -1}).usage(4 * time.Second)
+	if usage.CPUSeconds != 2.5 || usage.MaxMemoryBytes != 1<<30 || usage.MemoryGBSeconds != 4 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+
+	// Kernels without memory.peak only meter CPU time
+	os.Remove(filepath.Join(dir, "memory.peak"))
+	if usage := (&cgroup{dir: dir, fd: -1}).usage(time.Second); usage.CPUSeconds != 2.5 || usage.MaxMemoryBytes != 0 {
+		t.Errorf("Unexpected usage without memory.peak %+v", usage)
+	}
+}
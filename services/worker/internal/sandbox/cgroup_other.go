@@ -0,0 +1,35 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"distributed-analyzer/libs/model"
+	"errors"
+	"time"
+)
+
+// errNoCgroups is returned where commands would need a cgroup of their own
+var errNoCgroups = errors.New("cgroups are only supported on Linux")
+
+// cgroup is never created on this platform
+type cgroup struct {
+	dir string
+}
+
+// enableControllers fails, since there are no cgroups on this platform
+func enableControllers(dir string) error {
+	return errNoCgroups
+}
+
+// newCgroup fails, since there are no cgroups on this platform
+func newCgroup(root string, limits Limits) (*cgroup, error) {
+	return nil, errNoCgroups
+}
+
+// usage reports nothing, since there are no cgroups on this platform
+func (c *cgroup) usage(elapsed time.Duration) model.ResourceUsage {
+	return model.ResourceUsage{}
+}
+
+// remove does nothing, since there are no cgroups on this platform
+func (c *cgroup) remove() {}
@@ -0,0 +1,205 @@
+package sandbox
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runtimeFailureCode is the exit code of docker and podman run when the container could not be run
+const runtimeFailureCode = 125
+
+// oomExitCode is the exit code of a container whose process was killed by SIGKILL,
+// which is how the kernel ends processes that exceed the memory limit
+const oomExitCode = 137
+
+// forwardedEnv lists the go settings of the worker that containers inherit,
+// so that they use the same module proxy and checksum database
+var forwardedEnv = []string{"GOPROXY", "GOPRIVATE", "GONOPROXY", "GONOSUMDB", "GOSUMDB", "GOINSECURE", "GOFLAGS"}
+
+// cgroupMount is where the cgroups v2 hierarchy is mounted
+const cgroupMount = "/sys/fs/cgroup"
+
+// ContainerExecutor runs each command in a throwaway container of a container runtime.
+// Only the mounts of a command are available in its container, at the same
+// paths, so that paths in the output of commands match the workspace. Tasks
+// running side by side therefore cannot see each other's checkouts.
+//
+// Each container runs below a cgroup of its own, whose accounting outlives
+// the container, so that the usage of the command is metered rather than
+// the usage of the runtime client.
+type ContainerExecutor struct {
+	runtime    string
+	image      string
+	limits     Limits
+	cgroupRoot string
+
+	// globalArgs precede the run command, e.g. to choose the cgroup manager
+	globalArgs []string
+}
+
+// NewContainerExecutor creates a ContainerExecutor using the runtime binary,
+// e.g. docker, whose containers run below cgroupRoot. The cgroup must be
+// delegated to the worker on a cgroups v2 hierarchy, and the runtime must
+// manage cgroups itself rather than through systemd.
+func NewContainerExecutor(runtime, image string, limits Limits, cgroupRoot string) (*ContainerExecutor, error) {
+	if image == "" {
+		return nil, errors.New("container sandbox needs an image")
+	}
+	if _, err := exec.LookPath(runtime); err != nil {
+		return nil, fmt.Errorf("container runtime %s not found: %w", runtime, err)
+	}
+	if !strings.HasPrefix(cgroupRoot, cgroupMount+"/") {
+		return nil, fmt.Errorf("container sandbox needs a cgroup root below %s", cgroupMount)
+	}
+	if err := os.MkdirAll(cgroupRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup root: %w", err)
+	}
+	if err := enableControllers(cgroupRoot); err != nil {
+		return nil, err
+	}
+
+	e := &ContainerExecutor{runtime: runtime, image: image, limits: limits, cgroupRoot: cgroupRoot}
+	if runtime == TypePodman {
+		e.globalArgs = []string{"--cgroup-manager", "cgroupfs"}
+	} else if err := e.checkCgroupDriver(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// checkCgroupDriver makes sure the runtime accepts cgroup paths as parents of containers
+func (e *ContainerExecutor) checkCgroupDriver() error {
+	out, err := exec.Command(e.runtime, "info", "--format", "{{.CgroupDriver}}").Output()
+	if err != nil {
+		return fmt.Errorf("failed to query the cgroup driver of %s: %w", e.runtime, err)
+	}
+	if driver := strings.TrimSpace(string(out)); driver != "cgroupfs" {
+		return fmt.Errorf("%s uses the %s cgroup driver, but metering containers needs cgroupfs", e.runtime, driver)
+	}
+	return nil
+}
+
+// Run runs the command in a new container and removes the container afterwards
+func (e *ContainerExecutor) Run(ctx context.Context, c *analysis.Command) (*analysis.CommandResult, error) {
+	ctx, cancel := e.limits.withTimeout(ctx)
+	defer cancel()
+
+	cg, err := newCgroup(e.cgroupRoot, Limits{})
+	if err != nil {
+		return nil, err
+	}
+	defer cg.remove()
+	if err := enableControllers(cg.dir); err != nil {
+		return nil, err
+	}
+
+	name := randomName()
+	args := append(append([]string(nil), e.globalArgs...), e.runArgs(name, "/"+strings.TrimPrefix(cg.dir, cgroupMount+"/"), c)...)
+	cmd := exec.CommandContext(ctx, e.runtime, args...)
+	cmd.Env = os.Environ()
+	cmd.Cancel = func() error {
+		e.kill(name)
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = killWait
+
+	started := time.Now()
+	res, err := analysis.RunProcess(ctx, cmd, c.Log)
+	analysis.RecordUsage(ctx, cg.usage(time.Since(started)))
+	if ctx.Err() != nil {
+		// The runtime client was killed, which may leave the container behind
+		e.kill(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode == runtimeFailureCode {
+		return nil, fmt.Errorf("%s run: %s", e.runtime, strings.TrimSpace(res.Stderr))
+	}
+	if res.ExitCode == oomExitCode {
+		note := fmt.Sprintf("\nsandbox: killed, possibly after exceeding the memory limit of %d bytes\n", e.limits.MemoryBytes)
+		res.Output += note
+		res.Stderr += note
+		if c.Log != nil {
+			c.Log.WriteLog(model.LogStreamStderr, []byte(note))
+		}
+	}
+	return res, nil
+}
+
+// runArgs returns the arguments of the runtime's run command for a command
+// whose container runs below the cgroup parent
+func (e *ContainerExecutor) runArgs(name, parent string, c *analysis.Command) []string {
+	network := "none"
+	if c.Network {
+		network = "bridge"
+	}
+
+	args := []string{
+		"run", "--rm", "--init",
+		"--name", name,
+		"--cgroup-parent", parent,
+		"--network", network,
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--ulimit", "core=0",
+	}
+	for _, mount := range c.Mounts {
+		volume := mount.Dir + ":" + mount.Dir
+		if mount.ReadOnly {
+			volume += ":ro"
+		}
+		args = append(args, "--volume", volume)
+	}
+	if e.limits.CPUs > 0 {
+		args = append(args, "--cpus", strconv.Itoa(e.limits.CPUs))
+	}
+	if e.limits.MemoryBytes > 0 {
+		memory := strconv.FormatInt(e.limits.MemoryBytes, 10)
+		args = append(args, "--memory", memory, "--memory-swap", memory)
+	}
+	if e.limits.Pids > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(e.limits.Pids))
+	}
+	if e.limits.OpenFiles > 0 {
+		args = append(args, "--ulimit", fmt.Sprintf("nofile=%d:%d", e.limits.OpenFiles, e.limits.OpenFiles))
+	}
+	if c.Dir != "" {
+		args = append(args, "--workdir", c.Dir)
+	}
+	for _, kv := range e.env(c.Env) {
+		args = append(args, "--env", kv)
+	}
+
+	args = append(args, e.image, c.Name)
+	return append(args, c.Args...)
+}
+
+// env returns the environment of a container: the forwarded go settings
+// of the worker and the command's own variables
+func (e *ContainerExecutor) env(env []string) []string {
+	result := []string{"HOME=/tmp"}
+	for _, name := range forwardedEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			result = append(result, name+"="+value)
+		}
+	}
+	return append(result, env...)
+}
+
+// kill stops a container and removes it
+func (e *ContainerExecutor) kill(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), killWait)
+	defer cancel()
+
+	exec.CommandContext(ctx, e.runtime, "rm", "--force", name).Run()
+}
@@ -0,0 +1,108 @@
+package sandbox
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ProcessExecutor runs each command as a child process in its own cgroup,
+// user and network namespace, with resource limits on every process.
+// Cancelling a command kills all processes it started. It does not isolate
+// the file system, so commands are not limited to their mounts.
+type ProcessExecutor struct {
+	limits     Limits
+	cgroupRoot string
+}
+
+// NewProcessExecutor creates a ProcessExecutor whose commands get cgroups below cgroupRoot.
+// The cgroup must be delegated to the worker on a cgroups v2 hierarchy.
+func NewProcessExecutor(limits Limits, cgroupRoot string) (*ProcessExecutor, error) {
+	if cgroupRoot == "" {
+		return nil, errors.New("process sandbox needs a cgroup root")
+	}
+	if err := os.MkdirAll(cgroupRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup root: %w", err)
+	}
+	if err := enableControllers(cgroupRoot); err != nil {
+		return nil, err
+	}
+
+	return &ProcessExecutor{limits: limits, cgroupRoot: cgroupRoot}, nil
+}
+
+// Run runs the command and removes its cgroup, with every process left in it
+func (e *ProcessExecutor) Run(ctx context.Context, c *analysis.Command) (*analysis.CommandResult, error) {
+	ctx, cancel := e.limits.withTimeout(ctx)
+	defer cancel()
+
+	cg, err := newCgroup(e.cgroupRoot, e.limits)
+	if err != nil {
+		return nil, err
+	}
+	defer cg.remove()
+
+	// The shell applies the per-process limits and replaces itself with the command
+	args := append([]string{"-c", e.limitScript(), "sandbox", c.Name}, c.Args...)
+	cmd := exec.CommandContext(ctx, "/bin/sh", args...)
+	cmd.Dir = c.Dir
+	cmd.Env = commandEnv(c.Env)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:     true,
+		Pdeathsig:   syscall.SIGKILL,
+		UseCgroupFD: true,
+		CgroupFD:    cg.fd,
+	}
+	if !c.Network {
+		isolateNetwork(cmd.SysProcAttr)
+	}
+	cmd.Cancel = func() error {
+		cg.kill()
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = killWait
+
+	started := time.Now()
+	res, err := analysis.RunProcess(ctx, cmd, c.Log)
+	analysis.RecordUsage(ctx, cg.usage(time.Since(started)))
+	if err != nil {
+		return nil, err
+	}
+	if cg.oomKilled() {
+		note := fmt.Sprintf("\nsandbox: killed after exceeding the memory limit of %d bytes\n", e.limits.MemoryBytes)
+		res.Output += note
+		res.Stderr += note
+		if c.Log != nil {
+			c.Log.WriteLog(model.LogStreamStderr, []byte(note))
+		}
+	}
+	return res, nil
+}
+
+// limitScript returns the shell commands that set the resource limits of each process.
+// Loopback is brought up on a best-effort basis so that tests can listen on localhost.
+func (e *ProcessExecutor) limitScript() string {
+	script := []string{"ulimit -c 0"}
+	if e.limits.OpenFiles > 0 {
+		script = append(script, fmt.Sprintf("ulimit -n %d", e.limits.OpenFiles))
+	}
+	script = append(script, "ip link set lo up 2>/dev/null", `exec "$@"`)
+	return strings.Join(script, "; ")
+}
+
+// isolateNetwork gives the process a network namespace of its own, which has
+// only a loopback device. The user namespace maps root in it to the worker's
+// user, so that an unprivileged worker may create it.
+func isolateNetwork(attr *syscall.SysProcAttr) {
+	attr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}}
+	attr.GidMappingsEnableSetgroups = false
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"errors"
+)
+
+// ProcessExecutor needs cgroups and namespaces, which only Linux provides
+type ProcessExecutor struct{}
+
+// NewProcessExecutor fails, since the process sandbox cannot isolate commands on this platform
+func NewProcessExecutor(limits Limits, cgroupRoot string) (*ProcessExecutor, error) {
+	return nil, errors.New("process sandbox is only supported on Linux")
+}
+
+// Run never runs the command
+func (e *ProcessExecutor) Run(ctx context.Context, c *analysis.Command) (*analysis.CommandResult, error) {
+	return nil, errors.New("process sandbox is only supported on Linux")
+}
@@ -0,0 +1,138 @@
+package sandbox
+
+import (
+	"context"
+	"crypto/rand"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"distributed-analyzer/services/worker/internal/config"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IsolatesFiles reports whether the sandbox a configuration asks for limits
+// commands to their mounts, so that they cannot write the rest of the worker
+func IsolatesFiles(cfg config.SandboxConfig) bool {
+	return cfg.Enabled && (cfg.Type == TypeDocker || cfg.Type == TypePodman)
+}
+
+// Sandbox types of the worker configuration
+const (
+	TypeProcess = "process"
+	TypeDocker  = "docker"
+	TypePodman  = "podman"
+)
+
+// killWait is how long a killed command may take to release its output
+const killWait = 5 * time.Second
+
+// Limits are the resources a single command may use
+type Limits struct {
+	// Timeout bounds the run time of a command; zero leaves it to the context
+	Timeout time.Duration
+
+	// CPUs is the number of CPUs worth of time a command may use
+	CPUs int
+
+	// MemoryBytes bounds the memory of all processes of a command, without swap
+	MemoryBytes int64
+
+	// Pids bounds the number of processes and threads of a command
+	Pids int
+
+	// OpenFiles bounds the file descriptors of each process
+	OpenFiles int
+}
+
+// New creates the executor the sandbox configuration asks for. A disabled
+// sandbox runs commands as plain child processes.
+func New(cfg config.SandboxConfig, timeout time.Duration) (analysis.Executor, error) {
+	if !cfg.Enabled {
+		return analysis.LocalExecutor{}, nil
+	}
+
+	memory, err := ParseSize(cfg.Resources.MemoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory limit: %w", err)
+	}
+
+	limits := Limits{
+		Timeout:     timeout,
+		CPUs:        cfg.Resources.CPULimit,
+		MemoryBytes: memory,
+		Pids:        cfg.Resources.PidsLimit,
+		OpenFiles:   cfg.Resources.OpenFiles,
+	}
+
+	switch cfg.Type {
+	case TypeProcess:
+		return NewProcessExecutor(limits, cfg.CgroupRoot)
+	case TypeDocker, TypePodman:
+		return NewContainerExecutor(cfg.Type, cfg.Image, limits, cfg.CgroupRoot)
+	default:
+		return nil, fmt.Errorf("unknown sandbox type %q", cfg.Type)
+	}
+}
+
+// ParseSize parses a memory size such as 512MB, 2GiB or a plain number of bytes
+func ParseSize(size string) (int64, error) {
+	size = strings.TrimSpace(strings.ToUpper(size))
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1},
+	}
+
+	factor := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(size, unit.suffix) {
+			size, factor = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix)), unit.factor
+			break
+		}
+	}
+
+	value, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("malformed size %q", size)
+	}
+	return value * factor, nil
+}
+
+// commandEnv returns the environment of a sandboxed command: the go and
+// locale settings of the worker plus the command's own variables.
+// Anything else, such as credentials of the worker, is withheld.
+func commandEnv(env []string) []string {
+	var result []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		switch {
+		case strings.HasPrefix(name, "GO"), strings.HasPrefix(name, "LC_"):
+		case name == "PATH", name == "HOME", name == "TMPDIR", name == "LANG", name == "USER":
+		default:
+			continue
+		}
+		result = append(result, kv)
+	}
+	return append(result, env...)
+}
+
+// withTimeout bounds ctx by the command timeout of the limits
+func (l Limits) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, l.Timeout)
+}
+
+// randomName returns a name for the cgroup or container of a command
+func randomName() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "analyzer-" + hex.EncodeToString(b)
+}
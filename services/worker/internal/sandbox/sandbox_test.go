@@ -0,0 +1,64 @@
+package sandbox
+
+import (
+	"distributed-analyzer/services/worker/internal/analysis"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	sizes := map[string]int64{
+		"512MB":  512 * 1000 * 1000,
+		"512MiB": 512 << 20,
+		"2g":     2 << 30,
+		"4096":   4096,
+		" 1 GB ": 1000 * 1000 * 1000,
+	}
+	for size, expected := range sizes {
+		got, err := ParseSize(size)
+		if err != nil || got != expected {
+			t.Errorf("ParseSize(%q) = %d, %v, expected %d", size, got, err, expected)
+		}
+	}
+
+	for _, size := range []string{"", "MB", "-1MB", "1.5GB", "1TB"} {
+		if _, err := ParseSize(size); err == nil {
+			t.Errorf("Expected ParseSize(%q) to fail", size)
+		}
+	}
+}
+
+func TestContainerRunArgs(t *testing.T) {
+	e := &ContainerExecutor{
+		runtime: "docker",
+		image:   "golang:1.24-alpine",
+		limits:  Limits{CPUs: 2, MemoryBytes: 1 << 30, Pids: 256},
+	}
+
+	args := strings.Join(e.runArgs("analyzer-1", "/distributed-analyzer/analyzer-2", &analysis.Command{
+		Dir:  "/tmp/worker/task-1-0",
+		Env:  []string{"GOOS=linux"},
+		Name: "go",
+		Args: []string{"test", "./..."},
+		Mounts: []analysis.Mount{
+			{Dir: "/tmp/worker/task-1-0"},
+			{Dir: "/tmp/worker-cache/build/tenant-a"},
+			{Dir: "/tmp/worker-cache/mod", ReadOnly: true},
+		},
+	}), " ")
+
+	for _, expected := range []string{
+		"--cgroup-parent /distributed-analyzer/analyzer-2", "--network none", "--cpus 2", "--memory 1073741824 --memory-swap 1073741824", "--pids-limit 256",
+		"--volume /tmp/worker/task-1-0:/tmp/worker/task-1-0 --volume /tmp/worker-cache/build/tenant-a:/tmp/worker-cache/build/tenant-a --volume /tmp/worker-cache/mod:/tmp/worker-cache/mod:ro", "--workdir /tmp/worker/task-1-0", "--env GOOS=linux",
+	} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("Expected %q in run arguments %q", expected, args)
+		}
+	}
+	if strings.Contains(args, "--volume /tmp/worker:") {
+		t.Errorf("Expected only the workspace of the subtask to be mounted, got %q", args)
+	}
+	if !strings.HasSuffix(args, "golang:1.24-alpine go test ./...") {
+		t.Errorf("Expected the command after the image, got %q", args)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// artifactPrefix is the prefix of the keys of artifacts in the object store
+const artifactPrefix = "artifacts/"
+
+// outputArtifact is the artifact holding the full output of a subtask
+const outputArtifact = "output.log"
+
+// maxInlineOutputBytes bounds the output kept in the result of a subtask once
+// the full output is available as an artifact
+const maxInlineOutputBytes = 64 << 10
+
+// ResultArtifactSuffix is appended to a result key to name the artifact that
+// holds its value once the value is too large to be sent inline
+const ResultArtifactSuffix = ".artifact"
+
+// artifactBackedKeys maps the result keys the result service parses to the
+// artifact holding their full value. Unlike the output, such values cannot be
+// cut down, so they are left out of the result once they are too large.
+var artifactBackedKeys = map[string]string{
+	analysis.CoverProfileKey:    analysis.CoverProfileArtifact,
+	analysis.BenchmarkOutputKey: outputArtifact,
+}
+
+// artifactUploadTimeout bounds the upload of the artifacts of a subtask. The
+// upload has its own deadline, as the subtask may have used up its timeout.
+const artifactUploadTimeout = time.Minute
+
+// ArtifactKey returns the key of an artifact of a subtask in the object store
+func ArtifactKey(taskID, subtaskID, name string) string {
+	return artifactPrefix + taskID + "/" + subtaskID + "/" + name
+}
+
+// ArtifactUploader uploads the files subtasks produce to the object store, so
+// that large outputs do not travel in the result
+type ArtifactUploader struct {
+	store   analysis.ObjectStore
+	maxSize int64
+}
+
+// NewArtifactUploader creates a new ArtifactUploader that skips files larger than maxSize
+func NewArtifactUploader(store analysis.ObjectStore, maxSize int64) *ArtifactUploader {
+	return &ArtifactUploader{store: store, maxSize: maxSize}
+}
+
+// Upload uploads the artifacts of the workspace together with the full output
+// of the subtask, and cuts the output in the result down to its end. Values of
+// artifactBackedKeys that are too large are replaced by the name of their artifact.
+// Artifacts that fail to upload are skipped, as the result is still useful without them.
+func (u *ArtifactUploader) Upload(ctx context.Context, subTask *model.SubTask, ws *analysis.Workspace, result map[string]string) []model.Artifact {
+	output := result[analysis.ResultOutputKey]
+	if output != "" {
+		if err := addOutputArtifact(ws, output); err != nil {
+			log.Printf("Failed to write output of subtask %s: %v", subTask.ID, err)
+		}
+	}
+
+	var artifacts []model.Artifact
+	uploaded := make(map[string]bool)
+	for _, file := range ws.Artifacts {
+		artifact, err := u.upload(ctx, subTask, file)
+		if err != nil {
+			log.Printf("Failed to upload artifact %s of subtask %s: %v", file.Name, subTask.ID, err)
+			continue
+		}
+		artifacts = append(artifacts, *artifact)
+		uploaded[file.Name] = true
+
+		if file.Name == outputArtifact && len(output) > maxInlineOutputBytes {
+			result[analysis.ResultOutputKey] = fmt.Sprintf("[%d bytes omitted, see artifact %s]\n", len(output)-maxInlineOutputBytes, outputArtifact) +
+				output[len(output)-maxInlineOutputBytes:]
+		}
+	}
+
+	for key, name := range artifactBackedKeys {
+		if len(result[key]) > maxInlineOutputBytes && uploaded[name] {
+			delete(result, key)
+			result[key+ResultArtifactSuffix] = name
+		}
+	}
+	return artifacts
+}
+
+// upload uploads a single artifact
+func (u *ArtifactUploader) upload(ctx context.Context, subTask *model.SubTask, file analysis.Artifact) (*model.Artifact, error) {
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > u.maxSize {
+		return nil, fmt.Errorf("%d bytes exceed the limit of %d bytes", info.Size(), u.maxSize)
+	}
+
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ArtifactKey(subTask.ParentID, subTask.ID, file.Name)
+	if err := u.store.Put(ctx, key, content); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	return &model.Artifact{
+		SubTaskID:   subTask.ID,
+		Name:        file.Name,
+		ContentType: file.ContentType,
+		Size:        int64(len(content)),
+		Digest:      "sha256:" + hex.EncodeToString(sum[:]),
+		StorageKey:  key,
+	}, nil
+}
+
+// addOutputArtifact writes the output of a subtask to the artifact directory and records it
+func addOutputArtifact(ws *analysis.Workspace, output string) error {
+	dir, err := ws.ArtifactDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, outputArtifact)
+	if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+		return err
+	}
+	ws.AddArtifact(outputArtifact, analysis.ContentTypeText, path)
+	return nil
+}
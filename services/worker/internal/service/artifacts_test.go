@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memoryStore is an in-memory object store
+type memoryStore map[string][]byte
+
+func (s memoryStore) List(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (s memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s[key], nil
+}
+
+func (s memoryStore) Put(ctx context.Context, key string, content []byte) error {
+	s[key] = content
+	return nil
+}
+
+func TestArtifactUploaderUpload(t *testing.T) {
+	ws := &analysis.Workspace{Dir: t.TempDir()}
+	binary := filepath.Join(ws.Dir, "server")
+	if err := os.WriteFile(binary, []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ws.AddArtifact("bin/server", analysis.ContentTypeBinary, binary)
+	large := filepath.Join(ws.Dir, "large")
+	if err := os.WriteFile(large, make([]byte, 2*maxInlineOutputBytes+1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ws.AddArtifact("large", analysis.ContentTypeBinary, large)
+
+	store := memoryStore{}
+	uploader := NewArtifactUploader(store, 2*maxInlineOutputBytes)
+	output := strings.Repeat("x", maxInlineOutputBytes) + "tail"
+	benchmarks := strings.Repeat("BenchmarkEncode-8 1000 1200 ns/op\n", maxInlineOutputBytes/32)
+	result := map[string]string{analysis.ResultOutputKey: output, analysis.BenchmarkOutputKey: benchmarks}
+
+	artifacts := uploader.Upload(context.Background(), &model.SubTask{ID: "task-1-0", ParentID: "task-1"}, ws, result)
+
+	if len(artifacts) != 2 {
+		t.Fatalf("Expected the binary and the output to be uploaded, got %+v", artifacts)
+	}
+	if artifacts[0].Name != "bin/server" || artifacts[0].StorageKey != "artifacts/task-1/task-1-0/bin/server" || artifacts[0].Size != 6 {
+		t.Errorf("Unexpected binary artifact %+v", artifacts[0])
+	}
+	if !strings.HasPrefix(artifacts[0].Digest, "sha256:") {
+		t.Errorf("Unexpected digest %s", artifacts[0].Digest)
+	}
+	if artifacts[1].Name != outputArtifact || string(store[artifacts[1].StorageKey]) != output {
+		t.Errorf("Expected the full output to be uploaded, got %+v", artifacts[1])
+	}
+
+	inline := result[analysis.ResultOutputKey]
+	if !strings.HasPrefix(inline, "[4 bytes omitted") || !strings.HasSuffix(inline, "tail") {
+		t.Errorf("Expected the inline output to be cut down to its end, got %q", inline[:40])
+	}
+	if _, ok := result[analysis.BenchmarkOutputKey]; ok || result[analysis.BenchmarkOutputKey+ResultArtifactSuffix] != outputArtifact {
+		t.Errorf("Expected the benchmark output to point to %s, got %q", outputArtifact, result[analysis.BenchmarkOutputKey+ResultArtifactSuffix])
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// logFlushInterval is how often the output of a running subtask is published
+const logFlushInterval = 500 * time.Millisecond
+
+// maxLogChunkBytes bounds the data of a published log chunk
+const maxLogChunkBytes = 32 << 10
+
+// maxPendingLogBytes bounds the output waiting to be published. Output beyond
+// it is dropped, so that a slow broker never stalls the commands.
+const maxPendingLogBytes = 4 << 20
+
+// logStreamer publishes the output of the commands of a subtask while it runs,
+// in chunks numbered in the order they were written
+type logStreamer struct {
+	ctx       context.Context
+	publisher EventPublisher
+	taskID    string
+	subTaskID string
+	workerID  string
+
+	pending      []*model.LogChunk
+	pendingBytes int
+	dropped      int
+	sequence     int64
+
+	stop chan struct{}
+	done chan struct{}
+	mu   sync.Mutex
+}
+
+// newLogStreamer creates a logStreamer for a subtask and starts publishing.
+// Publishing outlives the cancellation of ctx, so the output of a subtask
+// that timed out is still published.
+func newLogStreamer(ctx context.Context, publisher EventPublisher, workerID string, subTask *model.SubTask) *logStreamer {
+	l := &logStreamer{
+		ctx:       context.WithoutCancel(ctx),
+		publisher: publisher,
+		taskID:    subTask.ParentID,
+		subTaskID: subTask.ID,
+		workerID:  workerID,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// WriteLog queues output for publishing, appending it to the last queued
+// chunk while that one is of the same stream and has room
+func (l *logStreamer) WriteLog(stream string, p []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pendingBytes+len(p) > maxPendingLogBytes {
+		l.dropped += len(p)
+		return
+	}
+	l.pendingBytes += len(p)
+
+	for len(p) > 0 {
+		var last *model.LogChunk
+		if n := len(l.pending); n > 0 {
+			last = l.pending[n-1]
+		}
+		if last == nil || last.Stream != stream || len(last.Data) >= maxLogChunkBytes {
+			last = l.chunk(stream)
+			l.pending = append(l.pending, last)
+		}
+
+		n := min(len(p), maxLogChunkBytes-len(last.Data))
+		last.Data = append(last.Data, p[:n]...)
+		p = p[n:]
+	}
+}
+
+// Close publishes the remaining output and stops publishing
+func (l *logStreamer) Close() {
+	close(l.stop)
+	<-l.done
+}
+
+// run publishes the queued output at the flush interval until stopped
+func (l *logStreamer) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.flush()
+		case <-l.stop:
+			l.flush()
+			return
+		}
+	}
+}
+
+// flush numbers and publishes the queued chunks, followed by a note if output was dropped
+func (l *logStreamer) flush() {
+	l.mu.Lock()
+	chunks := l.pending
+	l.pending = nil
+	l.pendingBytes = 0
+	if l.dropped > 0 {
+		note := l.chunk(model.LogStreamStderr)
+		note.Data = []byte(fmt.Sprintf("\n[%d bytes of output dropped]\n", l.dropped))
+		chunks = append(chunks, note)
+		l.dropped = 0
+	}
+	for _, chunk := range chunks {
+		l.sequence++
+		chunk.Sequence = l.sequence
+	}
+	l.mu.Unlock()
+
+	for _, chunk := range chunks {
+		if err := l.publisher.PublishTaskLog(l.ctx, chunk); err != nil {
+			log.Printf("Failed to publish output of subtask %s: %v", l.subTaskID, err)
+			return
+		}
+	}
+}
+
+// chunk creates an empty chunk of the subtask's output
+func (l *logStreamer) chunk(stream string) *model.LogChunk {
+	return &model.LogChunk{
+		TaskID:    l.taskID,
+		SubTaskID: l.subTaskID,
+		WorkerID:  l.workerID,
+		Stream:    stream,
+		WrittenAt: time.Now(),
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"distributed-analyzer/libs/model"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logPublisher records the published log chunks
+type logPublisher struct {
+	EventPublisher
+
+	chunks []*model.LogChunk
+	mu     sync.Mutex
+}
+
+func (p *logPublisher) PublishTaskLog(ctx context.Context, chunk *model.LogChunk) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.chunks = append(p.chunks, chunk)
+	return nil
+}
+
+func TestLogStreamerChunksOutput(t *testing.T) {
+	publisher := &logPublisher{}
+	logs := newLogStreamer(context.Background(), publisher, "worker-1", &model.SubTask{ID: "task-1-0", ParentID: "task-1"})
+
+	logs.WriteLog(model.LogStreamStdout, []byte("ok  \texample.com/a\n"))
+	logs.WriteLog(model.LogStreamStdout, bytes.Repeat([]byte("x"), maxLogChunkBytes))
+	logs.WriteLog(model.LogStreamStderr, []byte("FAIL\n"))
+	logs.Close()
+
+	var streams []string
+	var stdout bytes.Buffer
+	for i, chunk := range publisher.chunks {
+		if chunk.Sequence != int64(i+1) {
+			t.Errorf("Expected chunk %d to have sequence %d, got %d", i, i+1, chunk.Sequence)
+		}
+		if chunk.TaskID != "task-1" || chunk.SubTaskID != "task-1-0" || chunk.WorkerID != "worker-1" {
+			t.Errorf("Unexpected chunk origin %+v", chunk)
+		}
+		if len(chunk.Data) > maxLogChunkBytes {
+			t.Errorf("Chunk %d holds %d bytes", i, len(chunk.Data))
+		}
+		streams = append(streams, chunk.Stream)
+		if chunk.Stream == model.LogStreamStdout {
+			stdout.Write(chunk.Data)
+		}
+	}
+
+	if got := strings.Join(streams, ","); got != "stdout,stdout,stderr" {
+		t.Errorf("Unexpected chunk streams %s", got)
+	}
+	if stdout.Len() != len("ok  \texample.com/a\n")+maxLogChunkBytes {
+		t.Errorf("Expected all of stdout to be published, got %d bytes", stdout.Len())
+	}
+}
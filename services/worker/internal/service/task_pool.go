@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPoolStopped is returned when a task is submitted to a stopped pool
+var ErrPoolStopped = errors.New("task pool stopped")
+
+// reportTimeout bounds a single load report
+const reportTimeout = 5 * time.Second
+
+// LoadFunc reports the running and queued tasks of a pool with the given capacity
+type LoadFunc func(ctx context.Context, running, queued, capacity int) error
+
+// poolTask is a task waiting in the queue of a TaskPool
+type poolTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// TaskPool runs submitted tasks on a bounded number of goroutines.
+// Tasks wait in a local queue while all goroutines are busy, and Submit
+// blocks once the queue is full. The pool reports its load whenever it
+// changes and at a fixed interval.
+type TaskPool struct {
+	size           int
+	queue          chan poolTask
+	report         LoadFunc
+	reportInterval time.Duration
+
+	running atomic.Int32
+	changed chan struct{}
+
+	// ctx is the context of running tasks, which is cancelled when draining times out
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	workers  sync.WaitGroup
+	reporter sync.WaitGroup
+	stopped  bool
+	mu       sync.RWMutex
+}
+
+// NewTaskPool creates a TaskPool that runs size tasks at a time and queues up to queueSize more
+func NewTaskPool(size, queueSize int, reportInterval time.Duration, report LoadFunc) *TaskPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TaskPool{
+		size:           max(size, 1),
+		queue:          make(chan poolTask, max(queueSize, 0)),
+		report:         report,
+		reportInterval: reportInterval,
+		changed:        make(chan struct{}, 1),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+// Start starts the goroutines that run tasks and report the load
+func (p *TaskPool) Start(ctx context.Context) error {
+	for i := 0; i < p.size; i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+
+	p.reporter.Add(1)
+	go p.reportLoad()
+
+	return nil
+}
+
+// Stop stops accepting tasks and waits for the queued and running tasks to finish.
+// When ctx ends first, running tasks are cancelled and queued tasks still run
+// with the cancelled context, so that they report that they did not complete.
+func (p *TaskPool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return nil
+	}
+	p.stopped = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = fmt.Errorf("cancelled %d running and %d queued tasks: %w", p.running.Load(), len(p.queue), ctx.Err())
+		p.cancel()
+		<-drained
+	}
+
+	p.cancel()
+	close(p.changed)
+	p.reporter.Wait()
+
+	return err
+}
+
+// Name returns the component name for logging and identification
+func (p *TaskPool) Name() string {
+	return "TaskPool"
+}
+
+// Submit queues a task, waiting for room in the queue until ctx ends.
+// The task runs with a context of the pool rather than ctx, so that it
+// outlives the request it came from. Every queued task runs, with a
+// cancelled context once the pool gave up draining.
+func (p *TaskPool) Submit(ctx context.Context, name string, run func(ctx context.Context) error) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.stopped {
+		return ErrPoolStopped
+	}
+
+	select {
+	case p.queue <- poolTask{name: name, run: run}:
+		p.signal()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Load returns the number of running and queued tasks
+func (p *TaskPool) Load() (running, queued int) {
+	return int(p.running.Load()), len(p.queue)
+}
+
+// work runs queued tasks until the queue is closed
+func (p *TaskPool) work() {
+	defer p.workers.Done()
+
+	for task := range p.queue {
+		if p.ctx.Err() != nil {
+			log.Printf("Cancelling %s: worker is shutting down", task.name)
+		}
+
+		p.running.Add(1)
+		p.signal()
+
+		if err := task.run(p.ctx); err != nil {
+			log.Printf("Failed to run %s: %v", task.name, err)
+		}
+
+		p.running.Add(-1)
+		p.signal()
+	}
+}
+
+// signal notes a change of the load without waiting for it to be reported
+func (p *TaskPool) signal() {
+	select {
+	case p.changed <- struct{}{}:
+	default:
+	}
+}
+
+// reportLoad reports the load on every change and at the report interval,
+// and a final empty load once the pool has stopped
+func (p *TaskPool) reportLoad() {
+	defer p.reporter.Done()
+
+	ticker := time.NewTicker(p.reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case _, ok := <-p.changed:
+			if !ok {
+				p.send(0, 0)
+				return
+			}
+		case <-ticker.C:
+		}
+
+		running, queued := p.Load()
+		p.send(running, queued)
+	}
+}
+
+// send reports a load, logging failures since the next report supersedes this one
+func (p *TaskPool) send(running, queued int) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	if err := p.report(ctx, running, queued, p.size); err != nil {
+		log.Printf("Failed to report worker load: %v", err)
+	}
+}
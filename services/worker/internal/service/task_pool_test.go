@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskPoolLimitsConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var reports [][2]int
+	pool := NewTaskPool(2, 10, time.Hour, func(ctx context.Context, running, queued, capacity int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, [2]int{running, queued})
+		return nil
+	})
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+
+	var running, peak, done atomic.Int32
+	release := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		err := pool.Submit(context.Background(), "task", func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			running.Add(-1)
+			done.Add(1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to submit task: %v", err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if r, q := pool.Load(); r != 2 || q != 4 {
+		t.Errorf("Expected 2 running and 4 queued tasks, got %d and %d", r, q)
+	}
+
+	close(release)
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("Failed to drain pool: %v", err)
+	}
+	if done.Load() != 6 || peak.Load() != 2 {
+		t.Errorf("Expected 6 tasks with at most 2 at a time, got %d with %d", done.Load(), peak.Load())
+	}
+
+	mu.Lock()
+	last := reports[len(reports)-1]
+	mu.Unlock()
+	if last != [2]int{0, 0} {
+		t.Errorf("Expected a final empty load report, got %v", last)
+	}
+
+	if err := pool.Submit(context.Background(), "late", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrPoolStopped) {
+		t.Errorf("Expected ErrPoolStopped after Stop, got %v", err)
+	}
+}
+
+func TestTaskPoolCancelsOnDrainTimeout(t *testing.T) {
+	pool := NewTaskPool(1, 10, time.Hour, func(ctx context.Context, running, queued, capacity int) error { return nil })
+	pool.Start(context.Background())
+
+	var ran, cancelled atomic.Int32
+	for i := 0; i < 3; i++ {
+		pool.Submit(context.Background(), "task", func(ctx context.Context) error {
+			ran.Add(1)
+			if ctx.Err() != nil {
+				cancelled.Add(1)
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the drain to time out, got %v", err)
+	}
+	// Queued tasks still run, so that they can report that they were cancelled
+	if ran.Load() != 3 || cancelled.Load() != 2 {
+		t.Errorf("Expected 3 tasks to run, 2 of them cancelled, got %d with %d cancelled", ran.Load(), cancelled.Load())
+	}
+}
@@ -25,8 +25,11 @@ type WorkerManagerService interface {
 
 // WorkerNodeService defines the interface for worker node operations
 type WorkerNodeService interface {
-	// ExecuteTask executes a task on the worker
-	ExecuteTask(ctx context.Context, taskID string) error
+	// ExecuteTask executes a subtask on the worker
+	ExecuteTask(ctx context.Context, subTask *model.SubTask) error
+
+	// ComputeAffectedPackages computes the packages affected by the change of an incremental task
+	ComputeAffectedPackages(ctx context.Context, taskID string, input map[string]string) error
 
 	// LoadModel loads a model required for task execution
 	LoadModel(ctx context.Context, modelName string) error
@@ -34,6 +37,9 @@ type WorkerNodeService interface {
 	// ReportStatus reports the worker's current status
 	ReportStatus(ctx context.Context, status string) error
 
-	// SendResult sends the result of a completed task
-	SendResult(ctx context.Context, taskID string, result map[string]string) error
+	// ReportLoad reports the running and queued tasks of the worker
+	ReportLoad(ctx context.Context, running, queued, capacity int) error
+
+	// SendResult sends the result of a completed subtask with the artifacts it produced
+	SendResult(ctx context.Context, subTask *model.SubTask, result map[string]string, artifacts []model.Artifact) error
 }
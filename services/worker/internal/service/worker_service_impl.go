@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/worker/internal/analysis"
+	"distributed-analyzer/services/worker/internal/cache"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Result keys describing how a subtask ended
+const (
+	ResultStatusKey = "status"
+	ResultErrorKey  = "error"
+)
+
+// Keys of the build matrix cell a subtask runs, which is echoed from the input into the result
+const (
+	inputMatrixCellKey  = "matrix_cell"
+	resultMatrixCellKey = "matrix.cell"
+)
+
+// Subtask outcomes reported under ResultStatusKey
+const (
+	resultStatusSucceeded = "succeeded"
+	resultStatusFailed    = "failed"
+)
+
+// Worker statuses reported to the worker manager
+const (
+	workerStatusIdle = "IDLE"
+	workerStatusBusy = "BUSY"
+)
+
+// publishTimeout bounds publishing the outcome of a task once the worker is shutting down
+const publishTimeout = 10 * time.Second
+
+// toolchainCapability is the capability whose value is the Go toolchain version of the worker,
+// which the scheduler requires of the subtasks of a build matrix pinned to a Go version
+const toolchainCapability = "go"
+
+// ErrWorkerNotRegistered is returned by a LoadReporter when the worker
+// manager does not know the worker, e.g. because it restarted
+var ErrWorkerNotRegistered = errors.New("worker not registered")
+
+// EventPublisher publishes the events a worker emits
+type EventPublisher interface {
+	// PublishSubTaskCompleted publishes a SubTaskCompletedEvent
+	PublishSubTaskCompleted(ctx context.Context, subTask *model.SubTask, workerID string, result map[string]string, artifacts []model.Artifact) error
+
+	// PublishAffectedPackagesComputed publishes an AffectedPackagesComputedEvent
+	PublishAffectedPackagesComputed(ctx context.Context, taskID string, workerID string, packages []string, files []string, errMsg string) error
+
+	// PublishWorkerStatusChanged publishes a WorkerStatusChangedEvent
+	PublishWorkerStatusChanged(ctx context.Context, workerID string, oldStatus string, newStatus string) error
+
+	// PublishTaskLog publishes a TaskLogEvent
+	PublishTaskLog(ctx context.Context, chunk *model.LogChunk) error
+}
+
+// LoadReporter registers a worker with the worker manager and reports its load
+type LoadReporter interface {
+	// Register registers a worker with the capabilities and resources it offers
+	Register(ctx context.Context, workerID string, capabilities []model.Capability, resources []model.Resource) error
+
+	// ReportLoad reports the running and queued tasks of a worker and the
+	// repositories its cache is warm for, or ErrWorkerNotRegistered
+	ReportLoad(ctx context.Context, workerID string, running, queued, capacity int, warm []string) error
+}
+
+// WorkerNodeServiceImpl implements the WorkerNodeService interface
+type WorkerNodeServiceImpl struct {
+	workerID    string
+	toolchain   string
+	workDir     string
+	taskTimeout time.Duration
+	modes       *analysis.Registry
+	executor    analysis.Executor
+	cache       *cache.Cache
+	artifacts   *ArtifactUploader
+	publisher   EventPublisher
+	reporter    LoadReporter
+
+	status string
+	mu     sync.Mutex
+}
+
+// NewWorkerNodeServiceImpl creates a new instance of WorkerNodeServiceImpl
+func NewWorkerNodeServiceImpl(workerID, toolchain, workDir string, taskTimeout time.Duration, modes *analysis.Registry, executor analysis.Executor, cache *cache.Cache, artifacts *ArtifactUploader, publisher EventPublisher, reporter LoadReporter) *WorkerNodeServiceImpl {
+	return &WorkerNodeServiceImpl{
+		workerID:    workerID,
+		toolchain:   toolchain,
+		workDir:     workDir,
+		taskTimeout: taskTimeout,
+		modes:       modes,
+		executor:    executor,
+		cache:       cache,
+		artifacts:   artifacts,
+		publisher:   publisher,
+		reporter:    reporter,
+		status:      workerStatusIdle,
+	}
+}
+
+// ExecuteTask executes a subtask on the worker.
+// Analysis failures are reported as part of the subtask result; an error is
+// only returned when the result could not be published. A subtask whose
+// context already ended is not run but reported as failed.
+func (s *WorkerNodeServiceImpl) ExecuteTask(ctx context.Context, subTask *model.SubTask) error {
+	var result map[string]string
+	var artifacts []model.Artifact
+	err := ctx.Err()
+	if err != nil {
+		err = fmt.Errorf("worker shut down before the subtask ran: %w", err)
+	} else {
+		result, artifacts, err = s.run(ctx, subTask)
+	}
+	if err != nil {
+		log.Printf("Subtask %s failed: %v", subTask.ID, err)
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[analysis.ResultModeKey] = subTask.Input[analysis.InputModeKey]
+		result[ResultStatusKey] = resultStatusFailed
+		result[ResultErrorKey] = err.Error()
+	} else {
+		result[ResultStatusKey] = resultStatusSucceeded
+	}
+
+	if cell, ok := subTask.Input[inputMatrixCellKey]; ok {
+		result[resultMatrixCellKey] = cell
+	}
+
+	return s.SendResult(ctx, subTask, result, artifacts)
+}
+
+// ComputeAffectedPackages runs the go_affected mode for a task and publishes
+// the affected packages, or the reason they could not be computed
+func (s *WorkerNodeServiceImpl) ComputeAffectedPackages(ctx context.Context, taskID string, input map[string]string) error {
+	probeInput := make(map[string]string, len(input)+1)
+	for key, value := range input {
+		probeInput[key] = value
+	}
+	probeInput[analysis.InputModeKey] = (&analysis.AffectedMode{}).Name()
+
+	probe := &model.SubTask{
+		ID:       taskID + "-affected",
+		ParentID: taskID,
+		Input:    probeInput,
+	}
+
+	var packages, files []string
+	err := ctx.Err()
+	if err != nil {
+		err = fmt.Errorf("worker shut down before the affected packages were computed: %w", err)
+	} else {
+		var result map[string]string
+		if result, _, err = s.run(ctx, probe); err == nil {
+			err = decodeAffected(result, &packages, &files)
+		}
+	}
+
+	errMsg := ""
+	if err != nil {
+		log.Printf("Computing affected packages of task %s failed: %v", taskID, err)
+		errMsg = err.Error()
+	}
+
+	// The outcome is published even when the worker is shutting down
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	return s.publisher.PublishAffectedPackagesComputed(ctx, taskID, s.workerID, packages, files, errMsg)
+}
+
+// decodeAffected decodes the affected packages and changed files of a go_affected result
+func decodeAffected(result map[string]string, packages, files *[]string) error {
+	if err := json.Unmarshal([]byte(result[analysis.AffectedPackagesKey]), packages); err != nil {
+		return fmt.Errorf("failed to decode affected packages: %w", err)
+	}
+	if err := json.Unmarshal([]byte(result[analysis.AffectedFilesKey]), files); err != nil {
+		return fmt.Errorf("failed to decode changed files: %w", err)
+	}
+	return nil
+}
+
+// run prepares the workspace of a subtask, runs its analysis mode and uploads
+// the artifacts the mode produced. The resources used by its commands are
+// recorded in the usage of the subtask, whether it succeeded or not.
+func (s *WorkerNodeServiceImpl) run(ctx context.Context, subTask *model.SubTask) (map[string]string, []model.Artifact, error) {
+	ctx, meter := analysis.WithUsageMeter(ctx)
+	defer func() { subTask.Usage = meter.Usage() }()
+
+	mode, err := s.modes.Get(subTask.Input[analysis.InputModeKey])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if toolchain := subTask.Input[analysis.InputGoVersionKey]; toolchain != "" && toolchain != s.toolchain {
+		return nil, nil, fmt.Errorf("subtask needs Go %s but the worker has Go %s", toolchain, s.toolchain)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.taskTimeout)
+	defer cancel()
+
+	ws, err := analysis.PrepareWorkspace(ctx, s.executor, s.workDir, subTask.ID, subTask.Input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare workspace: %w", err)
+	}
+	defer func() {
+		if err := ws.Cleanup(); err != nil {
+			log.Printf("Failed to clean up workspace %s: %v", ws.Dir, err)
+		}
+	}()
+
+	logs := newLogStreamer(ctx, s.publisher, s.workerID, subTask)
+	defer logs.Close()
+	ws.Log = logs
+
+	scope := cacheScope(subTask)
+	ws.Env = append(ws.Env, s.cache.Env(scope)...)
+	ws.Mounts = append(ws.Mounts, s.cache.Mounts(scope)...)
+	release := s.cache.Prepare(ctx, scope, ws.Dir)
+	defer release()
+
+	if err := ws.DownloadModules(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to prepare workspace: %w", err)
+	}
+
+	log.Printf("Running %s for subtask %s of tenant %q in %s", mode.Name(), subTask.ID, subTask.TenantID, ws.Dir)
+	result, err := mode.Run(ctx, ws, subTask.Input)
+
+	// Failed runs keep their artifacts, which help to find out why
+	var artifacts []model.Artifact
+	if s.artifacts != nil && result != nil {
+		uploadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), artifactUploadTimeout)
+		artifacts = s.artifacts.Upload(uploadCtx, subTask, ws, result)
+		cancel()
+	}
+	if err != nil {
+		return result, artifacts, err
+	}
+
+	s.cache.UseRepository(subTask.Input[analysis.InputRepositoryKey])
+	if err := s.cache.Publish(ctx, scope, ws.Dir); err != nil {
+		log.Printf("Failed to publish cache bundle for subtask %s: %v", subTask.ID, err)
+	}
+	return result, artifacts, nil
+}
+
+// cacheScope returns the scope of the build cache of a subtask, so that
+// tenants never share build results
+func cacheScope(subTask *model.SubTask) string {
+	if subTask.TenantID != "" {
+		return "tenant:" + subTask.TenantID
+	}
+	return "user:" + subTask.OwnerID
+}
+
+// LoadModel loads a model required for task execution.
+// Go analyses need no models, so this is a no-op.
+func (s *WorkerNodeServiceImpl) LoadModel(ctx context.Context, modelName string) error {
+	return nil
+}
+
+// ReportStatus reports the worker's current status
+func (s *WorkerNodeServiceImpl) ReportStatus(ctx context.Context, status string) error {
+	s.mu.Lock()
+	oldStatus := s.status
+	s.status = status
+	s.mu.Unlock()
+
+	if oldStatus == status {
+		return nil
+	}
+
+	return s.publisher.PublishWorkerStatusChanged(ctx, s.workerID, oldStatus, status)
+}
+
+// ReportLoad reports the running and queued tasks of the worker and the
+// repositories its cache is warm for to the worker manager.
+// The worker is busy while all of its task slots are taken.
+func (s *WorkerNodeServiceImpl) ReportLoad(ctx context.Context, running, queued, capacity int) error {
+	status := workerStatusIdle
+	if running >= capacity {
+		status = workerStatusBusy
+	}
+	if err := s.ReportStatus(ctx, status); err != nil {
+		log.Printf("Failed to report worker status: %v", err)
+	}
+
+	err := s.reporter.ReportLoad(ctx, s.workerID, running, queued, capacity, s.cache.Repositories())
+	if errors.Is(err, ErrWorkerNotRegistered) {
+		// The worker manager forgot the worker, e.g. because it restarted
+		if err := s.Register(ctx); err != nil {
+			return err
+		}
+		err = s.reporter.ReportLoad(ctx, s.workerID, running, queued, capacity, s.cache.Repositories())
+	}
+	return err
+}
+
+// Register registers the worker with the worker manager, which only assigns
+// subtasks to workers it knows and reports the load of registered workers.
+// The worker offers its Go toolchain, if known, next to the default capability.
+func (s *WorkerNodeServiceImpl) Register(ctx context.Context) error {
+	capabilities := []model.Capability{{Name: "default", Value: "1.0"}}
+	if s.toolchain != "" {
+		capabilities = append(capabilities, model.Capability{Name: toolchainCapability, Value: s.toolchain})
+	}
+	resources := []model.Resource{{Type: "CPU", Value: 1}}
+	if err := s.reporter.Register(ctx, s.workerID, capabilities, resources); err != nil {
+		return fmt.Errorf("failed to register worker %s: %w", s.workerID, err)
+	}
+	log.Printf("Registered worker %s with the worker manager", s.workerID)
+	return nil
+}
+
+// SendResult sends the result of a completed subtask with the artifacts it
+// produced. The result is published even when the worker is shutting down.
+func (s *WorkerNodeServiceImpl) SendResult(ctx context.Context, subTask *model.SubTask, result map[string]string, artifacts []model.Artifact) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	return s.publisher.PublishSubTaskCompleted(ctx, subTask, s.workerID, result, artifacts)
+}
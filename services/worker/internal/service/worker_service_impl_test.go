@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"distributed-analyzer/libs/model"
+	"distributed-analyzer/services/worker/internal/cache"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// managerReporter forgets the worker until it registers
+type managerReporter struct {
+	registered   []model.Capability
+	registration int
+	reports      int
+}
+
+func (r *managerReporter) Register(ctx context.Context, workerID string, capabilities []model.Capability, resources []model.Resource) error {
+	r.registered = capabilities
+	r.registration++
+	return nil
+}
+
+func (r *managerReporter) ReportLoad(ctx context.Context, workerID string, running, queued, capacity int, warm []string) error {
+	if r.registered == nil {
+		return ErrWorkerNotRegistered
+	}
+	r.reports++
+	return nil
+}
+
+// resultPublisher records the published subtask results
+type resultPublisher struct {
+	EventPublisher
+
+	results map[string]map[string]string
+}
+
+func (p *resultPublisher) PublishSubTaskCompleted(ctx context.Context, subTask *model.SubTask, workerID string, result map[string]string, artifacts []model.Artifact) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.results[subTask.ID] = result
+	return nil
+}
+
+func (p *resultPublisher) PublishWorkerStatusChanged(ctx context.Context, workerID string, oldStatus string, newStatus string) error {
+	return nil
+}
+
+func newTestWorkerService(t *testing.T, publisher EventPublisher, reporter LoadReporter) *WorkerNodeServiceImpl {
+	t.Helper()
+	goCache, err := cache.NewCache(t.TempDir(), "1.24", cache.Limits{}, time.Minute, nil, false)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	return NewWorkerNodeServiceImpl("worker-1", "1.24", t.TempDir(), time.Minute, nil, nil, goCache, nil, publisher, reporter)
+}
+
+func TestReportLoadRegistersUnknownWorker(t *testing.T) {
+	reporter := &managerReporter{}
+	s := newTestWorkerService(t, &resultPublisher{}, reporter)
+
+	if err := s.ReportLoad(context.Background(), 1, 0, 2); err != nil {
+		t.Fatalf("Failed to report load: %v", err)
+	}
+	if reporter.registration != 1 || reporter.reports != 1 {
+		t.Errorf("Expected the worker to register and report once, got %d registrations and %d reports", reporter.registration, reporter.reports)
+	}
+	expected := []model.Capability{{Name: "default", Value: "1.0"}, {Name: toolchainCapability, Value: "1.24"}}
+	if !slices.Equal(reporter.registered, expected) {
+		t.Errorf("Expected the worker to offer %+v, got %+v", expected, reporter.registered)
+	}
+
+	if err := s.ReportLoad(context.Background(), 0, 0, 2); err != nil || reporter.registration != 1 {
+		t.Errorf("Expected a registered worker not to register again, got %v with %d registrations", err, reporter.registration)
+	}
+}
+
+func TestExecuteTaskReportsCancelledSubtask(t *testing.T) {
+	publisher := &resultPublisher{results: make(map[string]map[string]string)}
+	s := newTestWorkerService(t, publisher, &managerReporter{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	subTask := &model.SubTask{ID: "task-1-0", ParentID: "task-1", Input: map[string]string{inputMatrixCellKey: "linux"}}
+	if err := s.ExecuteTask(ctx, subTask); err != nil {
+		t.Fatalf("Failed to report cancelled subtask: %v", err)
+	}
+
+	result := publisher.results[subTask.ID]
+	if result[ResultStatusKey] != resultStatusFailed || !strings.Contains(result[ResultErrorKey], "shut down") || result[resultMatrixCellKey] != "linux" {
+		t.Errorf("Expected the subtask to be reported as failed, got %v", result)
+	}
+}